@@ -1,11 +1,11 @@
 package cmd
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 
+	"github.com/kqnd/kernus/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -13,35 +13,23 @@ var server string
 var username string
 var password string
 
-type JSONConfig struct {
-	Server   string `json:"server"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
-func ReadConfigJSONFile(jsonConfig *JSONConfig) {
-	jsonFile, err := os.Open("config.json")
-	if err != nil {
-		fmt.Println(err)
+// ReadConfigJSONFile loads config.json into jsonConfig. A missing file
+// leaves it empty, since most commands need no server; other errors are
+// reported on stderr.
+func ReadConfigJSONFile(jsonConfig *config.JSONConfig) {
+	cfg, err := config.Read(config.DefaultPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "Error: reading config:", err)
 	}
-
-	defer jsonFile.Close()
-
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-	json.Unmarshal(byteValue, &jsonConfig)
+	*jsonConfig = *cfg
 }
 
 func writeConfigJSONFile(server, username, password string) error {
-	jsonFile := JSONConfig{Server: server, Username: username, Password: password}
-	jsonData, err := json.MarshalIndent(jsonFile, "", "  ")
-	if err != nil {
-		return err
-	}
-	err = os.WriteFile("./config.json", jsonData, 0644)
-	if err != nil {
-		return err
-	}
-	return nil
+	return config.Update(config.DefaultPath, func(cfg *config.JSONConfig) {
+		cfg.Server = server
+		cfg.Username = username
+		cfg.Password = password
+	})
 }
 
 func printMissingFlag(flag string) {
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/export"
+	"github.com/kqnd/kernus/internal/metrics"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/spf13/cobra"
+)
+
+var exportListen string
+var exportInterval time.Duration
+var exportContexts []string
+var exportDemo bool
+var exportMachine bool
+
+var exportCommand = &cobra.Command{
+	Use:   "export",
+	Short: "Serve container and host metrics to Prometheus",
+	Long: `Collect container and host metrics at an interval and serve the latest
+collection on /metrics in the Prometheus text exposition format.
+
+Every container sample is labelled with host, id, name, image and
+compose_project. Containers report their state, health and restarts; running
+ones also report CPU, memory, network, block IO and PIDs. Hosts report
+whether they answered and their containers by state, and the host whose
+daemon runs on this machine also reports its CPUs, load, memory and uptime.
+
+A scrape config could be:
+
+  scrape_configs:
+    - job_name: kern
+      static_configs:
+        - targets: ["localhost:9323"]`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runExport(cmd.ErrOrStderr()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCommand)
+	exportCommand.Flags().StringVar(&exportListen, "listen", ":9323", "Address to serve /metrics on")
+	exportCommand.Flags().DurationVar(&exportInterval, "interval", 15*time.Second, "Time between collections")
+	exportCommand.Flags().StringSliceVar(&exportContexts, "context", nil,
+		`Docker context(s) to collect, repeatable or comma separated; "all" for every context`)
+	exportCommand.Flags().BoolVar(&exportDemo, "demo", false,
+		"Collect simulated hosts built from mock data instead of Docker")
+	exportCommand.Flags().BoolVar(&exportMachine, "machine", true,
+		"Report the metrics of this machine for the local Docker host")
+	exportCommand.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+		os.Exit(exitUsage)
+		return err
+	})
+}
+
+func runExport(stderr io.Writer) int {
+	if exportInterval <= 0 {
+		fmt.Fprintln(stderr, "Error: --interval must be positive")
+		return exitUsage
+	}
+
+	var runtimes []docker.ContainerRuntime
+	machineHost := ""
+	if exportDemo {
+		for _, runtime := range fake.DemoRuntimes(models.MockMachines(), "", exportInterval) {
+			runtimes = append(runtimes, runtime)
+		}
+	} else {
+		var err error
+		runtimes, _, err = connectRuntimes(exportContexts, stderr)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return exitUsage
+		}
+		if exportMachine {
+			machineHost = localContext(exportContexts)
+		}
+	}
+	defer closeRuntimes(runtimes)
+	if len(runtimes) == 0 {
+		return exitUnreachable
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	loop := export.NewLoop(metrics.NewCollector(runtimes, machineHost), exportInterval)
+	if err := serveMetrics(ctx, loop, exportListen, stderr); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+	return exitOK
+}
+
+// serveMetrics serves the latest collection of loop on listen while the
+// loop runs, until ctx is done.
+func serveMetrics(ctx context.Context, loop *export.Loop, listen string, stderr io.Writer) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", export.PrometheusHandler(loop.Latest()))
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "kern exporter, metrics are on /metrics")
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+	fmt.Fprintf(stderr, "serving metrics on http://%s/metrics\n", listener.Addr())
+
+	loopCtx, stopLoop := context.WithCancel(ctx)
+	defer stopLoop()
+	looped := make(chan struct{})
+	go func() {
+		defer close(looped)
+		loop.Run(loopCtx, func(err error) {
+			fmt.Fprintln(stderr, "Error:", err)
+		})
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err = <-served:
+	}
+	stopLoop()
+	<-looped
+	if err != nil {
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes of kern ps and kern stats, so scripts can tell an empty result from a failure.
+const (
+	exitOK          = 0
+	exitNoMatch     = 1
+	exitUsage       = 2
+	exitUnreachable = 3
+)
+
+var psAll bool
+var psFilters []string
+var psFormat string
+var psContexts []string
+
+var psCommand = &cobra.Command{
+	Use:   "ps",
+	Short: "List containers without the TUI",
+	Long: `List containers for scripts and CI.
+
+Formats: table (default), wide, json, yaml or a Go template run once per
+container, such as '{{.Name}} {{.Stats.CPU.Usage}}'. JSON, YAML and templates
+use the container fields kernus stores, logs left out.
+
+Filters are key=value pairs and all of them must match:
+  label=<key> or label=<key>=<value>
+  name=<part of the name>
+  id=<id prefix>
+  status=<running|exited|paused|...>
+  health=<healthy|unhealthy|starting|none>
+  host=<context name>
+
+Exit codes:
+  0  containers were listed
+  1  no container matched
+  2  invalid flags, format or filter
+  3  a Docker daemon could not be reached`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runPs(cmd.OutOrStdout(), cmd.ErrOrStderr()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(psCommand)
+	psCommand.Flags().BoolVarP(&psAll, "all", "a", false, "Show all containers, not only running ones")
+	psCommand.Flags().StringArrayVarP(&psFilters, "filter", "f", nil, "Filter containers, such as label=env=prod (repeatable)")
+	psCommand.Flags().StringVar(&psFormat, "format", output.FormatTable, "table, wide, json, yaml or a Go template")
+	psCommand.Flags().StringSliceVar(&psContexts, "context", nil,
+		`Docker context(s) to list, repeatable or comma separated; "all" for every context`)
+	psCommand.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+		os.Exit(exitUsage)
+		return err
+	})
+}
+
+func runPs(stdout, stderr io.Writer) int {
+	// A template is parsed before connecting, so a typo in it is a usage
+	// error even when no daemon can be reached.
+	var tmpl *template.Template
+	switch psFormat {
+	case output.FormatTable, output.FormatWide, output.FormatJSON, output.FormatYAML:
+	default:
+		if !output.IsTemplate(psFormat) {
+			fmt.Fprintf(stderr, "Error: unknown format %q\n", psFormat)
+			return exitUsage
+		}
+		var err error
+		if tmpl, err = output.Template(psFormat); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return exitUsage
+		}
+	}
+
+	filters, err := parseContainerFilters(psFilters)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+
+	runtimes, failed, err := connectRuntimes(psContexts, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+	defer closeRuntimes(runtimes)
+	hosts := len(runtimes) + failed
+
+	containers, listFailed := listContainers(runtimes, stderr)
+	failed += listFailed
+	if len(runtimes) == listFailed {
+		return exitUnreachable
+	}
+	containers = filterContainers(containers, filters)
+
+	if err := writeContainers(stdout, containers, hosts > 1, tmpl); err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+
+	switch {
+	case failed > 0:
+		return exitUnreachable
+	case len(containers) == 0:
+		return exitNoMatch
+	}
+	return exitOK
+}
+
+// listContainers lists every runtime in turn. Runtimes that fail are
+// reported and counted, the others still listed.
+func listContainers(runtimes []docker.ContainerRuntime, stderr io.Writer) ([]models.Container, int) {
+	var containers []models.Container
+	failed := 0
+	for _, runtime := range runtimes {
+		listed, err := runtime.ListContainersWith(docker.ListOptions{OnlyRunning: !psAll, SkipLogs: true})
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %s: %v\n", runtime.Name(), err)
+			failed++
+			continue
+		}
+		for _, c := range listed {
+			c.Name = c.ShortName()
+			containers = append(containers, c)
+		}
+	}
+	return containers, failed
+}
+
+// writeContainers writes containers in psFormat, using tmpl when the format
+// is a template.
+func writeContainers(w io.Writer, containers []models.Container, multiHost bool, tmpl *template.Template) error {
+	switch psFormat {
+	case output.FormatJSON:
+		if containers == nil {
+			containers = []models.Container{}
+		}
+		return output.JSON(w, containers)
+	case output.FormatYAML:
+		if containers == nil {
+			containers = []models.Container{}
+		}
+		return output.YAML(w, containers)
+	case output.FormatTable, output.FormatWide:
+		writeContainerTable(w, containers, psFormat == output.FormatWide, multiHost)
+		return nil
+	}
+
+	// Stopped containers have no stats; zero values keep templates such as
+	// {{.Stats.CPU.Usage}} working for them.
+	for i := range containers {
+		if containers[i].Stats == nil {
+			containers[i].Stats = &models.ContainerStats{}
+		}
+	}
+	return output.Execute(w, tmpl, containers)
+}
+
+func writeContainerTable(w io.Writer, containers []models.Container, wide, multiHost bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	if wide {
+		fmt.Fprintln(tw, "CONTAINER ID\tNAME\tIMAGE\tHOST\tSTATUS\tHEALTH\tRESTARTS\tCPU %\tMEM USAGE / LIMIT\tNET I/O\tCREATED\tPORTS")
+	} else if multiHost {
+		fmt.Fprintln(tw, "CONTAINER ID\tNAME\tIMAGE\tHOST\tSTATUS\tCPU %\tMEM USAGE / LIMIT\tPORTS")
+	} else {
+		fmt.Fprintln(tw, "CONTAINER ID\tNAME\tIMAGE\tSTATUS\tCPU %\tMEM USAGE / LIMIT\tPORTS")
+	}
+
+	for i := range containers {
+		c := &containers[i]
+		cpu, memory, network := "-", "-", "-"
+		if c.Stats != nil {
+			cpu = fmt.Sprintf("%.2f%%", c.Stats.CPU.Usage)
+			memory = models.FormatBytes(c.Stats.Memory.Usage) + " / " + models.FormatBytes(c.Stats.Memory.Limit)
+			network = models.FormatBytes(c.Stats.Network.RxBytes) + " / " + models.FormatBytes(c.Stats.Network.TxBytes)
+		}
+
+		status := c.State
+		if status == "" {
+			status = string(c.Status)
+		}
+
+		var ports []string
+		for _, port := range c.Ports {
+			ports = append(ports, port.String())
+		}
+
+		switch {
+		case wide:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s ago\t%s\n",
+				c.ShortID(), c.Name, c.Image, c.Host, status, c.HealthStatus(), c.RestartCount,
+				cpu, memory, network, c.FormatAge(), strings.Join(ports, ", "))
+		case multiHost:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				c.ShortID(), c.Name, c.Image, c.Host, status, cpu, memory, strings.Join(ports, ", "))
+		default:
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				c.ShortID(), c.Name, c.Image, status, cpu, memory, strings.Join(ports, ", "))
+		}
+	}
+}
+
+type containerFilter struct {
+	key   string
+	value string
+}
+
+func parseContainerFilters(args []string) ([]containerFilter, error) {
+	filters := make([]containerFilter, 0, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid filter %q, expected key=value", arg)
+		}
+		switch key {
+		case "label", "name", "id", "status", "health", "host":
+		default:
+			return nil, fmt.Errorf("unknown filter %q", key)
+		}
+		filters = append(filters, containerFilter{key: key, value: value})
+	}
+	return filters, nil
+}
+
+func (f containerFilter) match(c *models.Container) bool {
+	switch f.key {
+	case "label":
+		key, value, hasValue := strings.Cut(f.value, "=")
+		actual, ok := c.Labels[key]
+		return ok && (!hasValue || actual == value)
+	case "name":
+		return strings.Contains(c.ShortName(), f.value)
+	case "id":
+		return strings.HasPrefix(c.ID, f.value)
+	case "status":
+		return string(c.Status) == f.value
+	case "health":
+		return string(c.HealthStatus()) == f.value
+	case "host":
+		return c.Host == f.value
+	}
+	return false
+}
+
+func filterContainers(containers []models.Container, filters []containerFilter) []models.Container {
+	var matched []models.Container
+	for i := range containers {
+		ok := true
+		for _, filter := range filters {
+			if !filter.match(&containers[i]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			matched = append(matched, containers[i])
+		}
+	}
+	return matched
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/output"
+)
+
+func setPsFlags(t *testing.T, all bool, format string) {
+	t.Helper()
+	oldAll, oldFormat := psAll, psFormat
+	psAll, psFormat = all, format
+	t.Cleanup(func() { psAll, psFormat = oldAll, oldFormat })
+}
+
+func containerNames(containers []models.Container) string {
+	names := make([]string, len(containers))
+	for i := range containers {
+		names[i] = containers[i].Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestListContainersAcrossRuntimes(t *testing.T) {
+	setPsFlags(t, true, output.FormatTable)
+	local, remote := fake.NewRuntime("local"), fake.NewRuntime("remote")
+	runtimes := []docker.ContainerRuntime{local, remote}
+
+	var stderr bytes.Buffer
+	containers, failed := listContainers(runtimes, &stderr)
+	if failed != 0 || stderr.Len() > 0 {
+		t.Fatalf("listContainers() failed %d runtimes: %s", failed, stderr.String())
+	}
+	if want := 2 * len(models.MockContainers()); len(containers) != want {
+		t.Fatalf("listContainers() returned %d containers, want %d", len(containers), want)
+	}
+	for _, c := range containers {
+		if strings.HasPrefix(c.Name, "/") || c.Logs != nil {
+			t.Errorf("%s: name %q and %d log lines, want a short name and no logs", c.ID, c.Name, len(c.Logs))
+		}
+	}
+
+	remote.SetError(errors.New("connection refused"))
+	stderr.Reset()
+	containers, failed = listContainers(runtimes, &stderr)
+	if failed != 1 || len(containers) != len(models.MockContainers()) {
+		t.Errorf("listContainers() with remote down = %d containers, %d failed", len(containers), failed)
+	}
+	if !strings.Contains(stderr.String(), "remote: connection refused") {
+		t.Errorf("stderr = %q, want the failing runtime named", stderr.String())
+	}
+
+	setPsFlags(t, false, output.FormatTable)
+	containers, _ = listContainers([]docker.ContainerRuntime{local}, &stderr)
+	if got := containerNames(containers); got != "nginx-web postgres-db app-worker" {
+		t.Errorf("running containers = %s", got)
+	}
+}
+
+func TestFilterContainers(t *testing.T) {
+	setPsFlags(t, true, output.FormatTable)
+	containers, _ := listContainers([]docker.ContainerRuntime{fake.NewRuntime("local")}, &bytes.Buffer{})
+
+	tests := []struct {
+		filters []string
+		want    string
+	}{
+		{nil, "nginx-web postgres-db redis-cache app-worker monitoring-grafana"},
+		{[]string{"status=running"}, "nginx-web postgres-db app-worker"},
+		{[]string{"health=unhealthy"}, "app-worker"},
+		{[]string{"label=environment"}, "nginx-web app-worker"},
+		{[]string{"label=environment=production"}, "nginx-web"},
+		{[]string{"name=redis"}, "redis-cache"},
+		{[]string{"id=def"}, "postgres-db"},
+		{[]string{"status=running", "health=healthy"}, "nginx-web postgres-db"},
+		{[]string{"host=remote"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.filters, ","), func(t *testing.T) {
+			filters, err := parseContainerFilters(tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := containerNames(filterContainers(containers, filters)); got != tt.want {
+				t.Errorf("matched %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	for _, arg := range []string{"status", "status=", "color=red"} {
+		if _, err := parseContainerFilters([]string{arg}); err == nil {
+			t.Errorf("parseContainerFilters(%q) succeeded", arg)
+		}
+	}
+}
+
+func TestWriteContainers(t *testing.T) {
+	setPsFlags(t, true, output.FormatTable)
+	containers, _ := listContainers([]docker.ContainerRuntime{fake.NewRuntime("local")}, &bytes.Buffer{})
+
+	tests := []struct {
+		format    string
+		multiHost bool
+		check     func(t *testing.T, out string)
+	}{
+		{output.FormatTable, false, func(t *testing.T, out string) {
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			if len(lines) != len(containers)+1 || strings.Contains(lines[0], "HOST") {
+				t.Errorf("table = %q, want a header without HOST and a row per container", out)
+			}
+		}},
+		{output.FormatTable, true, func(t *testing.T, out string) {
+			if !strings.Contains(out, "HOST") || !strings.Contains(out, " local ") {
+				t.Errorf("multi-host table = %q, want the host column", out)
+			}
+		}},
+		{output.FormatWide, false, func(t *testing.T, out string) {
+			if !strings.Contains(out, "RESTARTS") || !strings.Contains(out, "unhealthy") {
+				t.Errorf("wide table = %q, want restarts and health", out)
+			}
+		}},
+		{output.FormatJSON, false, func(t *testing.T, out string) {
+			var decoded []models.Container
+			if err := json.Unmarshal([]byte(out), &decoded); err != nil || len(decoded) != len(containers) {
+				t.Errorf("json = %d containers, %v", len(decoded), err)
+			}
+		}},
+		{"{{.Name}} {{.Stats.CPU.Usage}}", false, func(t *testing.T, out string) {
+			if !strings.Contains(out, "redis-cache 0\n") {
+				t.Errorf("template output = %q, want zero stats for the stopped container", out)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			setPsFlags(t, true, tt.format)
+			var tmpl *template.Template
+			if output.IsTemplate(tt.format) {
+				var err error
+				if tmpl, err = output.Template(tt.format); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var out bytes.Buffer
+			if err := writeContainers(&out, append([]models.Container(nil), containers...), tt.multiHost, tmpl); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, out.String())
+		})
+	}
+}
+
+func TestRunPsRejectsFormatBeforeConnecting(t *testing.T) {
+	// Nothing listens on port 1, so listing is unreachable.
+	t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:1")
+	oldContexts := psContexts
+	psContexts = nil
+	t.Cleanup(func() { psContexts = oldContexts })
+
+	tests := []struct {
+		format string
+		want   int
+	}{
+		{"{{.Nope}", exitUsage},
+		{"csv", exitUsage},
+		{"{{.Name}}", exitUnreachable},
+	}
+	for _, tt := range tests {
+		setPsFlags(t, false, tt.format)
+		var stdout, stderr bytes.Buffer
+		if code := runPs(&stdout, &stderr); code != tt.want {
+			t.Errorf("runPs() with format %q = %d, want %d: %s", tt.format, code, tt.want, stderr.String())
+		}
+		if stdout.Len() > 0 {
+			t.Errorf("runPs() with format %q wrote %q to stdout", tt.format, stdout.String())
+		}
+	}
+}
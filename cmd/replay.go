@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kqnd/kernus/internal/session"
+	"github.com/kqnd/kernus/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+var replaySpeed float64
+
+var replayCommand = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Play back a session recorded with kern see --record",
+	Long: `Play back a recorded session in the monitoring interface, as the hosts
+looked at the time. Container actions are disabled while replaying.
+
+Space pauses and resumes, "," and "." seek back and forward, "-" and "+"
+change the speed. Press ? for every key binding.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		recording, err := session.Open(args[0])
+		if err != nil {
+			fmt.Printf("error opening session: %v\n", err)
+			return
+		}
+		if len(recording.Hosts()) == 0 {
+			fmt.Printf("error opening session: %s recorded no hosts\n", args[0])
+			return
+		}
+
+		player := session.NewPlayer(recording, replaySpeed)
+		app := tui.NewApp(&tui.Config{
+			Server:   "replay of " + args[0],
+			TUI:      CONFIG.TUI,
+			Runtimes: recording.Runtimes(player),
+			Playback: player,
+		})
+		if err := app.Run(); err != nil {
+			fmt.Printf("error replaying session: %v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCommand)
+	replayCommand.Flags().Float64Var(&replaySpeed, "speed", 1, "Playback speed, from 0.25 to 64")
+}
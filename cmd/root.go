@@ -4,12 +4,13 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/kqnd/kernus/internal/config"
 	nundb "github.com/kqnd/nun-db-go"
 	"github.com/spf13/cobra"
 )
 
 var NUNDB_CLIENT *nundb.Client
-var CONFIG *JSONConfig
+var CONFIG *config.JSONConfig
 
 var rootCmd = &cobra.Command{
 	Use:   "kern",
@@ -17,12 +18,12 @@ var rootCmd = &cobra.Command{
 }
 
 func init() {
-	config := &JSONConfig{}
-	ReadConfigJSONFile(config)
-	CONFIG = config
+	jsonConfig := &config.JSONConfig{}
+	ReadConfigJSONFile(jsonConfig)
+	CONFIG = jsonConfig
 
-	if config.Server != "" {
-		client, _ := nundb.NewClient(config.Server, config.Username, config.Password)
+	if jsonConfig.Server != "" {
+		client, _ := nundb.NewClient(jsonConfig.Server, jsonConfig.Username, jsonConfig.Password)
 		NUNDB_CLIENT = client
 	}
 }
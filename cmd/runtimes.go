@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kqnd/kernus/internal/docker"
+)
+
+// connectRuntimes connects to the named Docker contexts, or to the current
+// one without names. Endpoints that fail to connect are reported and
+// counted; an error means the contexts could not be resolved.
+func connectRuntimes(contexts []string, stderr io.Writer) ([]docker.ContainerRuntime, int, error) {
+	endpoints, err := docker.ResolveEndpoints(contexts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var runtimes []docker.ContainerRuntime
+	failed := 0
+	for _, endpoint := range endpoints {
+		client, err := docker.NewEndpointClient(endpoint)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			failed++
+			continue
+		}
+		runtimes = append(runtimes, client)
+	}
+	return runtimes, failed, nil
+}
+
+func closeRuntimes(runtimes []docker.ContainerRuntime) {
+	for _, runtime := range runtimes {
+		runtime.Close()
+	}
+}
+
+// localContext names the first of the contexts whose daemon runs on this
+// machine, so its host metrics can be read, or "" when all are remote.
+func localContext(contexts []string) string {
+	endpoints, err := docker.ResolveEndpoints(contexts)
+	if err != nil {
+		return ""
+	}
+	for _, endpoint := range endpoints {
+		if endpoint.Local() {
+			return endpoint.Name
+		}
+	}
+	return ""
+}
+
+// namedRuntime gives a runtime another host name, such as the machine name
+// of an agent.
+type namedRuntime struct {
+	docker.ContainerRuntime
+	name string
+}
+
+func (r namedRuntime) Name() string {
+	return r.name
+}
@@ -2,12 +2,20 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/session"
 	"github.com/kqnd/kernus/internal/tui"
 	"github.com/spf13/cobra"
 )
 
 var group string
+var dockerContexts []string
+var demo bool
+var seeRecord string
 
 var seeCommand = &cobra.Command{
 	Use:   "see",
@@ -16,25 +24,62 @@ var seeCommand = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Launching monitoring interface...")
 
-		config := &tui.Config{
-			Server: "server",
-			Group:  group,
+		appConfig := &tui.Config{
+			Server:         "server",
+			Group:          group,
+			ConfigPath:     config.DefaultPath,
+			TUI:            CONFIG.TUI,
+			Alerts:         CONFIG.Alerts,
+			DockerContexts: dockerContexts,
 		}
 
-		if NUNDB_CLIENT != nil {
+		if demo {
+			appConfig.Server = "demo"
+			for _, runtime := range fake.DemoRuntimes(models.MockMachines(), group, time.Second) {
+				appConfig.Runtimes = append(appConfig.Runtimes, runtime)
+			}
+		}
+
+		var recorder *session.Recorder
+		if seeRecord != "" {
+			var err error
+			if recorder, err = session.Create(seeRecord); err != nil {
+				fmt.Printf("error starting recording: %v\n", err)
+				return
+			}
+			appConfig.WrapRuntime = recorder.Wrap
+		}
+
+		if NUNDB_CLIENT != nil && !demo {
 			NUNDB_CLIENT.CreateDatabase("kern", "kern-pwd")
 			NUNDB_CLIENT.UseDatabase("kern", "kern-pwd")
 		}
 
-		app := tui.NewApp(config)
-		app.SetNunDBClient(NUNDB_CLIENT)
+		app := tui.NewApp(appConfig)
+		if !demo {
+			app.SetNunDBClient(NUNDB_CLIENT)
+		}
 		if err := app.Run(); err != nil {
 			fmt.Printf("error running monitoring interface: %v\n", err)
 		}
+
+		if recorder != nil {
+			if err := recorder.Close(); err != nil {
+				fmt.Printf("error recording session: %v\n", err)
+			} else {
+				fmt.Printf("Recorded session to %s, play it back with kern replay %s\n", seeRecord, seeRecord)
+			}
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(seeCommand)
 	seeCommand.Flags().StringVarP(&group, "group", "g", "", "group")
+	seeCommand.Flags().StringSliceVar(&dockerContexts, "context", nil,
+		`Docker context(s) to monitor, repeatable or comma separated; "all" for every context`)
+	seeCommand.Flags().BoolVar(&demo, "demo", false,
+		"Run against simulated hosts built from mock data instead of Docker")
+	seeCommand.Flags().StringVar(&seeRecord, "record", "",
+		"Record the session to a file for kern replay, appending to an existing one")
 }
@@ -3,20 +3,73 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/export"
+	"github.com/kqnd/kernus/internal/metrics"
 	"github.com/spf13/cobra"
 )
 
 var name string
+var sendListen string
+var sendOTLPEndpoint string
+var sendOTLPProtocol string
+var sendOTLPHeaders map[string]string
+var sendOTLPInsecure bool
+var sendOTLPInterval time.Duration
+var sendInfluxURL string
+var sendInfluxToken string
+var sendInfluxOrg string
+var sendInfluxBucket string
+var sendInfluxDatabase string
+var sendInfluxInterval time.Duration
+var sendStatsDAddress string
+var sendStatsDPrefix string
+var sendStatsDFlavor string
+var sendStatsDInterval time.Duration
+
+const (
+	// sendAlertInterval is how often kern send checks the alert rules.
+	sendAlertInterval = 10 * time.Second
+
+	// sendMetricsInterval is how often kern send collects for --listen.
+	sendMetricsInterval = 15 * time.Second
+
+	// defaultOTLPInterval matches the default export interval of the
+	// OpenTelemetry SDKs.
+	defaultOTLPInterval = time.Minute
+
+	// defaultInfluxInterval and defaultStatsDInterval match the defaults of
+	// Telegraf and of the StatsD flush interval.
+	defaultInfluxInterval = 10 * time.Second
+	defaultStatsDInterval = 10 * time.Second
+)
 
 var sendCommand = &cobra.Command{
 	Use:   "send",
 	Short: "Send machine to monitoring server",
-	Long:  "Send your machine to the monitoring server using the CLI options",
+	Long: `Send your machine to the monitoring server using the CLI options.
+
+When config.json has alert rules, the local Docker host is also checked
+against them and alerts are delivered to the sinks under "notify", as with
+kern watch. With --listen the metrics of the local Docker host and of this
+machine are served to Prometheus, as with kern export.
+
+With an OTLP endpoint, from --otlp-endpoint or "export": {"otlp": {...}} in
+config.json, the same metrics are pushed to an OpenTelemetry collector over
+gRPC or HTTP. Each host and container is a resource with host.name,
+container.id, container.name and container.image.name attributes.
+
+They can also be written as InfluxDB line protocol over HTTP or UDP, with
+--influx-url or "export": {"influxdb": {...}}, and sent to StatsD or
+DogStatsD over UDP, with --statsd-address or "export": {"statsd": {...}}.
+Every output shares one collection; one that falls behind drops its oldest
+waiting snapshots instead of holding up the others.`,
 	Run: func(cmd *cobra.Command, args []string) {
 
 		// ExitIfIsMissingFields()
@@ -26,10 +79,14 @@ var sendCommand = &cobra.Command{
 
 		fmt.Println("starting sending process... (ctrl + c for stop)")
 
+		watching := watchLocalAlerts(ctx)
+		exporting := exportLocalMetrics(ctx)
 		for {
 			select {
 			case <-ctx.Done():
 				fmt.Println("received interrupt signal")
+				<-watching
+				<-exporting
 				return
 			default:
 				fmt.Println("sending metrics")
@@ -42,4 +99,264 @@ var sendCommand = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(sendCommand)
 	sendCommand.Flags().StringVarP(&name, "name", "n", "", "machine name (required)")
+	sendCommand.Flags().StringVar(&sendListen, "listen", "", "Serve Prometheus metrics on this address, such as :9323")
+	sendCommand.Flags().StringVar(&sendOTLPEndpoint, "otlp-endpoint", "",
+		"Push metrics over OTLP to this collector, host:port for grpc or a URL for http/protobuf")
+	sendCommand.Flags().StringVar(&sendOTLPProtocol, "otlp-protocol", "", "OTLP protocol, grpc or http/protobuf (default grpc)")
+	sendCommand.Flags().StringToStringVar(&sendOTLPHeaders, "otlp-header", nil, "OTLP header as key=value, such as an API key (repeatable)")
+	sendCommand.Flags().BoolVar(&sendOTLPInsecure, "otlp-insecure", false, "Push over OTLP without TLS")
+	sendCommand.Flags().DurationVar(&sendOTLPInterval, "otlp-interval", 0, "Time between OTLP pushes (default 1m)")
+	sendCommand.Flags().StringVar(&sendInfluxURL, "influx-url", "",
+		"Write line protocol to this InfluxDB or Telegraf, such as http://localhost:8086 or udp://localhost:8089")
+	sendCommand.Flags().StringVar(&sendInfluxToken, "influx-token", "", "InfluxDB API token")
+	sendCommand.Flags().StringVar(&sendInfluxOrg, "influx-org", "", "InfluxDB organization")
+	sendCommand.Flags().StringVar(&sendInfluxBucket, "influx-bucket", "", "InfluxDB bucket")
+	sendCommand.Flags().StringVar(&sendInfluxDatabase, "influx-database", "", "Database for InfluxDB 1.x, instead of a bucket")
+	sendCommand.Flags().DurationVar(&sendInfluxInterval, "influx-interval", 0, "Time between InfluxDB writes (default 10s)")
+	sendCommand.Flags().StringVar(&sendStatsDAddress, "statsd-address", "", "Send metrics to this StatsD server, such as localhost:8125")
+	sendCommand.Flags().StringVar(&sendStatsDPrefix, "statsd-prefix", "", "Prefix of the StatsD metric names")
+	sendCommand.Flags().StringVar(&sendStatsDFlavor, "statsd-flavor", "", "statsd, with labels in the names, or dogstatsd, with tags (default statsd)")
+	sendCommand.Flags().DurationVar(&sendStatsDInterval, "statsd-interval", 0, "Time between StatsD sends (default 10s)")
+}
+
+// connectLocal connects to the current Docker context, named after the
+// machine when --name is set.
+func connectLocal() ([]docker.ContainerRuntime, error) {
+	runtimes, _, err := connectRuntimes(nil, os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		for i, runtime := range runtimes {
+			runtimes[i] = namedRuntime{runtime, name}
+		}
+	}
+	return runtimes, nil
+}
+
+// watchLocalAlerts watches the local Docker host for the alert rules of the
+// config until ctx is done. The returned channel is closed once queued
+// alerts are sent.
+func watchLocalAlerts(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	if len(CONFIG.Alerts) == 0 {
+		close(done)
+		return done
+	}
+
+	runtimes, err := connectLocal()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	watcher, err := newAlertWatcher(runtimes, os.Stdout, os.Stderr)
+	if err != nil || len(runtimes) == 0 {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		fmt.Println("not watching alerts")
+		closeRuntimes(runtimes)
+		close(done)
+		return done
+	}
+
+	go func() {
+		defer close(done)
+		defer closeRuntimes(runtimes)
+		watcher.run(ctx, sendAlertInterval)
+	}()
+	return done
+}
+
+// exportLocalMetrics collects the metrics of the local Docker host and of
+// this machine until ctx is done, serving them on --listen and pushing them
+// to the configured outputs. The returned channel is closed once it
+// stopped.
+func exportLocalMetrics(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	pushers := pushersFromFlags(os.Stderr)
+	if sendListen == "" && len(pushers) == 0 {
+		close(done)
+		return done
+	}
+
+	runtimes, err := connectLocal()
+	if err != nil || len(runtimes) == 0 {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		fmt.Println("not exporting metrics")
+		for _, p := range pushers {
+			p.Close()
+		}
+		close(done)
+		return done
+	}
+
+	machineHost := ""
+	if localContext(nil) != "" {
+		machineHost = runtimes[0].Name()
+	}
+	interval := time.Duration(0)
+	if sendListen != "" {
+		interval = sendMetricsInterval
+	}
+	loop := export.NewLoop(metrics.NewCollector(runtimes, machineHost), interval)
+	for _, p := range pushers {
+		loop.AddPusher(p, p.interval)
+		fmt.Printf("pushing metrics to %s every %s\n", p.Name(), p.interval)
+	}
+
+	go func() {
+		defer close(done)
+		defer closeRuntimes(runtimes)
+		if sendListen == "" {
+			loop.Run(ctx, func(err error) {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			})
+			return
+		}
+		if err := serveMetrics(ctx, loop, sendListen, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+	}()
+	return done
+}
+
+// timedPusher is a pusher and the time between its pushes.
+type timedPusher struct {
+	export.Pusher
+	interval time.Duration
+}
+
+// pushersFromFlags sets up the outputs of the config, overridden by the
+// send flags. An output that cannot be set up is reported and left out.
+func pushersFromFlags(stderr io.Writer) []timedPusher {
+	var pushers []timedPusher
+	for _, setup := range []func() (export.Pusher, time.Duration, error){otlpFromFlags, influxFromFlags, statsdFromFlags} {
+		pusher, interval, err := setup()
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			continue
+		}
+		if pusher != nil {
+			pushers = append(pushers, timedPusher{Pusher: pusher, interval: interval})
+		}
+	}
+	return pushers
+}
+
+// pushInterval is the interval of the flag, else of the config, else
+// fallback.
+func pushInterval(flag time.Duration, configured string, fallback time.Duration, output string) (time.Duration, error) {
+	if flag > 0 {
+		return flag, nil
+	}
+	if configured == "" {
+		return fallback, nil
+	}
+	interval, err := time.ParseDuration(configured)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid %s interval %q, expected for instance 30s", output, configured)
+	}
+	return interval, nil
+}
+
+// otlpFromFlags sets up the OTLP exporter, or returns nil when no endpoint
+// is set.
+func otlpFromFlags() (export.Pusher, time.Duration, error) {
+	cfg := CONFIG.Export.OTLP
+	if sendOTLPEndpoint != "" {
+		cfg.Endpoint = sendOTLPEndpoint
+	}
+	if sendOTLPProtocol != "" {
+		cfg.Protocol = sendOTLPProtocol
+	}
+	if sendOTLPInsecure {
+		cfg.Insecure = true
+	}
+	if len(sendOTLPHeaders) > 0 {
+		headers := make(map[string]string, len(cfg.Headers)+len(sendOTLPHeaders))
+		for key, value := range cfg.Headers {
+			headers[key] = value
+		}
+		for key, value := range sendOTLPHeaders {
+			headers[key] = value
+		}
+		cfg.Headers = headers
+	}
+	if cfg.Endpoint == "" {
+		return nil, 0, nil
+	}
+
+	interval, err := pushInterval(sendOTLPInterval, cfg.Interval, defaultOTLPInterval, "otlp")
+	if err != nil {
+		return nil, 0, err
+	}
+	otlp, err := export.NewOTLP(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return otlp, interval, nil
+}
+
+// influxFromFlags sets up the InfluxDB writer, or returns nil when no URL
+// is set.
+func influxFromFlags() (export.Pusher, time.Duration, error) {
+	cfg := CONFIG.Export.InfluxDB
+	if sendInfluxURL != "" {
+		cfg.URL = sendInfluxURL
+	}
+	if sendInfluxToken != "" {
+		cfg.Token = sendInfluxToken
+	}
+	if sendInfluxOrg != "" {
+		cfg.Org = sendInfluxOrg
+	}
+	if sendInfluxBucket != "" {
+		cfg.Bucket = sendInfluxBucket
+	}
+	if sendInfluxDatabase != "" {
+		cfg.Database = sendInfluxDatabase
+	}
+	if cfg.URL == "" {
+		return nil, 0, nil
+	}
+
+	interval, err := pushInterval(sendInfluxInterval, cfg.Interval, defaultInfluxInterval, "influxdb")
+	if err != nil {
+		return nil, 0, err
+	}
+	influx, err := export.NewInflux(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return influx, interval, nil
+}
+
+// statsdFromFlags sets up the StatsD client, or returns nil when no address
+// is set.
+func statsdFromFlags() (export.Pusher, time.Duration, error) {
+	cfg := CONFIG.Export.StatsD
+	if sendStatsDAddress != "" {
+		cfg.Address = sendStatsDAddress
+	}
+	if sendStatsDPrefix != "" {
+		cfg.Prefix = sendStatsDPrefix
+	}
+	if sendStatsDFlavor != "" {
+		cfg.Flavor = sendStatsDFlavor
+	}
+	if cfg.Address == "" {
+		return nil, 0, nil
+	}
+
+	interval, err := pushInterval(sendStatsDInterval, cfg.Interval, defaultStatsDInterval, "statsd")
+	if err != nil {
+		return nil, 0, err
+	}
+	statsd, err := export.NewStatsD(cfg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return statsd, interval, nil
 }
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/sample"
+	"github.com/spf13/cobra"
+)
+
+var statsInterval time.Duration
+var statsDuration time.Duration
+var statsRecord string
+var statsRecordFormat string
+var statsNoStream bool
+var statsContexts []string
+var statsDemo bool
+
+var statsCommand = &cobra.Command{
+	Use:   "stats [containers...]",
+	Short: "Stream container resource usage",
+	Long: `Stream the resource usage of running containers, like docker stats.
+Containers are named by name or ID prefix; without names every running
+container is shown.
+
+With --record every sample is also written to a file, as CSV or as
+newline-delimited JSON depending on its extension (.csv, .ndjson, .jsonl),
+for capacity planning and later analysis.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runStats(cmd.OutOrStdout(), cmd.ErrOrStderr(), args))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCommand)
+	statsCommand.Flags().DurationVar(&statsInterval, "interval", time.Second, "Time between samples")
+	statsCommand.Flags().DurationVar(&statsDuration, "duration", 0, "Stop after this long (default: until interrupted)")
+	statsCommand.Flags().StringVar(&statsRecord, "record", "", "Record samples to a .csv or .ndjson file")
+	statsCommand.Flags().StringVar(&statsRecordFormat, "record-format", "", "Recording format, csv or ndjson (default: from the file extension)")
+	statsCommand.Flags().BoolVar(&statsNoStream, "no-stream", false, "Print a single sample and exit")
+	statsCommand.Flags().StringSliceVar(&statsContexts, "context", nil,
+		`Docker context(s) to sample, repeatable or comma separated; "all" for every context`)
+	statsCommand.Flags().BoolVar(&statsDemo, "demo", false,
+		"Sample simulated hosts built from mock data instead of Docker")
+	statsCommand.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+		os.Exit(exitUsage)
+		return err
+	})
+}
+
+func runStats(stdout, stderr io.Writer, names []string) int {
+	if statsInterval <= 0 {
+		fmt.Fprintln(stderr, "Error: --interval must be positive")
+		return exitUsage
+	}
+
+	var runtimes []docker.ContainerRuntime
+	failed := 0
+	if statsDemo {
+		for _, runtime := range fake.DemoRuntimes(models.MockMachines(), "", statsInterval) {
+			runtimes = append(runtimes, runtime)
+		}
+	} else {
+		var err error
+		runtimes, failed, err = connectRuntimes(statsContexts, stderr)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return exitUsage
+		}
+	}
+	defer closeRuntimes(runtimes)
+	if len(runtimes) == 0 {
+		return exitUnreachable
+	}
+
+	var recorder sample.Recorder
+	if statsRecord != "" {
+		var err error
+		recorder, err = sample.Create(statsRecord, statsRecordFormat)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return exitUsage
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if statsDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, statsDuration)
+		defer cancel()
+	}
+
+	collector := sample.NewCollector(runtimes, names)
+	screen := isTerminal(stdout) && !statsNoStream
+	multiHost := len(runtimes)+failed > 1
+	recorded := 0
+	code := exitOK
+
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+	first := true
+sampling:
+	for {
+		samples, errs := collector.Collect(time.Now())
+
+		if first {
+			if unmatched := collector.Unmatched(); len(unmatched) > 0 {
+				for _, name := range unmatched {
+					fmt.Fprintf(stderr, "Error: no such container: %s\n", name)
+				}
+				if len(samples) == 0 {
+					code = exitNoMatch
+					break sampling
+				}
+			}
+		}
+
+		if screen {
+			fmt.Fprint(stdout, "\033[H\033[2J")
+		} else if !first {
+			fmt.Fprintln(stdout)
+		}
+		writeStatsTable(stdout, samples, multiHost)
+		for _, err := range errs {
+			fmt.Fprintln(stderr, "Error:", err)
+		}
+
+		if recorder != nil {
+			if err := recorder.Write(samples); err != nil {
+				fmt.Fprintln(stderr, "Error: recording stopped:", err)
+				code = exitUnreachable
+				break sampling
+			}
+			recorded += len(samples)
+		}
+
+		if statsNoStream {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break sampling
+		case <-ticker.C:
+		}
+		first = false
+	}
+
+	if recorder != nil {
+		if err := recorder.Close(); err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+		}
+		fmt.Fprintf(stderr, "Recorded %d samples to %s\n", recorded, statsRecord)
+	}
+	if code == exitOK && failed > 0 {
+		code = exitUnreachable
+	}
+	return code
+}
+
+func writeStatsTable(w io.Writer, samples []sample.Sample, multiHost bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	defer tw.Flush()
+
+	if multiHost {
+		fmt.Fprint(tw, "HOST\t")
+	}
+	fmt.Fprintln(tw, "CONTAINER ID\tNAME\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O\tPIDS")
+
+	for _, s := range samples {
+		stats := s.Stats
+		id := s.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		if multiHost {
+			fmt.Fprintf(tw, "%s\t", s.Host)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\t%d\n",
+			id, s.Name, stats.CPU.Usage,
+			models.FormatBytes(stats.Memory.Usage), models.FormatBytes(stats.Memory.Limit),
+			stats.Memory.Percentage(),
+			models.FormatBytes(stats.Network.RxBytes), models.FormatBytes(stats.Network.TxBytes),
+			models.FormatBytes(stats.BlockIO.ReadBytes), models.FormatBytes(stats.BlockIO.WriteBytes),
+			stats.PIDs)
+	}
+}
+
+// isTerminal reports whether w is a terminal, where the table is redrawn in
+// place rather than appended.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setStatsFlags samples the demo hosts once. The interval is long enough
+// that the simulation does not move while the test runs.
+func setStatsFlags(t *testing.T, interval time.Duration, record string) {
+	t.Helper()
+	oldInterval, oldRecord, oldFormat := statsInterval, statsRecord, statsRecordFormat
+	oldNoStream, oldDemo, oldDuration := statsNoStream, statsDemo, statsDuration
+	statsInterval, statsRecord, statsRecordFormat = interval, record, ""
+	statsNoStream, statsDemo, statsDuration = true, true, 0
+	t.Cleanup(func() {
+		statsInterval, statsRecord, statsRecordFormat = oldInterval, oldRecord, oldFormat
+		statsNoStream, statsDemo, statsDuration = oldNoStream, oldDemo, oldDuration
+	})
+}
+
+func TestRunStats(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "stats.csv")
+	setStatsFlags(t, time.Hour, record)
+
+	var stdout, stderr bytes.Buffer
+	if code := runStats(&stdout, &stderr, []string{"nginx-web", "def456"}); code != exitOK {
+		t.Fatalf("runStats() = %d, stderr:\n%s", code, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("table =\n%s\nwant a header and two rows", stdout.String())
+	}
+	if fields := strings.Fields(lines[0]); fields[0] != "HOST" || fields[1] != "CONTAINER" {
+		t.Errorf("header = %s, want a host column for the demo hosts", lines[0])
+	}
+	for i, want := range []string{"local abc123456789 nginx-web", "local def456789012 postgres-db"} {
+		if got := strings.Join(strings.Fields(lines[i+1])[:3], " "); got != want {
+			t.Errorf("row %d = %s, want %s", i+1, got, want)
+		}
+	}
+
+	if !strings.Contains(stderr.String(), "Recorded 2 samples to "+record) {
+		t.Errorf("stderr = %q, want the recording reported", stderr.String())
+	}
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows := strings.Split(strings.TrimSpace(string(data)), "\n"); len(rows) != 3 || !strings.HasPrefix(rows[0], "time,host,id,") {
+		t.Errorf("recording =\n%s\nwant the header and two samples", data)
+	}
+}
+
+func TestRunStatsNoMatch(t *testing.T) {
+	setStatsFlags(t, time.Hour, "")
+
+	var stdout, stderr bytes.Buffer
+	if code := runStats(&stdout, &stderr, []string{"missing"}); code != exitNoMatch {
+		t.Errorf("runStats() = %d, want %d", code, exitNoMatch)
+	}
+	if stdout.Len() > 0 {
+		t.Errorf("stdout = %q, want no table", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Error: no such container: missing") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestRunStatsRejectsInterval(t *testing.T) {
+	setStatsFlags(t, 0, "")
+
+	var stderr bytes.Buffer
+	if code := runStats(&bytes.Buffer{}, &stderr, nil); code != exitUsage {
+		t.Errorf("runStats() = %d, want %d", code, exitUsage)
+	}
+	if !strings.Contains(stderr.String(), "--interval must be positive") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+// flushTimeout bounds the delivery of the alerts still queued on exit.
+const flushTimeout = 10 * time.Second
+
+var watchInterval time.Duration
+var watchContexts []string
+var watchDemo bool
+
+var watchCommand = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch hosts for alerts and send notifications without the TUI",
+	Long: `Check the alert rules of config.json against Docker hosts at an interval
+and deliver the alerts that fire and resolve to the sinks under "notify":
+
+  "notify": {
+    "sinks": [
+      {"type": "webhook", "url": "https://example.com/hook"},
+      {"type": "slack", "url": "https://hooks.slack.com/services/..."},
+      {"type": "email", "smtp": "mail.example.com:587", "smtp_username": "kern",
+       "smtp_password": "...", "from": "kern@example.com", "to": ["ops@example.com"]}
+    ],
+    "group_by": "rule",
+    "group_wait": "30s",
+    "attempts": 4,
+    "rate_limit": "10/1h",
+    "silences": [{"rule": "busy", "host": "staging*", "until": "2025-06-01T18:00:00Z"}]
+  }
+
+Alerts of one group (rule, host or none) that change within the group wait
+are sent together. Failed deliveries are retried with backoff, sinks over
+the rate limit hold their alerts for the next notification, and silenced
+alerts are not sent. Every alert and delivery is also printed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runWatch(cmd.OutOrStdout(), cmd.ErrOrStderr()))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCommand)
+	watchCommand.Flags().DurationVar(&watchInterval, "interval", 10*time.Second, "Time between checks")
+	watchCommand.Flags().StringSliceVar(&watchContexts, "context", nil,
+		`Docker context(s) to watch, repeatable or comma separated; "all" for every context`)
+	watchCommand.Flags().BoolVar(&watchDemo, "demo", false,
+		"Watch simulated hosts built from mock data instead of Docker")
+	watchCommand.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+		os.Exit(exitUsage)
+		return err
+	})
+}
+
+func runWatch(stdout, stderr io.Writer) int {
+	if watchInterval <= 0 {
+		fmt.Fprintln(stderr, "Error: --interval must be positive")
+		return exitUsage
+	}
+
+	var runtimes []docker.ContainerRuntime
+	if watchDemo {
+		for _, runtime := range fake.DemoRuntimes(models.MockMachines(), "", watchInterval) {
+			runtimes = append(runtimes, runtime)
+		}
+	} else {
+		var err error
+		runtimes, _, err = connectRuntimes(watchContexts, stderr)
+		if err != nil {
+			fmt.Fprintln(stderr, "Error:", err)
+			return exitUsage
+		}
+	}
+	defer closeRuntimes(runtimes)
+
+	watcher, err := newAlertWatcher(runtimes, stdout, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return exitUsage
+	}
+	if len(watcher.rules) == 0 {
+		fmt.Fprintln(stderr, `Error: no alert rules, add them to "alerts" in config.json`)
+		return exitUsage
+	}
+	if len(runtimes) == 0 {
+		return exitUnreachable
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	watcher.run(ctx, watchInterval)
+	return exitOK
+}
+
+type watchedHost struct {
+	name     string
+	runtime  docker.ContainerRuntime
+	lastSeen time.Time
+	err      error
+}
+
+// alertWatcher evaluates the alert rules of the config against runtimes and
+// hands what fires and resolves to the configured notification sinks. It
+// is shared by kern watch and kern send.
+type alertWatcher struct {
+	rules      []alert.Rule
+	hosts      []*watchedHost
+	engine     *alert.Engine
+	dispatcher *notify.Dispatcher
+
+	mu     sync.Mutex
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// newAlertWatcher reads the alert rules and notification sinks of the
+// config.
+func newAlertWatcher(runtimes []docker.ContainerRuntime, stdout, stderr io.Writer) (*alertWatcher, error) {
+	rules, err := alert.ParseRules(CONFIG.Alerts)
+	if err != nil {
+		return nil, err
+	}
+	dispatcher, err := notify.FromConfig(CONFIG.Notify)
+	if err != nil {
+		return nil, fmt.Errorf("notify: %w", err)
+	}
+
+	w := &alertWatcher{
+		rules:      rules,
+		engine:     alert.NewEngine(rules),
+		dispatcher: dispatcher,
+		stdout:     stdout,
+		stderr:     stderr,
+	}
+	for _, runtime := range runtimes {
+		w.hosts = append(w.hosts, &watchedHost{name: runtime.Name(), runtime: runtime})
+	}
+	return w, nil
+}
+
+// run checks the rules every interval until ctx is done, then sends what is
+// still queued.
+func (w *alertWatcher) run(ctx context.Context, interval time.Duration) {
+	if len(w.dispatcher.Sinks()) == 0 {
+		w.printf(w.stderr, "Warning: no sinks under \"notify\" in config.json, alerts are only printed\n")
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		w.dispatcher.Run(ctx, w.report)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		w.check(time.Now())
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			flushCtx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+			defer cancel()
+			for _, delivery := range w.dispatcher.Flush(flushCtx, time.Now()) {
+				w.report(delivery)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *alertWatcher) check(now time.Time) {
+	hosts := make([]alert.Host, 0, len(w.hosts))
+	for _, host := range w.hosts {
+		containers, err := host.runtime.ListContainersWith(docker.ListOptions{SkipLogs: true})
+		switch {
+		case err != nil && host.err == nil:
+			w.printf(w.stderr, "%s %s is unreachable: %v\n", now.Format(time.TimeOnly), host.name, err)
+		case err == nil && host.err != nil:
+			w.printf(w.stderr, "%s %s is reachable again\n", now.Format(time.TimeOnly), host.name)
+		}
+		host.err = err
+
+		state := alert.Host{Name: host.name, Online: err == nil, LastSeen: host.lastSeen}
+		if err == nil {
+			host.lastSeen = now
+			state.LastSeen = now
+			for i := range containers {
+				state.Containers = append(state.Containers, &containers[i])
+			}
+		}
+		hosts = append(hosts, state)
+	}
+
+	changed := w.engine.Evaluate(now, hosts)
+	for _, a := range changed {
+		w.printf(w.stdout, "%s %-8s %s on %s (%s)\n", now.Format(time.TimeOnly), a.State, a.Rule, a.Subject(), a.Value)
+	}
+	for _, a := range w.dispatcher.Add(now, changed) {
+		w.printf(w.stdout, "%s silenced %s on %s\n", now.Format(time.TimeOnly), a.Rule, a.Subject())
+	}
+}
+
+func (w *alertWatcher) report(delivery notify.Delivery) {
+	now := time.Now().Format(time.TimeOnly)
+	if delivery.Err != nil {
+		w.printf(w.stderr, "%s failed to send %q to %s after %d attempt(s): %v\n",
+			now, delivery.Notification.Title(), delivery.Sink, delivery.Attempts, delivery.Err)
+		return
+	}
+	w.printf(w.stdout, "%s sent %q to %s\n", now, delivery.Notification.Title(), delivery.Sink)
+}
+
+func (w *alertWatcher) printf(out io.Writer, format string, args ...any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(out, format, args...)
+}
@@ -0,0 +1,326 @@
+package alert
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+type State string
+
+const (
+	StatePending  State = "pending"
+	StateFiring   State = "firing"
+	StateResolved State = "resolved"
+)
+
+// keepResolved is how long a resolved alert stays listed.
+const keepResolved = 15 * time.Minute
+
+// Alert is a rule that matched one container or host.
+type Alert struct {
+	Rule        string
+	Expr        string
+	Host        string
+	ContainerID string
+	Container   string
+	State       State
+
+	// Value is what the rule last saw, such as "cpu 93.5%".
+	Value string
+
+	// Since is when the condition started to hold.
+	Since      time.Time
+	FiredAt    time.Time
+	ResolvedAt time.Time
+}
+
+// Subject names what the alert is about: the container, or the host for
+// machine rules.
+func (a Alert) Subject() string {
+	if a.Container == "" {
+		return a.Host
+	}
+	return a.Host + "/" + a.Container
+}
+
+// Duration is how long the alert has been pending or firing at now, or how
+// long it fired once resolved.
+func (a Alert) Duration(now time.Time) time.Duration {
+	switch a.State {
+	case StateFiring:
+		return now.Sub(a.FiredAt)
+	case StateResolved:
+		return a.ResolvedAt.Sub(a.FiredAt)
+	}
+	return now.Sub(a.Since)
+}
+
+// Host is what the engine sees of one host at an evaluation. Containers of
+// an offline host are left as they were.
+type Host struct {
+	Name       string
+	Online     bool
+	LastSeen   time.Time
+	Containers []*models.Container
+}
+
+type restartCount struct {
+	count     int
+	increased time.Time
+}
+
+// Engine evaluates rules and keeps the state of their alerts. It is safe
+// for concurrent use.
+type Engine struct {
+	rules []Rule
+
+	mu       sync.Mutex
+	alerts   map[string]*Alert
+	restarts map[string]restartCount
+}
+
+func NewEngine(rules []Rule) *Engine {
+	return &Engine{
+		rules:    rules,
+		alerts:   make(map[string]*Alert),
+		restarts: make(map[string]restartCount),
+	}
+}
+
+func (e *Engine) Rules() []Rule {
+	return e.rules
+}
+
+// Evaluate checks every rule against hosts at now and returns the alerts
+// that started firing or resolved.
+func (e *Engine) Evaluate(now time.Time, hosts []Host) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var changed []Alert
+	update := func(key string, rule Rule, host string, c *models.Container, ok bool, since time.Time, value string) {
+		seen[key] = true
+		if transition, changes := e.update(now, key, rule, host, c, ok, since, value); changes {
+			changed = append(changed, transition)
+		}
+	}
+
+	for _, host := range hosts {
+		if host.Online {
+			e.countRestarts(now, host)
+		}
+		for i, rule := range e.rules {
+			if rule.Machine() {
+				since := host.LastSeen
+				value := "offline"
+				if !since.IsZero() {
+					value = fmt.Sprintf("offline, last seen %s ago", now.Sub(since).Round(time.Second))
+				}
+				update(fmt.Sprintf("%d|%s", i, host.Name), rule, host.Name, nil, !host.Online, since, value)
+				continue
+			}
+			if !host.Online {
+				e.keepHost(seen, i, host.Name)
+				continue
+			}
+			for _, c := range host.Containers {
+				ok, value := e.check(now, rule, host.Name, c)
+				update(fmt.Sprintf("%d|%s|%s", i, host.Name, c.ID), rule, host.Name, c, ok, time.Time{}, value)
+			}
+		}
+	}
+
+	for key, alert := range e.alerts {
+		if seen[key] {
+			continue
+		}
+		if transition, changes := e.update(now, key, Rule{}, alert.Host, nil, false, time.Time{}, alert.Value); changes {
+			changed = append(changed, transition)
+		}
+	}
+	for key, alert := range e.alerts {
+		if alert.State == StateResolved && now.Sub(alert.ResolvedAt) > keepResolved {
+			delete(e.alerts, key)
+		}
+	}
+	return changed
+}
+
+// keepHost marks the container alerts of rule i on an offline host as seen,
+// since the host cannot tell whether they still hold.
+func (e *Engine) keepHost(seen map[string]bool, i int, host string) {
+	prefix := fmt.Sprintf("%d|%s|", i, host)
+	for key := range e.alerts {
+		if len(key) > len(prefix) && key[:len(prefix)] == prefix {
+			seen[key] = true
+		}
+	}
+}
+
+// update moves the alert at key along pending, firing and resolved. It
+// reports the alert when it started firing or resolved. The caller holds
+// e.mu.
+func (e *Engine) update(now time.Time, key string, rule Rule, host string, c *models.Container,
+	ok bool, since time.Time, value string) (Alert, bool) {
+	alert := e.alerts[key]
+	if !ok {
+		switch {
+		case alert == nil:
+		case alert.State == StatePending:
+			delete(e.alerts, key)
+		case alert.State == StateFiring:
+			alert.State = StateResolved
+			alert.ResolvedAt = now
+			return *alert, true
+		}
+		return Alert{}, false
+	}
+
+	if alert == nil || alert.State == StateResolved {
+		if since.IsZero() || since.After(now) {
+			since = now
+		}
+		alert = &Alert{Rule: rule.Name, Expr: rule.Expr, Host: host, State: StatePending, Since: since}
+		if c != nil {
+			alert.ContainerID = c.ID
+			alert.Container = c.ShortName()
+		}
+		e.alerts[key] = alert
+	}
+	alert.Value = value
+	if alert.State == StatePending && now.Sub(alert.Since) >= rule.For {
+		alert.State = StateFiring
+		alert.FiredAt = now
+		return *alert, true
+	}
+	return Alert{}, false
+}
+
+// countRestarts remembers when the restart count of each container last
+// went up. The first count seen of a container is its baseline. The caller
+// holds e.mu.
+func (e *Engine) countRestarts(now time.Time, host Host) {
+	for _, c := range host.Containers {
+		key := host.Name + "/" + c.ID
+		previous, ok := e.restarts[key]
+		switch {
+		case !ok:
+			e.restarts[key] = restartCount{count: c.RestartCount}
+		case c.RestartCount > previous.count:
+			e.restarts[key] = restartCount{count: c.RestartCount, increased: now}
+		}
+	}
+}
+
+// check reports whether a container rule holds for c, and the value it saw.
+// The caller holds e.mu.
+func (e *Engine) check(now time.Time, rule Rule, host string, c *models.Container) (bool, string) {
+	switch rule.metric {
+	case metricHealth:
+		health := string(c.HealthStatus())
+		return compareText(rule.op, health, rule.text), "health " + health
+	case metricStatus:
+		status := string(c.Status)
+		return compareText(rule.op, status, rule.text), "status " + status
+	case metricRestarts:
+		if rule.op != "increased" {
+			return compare(rule.op, float64(c.RestartCount), rule.value), fmt.Sprintf("%d restarts", c.RestartCount)
+		}
+		restarts := e.restarts[host+"/"+c.ID]
+		increased := !restarts.increased.IsZero() && now.Sub(restarts.increased) < rule.within
+		return increased, fmt.Sprintf("%d restarts", c.RestartCount)
+	}
+
+	stats := c.Stats
+	if stats == nil || c.Status != models.StatusRunning {
+		return false, ""
+	}
+	switch rule.metric {
+	case metricCPU:
+		return compare(rule.op, stats.CPU.Usage, rule.value), fmt.Sprintf("cpu %.1f%%", stats.CPU.Usage)
+	case metricMemory:
+		usage := fmt.Sprintf("memory %s / %s", models.FormatBytes(stats.Memory.Usage), models.FormatBytes(stats.Memory.Limit))
+		if !rule.percent {
+			return compare(rule.op, float64(stats.Memory.Usage), rule.value), usage
+		}
+		if stats.Memory.Limit <= 0 {
+			return false, usage
+		}
+		percent := stats.Memory.Percentage()
+		return compare(rule.op, percent, rule.value), fmt.Sprintf("memory %.1f%% of limit", percent)
+	case metricPIDs:
+		return compare(rule.op, float64(stats.PIDs), rule.value), fmt.Sprintf("%d pids", stats.PIDs)
+	}
+	return false, ""
+}
+
+func compare(op string, value, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	case "==":
+		return value == threshold
+	case "!=":
+		return value != threshold
+	}
+	return false
+}
+
+func compareText(op, value, expected string) bool {
+	if op == "!=" {
+		return value != expected
+	}
+	return value == expected
+}
+
+// Alerts returns every pending, firing and recently resolved alert: firing
+// ones first, then pending, then resolved, each oldest first.
+func (e *Engine) Alerts() []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	alerts := make([]Alert, 0, len(e.alerts))
+	for _, alert := range e.alerts {
+		alerts = append(alerts, *alert)
+	}
+	order := map[State]int{StateFiring: 0, StatePending: 1, StateResolved: 2}
+	sort.Slice(alerts, func(i, j int) bool {
+		a, b := alerts[i], alerts[j]
+		if order[a.State] != order[b.State] {
+			return order[a.State] < order[b.State]
+		}
+		if !a.Since.Equal(b.Since) {
+			return a.Since.Before(b.Since)
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Subject() < b.Subject()
+	})
+	return alerts
+}
+
+// Firing counts the alerts that are firing.
+func (e *Engine) Firing() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	firing := 0
+	for _, alert := range e.alerts {
+		if alert.State == StateFiring {
+			firing++
+		}
+	}
+	return firing
+}
@@ -0,0 +1,228 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+var start = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+// exampleRules are the rules the alerts view suggests.
+func exampleRules(t *testing.T) []Rule {
+	t.Helper()
+	rules, err := ParseRules([]config.AlertRule{
+		{Name: "busy", Rule: "cpu > 90% for 2m"},
+		{Rule: "memory > 85% of limit"},
+		{Rule: "health == unhealthy"},
+		{Rule: "restarts increased"},
+		{Rule: "machine offline > 60s"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func onlyRule(t *testing.T, name string) []Rule {
+	t.Helper()
+	for _, rule := range exampleRules(t) {
+		if rule.Name == name {
+			return []Rule{rule}
+		}
+	}
+	t.Fatalf("no example rule %q", name)
+	return nil
+}
+
+func running(cpu float64, memory, limit int64) *models.Container {
+	return &models.Container{
+		ID:     "abc123456789",
+		Name:   "nginx-web",
+		Status: models.StatusRunning,
+		Stats: &models.ContainerStats{
+			CPU:    models.ContainerCPU{Usage: cpu},
+			Memory: models.ContainerMemory{Usage: memory, Limit: limit},
+		},
+	}
+}
+
+func online(at time.Time, containers ...*models.Container) []Host {
+	return []Host{{Name: "local", Online: true, LastSeen: at, Containers: containers}}
+}
+
+// step evaluates at start+offset and checks the alerts that changed and the
+// states of every alert listed afterwards.
+func step(t *testing.T, e *Engine, offset time.Duration, hosts []Host, changed []State, listed []State) []Alert {
+	t.Helper()
+	transitions := e.Evaluate(start.Add(offset), hosts)
+	if !sameStates(transitions, changed) {
+		t.Errorf("at %s changed %v, want %v", offset, states(transitions), changed)
+	}
+	if alerts := e.Alerts(); !sameStates(alerts, listed) {
+		t.Errorf("at %s listed %v, want %v", offset, states(alerts), listed)
+	}
+	return transitions
+}
+
+func states(alerts []Alert) []State {
+	result := make([]State, len(alerts))
+	for i, a := range alerts {
+		result[i] = a.State
+	}
+	return result
+}
+
+func sameStates(alerts []Alert, want []State) bool {
+	if len(alerts) != len(want) {
+		return false
+	}
+	for i := range alerts {
+		if alerts[i].State != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	none     []State
+	pending  = []State{StatePending}
+	firing   = []State{StateFiring}
+	resolved = []State{StateResolved}
+)
+
+func TestCPURuleWaitsForItsDuration(t *testing.T) {
+	e := NewEngine(onlyRule(t, "busy"))
+
+	step(t, e, 0, online(start, running(95, 0, 0)), none, pending)
+	step(t, e, time.Minute, online(start, running(97, 0, 0)), none, pending)
+	fired := step(t, e, 2*time.Minute, online(start, running(93, 0, 0)), firing, firing)
+	if len(fired) == 1 && (fired[0].Value != "cpu 93.0%" || fired[0].Subject() != "local/nginx-web") {
+		t.Errorf("fired %s on %s", fired[0].Value, fired[0].Subject())
+	}
+	step(t, e, 3*time.Minute, online(start, running(40, 0, 0)), resolved, resolved)
+
+	// Resolved alerts are dropped after a while.
+	step(t, e, 10*time.Minute, online(start, running(10, 0, 0)), none, resolved)
+	step(t, e, time.Hour, online(start, running(10, 0, 0)), none, none)
+
+	// A spike shorter than the duration never fires.
+	step(t, e, 61*time.Minute, online(start, running(99, 0, 0)), none, pending)
+	step(t, e, 62*time.Minute, online(start, running(10, 0, 0)), none, none)
+}
+
+func TestMemoryRuleFiresRightAway(t *testing.T) {
+	e := NewEngine(onlyRule(t, "memory > 85% of limit"))
+
+	step(t, e, 0, online(start, running(0, 800, 1000)), none, none)
+	fired := step(t, e, time.Second, online(start, running(0, 900, 1000)), firing, firing)
+	if len(fired) == 1 && fired[0].Value != "memory 90.0% of limit" {
+		t.Errorf("fired with value %q", fired[0].Value)
+	}
+	step(t, e, 2*time.Second, online(start, running(0, 700, 1000)), resolved, resolved)
+
+	// Without a limit there is nothing to compare with.
+	step(t, e, 3*time.Second, online(start, running(0, 900, 0)), none, resolved)
+}
+
+func TestHealthRule(t *testing.T) {
+	e := NewEngine(onlyRule(t, "health == unhealthy"))
+	container := func(health models.HealthStatus) *models.Container {
+		c := running(0, 0, 0)
+		c.Health = &models.ContainerHealth{Status: health}
+		return c
+	}
+
+	step(t, e, 0, online(start, container(models.HealthStatusHealthy)), none, none)
+	step(t, e, time.Second, online(start, container(models.HealthStatusUnhealthy)), firing, firing)
+	step(t, e, 2*time.Second, online(start, container(models.HealthStatusHealthy)), resolved, resolved)
+
+	// A container that goes away resolves its alert.
+	step(t, e, 3*time.Second, online(start, container(models.HealthStatusUnhealthy)), firing, firing)
+	step(t, e, 4*time.Second, online(start), resolved, resolved)
+}
+
+func TestRestartsIncreased(t *testing.T) {
+	e := NewEngine(onlyRule(t, "restarts increased"))
+	container := func(restarts int) *models.Container {
+		c := running(0, 0, 0)
+		c.RestartCount = restarts
+		return c
+	}
+
+	// The first count seen is the baseline, not an increase.
+	step(t, e, 0, online(start, container(2)), none, none)
+	step(t, e, time.Minute, online(start, container(2)), none, none)
+	fired := step(t, e, 2*time.Minute, online(start, container(3)), firing, firing)
+	if len(fired) == 1 && fired[0].Value != "3 restarts" {
+		t.Errorf("fired with value %q", fired[0].Value)
+	}
+	step(t, e, 6*time.Minute, online(start, container(3)), none, firing)
+	step(t, e, 7*time.Minute, online(start, container(3)), resolved, resolved)
+}
+
+func TestRestartsAbove(t *testing.T) {
+	rule, err := Parse("crashloop", "restarts > 3 for 1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine([]Rule{rule})
+	container := running(0, 0, 0)
+
+	container.RestartCount = 3
+	step(t, e, 0, online(start, container), none, none)
+	container.RestartCount = 4
+	step(t, e, time.Minute, online(start, container), none, pending)
+	step(t, e, 2*time.Minute, online(start, container), firing, firing)
+
+	// The rule counts stopped containers too.
+	container.Status, container.Stats = models.StatusExited, nil
+	step(t, e, 3*time.Minute, online(start, container), none, firing)
+}
+
+func TestMachineOffline(t *testing.T) {
+	rules := exampleRules(t)
+	e := NewEngine(rules)
+	busy := running(95, 0, 0)
+	offline := func(lastSeen time.Time) []Host {
+		return []Host{{Name: "local", LastSeen: lastSeen}}
+	}
+
+	step(t, e, 0, online(start, busy), none, pending)
+	step(t, e, 2*time.Minute, online(start.Add(2*time.Minute), busy), firing, firing)
+
+	// Offline for less than a minute is pending; the CPU alert of the
+	// container is kept, since the host cannot tell whether it still holds.
+	lastSeen := start.Add(2 * time.Minute)
+	step(t, e, 2*time.Minute+30*time.Second, offline(lastSeen), none, []State{StateFiring, StatePending})
+	fired := step(t, e, 3*time.Minute, offline(lastSeen), firing, []State{StateFiring, StateFiring})
+	if len(fired) == 1 {
+		if fired[0].Subject() != "local" || fired[0].Value != "offline, last seen 1m0s ago" {
+			t.Errorf("fired on %s with value %q", fired[0].Subject(), fired[0].Value)
+		}
+		if !fired[0].Since.Equal(lastSeen) {
+			t.Errorf("offline since %s, want the last time the host was seen", fired[0].Since)
+		}
+	}
+
+	// Back online with the container calm resolves both.
+	step(t, e, 4*time.Minute, online(start.Add(4*time.Minute), running(5, 0, 0)),
+		[]State{StateResolved, StateResolved}, []State{StateResolved, StateResolved})
+	if e.Firing() != 0 {
+		t.Errorf("Firing() = %d after recovery", e.Firing())
+	}
+}
+
+func TestMachineNeverSeen(t *testing.T) {
+	e := NewEngine(onlyRule(t, "machine offline > 60s"))
+	hosts := []Host{{Name: "remote"}}
+
+	step(t, e, 0, hosts, none, pending)
+	fired := step(t, e, time.Minute, hosts, firing, firing)
+	if len(fired) == 1 && fired[0].Value != "offline" {
+		t.Errorf("fired with value %q", fired[0].Value)
+	}
+}
@@ -0,0 +1,302 @@
+// Package alert evaluates threshold rules against container stats and host
+// heartbeats, and tracks every alert from pending to firing to resolved.
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kqnd/kernus/internal/config"
+)
+
+const (
+	metricCPU      = "cpu"
+	metricMemory   = "memory"
+	metricPIDs     = "pids"
+	metricHealth   = "health"
+	metricStatus   = "status"
+	metricRestarts = "restarts"
+	metricOffline  = "offline"
+)
+
+// defaultWithin is how long "restarts increased" keeps firing after the
+// last restart.
+const defaultWithin = 5 * time.Minute
+
+// Rule is a parsed alert rule, such as "cpu > 90% for 2m",
+// "memory > 85% of limit", "health == unhealthy", "restarts increased" or
+// "machine offline > 60s".
+type Rule struct {
+	Name string
+	Expr string
+
+	// For is how long the condition must hold before the alert fires.
+	For time.Duration
+
+	metric  string
+	op      string
+	value   float64
+	percent bool
+	text    string
+	within  time.Duration
+}
+
+// Machine reports whether the rule watches hosts rather than containers.
+func (r Rule) Machine() bool {
+	return r.metric == metricOffline
+}
+
+// ParseRules parses the alert rules of the config. Rules without a name
+// are named after their expression.
+func ParseRules(rules []config.AlertRule) ([]Rule, error) {
+	parsed := make([]Rule, 0, len(rules))
+	names := make(map[string]bool)
+	for _, rule := range rules {
+		name := rule.Name
+		if name == "" {
+			name = rule.Rule
+		}
+		if names[name] {
+			return nil, fmt.Errorf("alert %q is defined twice", name)
+		}
+		names[name] = true
+
+		r, err := Parse(name, rule.Rule)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+// Parse parses a rule expression.
+func Parse(name, expr string) (Rule, error) {
+	r := Rule{Name: name, Expr: expr}
+	p := &parser{tokens: tokenize(expr)}
+	if err := p.rule(&r); err != nil {
+		return Rule{}, fmt.Errorf("alert %q: %w", name, err)
+	}
+	return r, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token
+}
+
+func (p *parser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) rule(r *Rule) error {
+	subject := p.next()
+	switch subject {
+	case "":
+		return fmt.Errorf("empty rule")
+	case "cpu":
+		r.metric = metricCPU
+		if err := p.comparison(r); err != nil {
+			return err
+		}
+		if err := p.number(r, false); err != nil {
+			return err
+		}
+	case "memory", "mem":
+		r.metric = metricMemory
+		if err := p.comparison(r); err != nil {
+			return err
+		}
+		if err := p.number(r, true); err != nil {
+			return err
+		}
+		if p.peek() == "of" {
+			p.next()
+			if p.next() != "limit" || !r.percent {
+				return fmt.Errorf(`expected a percentage "of limit"`)
+			}
+		}
+	case "pids":
+		r.metric = metricPIDs
+		if err := p.comparison(r); err != nil {
+			return err
+		}
+		if err := p.number(r, false); err != nil {
+			return err
+		}
+	case "health", "status":
+		r.metric = subject
+		r.op = p.next()
+		if r.op != "==" && r.op != "!=" {
+			return fmt.Errorf("%s can only be compared with == or !=", subject)
+		}
+		r.text = p.next()
+		if r.text == "" {
+			return fmt.Errorf("expected a %s after %s", subject, r.op)
+		}
+	case "restarts":
+		r.metric = metricRestarts
+		if p.peek() == "increased" {
+			p.next()
+			r.op = "increased"
+			r.within = defaultWithin
+			if p.peek() == "within" {
+				p.next()
+				within, err := p.duration()
+				if err != nil {
+					return err
+				}
+				r.within = within
+			}
+			break
+		}
+		if err := p.comparison(r); err != nil {
+			return err
+		}
+		if err := p.number(r, false); err != nil {
+			return err
+		}
+	case "machine", "host":
+		r.metric = metricOffline
+		if p.next() != "offline" {
+			return fmt.Errorf(`expected "offline" after %s`, subject)
+		}
+		if op := p.peek(); op == ">" || op == ">=" {
+			p.next()
+			offline, err := p.duration()
+			if err != nil {
+				return err
+			}
+			r.For = offline
+		}
+	default:
+		return fmt.Errorf("unknown metric %q, expected cpu, memory, pids, health, status, restarts or machine", subject)
+	}
+
+	if p.peek() == "for" {
+		p.next()
+		duration, err := p.duration()
+		if err != nil {
+			return err
+		}
+		r.For = duration
+	}
+	if token := p.next(); token != "" {
+		return fmt.Errorf("unexpected %q", token)
+	}
+	return nil
+}
+
+func (p *parser) comparison(r *Rule) error {
+	r.op = p.next()
+	switch r.op {
+	case ">", ">=", "<", "<=", "==", "!=":
+		return nil
+	}
+	return fmt.Errorf("expected a comparison such as > or <=, got %q", r.op)
+}
+
+// number reads a plain number, a percentage or, when sizes are allowed, a
+// size such as 512MB.
+func (p *parser) number(r *Rule, sizes bool) error {
+	token := p.next()
+	if token == "" {
+		return fmt.Errorf("expected a number after %s", r.op)
+	}
+
+	digits := strings.TrimRightFunc(token, unicode.IsLetter)
+	unit := token[len(digits):]
+	if strings.HasSuffix(digits, "%") {
+		digits, unit = strings.TrimSuffix(digits, "%"), "%"
+	}
+	value, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %q", token)
+	}
+
+	switch {
+	case unit == "%" && (r.metric == metricCPU || r.metric == metricMemory):
+		r.percent = true
+	case unit == "" && r.metric != metricMemory:
+	case sizes:
+		multiplier, ok := sizeUnits[unit]
+		if !ok {
+			return fmt.Errorf("unknown size unit in %q, expected B, KB, MB, GB or TB", token)
+		}
+		value *= multiplier
+		r.percent = false
+	default:
+		return fmt.Errorf("invalid number %q", token)
+	}
+	r.value = value
+	return nil
+}
+
+func (p *parser) duration() (time.Duration, error) {
+	token := p.next()
+	duration, err := time.ParseDuration(token)
+	if err != nil || duration < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected for instance 30s or 2m", token)
+	}
+	return duration, nil
+}
+
+var sizeUnits = map[string]float64{
+	"b":  1,
+	"kb": 1 << 10,
+	"mb": 1 << 20,
+	"gb": 1 << 30,
+	"tb": 1 << 40,
+}
+
+// tokenize splits an expression into lower-case words and comparison
+// operators, so "cpu>90%" reads the same as "cpu > 90%".
+func tokenize(expr string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, strings.ToLower(current.String()))
+			current.Reset()
+		}
+	}
+
+	runes := []rune(expr)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case strings.ContainsRune("<>=!", r):
+			flush()
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+				i++
+			}
+			if op == "=" {
+				op = "=="
+			}
+			tokens = append(tokens, op)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+)
+
+const DefaultPath = "config.json"
+
+type JSONConfig struct {
+	Server   string       `json:"server"`
+	Username string       `json:"username"`
+	Password string       `json:"password"`
+	Database string       `json:"database,omitempty"`
+	Token    string       `json:"token,omitempty"`
+	TUI      TUIConfig    `json:"tui"`
+	Alerts   []AlertRule  `json:"alerts,omitempty"`
+	Notify   NotifyConfig `json:"notify"`
+	Export   ExportConfig `json:"export"`
+}
+
+// AlertRule is a threshold rule such as "cpu > 90% for 2m". See package
+// alert for the rule syntax.
+type AlertRule struct {
+	Name string `json:"name,omitempty"`
+	Rule string `json:"rule"`
+}
+
+// NotifyConfig says where alerts are delivered by kern watch and kern send.
+// Durations are Go durations such as "30s"; a rate limit is a count per
+// duration such as "10/1h".
+type NotifyConfig struct {
+	Sinks     []SinkConfig    `json:"sinks,omitempty"`
+	GroupBy   string          `json:"group_by,omitempty"`
+	GroupWait string          `json:"group_wait,omitempty"`
+	Attempts  int             `json:"attempts,omitempty"`
+	RateLimit string          `json:"rate_limit,omitempty"`
+	Silences  []SilenceConfig `json:"silences,omitempty"`
+}
+
+// SinkConfig is one notification target. Type is webhook, slack,
+// mattermost or email.
+type SinkConfig struct {
+	Name    string            `json:"name,omitempty"`
+	Type    string            `json:"type"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+
+	Channel  string `json:"channel,omitempty"`
+	Username string `json:"username,omitempty"`
+
+	SMTP         string   `json:"smtp,omitempty"`
+	SMTPUsername string   `json:"smtp_username,omitempty"`
+	SMTPPassword string   `json:"smtp_password,omitempty"`
+	From         string   `json:"from,omitempty"`
+	To           []string `json:"to,omitempty"`
+}
+
+// SilenceConfig mutes the alerts it matches until a time, or for good
+// without one. Rule, host and container are glob patterns.
+type SilenceConfig struct {
+	Rule      string `json:"rule,omitempty"`
+	Host      string `json:"host,omitempty"`
+	Container string `json:"container,omitempty"`
+	Until     string `json:"until,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// ExportConfig says where kern send pushes metrics.
+type ExportConfig struct {
+	OTLP     OTLPConfig     `json:"otlp"`
+	InfluxDB InfluxDBConfig `json:"influxdb"`
+	StatsD   StatsDConfig   `json:"statsd"`
+}
+
+// OTLPConfig is an OpenTelemetry collector to push metrics to. Protocol is
+// grpc, with the endpoint a host:port such as localhost:4317, or
+// http/protobuf, with the endpoint a URL such as
+// http://localhost:4318/v1/metrics. Interval is a Go duration.
+type OTLPConfig struct {
+	Endpoint string            `json:"endpoint,omitempty"`
+	Protocol string            `json:"protocol,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Insecure bool              `json:"insecure,omitempty"`
+	Interval string            `json:"interval,omitempty"`
+}
+
+// InfluxDBConfig is an InfluxDB or Telegraf listener to write line protocol
+// to. URL is http(s)://host:8086 for the HTTP write API, version 2 with a
+// bucket and version 1 with a database, or udp://host:8089. Interval is a
+// Go duration.
+type InfluxDBConfig struct {
+	URL      string `json:"url,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Org      string `json:"org,omitempty"`
+	Bucket   string `json:"bucket,omitempty"`
+	Database string `json:"database,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// StatsDConfig is a StatsD server to send metrics to over UDP. Flavor is
+// statsd, which puts labels in metric names, or dogstatsd, which sends them
+// as tags. Interval is a Go duration.
+type StatsDConfig struct {
+	Address  string `json:"address,omitempty"`
+	Prefix   string `json:"prefix,omitempty"`
+	Flavor   string `json:"flavor,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+type TUIConfig struct {
+	ContainerView string                       `json:"container_view,omitempty"`
+	TableColumns  []string                     `json:"table_columns,omitempty"`
+	KeyBindings   map[string][]string          `json:"key_bindings,omitempty"`
+	Theme         string                       `json:"theme,omitempty"`
+	Themes        map[string]map[string]string `json:"themes,omitempty"`
+	Layout        LayoutConfig                 `json:"layout"`
+}
+
+type LayoutConfig struct {
+	Orientation  string `json:"orientation,omitempty"`
+	StackWidth   int    `json:"stack_width,omitempty"`
+	ListWidth    int    `json:"list_width,omitempty"`
+	TableWidth   int    `json:"table_width,omitempty"`
+	ListHeight   int    `json:"list_height,omitempty"`
+	BottomPane   string `json:"bottom_pane,omitempty"`
+	BottomHeight int    `json:"bottom_height,omitempty"`
+	Zoomed       bool   `json:"zoomed,omitempty"`
+}
+
+func Read(path string) (*JSONConfig, error) {
+	cfg := &JSONConfig{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func Write(path string, cfg *JSONConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Update applies fn to the config stored at path and writes it back, so
+// that saving one section never drops the others. A missing file is
+// treated as an empty config.
+func Update(path string, fn func(cfg *JSONConfig)) error {
+	cfg, err := Read(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	fn(cfg)
+	return Write(path, cfg)
+}
@@ -2,10 +2,16 @@ package docker
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -14,9 +20,34 @@ import (
 	"github.com/kqnd/kernus/internal/models"
 )
 
+const pingTimeout = 10 * time.Second
+
+// listWorkers bounds the containers a listing reads at once.
+const listWorkers = 8
+
 type Client struct {
-	cli *client.Client
-	ctx context.Context
+	cli  *client.Client
+	ctx  context.Context
+	name string
+	tls  bool
+
+	engineMu sync.Mutex
+	engine   string
+
+	statsMu   sync.Mutex
+	prevStats map[string]*models.ContainerStats
+	prevCPU   map[string]dockerCPUStats
+	cpuLimits map[string]float64
+	inspected map[string]listedInspect
+}
+
+// listedInspect is what a listing takes from the inspect of a container. It
+// is kept until the state or status text of the listed container changes,
+// as it does when the container stops, starts or restarts.
+type listedInspect struct {
+	status       string
+	restartCount int
+	started      time.Time
 }
 
 type dockerStats struct {
@@ -42,74 +73,13 @@ type dockerStats struct {
 	NumProcs     int `json:"num_procs"`
 	StorageStats struct {
 	} `json:"storage_stats"`
-	CPUStats struct {
-		CPUUsage struct {
-			TotalUsage        int64   `json:"total_usage"`
-			PercpuUsage       []int64 `json:"percpu_usage"`
-			UsageInKernelmode int64   `json:"usage_in_kernelmode"`
-			UsageInUsermode   int64   `json:"usage_in_usermode"`
-		} `json:"cpu_usage"`
-		SystemCPUUsage int64 `json:"system_cpu_usage"`
-		OnlineCpus     int   `json:"online_cpus"`
-		ThrottlingData struct {
-			Periods          int64 `json:"periods"`
-			ThrottledPeriods int64 `json:"throttled_periods"`
-			ThrottledTime    int64 `json:"throttled_time"`
-		} `json:"throttling_data"`
-	} `json:"cpu_stats"`
-	PreCPUStats struct {
-		CPUUsage struct {
-			TotalUsage        int64   `json:"total_usage"`
-			PercpuUsage       []int64 `json:"percpu_usage"`
-			UsageInKernelmode int64   `json:"usage_in_kernelmode"`
-			UsageInUsermode   int64   `json:"usage_in_usermode"`
-		} `json:"cpu_usage"`
-		SystemCPUUsage int64 `json:"system_cpu_usage"`
-		OnlineCpus     int   `json:"online_cpus"`
-		ThrottlingData struct {
-			Periods          int64 `json:"periods"`
-			ThrottledPeriods int64 `json:"throttled_periods"`
-			ThrottledTime    int64 `json:"throttled_time"`
-		} `json:"throttling_data"`
-	} `json:"precpu_stats"`
+	CPUStats    dockerCPUStats `json:"cpu_stats"`
+	PreCPUStats dockerCPUStats `json:"precpu_stats"`
 	MemoryStats struct {
-		Usage    int64 `json:"usage"`
-		MaxUsage int64 `json:"max_usage"`
-		Stats    struct {
-			ActiveAnon              int64 `json:"active_anon"`
-			ActiveFile              int64 `json:"active_file"`
-			Cache                   int64 `json:"cache"`
-			Dirty                   int64 `json:"dirty"`
-			HierarchicalMemoryLimit int64 `json:"hierarchical_memory_limit"`
-			HierarchicalMemswLimit  int64 `json:"hierarchical_memsw_limit"`
-			InactiveAnon            int64 `json:"inactive_anon"`
-			InactiveFile            int64 `json:"inactive_file"`
-			MappedFile              int64 `json:"mapped_file"`
-			Pgfault                 int64 `json:"pgfault"`
-			Pgmajfault              int64 `json:"pgmajfault"`
-			Pgpgin                  int64 `json:"pgpgin"`
-			Pgpgout                 int64 `json:"pgpgout"`
-			RSS                     int64 `json:"rss"`
-			RSSHuge                 int64 `json:"rss_huge"`
-			TotalActiveAnon         int64 `json:"total_active_anon"`
-			TotalActiveFile         int64 `json:"total_active_file"`
-			TotalCache              int64 `json:"total_cache"`
-			TotalDirty              int64 `json:"total_dirty"`
-			TotalInactiveAnon       int64 `json:"total_inactive_anon"`
-			TotalInactiveFile       int64 `json:"total_inactive_file"`
-			TotalMappedFile         int64 `json:"total_mapped_file"`
-			TotalPgfault            int64 `json:"total_pgfault"`
-			TotalPgmajfault         int64 `json:"total_pgmajfault"`
-			TotalPgpgin             int64 `json:"total_pgpgin"`
-			TotalPgpgout            int64 `json:"total_pgpgout"`
-			TotalRSS                int64 `json:"total_rss"`
-			TotalRSSHuge            int64 `json:"total_rss_huge"`
-			TotalUnevictable        int64 `json:"total_unevictable"`
-			TotalWriteback          int64 `json:"total_writeback"`
-			Unevictable             int64 `json:"unevictable"`
-			Writeback               int64 `json:"writeback"`
-		} `json:"stats"`
-		Limit int64 `json:"limit"`
+		Usage    int64             `json:"usage"`
+		MaxUsage int64             `json:"max_usage"`
+		Stats    map[string]uint64 `json:"stats"`
+		Limit    int64             `json:"limit"`
 	} `json:"memory_stats"`
 	Name     string `json:"name"`
 	ID       string `json:"id"`
@@ -125,29 +95,115 @@ type dockerStats struct {
 	} `json:"networks"`
 }
 
-func NewClient(host string) (*Client, error) {
-	var opts []client.Opt
-	if host != "" {
-		opts = append(opts, client.WithHost(host))
+type dockerCPUStats struct {
+	CPUUsage struct {
+		TotalUsage        int64   `json:"total_usage"`
+		PercpuUsage       []int64 `json:"percpu_usage"`
+		UsageInKernelmode int64   `json:"usage_in_kernelmode"`
+		UsageInUsermode   int64   `json:"usage_in_usermode"`
+	} `json:"cpu_usage"`
+	SystemCPUUsage int64 `json:"system_cpu_usage"`
+	OnlineCpus     int   `json:"online_cpus"`
+	ThrottlingData struct {
+		Periods          int64 `json:"periods"`
+		ThrottledPeriods int64 `json:"throttled_periods"`
+		ThrottledTime    int64 `json:"throttled_time"`
+	} `json:"throttling_data"`
+}
+
+// NewEndpointClient connects to a resolved context endpoint. Containers it
+// lists carry the endpoint name as their host.
+func NewEndpointClient(endpoint Endpoint) (*Client, error) {
+	opts := []client.Opt{client.WithAPIVersionNegotiation()}
+
+	switch {
+	case strings.HasPrefix(endpoint.Host, "ssh://"):
+		dialer, err := sshDialer(endpoint.Host)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts,
+			client.WithHost("http://docker.example.com"),
+			client.WithDialContext(dialer))
+	case endpoint.TLS():
+		tlsConfig, err := endpointTLSConfig(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("context %s: %w", endpoint.Name, err)
+		}
+		opts = append(opts,
+			client.WithHTTPClient(&http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}),
+			client.WithHost(endpoint.Host))
+	default:
+		opts = append(opts, client.WithHost(endpoint.Host))
 	}
 
 	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("context %s: %w", endpoint.Name, err)
 	}
 
+	c := newClient(cli, endpoint.Name)
+	c.tls = endpoint.TLS() && !strings.HasPrefix(endpoint.Host, "ssh://")
+	return c, nil
+}
+
+func newClient(cli *client.Client, name string) *Client {
 	return &Client{
-		cli: cli,
-		ctx: context.Background(),
-	}, nil
+		cli:       cli,
+		ctx:       context.Background(),
+		name:      name,
+		prevStats: make(map[string]*models.ContainerStats),
+		prevCPU:   make(map[string]dockerCPUStats),
+		cpuLimits: make(map[string]float64),
+		inspected: make(map[string]listedInspect),
+	}
+}
+
+func endpointTLSConfig(endpoint Endpoint) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: endpoint.SkipTLSVerify,
+	}
+
+	if endpoint.CACert != "" {
+		pem, err := os.ReadFile(endpoint.CACert)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", endpoint.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if endpoint.Cert != "" && endpoint.Key != "" {
+		cert, err := tls.LoadX509KeyPair(endpoint.Cert, endpoint.Key)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+func (c *Client) Name() string {
+	return c.name
 }
 
 func (c *Client) Close() error {
 	return c.cli.Close()
 }
 func (c *Client) ListContainers(onlyRunning bool) ([]models.Container, error) {
+	return c.ListContainersWith(ListOptions{OnlyRunning: onlyRunning})
+}
+
+// ListContainersWith reads the inspect, stats and logs of up to
+// listWorkers containers at a time; one-shot stats block for a second or
+// two each, which added up when read one after the other.
+func (c *Client) ListContainersWith(opts ListOptions) ([]models.Container, error) {
 	options := container.ListOptions{
-		All: !onlyRunning,
+		All: !opts.OnlyRunning,
 	}
 
 	containers, err := c.cli.ContainerList(c.ctx, options)
@@ -155,25 +211,86 @@ func (c *Client) ListContainers(onlyRunning bool) ([]models.Container, error) {
 		return nil, err
 	}
 
-	result := make([]models.Container, 0, len(containers))
-	for _, container := range containers {
-		modelContainer := c.convertContainer(container)
-
-		if modelContainer.Status == models.StatusRunning {
-			if stats, err := c.GetContainerStats(container.ID); err == nil {
-				modelContainer.Stats = stats
+	c.forgetStaleStats(containers)
+
+	result := make([]models.Container, len(containers))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(listWorkers, len(containers)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result[i] = c.readListed(containers[i], opts)
 			}
+		}()
+	}
+	for i := range containers {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	c.assignPods(result)
+	return result, nil
+}
+
+// readListed converts a listed container and reads what the listing lacks.
+func (c *Client) readListed(listed types.Container, opts ListOptions) models.Container {
+	modelContainer := c.convertContainer(listed)
+	c.inspectListed(&modelContainer, listed)
+
+	if modelContainer.Status == models.StatusRunning {
+		if stats, err := c.GetContainerStats(listed.ID); err == nil {
+			modelContainer.Stats = stats
 		}
+	}
+
+	if opts.SkipLogs {
+		return modelContainer
+	}
+	if logs, err := c.GetContainerLogs(listed.ID, 100); err == nil {
+		modelContainer.Logs = logs
+	} else {
+		modelContainer.Logs = make([]string, 0)
+	}
+	return modelContainer
+}
 
-		if logs, err := c.GetContainerLogs(container.ID, 100); err == nil {
-			modelContainer.Logs = logs
-		} else {
-			modelContainer.Logs = make([]string, 0)
+// inspectListed fills in the restart count and start time, which listings
+// do not carry but alert rules, metrics and kern ps need. A container that
+// never started keeps its creation time. The inspect also serves the CPU
+// limit that stats would otherwise inspect for. A container is inspected
+// again only once its listed status changes; the status text of a running
+// container ages with its uptime, so a restart always shows in it.
+func (c *Client) inspectListed(container *models.Container, listed types.Container) {
+	status := string(listed.State) + " " + listed.Status
+	c.statsMu.Lock()
+	cached, ok := c.inspected[listed.ID]
+	c.statsMu.Unlock()
+
+	if !ok || cached.status != status {
+		inspect, err := c.InspectContainer(listed.ID)
+		if err != nil {
+			return
 		}
+		cached = listedInspect{status: status, restartCount: inspect.RestartCount}
+		if inspect.State != nil {
+			if started, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt); err == nil && !started.IsZero() {
+				cached.started = started
+			}
+		}
+		c.rememberCPULimit(inspect)
 
-		result = append(result, modelContainer)
+		c.statsMu.Lock()
+		c.inspected[listed.ID] = cached
+		c.statsMu.Unlock()
+	}
+
+	container.RestartCount = cached.restartCount
+	if !cached.started.IsZero() {
+		container.Started = cached.started
 	}
-	return result, nil
 }
 
 func (c *Client) StartContainer(containerID string) error {
@@ -187,11 +304,18 @@ func (c *Client) StopContainer(containerID string) error {
 	})
 }
 
+// RestartContainer also drops the cached inspect of the container: restarted
+// within a second of starting, it lists as "Up Less than a second" again.
 func (c *Client) RestartContainer(containerID string) error {
 	timeoutSecs := 30
-	return c.cli.ContainerRestart(c.ctx, containerID, container.StopOptions{
+	err := c.cli.ContainerRestart(c.ctx, containerID, container.StopOptions{
 		Timeout: &timeoutSecs,
 	})
+
+	c.statsMu.Lock()
+	delete(c.inspected, containerID)
+	c.statsMu.Unlock()
+	return err
 }
 
 func (c *Client) PauseContainer(containerID string) error {
@@ -220,7 +344,64 @@ func (c *Client) GetContainerStats(containerID string) (*models.ContainerStats,
 		return nil, err
 	}
 
-	return c.convertStats(&dockerStat), nil
+	c.fillPreCPU(containerID, &dockerStat)
+	result := c.convertStats(&dockerStat)
+	result.CPU.Limit = c.cpuLimit(containerID)
+	c.applyRates(containerID, result)
+	return result, nil
+}
+
+func (c *Client) applyRates(containerID string, stats *models.ContainerStats) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	stats.ComputeRates(c.prevStats[containerID])
+	c.prevStats[containerID] = stats
+}
+
+// fillPreCPU stands in the previous read of a container when the daemon
+// leaves precpu_stats empty, as Podman does for one-shot stats; otherwise
+// CPU usage would be the average since the container started.
+func (c *Client) fillPreCPU(containerID string, stats *dockerStats) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	if stats.PreCPUStats.SystemCPUUsage == 0 {
+		if prev, ok := c.prevCPU[containerID]; ok && prev.SystemCPUUsage < stats.CPUStats.SystemCPUUsage {
+			stats.PreCPUStats = prev
+		}
+	}
+	c.prevCPU[containerID] = stats.CPUStats
+}
+
+func (c *Client) forgetStaleStats(containers []types.Container) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	alive := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		alive[container.ID] = true
+	}
+	for id := range c.prevStats {
+		if !alive[id] {
+			delete(c.prevStats, id)
+		}
+	}
+	for id := range c.prevCPU {
+		if !alive[id] {
+			delete(c.prevCPU, id)
+		}
+	}
+	for id := range c.cpuLimits {
+		if !alive[id] {
+			delete(c.cpuLimits, id)
+		}
+	}
+	for id := range c.inspected {
+		if !alive[id] {
+			delete(c.inspected, id)
+		}
+	}
 }
 
 func (c *Client) GetContainerLogs(containerID string, lines int) ([]string, error) {
@@ -307,69 +488,77 @@ func (c *Client) InspectContainer(containerID string) (*types.ContainerJSON, err
 	return &resp, nil
 }
 
-func (c *Client) convertStats(stats *dockerStats) *models.ContainerStats {
-	cpuUsage := c.calculateCPUPercentage(stats)
+// LoadContainerConfig fills in the fields that container listings do not
+// carry, such as the environment and the restart count.
+func (c *Client) LoadContainerConfig(container *models.Container) error {
+	inspect, err := c.InspectContainer(container.ID)
+	if err != nil {
+		return err
+	}
+
+	container.RestartCount = inspect.RestartCount
+	if inspect.Config != nil {
+		container.Env = inspect.Config.Env
+	}
+	if inspect.HostConfig != nil {
+		container.RestartPolicy = models.RestartPolicy{
+			Name:              string(inspect.HostConfig.RestartPolicy.Name),
+			MaximumRetryCount: inspect.HostConfig.RestartPolicy.MaximumRetryCount,
+		}
+	}
+	return nil
+}
 
-	var totalRx, totalTx, totalRxPackets, totalTxPackets, totalRxErrors, totalTxErrors, totalRxDropped, totalTxDropped int64
-	for _, netStats := range stats.Networks {
-		totalRx += netStats.RxBytes
-		totalTx += netStats.TxBytes
-		totalRxPackets += netStats.RxPackets
-		totalTxPackets += netStats.TxPackets
-		totalRxErrors += netStats.RxErrors
-		totalTxErrors += netStats.TxErrors
-		totalRxDropped += netStats.RxDropped
-		totalTxDropped += netStats.TxDropped
+func (c *Client) convertStats(stats *dockerStats) *models.ContainerStats {
+	network := models.ContainerNetwork{
+		Interfaces: make([]models.NetworkInterface, 0, len(stats.Networks)),
 	}
+	for name, netStats := range stats.Networks {
+		network.RxBytes += netStats.RxBytes
+		network.TxBytes += netStats.TxBytes
+		network.RxPackets += netStats.RxPackets
+		network.TxPackets += netStats.TxPackets
+		network.RxErrors += netStats.RxErrors
+		network.TxErrors += netStats.TxErrors
+		network.RxDropped += netStats.RxDropped
+		network.TxDropped += netStats.TxDropped
+
+		network.Interfaces = append(network.Interfaces, models.NetworkInterface{
+			Name:      name,
+			RxBytes:   netStats.RxBytes,
+			RxPackets: netStats.RxPackets,
+			RxErrors:  netStats.RxErrors,
+			RxDropped: netStats.RxDropped,
+			TxBytes:   netStats.TxBytes,
+			TxPackets: netStats.TxPackets,
+			TxErrors:  netStats.TxErrors,
+			TxDropped: netStats.TxDropped,
+		})
+	}
+	sort.Slice(network.Interfaces, func(i, j int) bool {
+		return network.Interfaces[i].Name < network.Interfaces[j].Name
+	})
 
 	var readBytes, writeBytes, readOps, writeOps int64
 	for _, blkio := range stats.BlkioStats.IoServiceBytesRecursive {
-		if blkio.Op == "Read" {
+		if strings.EqualFold(blkio.Op, "read") {
 			readBytes += blkio.Value
-		} else if blkio.Op == "Write" {
+		} else if strings.EqualFold(blkio.Op, "write") {
 			writeBytes += blkio.Value
 		}
 	}
 	for _, blkio := range stats.BlkioStats.IoServicedRecursive {
-		if blkio.Op == "Read" {
+		if strings.EqualFold(blkio.Op, "read") {
 			readOps += blkio.Value
-		} else if blkio.Op == "Write" {
+		} else if strings.EqualFold(blkio.Op, "write") {
 			writeOps += blkio.Value
 		}
 	}
 
 	return &models.ContainerStats{
-		CPU: models.ContainerCPU{
-			Usage:  cpuUsage,
-			System: c.calculateSystemCPUPercentage(stats),
-			Cores:  stats.CPUStats.OnlineCpus,
-			Throttling: struct {
-				Periods          int64 `json:"periods"`
-				ThrottledPeriods int64 `json:"throttled_periods"`
-				ThrottledTime    int64 `json:"throttled_time"`
-			}{
-				Periods:          stats.CPUStats.ThrottlingData.Periods,
-				ThrottledPeriods: stats.CPUStats.ThrottlingData.ThrottledPeriods,
-				ThrottledTime:    stats.CPUStats.ThrottlingData.ThrottledTime,
-			},
-		},
-		Memory: models.ContainerMemory{
-			Usage:    stats.MemoryStats.Usage,
-			Limit:    stats.MemoryStats.Limit,
-			Cache:    stats.MemoryStats.Stats.Cache,
-			RSS:      stats.MemoryStats.Stats.RSS,
-			MaxUsage: stats.MemoryStats.MaxUsage,
-		},
-		Network: models.ContainerNetwork{
-			RxBytes:   totalRx,
-			RxPackets: totalRxPackets,
-			RxErrors:  totalRxErrors,
-			RxDropped: totalRxDropped,
-			TxBytes:   totalTx,
-			TxPackets: totalTxPackets,
-			TxErrors:  totalTxErrors,
-			TxDropped: totalTxDropped,
-		},
+		CPU:     c.convertCPU(stats),
+		Memory:  c.convertMemory(stats),
+		Network: network,
 		BlockIO: models.ContainerBlockIO{
 			ReadBytes:  readBytes,
 			WriteBytes: writeBytes,
@@ -381,20 +570,6 @@ func (c *Client) convertStats(stats *dockerStats) *models.ContainerStats {
 	}
 }
 
-func (c *Client) calculateCPUPercentage(stats *dockerStats) float64 {
-	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage - stats.PreCPUStats.CPUUsage.TotalUsage)
-	systemDelta := float64(stats.CPUStats.SystemCPUUsage - stats.PreCPUStats.SystemCPUUsage)
-
-	if systemDelta > 0.0 && cpuDelta > 0.0 {
-		return (cpuDelta / systemDelta) * float64(stats.CPUStats.OnlineCpus) * 100.0
-	}
-	return 0.0
-}
-
-func (c *Client) calculateSystemCPUPercentage(stats *dockerStats) float64 {
-	return float64(stats.CPUStats.SystemCPUUsage) / 1e9 * 100.0
-}
-
 func (c *Client) convertContainer(container types.Container) models.Container {
 	name := container.Names[0]
 	if strings.HasPrefix(name, "/") {
@@ -440,17 +615,8 @@ func (c *Client) convertContainer(container types.Container) models.Container {
 
 	var health *models.ContainerHealth
 	if container.State == "running" {
-		healthStatus := models.HealthStatusNone
-		if strings.Contains(container.Status, "healthy") {
-			healthStatus = models.HealthStatusHealthy
-		} else if strings.Contains(container.Status, "unhealthy") {
-			healthStatus = models.HealthStatusUnhealthy
-		} else if strings.Contains(container.Status, "starting") {
-			healthStatus = models.HealthStatusStarting
-		}
-
 		health = &models.ContainerHealth{
-			Status:        healthStatus,
+			Status:        parseHealth(container.Status),
 			FailingStreak: 0,
 		}
 	}
@@ -459,7 +625,7 @@ func (c *Client) convertContainer(container types.Container) models.Container {
 		ID:            container.ID,
 		Name:          name,
 		Image:         container.Image,
-		Status:        models.ContainerStatus(container.State),
+		Status:        models.ContainerStatus(normalizeState(container.State)),
 		State:         container.Status,
 		Created:       time.Unix(container.Created, 0),
 		Started:       time.Unix(container.Created, 0),
@@ -470,10 +636,14 @@ func (c *Client) convertContainer(container types.Container) models.Container {
 		Command:       container.Command,
 		RestartPolicy: restartPolicy,
 		Health:        health,
+		Host:          c.name,
 	}
 }
 
 func (c *Client) Ping() error {
-	_, err := c.cli.Ping(c.ctx)
+	ctx, cancel := context.WithTimeout(c.ctx, pingTimeout)
+	defer cancel()
+
+	_, err := c.cli.Ping(ctx)
 	return err
 }
@@ -0,0 +1,389 @@
+package docker
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/kqnd/kernus/internal/docker/dockertest"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func newTestClient(t *testing.T) (*Client, *dockertest.Server) {
+	t.Helper()
+	return newServerClient(t, models.MockContainers())
+}
+
+// newServerClient connects to a fake daemon serving the given containers.
+func newServerClient(t *testing.T, containers []models.Container) (*Client, *dockertest.Server) {
+	t.Helper()
+	server := dockertest.NewServer(containers)
+	t.Cleanup(server.Close)
+
+	c, err := NewEndpointClient(Endpoint{Name: "local", Host: server.Host()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, server
+}
+
+func findContainer(t *testing.T, c *Client, id string) *models.Container {
+	t.Helper()
+	containers, err := c.ListContainers(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range containers {
+		if containers[i].ID == id {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func TestListContainers(t *testing.T) {
+	c, _ := newTestClient(t)
+	mocks := models.MockContainers()
+
+	containers, err := c.ListContainers(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != len(mocks) {
+		t.Fatalf("ListContainers(false) returned %d containers, want %d", len(containers), len(mocks))
+	}
+
+	for i, want := range mocks {
+		got := containers[i]
+		if got.ID != want.ID || got.ShortName() != want.ShortName() || got.Image != want.Image {
+			t.Errorf("container %d = %s %s %s, want %s %s %s",
+				i, got.ID, got.ShortName(), got.Image, want.ID, want.ShortName(), want.Image)
+		}
+		if got.Status != want.Status {
+			t.Errorf("%s: Status = %q, want %q", want.Name, got.Status, want.Status)
+		}
+		if got.Host != "local" {
+			t.Errorf("%s: Host = %q, want local", want.Name, got.Host)
+		}
+		if running := got.Status == models.StatusRunning; running != (got.Stats != nil) {
+			t.Errorf("%s: Stats = %v for status %s, want stats only when running", want.Name, got.Stats, got.Status)
+		}
+		if len(got.Ports) != len(want.Ports) {
+			t.Errorf("%s: %d ports, want %d", want.Name, len(got.Ports), len(want.Ports))
+		}
+	}
+
+	running, err := c.ListContainers(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, container := range running {
+		if container.Status != models.StatusRunning {
+			t.Errorf("ListContainers(true) returned %s in state %s", container.Name, container.Status)
+		}
+	}
+	if len(running) == 0 || len(running) >= len(containers) {
+		t.Errorf("ListContainers(true) returned %d of %d containers", len(running), len(containers))
+	}
+}
+
+func TestListContainersStats(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	nginx := findContainer(t, c, "abc123456789")
+	want := models.MockContainers()[0].Stats
+	if nginx == nil || nginx.Stats == nil {
+		t.Fatal("nginx listed without stats")
+	}
+	if got := nginx.Stats.Memory.Usage; got != want.Memory.Usage {
+		t.Errorf("memory usage = %d, want %d", got, want.Memory.Usage)
+	}
+	if got := nginx.Stats.CPU.Usage; !near(got, want.CPU.Usage) {
+		t.Errorf("CPU usage = %.2f, want %.2f", got, want.CPU.Usage)
+	}
+	if got := nginx.Stats.Network.RxBytes; got != want.Network.RxBytes {
+		t.Errorf("rx bytes = %d, want %d", got, want.Network.RxBytes)
+	}
+}
+
+func TestListContainersSkippingLogs(t *testing.T) {
+	c, server := newTestClient(t)
+	mocks := models.MockContainers()
+
+	containers, err := c.ListContainersWith(ListOptions{SkipLogs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != len(mocks) {
+		t.Fatalf("listed %d containers, want %d", len(containers), len(mocks))
+	}
+	// Containers are read in parallel but keep the order of the listing.
+	for i, want := range mocks {
+		got := containers[i]
+		if got.ID != want.ID {
+			t.Errorf("container %d = %s, want %s", i, got.ID, want.ID)
+		}
+		if got.Logs != nil {
+			t.Errorf("%s: %d log lines, want none", want.Name, len(got.Logs))
+		}
+		if running := got.Status == models.StatusRunning; running != (got.Stats != nil) {
+			t.Errorf("%s: Stats = %v for status %s, want stats only when running", want.Name, got.Stats, got.Status)
+		}
+	}
+	for _, request := range server.Requests() {
+		if strings.Contains(request, "/logs") {
+			t.Errorf("listing without logs requested %s", request)
+		}
+	}
+}
+
+func TestListContainersCachesInspect(t *testing.T) {
+	c, server := newTestClient(t)
+	inspects := func(id string) int {
+		count := 0
+		for _, request := range server.Requests() {
+			if strings.HasPrefix(request, "GET ") && strings.HasSuffix(request, "/containers/"+id+"/json") {
+				count++
+			}
+		}
+		return count
+	}
+	list := func() {
+		if _, err := c.ListContainersWith(ListOptions{SkipLogs: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list()
+	list()
+	for _, mock := range models.MockContainers() {
+		if n := inspects(mock.ID); n != 1 {
+			t.Errorf("%s inspected %d times in two listings, want once", mock.Name, n)
+		}
+	}
+
+	// Stopping changes the listed state, restarting at least the status text
+	// or else the cached inspect is dropped.
+	if err := c.StopContainer("abc123456789"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RestartContainer("def456789012"); err != nil {
+		t.Fatal(err)
+	}
+	list()
+	for id, want := range map[string]int{"abc123456789": 2, "def456789012": 2, "jkl012345678": 1} {
+		if n := inspects(id); n != want {
+			t.Errorf("%s inspected %d times, want %d", id, n, want)
+		}
+	}
+}
+
+func TestGetContainerStatsRates(t *testing.T) {
+	c, server := newTestClient(t)
+	const id = "abc123456789"
+
+	start := time.Now().Truncate(time.Second)
+	sample := func(offset time.Duration, rx, tx, read, write uint64) []byte {
+		raw, err := json.Marshal(container.StatsResponse{
+			ID:   id,
+			Read: start.Add(offset),
+			Networks: map[string]container.NetworkStats{
+				"eth0": {RxBytes: rx, TxBytes: tx},
+			},
+			BlkioStats: container.BlkioStats{
+				IoServiceBytesRecursive: []container.BlkioStatEntry{
+					{Major: 259, Op: "read", Value: read},
+					{Major: 259, Op: "write", Value: write},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+
+	server.SetStats(id, sample(0, 1000, 500, 4096, 8192))
+	first, err := c.GetContainerStats(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Network.Rate != (models.NetworkRate{}) || first.BlockIO.Rate != (models.BlockIORate{}) {
+		t.Errorf("first read rates = %+v %+v, want none without a previous read",
+			first.Network.Rate, first.BlockIO.Rate)
+	}
+
+	server.SetStats(id, sample(2*time.Second, 3000, 1500, 8192, 8192))
+	second, err := c.GetContainerStats(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := second.Network.Rate; !near(got.RxBytes, 1000) || !near(got.TxBytes, 500) {
+		t.Errorf("network rate = %+v, want 1000 rx and 500 tx per second", got)
+	}
+	if got := second.BlockIO.Rate; !near(got.ReadBytes, 2048) || !near(got.WriteBytes, 0) {
+		t.Errorf("block IO rate = %+v, want 2048 read and 0 write per second", got)
+	}
+
+	// A counter that goes backwards, as after a restart, gives no rate
+	// rather than a negative one.
+	server.SetStats(id, sample(3*time.Second, 100, 50, 0, 0))
+	third, err := c.GetContainerStats(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := third.Network.Rate; got.RxBytes < 0 || got.TxBytes < 0 {
+		t.Errorf("network rate after reset = %+v, want no negative rate", got)
+	}
+}
+
+func TestContainerActions(t *testing.T) {
+	c, server := newTestClient(t)
+	const id = "abc123456789"
+
+	status := func() models.ContainerStatus {
+		t.Helper()
+		container := findContainer(t, c, id)
+		if container == nil {
+			t.Fatalf("container %s not listed", id)
+		}
+		return container.Status
+	}
+
+	if err := c.StopContainer(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got != models.StatusExited {
+		t.Errorf("after stop Status = %q, want exited", got)
+	}
+
+	if err := c.StartContainer(id); err != nil {
+		t.Fatal(err)
+	}
+	if got := status(); got != models.StatusRunning {
+		t.Errorf("after start Status = %q, want running", got)
+	}
+
+	var before models.Container
+	before.ID = id
+	if err := c.LoadContainerConfig(&before); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RestartContainer(id); err != nil {
+		t.Fatal(err)
+	}
+	after := models.Container{ID: id}
+	if err := c.LoadContainerConfig(&after); err != nil {
+		t.Fatal(err)
+	}
+	if after.RestartCount != before.RestartCount+1 {
+		t.Errorf("RestartCount = %d after restart, want %d", after.RestartCount, before.RestartCount+1)
+	}
+	if listed := findContainer(t, c, id); listed.RestartCount != after.RestartCount {
+		t.Errorf("listed RestartCount = %d, want %d as inspected", listed.RestartCount, after.RestartCount)
+	}
+
+	if err := c.RemoveContainer(id, false); err == nil {
+		t.Error("RemoveContainer() of a running container without force succeeded")
+	}
+	if err := c.RemoveContainer(id, true); err != nil {
+		t.Fatal(err)
+	}
+	if findContainer(t, c, id) != nil {
+		t.Error("container still listed after a forced remove")
+	}
+	if err := c.StartContainer(id); err == nil {
+		t.Error("StartContainer() of a removed container succeeded")
+	}
+
+	var actions []string
+	for _, request := range server.Requests() {
+		if strings.HasPrefix(request, "POST ") || strings.HasPrefix(request, "DELETE ") {
+			actions = append(actions, request[strings.LastIndex(request, "/")+1:])
+		}
+	}
+	want := []string{"stop", "start", "restart", id, id, "start"}
+	if strings.Join(actions, " ") != strings.Join(want, " ") {
+		t.Errorf("actions sent = %v, want %v", actions, want)
+	}
+}
+
+func TestGetContainerLogs(t *testing.T) {
+	c, _ := newTestClient(t)
+	const id = "abc123456789"
+
+	all, err := c.GetContainerLogs(id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 3 {
+		t.Fatalf("GetContainerLogs() returned %d lines, want the recorded log", len(all))
+	}
+	for _, line := range all {
+		stamp, _, ok := strings.Cut(line, " ")
+		if _, err := time.Parse(time.RFC3339Nano, stamp); !ok || err != nil {
+			t.Errorf("log line %q does not start with a timestamp", line)
+		}
+	}
+
+	tail, err := c.GetContainerLogs(id, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := func(line string) string {
+		_, text, _ := strings.Cut(line, " ")
+		return text
+	}
+	if len(tail) != 2 || message(tail[0]) != message(all[len(all)-2]) || message(tail[1]) != message(all[len(all)-1]) {
+		t.Errorf("GetContainerLogs(2) = %q, want the last two of %q", tail, all)
+	}
+
+	if _, err := c.GetContainerLogs("missing", 10); err == nil {
+		t.Error("GetContainerLogs() of an unknown container succeeded")
+	}
+}
+
+func TestInspectContainer(t *testing.T) {
+	c, _ := newTestClient(t)
+	want := models.MockContainers()[1]
+
+	inspect, err := c.InspectContainer(want.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inspect.Name != want.Name || inspect.Config.Image != want.Image {
+		t.Errorf("inspect = %s %s, want %s %s", inspect.Name, inspect.Config.Image, want.Name, want.Image)
+	}
+	if !inspect.State.Running {
+		t.Error("State.Running = false for a running container")
+	}
+
+	// Listing reads when containers started; one that never ran keeps its
+	// creation time.
+	started, err := time.Parse(time.RFC3339Nano, inspect.State.StartedAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed := findContainer(t, c, want.ID); !listed.Started.Equal(started) {
+		t.Errorf("listed Started = %s, want %s as inspected", listed.Started, started)
+	}
+	if redis := findContainer(t, c, "ghi789012345"); !redis.Started.Equal(redis.Created) {
+		t.Errorf("never started container listed with Started = %s, want its creation time %s", redis.Started, redis.Created)
+	}
+
+	var loaded models.Container
+	loaded.ID = want.ID
+	if err := c.LoadContainerConfig(&loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.RestartPolicy != want.RestartPolicy {
+		t.Errorf("RestartPolicy = %+v, want %+v", loaded.RestartPolicy, want.RestartPolicy)
+	}
+
+	if _, err := c.InspectContainer("missing"); err == nil {
+		t.Error("InspectContainer() of an unknown container succeeded")
+	}
+}
@@ -0,0 +1,235 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	DefaultContext = "default"
+	AllContexts    = "all"
+)
+
+// Endpoint is a Docker daemon kernus can connect to, usually resolved from a
+// Docker CLI context.
+type Endpoint struct {
+	Name          string
+	Host          string
+	CACert        string
+	Cert          string
+	Key           string
+	SkipTLSVerify bool
+}
+
+func (e Endpoint) TLS() bool {
+	return e.CACert != "" || e.Cert != "" || e.SkipTLSVerify
+}
+
+// Local reports whether the daemon runs on this machine, behind a unix
+// socket or a Windows named pipe.
+func (e Endpoint) Local() bool {
+	return e.Host == "" || strings.HasPrefix(e.Host, "unix://") || strings.HasPrefix(e.Host, "npipe://")
+}
+
+type contextMeta struct {
+	Name      string `json:"Name"`
+	Endpoints map[string]struct {
+		Host          string `json:"Host"`
+		SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	} `json:"Endpoints"`
+}
+
+// ConfigDir is the Docker CLI configuration directory, honouring
+// DOCKER_CONFIG like the CLI does.
+func ConfigDir() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".docker"
+	}
+	return filepath.Join(home, ".docker")
+}
+
+// CurrentContext returns the context the Docker CLI would use: DOCKER_CONTEXT,
+// then currentContext from config.json, then the default context.
+func CurrentContext(configDir string) string {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err == nil {
+		var cfg struct {
+			CurrentContext string `json:"currentContext"`
+		}
+		if json.Unmarshal(data, &cfg) == nil && cfg.CurrentContext != "" {
+			return cfg.CurrentContext
+		}
+	}
+	return DefaultContext
+}
+
+// LoadContexts reads every context stored by the Docker CLI. The default
+// context is always first and points at DOCKER_HOST or the local socket.
+func LoadContexts(configDir string) ([]Endpoint, error) {
+	endpoints := []Endpoint{defaultEndpoint()}
+
+	metaDir := filepath.Join(configDir, "contexts", "meta")
+	entries, err := os.ReadDir(metaDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var stored []Endpoint
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(metaDir, entry.Name(), "meta.json"))
+		if err != nil {
+			continue
+		}
+
+		var meta contextMeta
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("docker context %s: %w", entry.Name(), err)
+		}
+		dockerEndpoint, ok := meta.Endpoints["docker"]
+		if !ok || meta.Name == "" {
+			continue
+		}
+
+		endpoint := Endpoint{
+			Name:          meta.Name,
+			Host:          dockerEndpoint.Host,
+			SkipTLSVerify: dockerEndpoint.SkipTLSVerify,
+		}
+		tlsDir := filepath.Join(configDir, "contexts", "tls", contextDirName(meta.Name), "docker")
+		endpoint.CACert = existingFile(filepath.Join(tlsDir, "ca.pem"))
+		endpoint.Cert = existingFile(filepath.Join(tlsDir, "cert.pem"))
+		endpoint.Key = existingFile(filepath.Join(tlsDir, "key.pem"))
+		stored = append(stored, endpoint)
+	}
+
+	if podman, ok := podmanEndpoint(stored, endpoints[0].Host); ok {
+		stored = append(stored, podman)
+	}
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].Name < stored[j].Name
+	})
+	return append(endpoints, stored...), nil
+}
+
+// podmanEndpoint is offered as a context of its own when a Podman socket
+// exists, so Docker and Podman can be watched side by side.
+func podmanEndpoint(stored []Endpoint, defaultHost string) (Endpoint, bool) {
+	socket := podmanSocketPath()
+	if socket == "" || defaultHost == "unix://"+socket {
+		return Endpoint{}, false
+	}
+	for _, endpoint := range stored {
+		if endpoint.Name == PodmanContext {
+			return Endpoint{}, false
+		}
+	}
+	return Endpoint{Name: PodmanContext, Host: "unix://" + socket}, true
+}
+
+// ResolveEndpoints turns context names into endpoints. With no names it
+// follows the CLI: DOCKER_HOST wins, otherwise the current context is used.
+// The name "all" selects every known context.
+func ResolveEndpoints(names []string) ([]Endpoint, error) {
+	configDir := ConfigDir()
+	contexts, err := LoadContexts(configDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		if os.Getenv("DOCKER_HOST") != "" {
+			return []Endpoint{defaultEndpoint()}, nil
+		}
+		names = []string{CurrentContext(configDir)}
+	}
+
+	byName := make(map[string]Endpoint, len(contexts))
+	for _, endpoint := range contexts {
+		byName[endpoint.Name] = endpoint
+	}
+
+	var result []Endpoint
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name == AllContexts {
+			for _, endpoint := range contexts {
+				if !seen[endpoint.Name] {
+					seen[endpoint.Name] = true
+					result = append(result, endpoint)
+				}
+			}
+			continue
+		}
+
+		endpoint, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("docker context %q not found in %s", name, configDir)
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, endpoint)
+		}
+	}
+	return result, nil
+}
+
+func defaultEndpoint() Endpoint {
+	endpoint := Endpoint{Name: DefaultContext, Host: os.Getenv("DOCKER_HOST")}
+	if endpoint.Host == "" {
+		endpoint.Host = client.DefaultDockerHost
+		if socket := podmanSocketPath(); socket != "" && !dockerSocketExists() {
+			endpoint.Host = "unix://" + socket
+		}
+	}
+
+	if os.Getenv("DOCKER_TLS_VERIFY") != "" || os.Getenv("DOCKER_CERT_PATH") != "" {
+		certPath := os.Getenv("DOCKER_CERT_PATH")
+		if certPath == "" {
+			certPath = ConfigDir()
+		}
+		endpoint.CACert = existingFile(filepath.Join(certPath, "ca.pem"))
+		endpoint.Cert = existingFile(filepath.Join(certPath, "cert.pem"))
+		endpoint.Key = existingFile(filepath.Join(certPath, "key.pem"))
+		endpoint.SkipTLSVerify = os.Getenv("DOCKER_TLS_VERIFY") == ""
+	}
+	return endpoint
+}
+
+func dockerSocketExists() bool {
+	socket, ok := strings.CutPrefix(client.DefaultDockerHost, "unix://")
+	return !ok || existingFile(socket) != ""
+}
+
+// The CLI stores each context under the hex SHA-256 of its name.
+func contextDirName(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+func existingFile(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
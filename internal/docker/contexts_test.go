@@ -0,0 +1,207 @@
+package docker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dockerConfig points DOCKER_CONFIG at a temporary directory, clears the
+// variables the CLI reads and puts a Podman socket under XDG_RUNTIME_DIR so
+// the contexts found do not depend on the machine.
+func dockerConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("DOCKER_HOST", "tcp://127.0.0.1:2375")
+	t.Setenv("DOCKER_CONTEXT", "")
+	t.Setenv("DOCKER_TLS_VERIFY", "")
+	t.Setenv("DOCKER_CERT_PATH", "")
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	writeFile(t, filepath.Join(runtimeDir, "podman", "podman.sock"), "")
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeContext stores a context the way `docker context create` does.
+func writeContext(t *testing.T, configDir, name, host string, tlsFiles ...string) {
+	t.Helper()
+	meta := map[string]any{
+		"Name":      name,
+		"Metadata":  map[string]any{},
+		"Endpoints": map[string]any{"docker": map[string]any{"Host": host, "SkipTLSVerify": false}},
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(configDir, "contexts", "meta", contextDirName(name), "meta.json"), string(data))
+	for _, file := range tlsFiles {
+		writeFile(t, filepath.Join(configDir, "contexts", "tls", contextDirName(name), "docker", file), "pem")
+	}
+}
+
+func endpointNames(endpoints []Endpoint) string {
+	names := make([]string, len(endpoints))
+	for i, endpoint := range endpoints {
+		names[i] = endpoint.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestLoadContexts(t *testing.T) {
+	dir := dockerConfig(t)
+	writeContext(t, dir, "prod", "ssh://deploy@prod.example.com:2222")
+	writeContext(t, dir, "build", "tcp://build.example.com:2376", "ca.pem", "cert.pem", "key.pem")
+	// A context directory without meta.json, as left by an interrupted
+	// `docker context rm`, is skipped.
+	if err := os.MkdirAll(filepath.Join(dir, "contexts", "meta", contextDirName("gone")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// So is a context without a docker endpoint.
+	writeFile(t, filepath.Join(dir, "contexts", "meta", contextDirName("k8s"), "meta.json"),
+		`{"Name":"k8s","Endpoints":{"kubernetes":{"Host":"https://k8s.example.com"}}}`)
+
+	contexts, err := LoadContexts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := endpointNames(contexts); got != "default build podman prod" {
+		t.Fatalf("LoadContexts() = %s, want default first and the rest by name", got)
+	}
+	byName := make(map[string]Endpoint)
+	for _, endpoint := range contexts {
+		byName[endpoint.Name] = endpoint
+	}
+
+	if got := byName["default"]; got.Host != "tcp://127.0.0.1:2375" || got.TLS() || got.Local() {
+		t.Errorf("default = %+v, want DOCKER_HOST without TLS", got)
+	}
+
+	build := byName["build"]
+	tlsDir := filepath.Join(dir, "contexts", "tls", contextDirName("build"), "docker")
+	if build.CACert != filepath.Join(tlsDir, "ca.pem") || build.Cert != filepath.Join(tlsDir, "cert.pem") ||
+		build.Key != filepath.Join(tlsDir, "key.pem") || !build.TLS() {
+		t.Errorf("build = %+v, want the stored TLS files", build)
+	}
+
+	prod := byName["prod"]
+	if prod.Host != "ssh://deploy@prod.example.com:2222" || prod.TLS() || prod.Local() {
+		t.Errorf("prod = %+v, want a remote ssh host without TLS", prod)
+	}
+
+	podman := byName["podman"]
+	if podman.Host != "unix://"+filepath.Join(os.Getenv("XDG_RUNTIME_DIR"), "podman", "podman.sock") || !podman.Local() {
+		t.Errorf("podman = %+v, want the local socket", podman)
+	}
+}
+
+func TestLoadContextsStoredPodmanWins(t *testing.T) {
+	dir := dockerConfig(t)
+	writeContext(t, dir, "podman", "ssh://core@podman.example.com/run/podman/podman.sock")
+
+	contexts, err := LoadContexts(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := endpointNames(contexts); got != "default podman" {
+		t.Fatalf("LoadContexts() = %s, want the stored podman context only", got)
+	}
+	if host := contexts[1].Host; !strings.HasPrefix(host, "ssh://") {
+		t.Errorf("podman host = %s, want the stored one", host)
+	}
+}
+
+func TestLoadContextsRejectsBrokenMeta(t *testing.T) {
+	dir := dockerConfig(t)
+	writeFile(t, filepath.Join(dir, "contexts", "meta", contextDirName("broken"), "meta.json"), `{"Name":`)
+
+	if _, err := LoadContexts(dir); err == nil || !strings.Contains(err.Error(), "docker context "+contextDirName("broken")) {
+		t.Errorf("LoadContexts() = %v, want the broken context named", err)
+	}
+}
+
+func TestCurrentContext(t *testing.T) {
+	dir := dockerConfig(t)
+	if got := CurrentContext(dir); got != DefaultContext {
+		t.Errorf("CurrentContext() without config.json = %s, want default", got)
+	}
+
+	writeFile(t, filepath.Join(dir, "config.json"), `{"auths":{},"currentContext":"prod"}`)
+	if got := CurrentContext(dir); got != "prod" {
+		t.Errorf("CurrentContext() = %s, want prod from config.json", got)
+	}
+
+	t.Setenv("DOCKER_CONTEXT", "build")
+	if got := CurrentContext(dir); got != "build" {
+		t.Errorf("CurrentContext() = %s, want DOCKER_CONTEXT", got)
+	}
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	dir := dockerConfig(t)
+	writeContext(t, dir, "prod", "ssh://deploy@prod.example.com")
+	writeContext(t, dir, "build", "tcp://build.example.com:2376")
+	writeFile(t, filepath.Join(dir, "config.json"), `{"currentContext":"prod"}`)
+
+	tests := []struct {
+		name       string
+		names      []string
+		dockerHost string
+		want       string
+		wantErr    string
+	}{
+		{name: "DOCKER_HOST wins over the current context", dockerHost: "tcp://10.0.0.1:2375", want: "default"},
+		{name: "current context", want: "prod"},
+		{name: "named contexts in order, once each", names: []string{"build", "prod", "build"}, want: "build prod"},
+		// DOCKER_HOST keeps the default context off the Podman socket.
+		{name: "all", names: []string{"prod", AllContexts}, dockerHost: "tcp://10.0.0.1:2375", want: "prod default build podman"},
+		{name: "unknown context", names: []string{"staging"}, wantErr: `docker context "staging" not found in ` + dir},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DOCKER_HOST", tt.dockerHost)
+			endpoints, err := ResolveEndpoints(tt.names)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ResolveEndpoints() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := endpointNames(endpoints); got != tt.want {
+				t.Errorf("ResolveEndpoints(%v) = %s, want %s", tt.names, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHEndpointClient(t *testing.T) {
+	c, err := NewEndpointClient(Endpoint{Name: "prod", Host: "ssh://deploy@prod.example.com:2222/var/run/docker.sock"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if c.tls {
+		t.Error("ssh endpoint marked as TLS")
+	}
+
+	if _, err := NewEndpointClient(Endpoint{Name: "broken", Host: "ssh://"}); err == nil {
+		t.Error("NewEndpointClient() accepted an ssh endpoint without a host")
+	}
+}
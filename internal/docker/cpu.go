@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func (c *Client) convertCPU(stats *dockerStats) models.ContainerCPU {
+	cpu := models.ContainerCPU{
+		Usage:   c.calculateCPUPercentage(stats),
+		System:  c.calculateModePercentage(stats, stats.CPUStats.CPUUsage.UsageInKernelmode, stats.PreCPUStats.CPUUsage.UsageInKernelmode),
+		User:    c.calculateModePercentage(stats, stats.CPUStats.CPUUsage.UsageInUsermode, stats.PreCPUStats.CPUUsage.UsageInUsermode),
+		Cores:   onlineCPUs(stats),
+		PerCore: c.calculatePerCorePercentages(stats),
+	}
+	cpu.Throttling.Periods = stats.CPUStats.ThrottlingData.Periods
+	cpu.Throttling.ThrottledPeriods = stats.CPUStats.ThrottlingData.ThrottledPeriods
+	cpu.Throttling.ThrottledTime = stats.CPUStats.ThrottlingData.ThrottledTime
+	return cpu
+}
+
+// onlineCPUs falls back to the per-CPU slice length for daemons that do not
+// report online_cpus, the same way the Docker CLI does.
+func onlineCPUs(stats *dockerStats) int {
+	if stats.CPUStats.OnlineCpus > 0 {
+		return stats.CPUStats.OnlineCpus
+	}
+	return len(stats.CPUStats.CPUUsage.PercpuUsage)
+}
+
+func systemDelta(stats *dockerStats) float64 {
+	return float64(stats.CPUStats.SystemCPUUsage - stats.PreCPUStats.SystemCPUUsage)
+}
+
+func (c *Client) calculateCPUPercentage(stats *dockerStats) float64 {
+	return c.calculateModePercentage(stats, stats.CPUStats.CPUUsage.TotalUsage, stats.PreCPUStats.CPUUsage.TotalUsage)
+}
+
+func (c *Client) calculateModePercentage(stats *dockerStats, current, previous int64) float64 {
+	cpuDelta := float64(current - previous)
+	sysDelta := systemDelta(stats)
+
+	if sysDelta > 0.0 && cpuDelta > 0.0 {
+		return (cpuDelta / sysDelta) * float64(onlineCPUs(stats)) * 100.0
+	}
+	return 0.0
+}
+
+// calculatePerCorePercentages is empty on cgroup v2, where the daemon does
+// not report percpu_usage.
+func (c *Client) calculatePerCorePercentages(stats *dockerStats) []float64 {
+	current := stats.CPUStats.CPUUsage.PercpuUsage
+	previous := stats.PreCPUStats.CPUUsage.PercpuUsage
+	cores := onlineCPUs(stats)
+	sysDelta := systemDelta(stats)
+
+	if len(current) == 0 || cores == 0 || sysDelta <= 0 {
+		return nil
+	}
+
+	perCoreCapacity := sysDelta / float64(cores)
+	result := make([]float64, len(current))
+	for i := range current {
+		if i >= len(previous) {
+			break
+		}
+		delta := float64(current[i] - previous[i])
+		if delta > 0 {
+			result[i] = delta / perCoreCapacity * 100.0
+		}
+	}
+	return result
+}
+
+func (c *Client) cpuLimit(containerID string) float64 {
+	c.statsMu.Lock()
+	limit, ok := c.cpuLimits[containerID]
+	c.statsMu.Unlock()
+	if ok {
+		return limit
+	}
+
+	inspect, err := c.InspectContainer(containerID)
+	if err != nil {
+		return 0
+	}
+	return c.rememberCPULimit(inspect)
+}
+
+// rememberCPULimit caches the CPU limit of an inspected container, in cores,
+// or 0 without one.
+func (c *Client) rememberCPULimit(inspect *types.ContainerJSON) float64 {
+	var limit float64
+	if inspect.HostConfig != nil {
+		resources := inspect.HostConfig.Resources
+		switch {
+		case resources.NanoCPUs > 0:
+			limit = float64(resources.NanoCPUs) / 1e9
+		case resources.CPUQuota > 0:
+			period := resources.CPUPeriod
+			if period == 0 {
+				period = 100000
+			}
+			limit = float64(resources.CPUQuota) / float64(period)
+		}
+	}
+
+	c.statsMu.Lock()
+	c.cpuLimits[inspect.ID] = limit
+	c.statsMu.Unlock()
+	return limit
+}
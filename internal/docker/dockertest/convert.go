@@ -0,0 +1,364 @@
+package dockertest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// cpuWindow is the time between the two CPU reads of a stats response.
+const cpuWindow = uint64(time.Second)
+
+func (s *Server) addContainer(c models.Container) {
+	name := "/" + strings.TrimPrefix(c.Name, "/")
+	summary := container.Summary{
+		ID:              c.ID,
+		Names:           []string{name},
+		Image:           c.Image,
+		ImageID:         imageID(c.Image),
+		Command:         c.Command,
+		Created:         c.Created.Unix(),
+		Labels:          c.Labels,
+		State:           container.ContainerState(c.Status),
+		Status:          statusText(&c),
+		NetworkSettings: &container.NetworkSettingsSummary{Networks: make(map[string]*network.EndpointSettings)},
+	}
+	for _, port := range c.Ports {
+		summary.Ports = append(summary.Ports, container.Port{
+			IP:          port.IP,
+			PrivatePort: uint16(port.PrivatePort),
+			PublicPort:  uint16(port.PublicPort),
+			Type:        port.Type,
+		})
+	}
+	for _, m := range c.Mounts {
+		point := container.MountPoint{
+			Type:        mount.Type(m.Type),
+			Source:      m.Source,
+			Destination: m.Destination,
+			Mode:        m.Mode,
+			RW:          m.RW,
+		}
+		if point.Type == mount.TypeVolume {
+			point.Name = volumeName(m.Source)
+			point.Driver = "local"
+		}
+		summary.Mounts = append(summary.Mounts, point)
+	}
+
+	networks := c.Networks
+	if len(networks) == 0 {
+		index := len(s.containers) + 2
+		networks = []models.Network{{
+			Name:       "bridge",
+			IPAddress:  fmt.Sprintf("172.17.0.%d", index),
+			Gateway:    "172.17.0.1",
+			MacAddress: fmt.Sprintf("02:42:ac:11:00:%02x", index),
+		}}
+	}
+	for _, n := range networks {
+		settings := &network.EndpointSettings{
+			NetworkID:  hashID(n.Name),
+			Gateway:    n.Gateway,
+			MacAddress: n.MacAddress,
+		}
+		if c.Status == models.StatusRunning || c.Status == models.StatusPaused {
+			settings.IPAddress = n.IPAddress
+		}
+		summary.NetworkSettings.Networks[n.Name] = settings
+	}
+
+	s.containers = append(s.containers, summary)
+	s.inspect[c.ID] = inspectResponse(&c, summary)
+	if c.Stats != nil {
+		raw, _ := json.Marshal(statsResponse(&c, c.Stats))
+		s.stats[c.ID] = raw
+	}
+	s.logs[c.ID] = logLines(&c)
+}
+
+func statusText(c *models.Container) string {
+	switch c.Status {
+	case models.StatusRunning, models.StatusPaused:
+		status := "Up " + humanDuration(time.Since(c.Started))
+		if c.Health != nil && c.Health.Status != models.HealthStatusNone {
+			status += fmt.Sprintf(" (%s)", c.Health.Status)
+		}
+		if c.Status == models.StatusPaused {
+			status += " (Paused)"
+		}
+		return status
+	case models.StatusExited:
+		return fmt.Sprintf("Exited (%d) %s ago", c.ExitCode, humanDuration(time.Since(c.Finished)))
+	case models.StatusCreated:
+		return "Created"
+	}
+	return strings.ToUpper(string(c.Status[:1])) + string(c.Status[1:])
+}
+
+func inspectResponse(c *models.Container, summary container.Summary) *container.InspectResponse {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "0001-01-01T00:00:00Z"
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	inspect := &container.InspectResponse{
+		ContainerJSONBase: &container.ContainerJSONBase{
+			ID:           c.ID,
+			Created:      formatTime(c.Created),
+			Name:         summary.Names[0],
+			Image:        summary.ImageID,
+			RestartCount: c.RestartCount,
+			State: &container.State{
+				Status:     summary.State,
+				Running:    c.Status == models.StatusRunning || c.Status == models.StatusPaused,
+				Paused:     c.Status == models.StatusPaused,
+				ExitCode:   c.ExitCode,
+				StartedAt:  formatTime(c.Started),
+				FinishedAt: formatTime(c.Finished),
+			},
+			HostConfig: &container.HostConfig{
+				RestartPolicy: container.RestartPolicy{
+					Name:              container.RestartPolicyMode(c.RestartPolicy.Name),
+					MaximumRetryCount: c.RestartPolicy.MaximumRetryCount,
+				},
+			},
+		},
+		Mounts: summary.Mounts,
+		Config: &container.Config{
+			Image:  c.Image,
+			Env:    c.Env,
+			Labels: c.Labels,
+			Cmd:    strings.Fields(c.Command),
+		},
+	}
+	if c.Stats != nil && c.Stats.CPU.Limit > 0 {
+		inspect.HostConfig.NanoCPUs = int64(c.Stats.CPU.Limit * 1e9)
+	}
+	return inspect
+}
+
+// statsResponse builds the counters the daemon would report for the given
+// stats, so that the client computes the same percentages back.
+func statsResponse(c *models.Container, stats *models.ContainerStats) container.StatsResponse {
+	cores := uint64(stats.CPU.Cores)
+	if cores == 0 {
+		cores = 1
+	}
+	systemWindow := cpuWindow * cores
+	used := func(percent float64) uint64 {
+		return uint64(percent / 100 * float64(cpuWindow))
+	}
+
+	previous := container.CPUStats{
+		CPUUsage:    container.CPUUsage{TotalUsage: uint64(c.Created.Unix()) % 1000 * cpuWindow},
+		SystemUsage: 1_000_000 * systemWindow,
+		OnlineCPUs:  uint32(cores),
+	}
+	current := previous
+	current.CPUUsage.TotalUsage += used(stats.CPU.Usage)
+	current.CPUUsage.UsageInKernelmode += used(stats.CPU.System)
+	current.CPUUsage.UsageInUsermode += used(stats.CPU.User)
+	current.SystemUsage += systemWindow
+	current.ThrottlingData = container.ThrottlingData{
+		Periods:          uint64(stats.CPU.Throttling.Periods),
+		ThrottledPeriods: uint64(stats.CPU.Throttling.ThrottledPeriods),
+		ThrottledTime:    uint64(stats.CPU.Throttling.ThrottledTime),
+	}
+
+	read := stats.Timestamp
+	if read.IsZero() {
+		read = time.Now()
+	}
+
+	return container.StatsResponse{
+		Name:        "/" + strings.TrimPrefix(c.Name, "/"),
+		ID:          c.ID,
+		Read:        read,
+		PreRead:     read.Add(-time.Second),
+		PidsStats:   container.PidsStats{Current: uint64(stats.PIDs)},
+		CPUStats:    current,
+		PreCPUStats: previous,
+		MemoryStats: container.MemoryStats{
+			Usage: uint64(stats.Memory.Usage + stats.Memory.InactiveFile),
+			Limit: uint64(stats.Memory.Limit),
+			Stats: map[string]uint64{
+				"anon":          uint64(stats.Memory.RSS),
+				"file":          uint64(stats.Memory.Cache),
+				"inactive_file": uint64(stats.Memory.InactiveFile),
+			},
+		},
+		BlkioStats: container.BlkioStats{
+			IoServiceBytesRecursive: []container.BlkioStatEntry{
+				{Major: 259, Op: "read", Value: uint64(stats.BlockIO.ReadBytes)},
+				{Major: 259, Op: "write", Value: uint64(stats.BlockIO.WriteBytes)},
+			},
+			IoServicedRecursive: []container.BlkioStatEntry{
+				{Major: 259, Op: "read", Value: uint64(stats.BlockIO.ReadOps)},
+				{Major: 259, Op: "write", Value: uint64(stats.BlockIO.WriteOps)},
+			},
+		},
+		Networks: map[string]container.NetworkStats{
+			"eth0": {
+				RxBytes:   uint64(stats.Network.RxBytes),
+				RxPackets: uint64(stats.Network.RxPackets),
+				RxErrors:  uint64(stats.Network.RxErrors),
+				RxDropped: uint64(stats.Network.RxDropped),
+				TxBytes:   uint64(stats.Network.TxBytes),
+				TxPackets: uint64(stats.Network.TxPackets),
+				TxErrors:  uint64(stats.Network.TxErrors),
+				TxDropped: uint64(stats.Network.TxDropped),
+			},
+		},
+	}
+}
+
+func logLines(c *models.Container) []string {
+	if len(c.Logs) > 0 {
+		return c.Logs
+	}
+	if c.Started.IsZero() {
+		return nil
+	}
+	name := strings.TrimPrefix(c.Name, "/")
+	return []string{
+		"starting " + name,
+		"loaded configuration",
+		"listening on " + c.MainPort(),
+		"ready to accept connections",
+	}
+}
+
+// deriveResources fills images, volumes and networks from what the
+// containers reference, plus a dangling image and the given predefined
+// networks.
+func (s *Server) deriveResources(predefined ...string) {
+	seenImages := make(map[string]bool)
+	seenVolumes := make(map[string]bool)
+	networks := make(map[string]*network.Summary)
+
+	for i, c := range s.containers {
+		if !seenImages[c.ImageID] {
+			seenImages[c.ImageID] = true
+			tag := c.Image
+			if !strings.Contains(tag[strings.LastIndex(tag, "/")+1:], ":") {
+				tag += ":latest"
+			}
+			s.images = append(s.images, image.Summary{
+				ID:       c.ImageID,
+				RepoTags: []string{tag},
+				Created:  c.Created - 86400,
+				Size:     int64(40+25*i) * 1024 * 1024,
+				Labels:   map[string]string{},
+			})
+		}
+
+		for _, m := range c.Mounts {
+			if m.Type != mount.TypeVolume || seenVolumes[m.Name] {
+				continue
+			}
+			seenVolumes[m.Name] = true
+			s.volumes = append(s.volumes, &volume.Volume{
+				Name:       m.Name,
+				Driver:     "local",
+				Mountpoint: m.Source,
+				Scope:      "local",
+				CreatedAt:  time.Unix(c.Created, 0).UTC().Format(time.RFC3339),
+				Labels:     map[string]string{},
+				Options:    map[string]string{},
+				UsageData:  &volume.UsageData{Size: int64(len(m.Name)) * 4 * 1024 * 1024},
+			})
+		}
+
+		if c.NetworkSettings == nil {
+			continue
+		}
+		for name, settings := range c.NetworkSettings.Networks {
+			if _, ok := networks[name]; ok {
+				continue
+			}
+			n := &network.Summary{
+				Name:   name,
+				ID:     settings.NetworkID,
+				Driver: "bridge",
+				Scope:  "local",
+			}
+			if n.ID == "" {
+				n.ID = hashID(name)
+			}
+			if name == "bridge" {
+				n.IPAM.Config = []network.IPAMConfig{{Subnet: "172.17.0.0/16", Gateway: "172.17.0.1"}}
+			}
+			networks[name] = n
+		}
+	}
+
+	s.images = append(s.images, image.Summary{
+		ID:       imageID("<dangling>"),
+		RepoTags: nil,
+		Created:  time.Now().Add(-72 * time.Hour).Unix(),
+		Size:     120 * 1024 * 1024,
+		Labels:   map[string]string{},
+	})
+
+	for _, name := range predefined {
+		if _, ok := networks[name]; !ok {
+			driver := name
+			if name == "none" {
+				driver = "null"
+			}
+			networks[name] = &network.Summary{Name: name, ID: hashID(name), Driver: driver, Scope: "local"}
+		}
+	}
+	for _, n := range networks {
+		s.networks = append(s.networks, *n)
+	}
+	sort.Slice(s.networks, func(i, j int) bool {
+		return s.networks[i].Name < s.networks[j].Name
+	})
+}
+
+// hashID gives made up objects stable IDs that look like the real ones.
+func hashID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+func imageID(image string) string {
+	return "sha256:" + hashID(image)
+}
+
+func volumeName(source string) string {
+	source = strings.TrimSuffix(source, "/_data")
+	if i := strings.LastIndex(source, "/"); i >= 0 {
+		return source[i+1:]
+	}
+	return source
+}
+
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "Less than a second"
+	case d < time.Minute:
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d days", int(d.Hours()/24))
+}
@@ -0,0 +1,64 @@
+package dockertest
+
+import (
+	"embed"
+	"encoding/json"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// Responses recorded from real daemons, kept to reproduce the differences
+// between Docker on cgroup v1 and v2 and Podman's compatible API.
+//
+//go:embed testdata/*.json
+var fixtures embed.FS
+
+// Fixture returns a recorded response by file name, such as
+// "stats_cgroup_v1.json". It panics on unknown names since fixtures are
+// compiled in.
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("testdata/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// NewPodmanServer replays the recorded Podman responses: a pod of three
+// containers plus its infra container, and two containers outside of it.
+// Every running container reports the recorded one-shot stats.
+func NewPodmanServer() *Server {
+	var version types.Version
+	mustDecode("podman_version.json", &version)
+	s := newServer(version)
+
+	mustDecode("podman_containers.json", &s.containers)
+	for _, c := range s.containers {
+		s.inspect[c.ID] = &container.InspectResponse{
+			ContainerJSONBase: &container.ContainerJSONBase{
+				ID:         c.ID,
+				Name:       c.Names[0],
+				Image:      c.ImageID,
+				State:      &container.State{Status: c.State, Running: c.State == container.StateRunning},
+				HostConfig: &container.HostConfig{},
+			},
+			Mounts: c.Mounts,
+			Config: &container.Config{Image: c.Image, Labels: c.Labels},
+		}
+		if c.State == container.StateRunning {
+			s.stats[c.ID] = Fixture("podman_stats.json")
+		}
+	}
+	s.pods = Fixture("podman_pods.json")
+
+	s.deriveResources()
+	s.start()
+	return s
+}
+
+func mustDecode(name string, v any) {
+	if err := json.Unmarshal(Fixture(name), v); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,597 @@
+// Package dockertest serves a fake Docker Engine API over httptest, so the
+// docker client can be pointed at it instead of a real daemon.
+package dockertest
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9.]+/`)
+
+// anonymousLabel marks the volumes the daemon created without a name.
+const anonymousLabel = "com.docker.volume.anonymous"
+
+// Server keeps its state in Engine API types and changes it on actions the
+// way the daemon would, so a client sees containers start, stop and go away.
+type Server struct {
+	httpServer *httptest.Server
+
+	mu         sync.Mutex
+	version    types.Version
+	containers []container.Summary
+	inspect    map[string]*container.InspectResponse
+	stats      map[string]json.RawMessage
+	logs       map[string][]string
+	images     []image.Summary
+	volumes    []*volume.Volume
+	networks   []network.Summary
+	pods       json.RawMessage
+	requests   []string
+}
+
+// NewServer serves the given containers, usually models.MockContainers.
+// Images, volumes and networks are derived from them.
+func NewServer(containers []models.Container) *Server {
+	s := newServer(types.Version{
+		Version:       "28.4.0",
+		APIVersion:    "1.51",
+		MinAPIVersion: "1.24",
+		Os:            "linux",
+		Arch:          "amd64",
+		Components:    []types.ComponentVersion{{Name: "Engine", Version: "28.4.0"}},
+	})
+	for _, c := range containers {
+		s.addContainer(c)
+	}
+	s.deriveResources("bridge", "host", "none")
+	s.start()
+	return s
+}
+
+func newServer(version types.Version) *Server {
+	return &Server{
+		version: version,
+		inspect: make(map[string]*container.InspectResponse),
+		stats:   make(map[string]json.RawMessage),
+		logs:    make(map[string][]string),
+	}
+}
+
+func (s *Server) start() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_ping", s.handlePing)
+	mux.HandleFunc("HEAD /_ping", s.handlePing)
+	mux.HandleFunc("GET /version", s.handleVersion)
+
+	mux.HandleFunc("GET /containers/json", s.handleContainerList)
+	mux.HandleFunc("GET /containers/{id}/json", s.handleContainerInspect)
+	mux.HandleFunc("GET /containers/{id}/stats", s.handleContainerStats)
+	mux.HandleFunc("GET /containers/{id}/logs", s.handleContainerLogs)
+	mux.HandleFunc("POST /containers/{id}/{action}", s.handleContainerAction)
+	mux.HandleFunc("DELETE /containers/{id}", s.handleContainerRemove)
+
+	mux.HandleFunc("GET /images/json", s.handleImageList)
+	mux.HandleFunc("GET /images/{id}/history", s.handleImageHistory)
+	mux.HandleFunc("DELETE /images/{id}", s.handleImageRemove)
+	mux.HandleFunc("POST /images/prune", s.handleImagesPrune)
+
+	mux.HandleFunc("GET /system/df", s.handleDiskUsage)
+	mux.HandleFunc("GET /volumes", s.handleVolumeList)
+	mux.HandleFunc("DELETE /volumes/{name}", s.handleVolumeRemove)
+	mux.HandleFunc("POST /volumes/prune", s.handleVolumesPrune)
+
+	mux.HandleFunc("GET /networks", s.handleNetworkList)
+	mux.HandleFunc("GET /libpod/pods/json", s.handlePodList)
+
+	s.httpServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		s.mu.Unlock()
+
+		r.URL.Path = versionPrefix.ReplaceAllString(r.URL.Path, "/")
+		w.Header().Set("Api-Version", s.version.APIVersion)
+		w.Header().Set("Ostype", "linux")
+		mux.ServeHTTP(w, r)
+	}))
+}
+
+// Host is the daemon address to hand to the docker client, such as
+// "tcp://127.0.0.1:41234".
+func (s *Server) Host() string {
+	return "tcp://" + s.httpServer.Listener.Addr().String()
+}
+
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Requests lists every request received so far, such as
+// "POST /v1.51/containers/abc123456789/stop".
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+// SetAPIVersion makes the server answer as a daemon of an older or newer
+// API version. Clients negotiate down to it on their first request.
+func (s *Server) SetAPIVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version.APIVersion = version
+}
+
+// AddVolume adds a volume no container mounts. Without UsageData the
+// volume has no size in /system/df, as when the daemon did not compute it.
+func (s *Server) AddVolume(v volume.Volume) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.volumes = append(s.volumes, &v)
+}
+
+// ClearNetworkIDs lists the endpoints of a container by network name only,
+// the way some daemons and Podman's compatible API do.
+func (s *Server) ClearNetworkIDs(containerID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c := s.findContainer(containerID); c != nil {
+		for _, settings := range c.NetworkSettings.Networks {
+			settings.NetworkID = ""
+		}
+	}
+}
+
+// SetStats replaces the stats returned for a container with a raw Engine
+// API response, for instance one of the recorded fixtures.
+func (s *Server) SetStats(containerID string, raw []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats[containerID] = raw
+}
+
+func (s *Server) handlePing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.Method == http.MethodGet {
+		w.Write([]byte("OK"))
+	}
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.version)
+}
+
+func (s *Server) handleContainerList(w http.ResponseWriter, r *http.Request) {
+	all := r.URL.Query().Get("all")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]container.Summary, 0, len(s.containers))
+	for _, c := range s.containers {
+		if (all == "" || all == "0") && c.State != container.StateRunning {
+			continue
+		}
+		result = append(result, c)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleContainerInspect(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContainer(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.inspect[c.ID])
+}
+
+func (s *Server) handleContainerStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContainer(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", r.PathValue("id"))
+		return
+	}
+
+	raw, ok := s.stats[c.ID]
+	if !ok || c.State != container.StateRunning && c.State != container.StatePaused {
+		writeJSON(w, http.StatusOK, container.StatsResponse{Name: c.Names[0], ID: c.ID})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(raw)
+}
+
+// handleContainerLogs answers with the multiplexed stream the daemon uses
+// for containers without a TTY.
+func (s *Server) handleContainerLogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContainer(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", r.PathValue("id"))
+		return
+	}
+
+	lines := s.logs[c.ID]
+	if tail, err := strconv.Atoi(r.URL.Query().Get("tail")); err == nil && tail >= 0 && tail < len(lines) {
+		lines = lines[len(lines)-tail:]
+	}
+	timestamps := r.URL.Query().Get("timestamps") == "1" || r.URL.Query().Get("timestamps") == "true"
+	started := time.Unix(c.Created, 0).UTC()
+
+	w.Header().Set("Content-Type", "application/vnd.docker.multiplexed-stream")
+	for i, line := range lines {
+		if timestamps {
+			line = started.Add(time.Duration(i)*time.Second).Format(time.RFC3339Nano) + " " + line
+		}
+		frame := make([]byte, 8, 8+len(line)+1)
+		frame[0] = 1
+		binary.BigEndian.PutUint32(frame[4:], uint32(len(line)+1))
+		frame = append(frame, line...)
+		w.Write(append(frame, '\n'))
+	}
+}
+
+func (s *Server) handleContainerAction(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContainer(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", r.PathValue("id"))
+		return
+	}
+
+	switch r.PathValue("action") {
+	case "start":
+		if c.State == container.StateRunning {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.setState(c, container.StateRunning, "Up Less than a second")
+	case "stop":
+		if c.State != container.StateRunning && c.State != container.StatePaused {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		s.setState(c, container.StateExited, "Exited (0) Less than a second ago")
+	case "restart":
+		s.setState(c, container.StateRunning, "Up Less than a second")
+		s.inspect[c.ID].RestartCount++
+	case "pause":
+		if c.State != container.StateRunning {
+			writeError(w, http.StatusConflict, "Container %s is not running", c.ID)
+			return
+		}
+		s.setState(c, container.StatePaused, strings.TrimSuffix(c.Status, " (Paused)")+" (Paused)")
+	case "unpause":
+		if c.State != container.StatePaused {
+			writeError(w, http.StatusConflict, "Container %s is not paused", c.ID)
+			return
+		}
+		s.setState(c, container.StateRunning, strings.TrimSuffix(c.Status, " (Paused)"))
+	default:
+		writeError(w, http.StatusNotFound, "page not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleContainerRemove(w http.ResponseWriter, r *http.Request) {
+	force := r.URL.Query().Get("force") == "1" || r.URL.Query().Get("force") == "true"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.findContainer(r.PathValue("id"))
+	if c == nil {
+		writeError(w, http.StatusNotFound, "No such container: %s", r.PathValue("id"))
+		return
+	}
+	if c.State == container.StateRunning && !force {
+		writeError(w, http.StatusConflict, "cannot remove container %q: container is running: stop the container before removing or force remove", c.Names[0])
+		return
+	}
+
+	for i := range s.containers {
+		if s.containers[i].ID == c.ID {
+			delete(s.inspect, c.ID)
+			delete(s.stats, c.ID)
+			delete(s.logs, c.ID)
+			s.containers = append(s.containers[:i], s.containers[i+1:]...)
+			break
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleImageList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]image.Summary, 0, len(s.images))
+	for _, img := range s.images {
+		img.Containers = 0
+		for _, c := range s.containers {
+			if c.ImageID == img.ID {
+				img.Containers++
+			}
+		}
+		result = append(result, img)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (s *Server) handleImageHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	img := s.findImage(r.PathValue("id"))
+	if img == nil {
+		writeError(w, http.StatusNotFound, "No such image: %s", r.PathValue("id"))
+		return
+	}
+	writeJSON(w, http.StatusOK, []image.HistoryResponseItem{
+		{ID: img.ID, Created: img.Created, CreatedBy: `/bin/sh -c #(nop)  CMD ["start"]`, Tags: img.RepoTags},
+		{ID: "<missing>", Created: img.Created - 3600, CreatedBy: "/bin/sh -c #(nop) ADD file:rootfs.tar in / ", Size: img.Size},
+	})
+}
+
+func (s *Server) handleImageRemove(w http.ResponseWriter, r *http.Request) {
+	force := r.URL.Query().Get("force") == "1" || r.URL.Query().Get("force") == "true"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	img := s.findImage(r.PathValue("id"))
+	if img == nil {
+		writeError(w, http.StatusNotFound, "No such image: %s", r.PathValue("id"))
+		return
+	}
+	if !force {
+		for _, c := range s.containers {
+			if c.ImageID == img.ID {
+				writeError(w, http.StatusConflict, "conflict: unable to delete %s (must be forced) - image is being used by stopped container %s", shortID(img.ID), shortID(c.ID))
+				return
+			}
+		}
+	}
+
+	id := img.ID
+	s.removeImage(id)
+	writeJSON(w, http.StatusOK, []image.DeleteResponse{{Deleted: id}})
+}
+
+func (s *Server) handleImagesPrune(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var report image.PruneReport
+	for _, img := range append([]image.Summary(nil), s.images...) {
+		if len(img.RepoTags) == 0 {
+			s.removeImage(img.ID)
+			report.ImagesDeleted = append(report.ImagesDeleted, image.DeleteResponse{Deleted: img.ID})
+			report.SpaceReclaimed += uint64(img.Size)
+		}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleDiskUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage := types.DiskUsage{Volumes: make([]*volume.Volume, 0, len(s.volumes))}
+	for _, v := range s.volumes {
+		withUsage := *v
+		if v.UsageData != nil {
+			withUsage.UsageData = &volume.UsageData{RefCount: int64(len(s.volumeUsers(v.Name))), Size: v.UsageData.Size}
+		}
+		usage.Volumes = append(usage.Volumes, &withUsage)
+	}
+	writeJSON(w, http.StatusOK, usage)
+}
+
+func (s *Server) handleVolumeList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, volume.ListResponse{Volumes: s.volumes})
+}
+
+func (s *Server) handleVolumeRemove(w http.ResponseWriter, r *http.Request) {
+	force := r.URL.Query().Get("force") == "1" || r.URL.Query().Get("force") == "true"
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name := r.PathValue("name")
+	for i, v := range s.volumes {
+		if v.Name != name {
+			continue
+		}
+		if users := s.volumeUsers(name); len(users) > 0 && !force {
+			writeError(w, http.StatusConflict, "remove %s: volume is in use - [%s]", name, strings.Join(users, ", "))
+			return
+		}
+		s.volumes = append(s.volumes[:i], s.volumes[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeError(w, http.StatusNotFound, "get %s: no such volume", name)
+}
+
+// handleVolumesPrune removes unused volumes. Since API 1.42 named volumes
+// are only removed with the all filter, and older daemons reject it.
+func (s *Server) handleVolumesPrune(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pruneFilters, err := filters.FromJSON(r.URL.Query().Get("filters"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	onlyAnonymous := false
+	if versions.LessThan(s.version.APIVersion, "1.42") {
+		if pruneFilters.Contains("all") {
+			writeError(w, http.StatusBadRequest, "invalid filter 'all'")
+			return
+		}
+	} else {
+		onlyAnonymous = !pruneFilters.ExactMatch("all", "true") && !pruneFilters.ExactMatch("all", "1")
+	}
+
+	var report volume.PruneReport
+	kept := s.volumes[:0]
+	for _, v := range s.volumes {
+		_, anonymous := v.Labels[anonymousLabel]
+		if len(s.volumeUsers(v.Name)) > 0 || onlyAnonymous && !anonymous {
+			kept = append(kept, v)
+			continue
+		}
+		report.VolumesDeleted = append(report.VolumesDeleted, v.Name)
+		if v.UsageData != nil && v.UsageData.Size > 0 {
+			report.SpaceReclaimed += uint64(v.UsageData.Size)
+		}
+	}
+	s.volumes = kept
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleNetworkList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.networks)
+}
+
+// handlePodList is only answered by Podman servers, like the libpod API.
+func (s *Server) handlePodList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pods == nil {
+		writeError(w, http.StatusNotFound, "page not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.pods)
+}
+
+// findContainer accepts an ID, an ID prefix or a name, like the daemon.
+func (s *Server) findContainer(ref string) *container.Summary {
+	for i := range s.containers {
+		c := &s.containers[i]
+		if c.ID == ref || strings.HasPrefix(c.ID, ref) {
+			return c
+		}
+		for _, name := range c.Names {
+			if strings.TrimPrefix(name, "/") == strings.TrimPrefix(ref, "/") {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) findImage(ref string) *image.Summary {
+	for i := range s.images {
+		img := &s.images[i]
+		if img.ID == ref || strings.TrimPrefix(img.ID, "sha256:") == ref || strings.HasPrefix(strings.TrimPrefix(img.ID, "sha256:"), ref) {
+			return img
+		}
+		for _, tag := range img.RepoTags {
+			if tag == ref {
+				return img
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Server) removeImage(id string) {
+	for i := range s.images {
+		if s.images[i].ID == id {
+			s.images = append(s.images[:i], s.images[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *Server) volumeUsers(name string) []string {
+	var users []string
+	for _, c := range s.containers {
+		for _, m := range c.Mounts {
+			if m.Name == name {
+				users = append(users, shortID(c.ID))
+			}
+		}
+	}
+	return users
+}
+
+func (s *Server) setState(c *container.Summary, state container.ContainerState, status string) {
+	previous := c.State
+	c.State = state
+	c.Status = status
+
+	inspect := s.inspect[c.ID]
+	if inspect == nil {
+		return
+	}
+	now := time.Now().UTC().Format(time.RFC3339Nano)
+	inspect.State.Status = state
+	inspect.State.Running = state == container.StateRunning || state == container.StatePaused
+	inspect.State.Paused = state == container.StatePaused
+	switch {
+	case state == container.StateRunning && previous != container.StatePaused:
+		inspect.State.StartedAt = now
+		inspect.State.ExitCode = 0
+	case state == container.StateExited:
+		inspect.State.FinishedAt = now
+		inspect.State.ExitCode = 0
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...any) {
+	writeJSON(w, status, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+func shortID(id string) string {
+	id = strings.TrimPrefix(id, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
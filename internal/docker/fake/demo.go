@@ -0,0 +1,345 @@
+package fake
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+const (
+	maxDemoLogs = 500
+
+	crashChance     = 0.002
+	unhealthyChance = 0.02
+	recoverChance   = 0.15
+	logChance       = 0.35
+)
+
+var processImages = map[string]string{
+	"nginx":    "nginx:1.27",
+	"postgres": "postgres:16",
+	"mysql":    "mysql:8.4",
+	"redis":    "redis:7-alpine",
+	"worker":   "ghcr.io/example/worker:2.4.1",
+	"app":      "ghcr.io/example/app:2.4.1",
+}
+
+var demoMessages = []struct {
+	level   string
+	weight  int
+	formats []string
+}{
+	{"INFO", 60, []string{
+		`request completed method=GET path=/api/v1/orders status=200 duration=%dms`,
+		`request completed method=POST path=/api/v1/sessions status=201 duration=%dms`,
+		`connected to upstream pool size=%d`,
+		`job finished queue=emails processed=%d`,
+	}},
+	{"DEBUG", 20, []string{
+		`cache lookup key=session:%d hit=false`,
+		`gc pause=%dus heap=48MB`,
+	}},
+	{"WARN", 14, []string{
+		`slow query took %dms table=orders`,
+		`retrying upstream request attempt=%d`,
+		`connection pool nearly exhausted in_use=%d`,
+	}},
+	{"ERROR", 6, []string{
+		`upstream request failed status=502 attempt=%d`,
+		`connection reset by peer after %dms`,
+	}},
+}
+
+// simulation animates a runtime: stats drift, logs keep coming and now and
+// then a container crashes, is restarted by its policy or turns unhealthy.
+type simulation struct {
+	rand      *rand.Rand
+	tick      int
+	restartAt map[string]int
+	done      chan struct{}
+}
+
+// DemoRuntimes builds one animated runtime per machine, each serving a
+// container per process of the machine. The "local" runtime serves
+// models.MockContainers. Offline machines are unreachable. With a group only
+// the machines of that group are included.
+func DemoRuntimes(machines []models.Machine, group string, interval time.Duration) []*Runtime {
+	local := NewRuntime("local")
+	local.Animate(interval, time.Now().UnixNano())
+	runtimes := []*Runtime{local}
+
+	for i, machine := range machines {
+		if group != "" && machine.Group != group {
+			continue
+		}
+
+		runtime := NewRuntimeWith(machine.Name, machineContainers(machine))
+		switch machine.Status {
+		case models.StatusOffline:
+			runtime.SetError(fmt.Errorf("dial tcp %s:2376: connect: connection refused", machine.IP))
+		case models.StatusError:
+			runtime.SetError(fmt.Errorf("Error response from daemon: %s is not responding", machine.Name))
+		}
+		runtime.Animate(interval, time.Now().UnixNano()+int64(i))
+		runtimes = append(runtimes, runtime)
+	}
+	return runtimes
+}
+
+func machineContainers(machine models.Machine) []models.Container {
+	started := time.Now().Add(-time.Duration(machine.Uptime.Seconds) * time.Second)
+	processes := len(machine.Processes)
+	if processes == 0 {
+		return nil
+	}
+
+	containers := make([]models.Container, 0, processes)
+	for _, process := range machine.Processes {
+		image, ok := processImages[process.Name]
+		if !ok {
+			image = process.Name + ":latest"
+		}
+		cpu := machine.CPUUsage / float64(processes)
+
+		containers = append(containers, models.Container{
+			ID:      hashID(machine.ID + "/" + process.Name)[:12],
+			Name:    process.Name,
+			Image:   image,
+			Status:  models.StatusRunning,
+			State:   "Up " + humanDuration(time.Since(started)),
+			Created: started,
+			Started: started,
+			Ports: []models.Port{
+				{PrivatePort: process.Port, PublicPort: process.Port, Type: "tcp", IP: "0.0.0.0"},
+			},
+			Command: process.Name,
+			Stats: &models.ContainerStats{
+				CPU: models.ContainerCPU{Usage: cpu, System: cpu * 0.3, User: cpu * 0.7, Cores: 4},
+				Memory: models.ContainerMemory{
+					Usage: machine.MemoryUsage.Used / int64(processes) / 8,
+					Limit: machine.MemoryUsage.Total / int64(processes),
+				},
+				PIDs: 4,
+			},
+			Health:        &models.ContainerHealth{Status: models.HealthStatusHealthy},
+			RestartPolicy: models.RestartPolicy{Name: "unless-stopped"},
+			Labels: map[string]string{
+				"com.docker.compose.project": machine.Group,
+				"com.docker.compose.service": process.Name,
+			},
+		})
+	}
+	return containers
+}
+
+// Animate advances the runtime every interval until it is closed. The seed
+// makes a demo reproducible.
+func (r *Runtime) Animate(interval time.Duration, seed int64) {
+	r.mu.Lock()
+	if r.sim != nil {
+		r.mu.Unlock()
+		return
+	}
+	sim := &simulation{
+		rand:      rand.New(rand.NewSource(seed)),
+		restartAt: make(map[string]int),
+		done:      make(chan struct{}),
+	}
+	r.sim = sim
+	r.mu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-sim.done:
+				return
+			case now := <-ticker.C:
+				r.Step(now)
+			}
+		}
+	}()
+}
+
+// Step advances an animated runtime by one tick as of now.
+func (r *Runtime) Step(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sim == nil {
+		return
+	}
+	sim := r.sim
+	sim.tick++
+
+	for i := range r.containers {
+		c := &r.containers[i]
+
+		if at, ok := sim.restartAt[c.ID]; ok && sim.tick >= at {
+			delete(sim.restartAt, c.ID)
+			if c.Status == models.StatusExited {
+				r.setRunning(c)
+				c.RestartCount++
+				c.Health = &models.ContainerHealth{Status: models.HealthStatusStarting}
+				r.appendLog(c, now, "INFO", "container restarted by policy "+c.RestartPolicy.Name)
+			}
+		}
+
+		if c.Status != models.StatusRunning {
+			continue
+		}
+
+		sim.driftStats(c, now)
+		sim.cycleHealth(r, c, now)
+
+		if sim.rand.Float64() < logChance {
+			level, message := sim.logMessage()
+			r.appendLog(c, now, level, message)
+		}
+
+		if sim.rand.Float64() < crashChance {
+			code := 1
+			if sim.rand.Intn(2) == 0 {
+				code = 137
+			}
+			r.appendLog(c, now, "ERROR", fmt.Sprintf("fatal: process exited with code %d", code))
+			r.setExited(c, code)
+			if restarts(c.RestartPolicy, c.RestartCount) {
+				sim.restartAt[c.ID] = sim.tick + 3 + sim.rand.Intn(5)
+			}
+		}
+	}
+}
+
+func (sim *simulation) driftStats(c *models.Container, now time.Time) {
+	if c.Stats == nil {
+		c.Stats = &models.ContainerStats{}
+	}
+	prev := *c.Stats
+	stats := *c.Stats
+
+	cores := float64(stats.CPU.Cores)
+	if cores == 0 {
+		cores = 1
+	}
+	stats.CPU.Usage = clamp(stats.CPU.Usage+sim.rand.NormFloat64()*3, 0.5, 95*cores)
+	stats.CPU.System = stats.CPU.Usage * 0.3
+	stats.CPU.User = stats.CPU.Usage * 0.7
+
+	if stats.Memory.Limit == 0 {
+		stats.Memory.Limit = 512 * 1024 * 1024
+	}
+	step := float64(stats.Memory.Limit) * 0.01 * sim.rand.NormFloat64()
+	stats.Memory.Usage = int64(clamp(float64(stats.Memory.Usage)+step, float64(stats.Memory.Limit)/20, float64(stats.Memory.Limit)*0.95))
+
+	rx := sim.rand.Int63n(256 * 1024)
+	tx := sim.rand.Int63n(128 * 1024)
+	stats.Network.RxBytes += rx
+	stats.Network.TxBytes += tx
+	stats.Network.RxPackets += rx / 900
+	stats.Network.TxPackets += tx / 900
+	stats.Network.Interfaces = []models.NetworkInterface{{
+		Name:      "eth0",
+		RxBytes:   stats.Network.RxBytes,
+		RxPackets: stats.Network.RxPackets,
+		TxBytes:   stats.Network.TxBytes,
+		TxPackets: stats.Network.TxPackets,
+	}}
+
+	reads := sim.rand.Int63n(64)
+	writes := sim.rand.Int63n(32)
+	stats.BlockIO.ReadOps += reads
+	stats.BlockIO.WriteOps += writes
+	stats.BlockIO.ReadBytes += reads * 4096
+	stats.BlockIO.WriteBytes += writes * 4096
+
+	stats.PIDs += sim.rand.Intn(3) - 1
+	if stats.PIDs < 1 {
+		stats.PIDs = 1
+	}
+
+	stats.Timestamp = now
+	if prev.Timestamp.IsZero() {
+		prev.Timestamp = now.Add(-time.Second)
+	}
+	stats.ComputeRates(&prev)
+	c.Stats = &stats
+}
+
+// cycleHealth turns containers with a health check unhealthy now and then
+// and lets them recover a little later.
+func (sim *simulation) cycleHealth(r *Runtime, c *models.Container, now time.Time) {
+	if c.Health == nil || c.Health.Status == models.HealthStatusNone {
+		return
+	}
+
+	switch c.Health.Status {
+	case models.HealthStatusStarting, models.HealthStatusUnhealthy:
+		if sim.rand.Float64() < recoverChance {
+			c.Health = &models.ContainerHealth{Status: models.HealthStatusHealthy}
+			r.appendLog(c, now, "INFO", "health check passed")
+		} else if c.Health.Status == models.HealthStatusUnhealthy {
+			c.Health = &models.ContainerHealth{Status: models.HealthStatusUnhealthy, FailingStreak: c.Health.FailingStreak + 1}
+		}
+	case models.HealthStatusHealthy:
+		if sim.rand.Float64() < unhealthyChance {
+			c.Health = &models.ContainerHealth{Status: models.HealthStatusUnhealthy, FailingStreak: 1}
+			r.appendLog(c, now, "WARN", "health check failed: timeout after 5s")
+		}
+	}
+	c.State = "Up " + humanDuration(time.Since(c.Started))
+	if c.Health.Status != models.HealthStatusHealthy {
+		c.State += fmt.Sprintf(" (%s)", c.Health.Status)
+	}
+}
+
+func (sim *simulation) logMessage() (string, string) {
+	total := 0
+	for _, kind := range demoMessages {
+		total += kind.weight
+	}
+
+	pick := sim.rand.Intn(total)
+	for _, kind := range demoMessages {
+		if pick < kind.weight {
+			format := kind.formats[sim.rand.Intn(len(kind.formats))]
+			return kind.level, fmt.Sprintf(format, 1+sim.rand.Intn(900))
+		}
+		pick -= kind.weight
+	}
+	return "INFO", "ok"
+}
+
+func (r *Runtime) appendLog(c *models.Container, now time.Time, level, message string) {
+	line := fmt.Sprintf("%s %s %s", now.UTC().Format(time.RFC3339Nano), level, message)
+	c.Logs = append(c.Logs, line)
+	if len(c.Logs) > maxDemoLogs {
+		c.Logs = append([]string(nil), c.Logs[len(c.Logs)-maxDemoLogs:]...)
+	}
+}
+
+// restarts reports whether Docker would restart a container that just
+// failed under the given policy.
+func restarts(policy models.RestartPolicy, restartCount int) bool {
+	switch strings.ToLower(policy.Name) {
+	case "always", "unless-stopped":
+		return true
+	case "on-failure":
+		return policy.MaximumRetryCount == 0 || restartCount < policy.MaximumRetryCount
+	}
+	return false
+}
+
+func clamp(value, low, high float64) float64 {
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
@@ -0,0 +1,184 @@
+package fake
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+const demoTicks = 600
+
+var demoStart = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+// demoRuntimes animates the local runtime and one per online mock machine
+// with a fixed seed. The interval is long enough that only Step moves them.
+func demoRuntimes(t *testing.T, seed int64) []*Runtime {
+	t.Helper()
+	runtimes := []*Runtime{NewRuntime("local")}
+	for _, machine := range models.MockMachines() {
+		if machine.Status == models.StatusOnline {
+			runtimes = append(runtimes, NewRuntimeWith(machine.Name, machineContainers(machine)))
+		}
+	}
+	for i, runtime := range runtimes {
+		runtime.Animate(time.Hour, seed+int64(i))
+		t.Cleanup(func() { runtime.Close() })
+	}
+	return runtimes
+}
+
+func listAll(t *testing.T, runtime *Runtime) []models.Container {
+	t.Helper()
+	containers, err := runtime.ListContainers(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return containers
+}
+
+type counter struct {
+	value int64
+	rate  float64
+}
+
+// counters lists the cumulative counters of stats with their rates.
+func counters(stats *models.ContainerStats) map[string]counter {
+	return map[string]counter{
+		"rx bytes":    {stats.Network.RxBytes, stats.Network.Rate.RxBytes},
+		"tx bytes":    {stats.Network.TxBytes, stats.Network.Rate.TxBytes},
+		"rx packets":  {stats.Network.RxPackets, stats.Network.Rate.RxPackets},
+		"tx packets":  {stats.Network.TxPackets, stats.Network.Rate.TxPackets},
+		"read bytes":  {stats.BlockIO.ReadBytes, stats.BlockIO.Rate.ReadBytes},
+		"write bytes": {stats.BlockIO.WriteBytes, stats.BlockIO.Rate.WriteBytes},
+		"read ops":    {stats.BlockIO.ReadOps, stats.BlockIO.Rate.ReadOps},
+		"write ops":   {stats.BlockIO.WriteOps, stats.BlockIO.Rate.WriteOps},
+	}
+}
+
+func TestDemoCountersOnlyGrowWhileRunning(t *testing.T) {
+	runtimes := demoRuntimes(t, 1)
+
+	type seen struct {
+		started time.Time
+		stats   *models.ContainerStats
+	}
+	previous := make(map[string]seen)
+	ids := make(map[string]bool)
+	for _, runtime := range runtimes {
+		for _, c := range listAll(t, runtime) {
+			ids[runtime.Name()+"/"+c.ID] = true
+		}
+	}
+
+	for tick := 1; tick <= demoTicks; tick++ {
+		now := demoStart.Add(time.Duration(tick) * time.Second)
+		for _, runtime := range runtimes {
+			runtime.Step(now)
+			for _, c := range listAll(t, runtime) {
+				key := runtime.Name() + "/" + c.ID
+				if !ids[key] {
+					t.Fatalf("tick %d: unknown container %s", tick, key)
+				}
+				if c.Status != models.StatusRunning || c.Stats == nil {
+					delete(previous, key)
+					continue
+				}
+				where := fmt.Sprintf("tick %d %s", tick, key)
+				stats := c.Stats
+
+				if stats.CPU.Usage < 0 || stats.Memory.Usage < 0 || stats.Memory.Usage > stats.Memory.Limit || stats.PIDs < 1 {
+					t.Errorf("%s: cpu %g, memory %d of %d, %d pids", where, stats.CPU.Usage, stats.Memory.Usage, stats.Memory.Limit, stats.PIDs)
+				}
+				current := counters(stats)
+				for name, counter := range current {
+					if counter.value < 0 || counter.rate < 0 {
+						t.Errorf("%s: %s = %d at %g/s", where, name, counter.value, counter.rate)
+					}
+				}
+				// A restart starts the counters over, and says so with a
+				// new start time.
+				if last, ok := previous[key]; ok && last.started.Equal(c.Started) {
+					for name, before := range counters(last.stats) {
+						if after := current[name]; after.value < before.value {
+							t.Errorf("%s: %s went from %d to %d", where, name, before.value, after.value)
+						}
+					}
+				}
+				previous[key] = seen{started: c.Started, stats: stats}
+			}
+		}
+	}
+}
+
+func TestDemoIsReproducible(t *testing.T) {
+	first, second := demoRuntimes(t, 7), demoRuntimes(t, 7)
+	for tick := 1; tick <= demoTicks; tick++ {
+		now := demoStart.Add(time.Duration(tick) * time.Second)
+		for i := range first {
+			first[i].Step(now)
+			second[i].Step(now)
+		}
+	}
+
+	for i := range first {
+		a, b := listAll(t, first[i]), listAll(t, second[i])
+		if len(a) != len(b) {
+			t.Fatalf("%s: %d and %d containers", first[i].Name(), len(a), len(b))
+		}
+		for j := range a {
+			if a[j].ID != b[j].ID || a[j].Status != b[j].Status || a[j].RestartCount != b[j].RestartCount {
+				t.Errorf("%s: %s %s restarted %d times, then %s %s restarted %d times",
+					first[i].Name(), a[j].ID, a[j].Status, a[j].RestartCount, b[j].ID, b[j].Status, b[j].RestartCount)
+				continue
+			}
+			if a[j].Stats == nil || b[j].Stats == nil {
+				continue
+			}
+			for name, counter := range counters(a[j].Stats) {
+				if other := counters(b[j].Stats)[name]; other != counter {
+					t.Errorf("%s/%s: %s = %v and %v with the same seed", first[i].Name(), a[j].ID, name, counter, other)
+				}
+			}
+		}
+	}
+}
+
+func TestDemoRuntimes(t *testing.T) {
+	machines := models.MockMachines()
+	runtimes := DemoRuntimes(machines, "", time.Hour)
+	again := DemoRuntimes(machines, "", time.Hour)
+	defer func() {
+		for _, runtime := range append(runtimes, again...) {
+			runtime.Close()
+		}
+	}()
+
+	if len(runtimes) != len(machines)+1 || runtimes[0].Name() != "local" {
+		t.Fatalf("%d runtimes, want local and one per machine", len(runtimes))
+	}
+	for i, machine := range machines {
+		runtime := runtimes[i+1]
+		containers, err := runtime.ListContainers(false)
+		if machine.Status != models.StatusOnline {
+			if err == nil {
+				t.Errorf("%s is %s but lists containers", machine.Name, machine.Status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		// IDs stay the same from one run to the next.
+		others, _ := again[i+1].ListContainers(false)
+		if len(containers) != len(machine.Processes) || len(others) != len(containers) {
+			t.Fatalf("%s: %d and %d containers for %d processes", machine.Name, len(containers), len(others), len(machine.Processes))
+		}
+		for j := range containers {
+			if containers[j].ID != others[j].ID {
+				t.Errorf("%s: container %d is %s, then %s", machine.Name, j, containers[j].ID, others[j].ID)
+			}
+		}
+	}
+}
@@ -0,0 +1,682 @@
+// Package fake provides an in-memory docker.ContainerRuntime seeded with
+// models.MockContainers, for running the TUI without a daemon.
+package fake
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+var _ docker.ContainerRuntime = (*Runtime)(nil)
+
+// Runtime behaves like a small daemon: actions change container states the
+// way Docker would and refuse the same invalid transitions.
+type Runtime struct {
+	mu   sync.Mutex
+	name string
+	err  error
+
+	containers []models.Container
+	images     []models.Image
+	volumes    []models.Volume
+	networks   []models.DockerNetwork
+	calls      []string
+
+	sim *simulation
+}
+
+func NewRuntime(name string) *Runtime {
+	return NewRuntimeWith(name, models.MockContainers())
+}
+
+// NewRuntimeWith serves the given containers. Images and networks are
+// derived from them, plus a dangling image so pruning has something to do.
+func NewRuntimeWith(name string, containers []models.Container) *Runtime {
+	r := &Runtime{name: name}
+	for _, c := range containers {
+		c.Name = strings.TrimPrefix(c.Name, "/")
+		c.Host = name
+		if c.Logs == nil {
+			c.Logs = sampleLogs(&c)
+		}
+		r.containers = append(r.containers, c)
+	}
+	r.images = deriveImages(r.containers)
+	r.networks = deriveNetworks(r.containers)
+	r.volumes = deriveVolumes(r.containers)
+	return r
+}
+
+// SetError makes Ping and ListContainers fail, as an unreachable daemon
+// would. A nil error brings the runtime back.
+func (r *Runtime) SetError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.err = err
+}
+
+func (r *Runtime) SetVolumes(volumes []models.Volume) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.volumes = volumes
+}
+
+// Calls lists the actions performed so far, such as "stop abc123456789".
+func (r *Runtime) Calls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *Runtime) Name() string {
+	return r.name
+}
+
+func (r *Runtime) Ping() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close stops the animation started by Animate, if any.
+func (r *Runtime) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.sim != nil {
+		close(r.sim.done)
+		r.sim = nil
+	}
+	return nil
+}
+
+func (r *Runtime) ListContainers(onlyRunning bool) ([]models.Container, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	result := make([]models.Container, 0, len(r.containers))
+	for _, c := range r.containers {
+		if onlyRunning && c.Status != models.StatusRunning {
+			continue
+		}
+		if c.Status != models.StatusRunning && c.Status != models.StatusPaused {
+			c.Stats = nil
+		} else if c.Stats != nil {
+			stats := *c.Stats
+			c.Stats = &stats
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func (r *Runtime) ListContainersWith(options docker.ListOptions) ([]models.Container, error) {
+	containers, err := r.ListContainers(options.OnlyRunning)
+	if options.SkipLogs {
+		for i := range containers {
+			containers[i].Logs = nil
+		}
+	}
+	return containers, err
+}
+
+func (r *Runtime) GetContainerStats(containerID string) (*models.ContainerStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.find(containerID)
+	if err != nil {
+		return nil, err
+	}
+	if c.Status != models.StatusRunning && c.Status != models.StatusPaused {
+		return &models.ContainerStats{Timestamp: time.Now()}, nil
+	}
+
+	stats := models.ContainerStats{}
+	if c.Stats != nil {
+		stats = *c.Stats
+	}
+	if stats.Timestamp.IsZero() {
+		stats.Timestamp = time.Now()
+	}
+	return &stats, nil
+}
+
+func (r *Runtime) GetContainerLogs(containerID string, lines int) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.find(containerID)
+	if err != nil {
+		return nil, err
+	}
+	logs := c.Logs
+	if lines > 0 && len(logs) > lines {
+		logs = logs[len(logs)-lines:]
+	}
+	return append([]string(nil), logs...), nil
+}
+
+func (r *Runtime) RefreshContainerLogs(containerID string, lines int) ([]string, error) {
+	return r.GetContainerLogs(containerID, lines)
+}
+
+func (r *Runtime) InspectContainer(containerID string) (*types.ContainerJSON, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, err := r.find(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	inspect := &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:           c.ID,
+			Name:         "/" + c.Name,
+			Created:      c.Created.Format(time.RFC3339Nano),
+			Image:        imageID(c.Image),
+			RestartCount: c.RestartCount,
+			State: &container.State{
+				Status:     container.ContainerState(c.Status),
+				Running:    c.Status == models.StatusRunning || c.Status == models.StatusPaused,
+				Paused:     c.Status == models.StatusPaused,
+				ExitCode:   c.ExitCode,
+				StartedAt:  c.Started.Format(time.RFC3339Nano),
+				FinishedAt: c.Finished.Format(time.RFC3339Nano),
+			},
+			HostConfig: &container.HostConfig{
+				RestartPolicy: container.RestartPolicy{
+					Name:              container.RestartPolicyMode(c.RestartPolicy.Name),
+					MaximumRetryCount: c.RestartPolicy.MaximumRetryCount,
+				},
+			},
+		},
+		Config: &container.Config{
+			Image:  c.Image,
+			Env:    c.Env,
+			Labels: c.Labels,
+			Cmd:    strings.Fields(c.Command),
+		},
+	}
+	if c.Stats != nil && c.Stats.CPU.Limit > 0 {
+		inspect.HostConfig.NanoCPUs = int64(c.Stats.CPU.Limit * 1e9)
+	}
+	return inspect, nil
+}
+
+func (r *Runtime) LoadContainerConfig(c *models.Container) error {
+	inspect, err := r.InspectContainer(c.ID)
+	if err != nil {
+		return err
+	}
+	c.RestartCount = inspect.RestartCount
+	c.Env = inspect.Config.Env
+	c.RestartPolicy = models.RestartPolicy{
+		Name:              string(inspect.HostConfig.RestartPolicy.Name),
+		MaximumRetryCount: inspect.HostConfig.RestartPolicy.MaximumRetryCount,
+	}
+	return nil
+}
+
+func (r *Runtime) StartContainer(containerID string) error {
+	return r.transition("start", containerID, func(c *models.Container) error {
+		switch c.Status {
+		case models.StatusRunning:
+			return nil
+		case models.StatusPaused:
+			return fmt.Errorf("cannot start a paused container, try unpause instead")
+		}
+		r.setRunning(c)
+		return nil
+	})
+}
+
+func (r *Runtime) StopContainer(containerID string) error {
+	return r.transition("stop", containerID, func(c *models.Container) error {
+		if c.Status == models.StatusRunning || c.Status == models.StatusPaused {
+			r.setExited(c, 0)
+		}
+		return nil
+	})
+}
+
+func (r *Runtime) RestartContainer(containerID string) error {
+	return r.transition("restart", containerID, func(c *models.Container) error {
+		r.setRunning(c)
+		return nil
+	})
+}
+
+func (r *Runtime) PauseContainer(containerID string) error {
+	return r.transition("pause", containerID, func(c *models.Container) error {
+		if c.Status != models.StatusRunning {
+			return fmt.Errorf("container %s is not running", c.ID)
+		}
+		c.Status = models.StatusPaused
+		c.State = "Up " + humanDuration(time.Since(c.Started)) + " (Paused)"
+		return nil
+	})
+}
+
+func (r *Runtime) UnpauseContainer(containerID string) error {
+	return r.transition("unpause", containerID, func(c *models.Container) error {
+		if c.Status != models.StatusPaused {
+			return fmt.Errorf("container %s is not paused", c.ID)
+		}
+		c.Status = models.StatusRunning
+		c.State = "Up " + humanDuration(time.Since(c.Started))
+		return nil
+	})
+}
+
+func (r *Runtime) RemoveContainer(containerID string, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "remove "+containerID)
+	for i := range r.containers {
+		c := &r.containers[i]
+		if c.ID != containerID && c.Name != containerID {
+			continue
+		}
+		if c.Status == models.StatusRunning && !force {
+			return fmt.Errorf("cannot remove container %s: container is running: stop the container before removing or force remove", c.Name)
+		}
+		r.containers = append(r.containers[:i], r.containers[i+1:]...)
+		return nil
+	}
+	return notFound(containerID)
+}
+
+func (r *Runtime) ImageList() ([]models.Image, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	usedBy := make(map[string][]string)
+	for _, c := range r.containers {
+		id := imageID(c.Image)
+		usedBy[id] = append(usedBy[id], c.Name)
+	}
+
+	result := make([]models.Image, 0, len(r.images))
+	for _, image := range r.images {
+		image.Containers = usedBy[image.ID]
+		sort.Strings(image.Containers)
+		result = append(result, image)
+	}
+	return result, nil
+}
+
+func (r *Runtime) ImageHistory(id string) ([]models.ImageLayer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, image := range r.images {
+		if image.ID != id {
+			continue
+		}
+		return []models.ImageLayer{
+			{ID: image.ID, CreatedBy: "/bin/sh -c #(nop)  CMD [\"start\"]", Created: image.Created, Tags: image.RepoTags},
+			{ID: "<missing>", CreatedBy: "/bin/sh -c #(nop) ADD file:rootfs.tar in / ", Created: image.Created.Add(-time.Hour), Size: image.Size},
+		}, nil
+	}
+	return nil, fmt.Errorf("No such image: %s", id)
+}
+
+func (r *Runtime) ImageRemove(id string, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "rmi "+id)
+	for i, image := range r.images {
+		if image.ID != id {
+			continue
+		}
+		if !force {
+			for _, c := range r.containers {
+				if id == imageID(c.Image) {
+					return fmt.Errorf("conflict: unable to delete %s - image is being used by container %s", image.ShortID(), c.ID)
+				}
+			}
+		}
+		r.images = append(r.images[:i], r.images[i+1:]...)
+		return nil
+	}
+	return fmt.Errorf("No such image: %s", id)
+}
+
+func (r *Runtime) ImagesPrune() (models.PruneReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "image prune")
+	var report models.PruneReport
+	kept := r.images[:0]
+	for _, image := range r.images {
+		if image.Dangling() {
+			report.Deleted++
+			report.SpaceReclaimed += uint64(image.Size)
+			continue
+		}
+		kept = append(kept, image)
+	}
+	r.images = kept
+	return report, nil
+}
+
+func (r *Runtime) VolumeList() ([]models.Volume, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]models.Volume, 0, len(r.volumes))
+	for _, volume := range r.volumes {
+		volume.Users = nil
+		for _, c := range r.containers {
+			for _, m := range c.Mounts {
+				if m.Type == "volume" && m.Source == volume.Mountpoint {
+					volume.Users = append(volume.Users, models.VolumeUser{
+						Container:   c.Name,
+						Running:     c.Status == models.StatusRunning,
+						Destination: m.Destination,
+						RW:          m.RW,
+					})
+				}
+			}
+		}
+		result = append(result, volume)
+	}
+	return result, nil
+}
+
+func (r *Runtime) VolumeRemove(name string, force bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "volume rm "+name)
+	for i, volume := range r.volumes {
+		if volume.Name != name {
+			continue
+		}
+		if !force && r.volumeInUse(volume) {
+			return fmt.Errorf("remove %s: volume is in use", name)
+		}
+		r.volumes = append(r.volumes[:i], r.volumes[i+1:]...)
+		return nil
+	}
+	return fmt.Errorf("get %s: no such volume", name)
+}
+
+func (r *Runtime) VolumesPrune() (models.PruneReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "volume prune")
+	var report models.PruneReport
+	kept := r.volumes[:0]
+	for _, volume := range r.volumes {
+		if !r.volumeInUse(volume) {
+			report.Deleted++
+			if volume.Size > 0 {
+				report.SpaceReclaimed += uint64(volume.Size)
+			}
+			continue
+		}
+		kept = append(kept, volume)
+	}
+	r.volumes = kept
+	return report, nil
+}
+
+func (r *Runtime) NetworkList() ([]models.DockerNetwork, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]models.DockerNetwork, 0, len(r.networks))
+	for _, network := range r.networks {
+		endpoints := make([]models.NetworkEndpoint, 0, len(network.Endpoints))
+		for _, endpoint := range network.Endpoints {
+			if c, err := r.find(endpoint.ContainerID); err == nil {
+				endpoint.Running = c.Status == models.StatusRunning
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+		network.Endpoints = endpoints
+		result = append(result, network)
+	}
+	return result, nil
+}
+
+func (r *Runtime) transition(action, containerID string, fn func(*models.Container) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, action+" "+containerID)
+	c, err := r.find(containerID)
+	if err != nil {
+		return err
+	}
+	return fn(c)
+}
+
+// find accepts an ID or a name, like the Docker API.
+func (r *Runtime) find(containerID string) (*models.Container, error) {
+	for i := range r.containers {
+		if r.containers[i].ID == containerID || r.containers[i].Name == containerID {
+			return &r.containers[i], nil
+		}
+	}
+	return nil, notFound(containerID)
+}
+
+// setRunning starts a container afresh: counters start over, limits stay.
+func (r *Runtime) setRunning(c *models.Container) {
+	c.Status = models.StatusRunning
+	c.State = "Up Less than a second"
+	c.Started = time.Now()
+	c.Finished = time.Time{}
+	c.ExitCode = 0
+
+	stats := &models.ContainerStats{PIDs: 1}
+	if c.Stats != nil {
+		stats.CPU.Cores = c.Stats.CPU.Cores
+		stats.CPU.Limit = c.Stats.CPU.Limit
+		stats.Memory.Limit = c.Stats.Memory.Limit
+		stats.Memory.Usage = c.Stats.Memory.Limit / 20
+	}
+	c.Stats = stats
+}
+
+// setExited keeps the stats of the container, which still carry its limits,
+// but they are no longer reported.
+func (r *Runtime) setExited(c *models.Container, code int) {
+	c.Status = models.StatusExited
+	c.State = fmt.Sprintf("Exited (%d) Less than a second ago", code)
+	c.Finished = time.Now()
+	c.ExitCode = code
+	c.Health = nil
+}
+
+func (r *Runtime) volumeInUse(volume models.Volume) bool {
+	for _, c := range r.containers {
+		for _, m := range c.Mounts {
+			if m.Type == "volume" && m.Source == volume.Mountpoint {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func notFound(containerID string) error {
+	return fmt.Errorf("No such container: %s", containerID)
+}
+
+// hashID gives made up objects stable IDs that look like the real ones.
+func hashID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+func imageID(image string) string {
+	return "sha256:" + hashID(image)
+}
+
+func deriveImages(containers []models.Container) []models.Image {
+	seen := make(map[string]bool)
+	var images []models.Image
+	for i, c := range containers {
+		if seen[c.Image] {
+			continue
+		}
+		seen[c.Image] = true
+
+		tag := c.Image
+		if !strings.Contains(tag[strings.LastIndex(tag, "/")+1:], ":") {
+			tag += ":latest"
+		}
+		images = append(images, models.Image{
+			ID:       imageID(c.Image),
+			RepoTags: []string{tag},
+			Size:     int64(40+25*i) * 1024 * 1024,
+			Created:  c.Created.Add(-24 * time.Hour),
+		})
+	}
+
+	images = append(images, models.Image{
+		ID:       imageID("<dangling>"),
+		RepoTags: []string{"<none>:<none>"},
+		Size:     120 * 1024 * 1024,
+		Created:  time.Now().Add(-72 * time.Hour),
+	})
+	return images
+}
+
+// deriveNetworks attaches containers without networks of their own to the
+// default bridge, like containers started without --network.
+func deriveNetworks(containers []models.Container) []models.DockerNetwork {
+	bridge := models.DockerNetwork{
+		ID:       hashID("bridge"),
+		Name:     "bridge",
+		Driver:   "bridge",
+		Scope:    "local",
+		Subnets:  []string{"172.17.0.0/16"},
+		Gateways: []string{"172.17.0.1"},
+	}
+	networks := map[string]*models.DockerNetwork{"bridge": &bridge}
+	order := []string{"bridge"}
+
+	for i, c := range containers {
+		attachments := c.Networks
+		if len(attachments) == 0 {
+			attachments = []models.Network{{
+				Name:       "bridge",
+				IPAddress:  fmt.Sprintf("172.17.0.%d", i+2),
+				MacAddress: fmt.Sprintf("02:42:ac:11:00:%02x", i+2),
+			}}
+		}
+		for _, attachment := range attachments {
+			network, ok := networks[attachment.Name]
+			if !ok {
+				network = &models.DockerNetwork{
+					ID:     hashID(attachment.Name),
+					Name:   attachment.Name,
+					Driver: "bridge",
+					Scope:  "local",
+				}
+				networks[attachment.Name] = network
+				order = append(order, attachment.Name)
+			}
+			network.Endpoints = append(network.Endpoints, models.NetworkEndpoint{
+				Container:   c.Name,
+				ContainerID: c.ID,
+				IPv4Address: attachment.IPAddress,
+				MacAddress:  attachment.MacAddress,
+			})
+		}
+	}
+
+	result := make([]models.DockerNetwork, 0, len(order)+2)
+	for _, name := range order {
+		result = append(result, *networks[name])
+	}
+	result = append(result,
+		models.DockerNetwork{ID: hashID("host"), Name: "host", Driver: "host", Scope: "local"},
+		models.DockerNetwork{ID: hashID("none"), Name: "none", Driver: "null", Scope: "local"},
+	)
+	return result
+}
+
+func deriveVolumes(containers []models.Container) []models.Volume {
+	seen := make(map[string]bool)
+	var volumes []models.Volume
+	for _, c := range containers {
+		for _, m := range c.Mounts {
+			if m.Type != "volume" || seen[m.Source] {
+				continue
+			}
+			seen[m.Source] = true
+
+			name := filepath.Base(m.Source)
+			if name == "_data" {
+				name = filepath.Base(filepath.Dir(m.Source))
+			}
+			volumes = append(volumes, models.Volume{
+				Name:       name,
+				Driver:     "local",
+				Mountpoint: m.Source,
+				Scope:      "local",
+				Created:    c.Created,
+				Size:       -1,
+			})
+		}
+	}
+	return volumes
+}
+
+func sampleLogs(c *models.Container) []string {
+	if c.Started.IsZero() {
+		return []string{}
+	}
+
+	messages := []string{
+		"starting " + c.Name,
+		"loaded configuration",
+		"listening on " + c.MainPort(),
+		"ready to accept connections",
+	}
+	logs := make([]string, len(messages))
+	for i, message := range messages {
+		at := c.Started.Add(time.Duration(i) * time.Second).UTC()
+		logs[i] = at.Format(time.RFC3339Nano) + " " + message
+	}
+	return logs
+}
+
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "Less than a second"
+	case d < time.Minute:
+		return fmt.Sprintf("%d seconds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%d minutes", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+}
@@ -0,0 +1,101 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/image"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// ImageList returns all tagged and dangling images together with the names
+// of the containers (running or not) created from each of them.
+func (c *Client) ImageList() ([]models.Image, error) {
+	images, err := c.cli.ImageList(c.ctx, image.ListOptions{
+		SharedSize:     true,
+		ContainerCount: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := c.cli.ContainerList(c.ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make(map[string][]string)
+	for _, ctr := range containers {
+		name := ctr.ID
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		usedBy[ctr.ImageID] = append(usedBy[ctr.ImageID], name)
+	}
+
+	result := make([]models.Image, 0, len(images))
+	for _, img := range images {
+		users := usedBy[img.ID]
+		sort.Strings(users)
+
+		result = append(result, models.Image{
+			ID:         img.ID,
+			RepoTags:   img.RepoTags,
+			Size:       img.Size,
+			SharedSize: img.SharedSize,
+			Created:    time.Unix(img.Created, 0),
+			Containers: users,
+			Labels:     img.Labels,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Created.After(result[j].Created)
+	})
+	return result, nil
+}
+
+func (c *Client) ImageHistory(imageID string) ([]models.ImageLayer, error) {
+	history, err := c.cli.ImageHistory(c.ctx, imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([]models.ImageLayer, 0, len(history))
+	for _, item := range history {
+		layers = append(layers, models.ImageLayer{
+			ID:        item.ID,
+			CreatedBy: item.CreatedBy,
+			Created:   time.Unix(item.Created, 0),
+			Size:      item.Size,
+			Comment:   item.Comment,
+			Tags:      item.Tags,
+		})
+	}
+	return layers, nil
+}
+
+func (c *Client) ImageRemove(imageID string, force bool) error {
+	_, err := c.cli.ImageRemove(c.ctx, imageID, image.RemoveOptions{
+		Force:         force,
+		PruneChildren: true,
+	})
+	return err
+}
+
+// ImagesPrune removes dangling images only, the same as a plain
+// `docker image prune`.
+func (c *Client) ImagesPrune() (models.PruneReport, error) {
+	report, err := c.cli.ImagesPrune(c.ctx, filters.NewArgs(filters.Arg("dangling", "true")))
+	if err != nil {
+		return models.PruneReport{}, err
+	}
+
+	return models.PruneReport{
+		Deleted:        len(report.ImagesDeleted),
+		SpaceReclaimed: report.SpaceReclaimed,
+	}, nil
+}
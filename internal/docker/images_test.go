@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func TestImageList(t *testing.T) {
+	containers := models.MockContainers()
+	// A second container from the nginx image, listed after the first.
+	canary := containers[0]
+	canary.ID = "aaa000000001"
+	canary.Name = "/canary-web"
+	containers = append(containers, canary)
+	c, _ := newServerClient(t, containers)
+
+	images, err := c.ImageList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One image per distinct container image, plus a dangling one.
+	if len(images) != len(containers) {
+		t.Fatalf("ImageList() returned %d images, want %d", len(images), len(containers))
+	}
+
+	var nginx, dangling *models.Image
+	for i := range images {
+		image := &images[i]
+		if i > 0 && image.Created.After(images[i-1].Created) {
+			t.Errorf("image %d created %s after image %d, want newest first", i, image.Created, i-1)
+		}
+		switch {
+		case len(image.RepoTags) == 0:
+			dangling = image
+		case image.RepoTags[0] == "nginx:latest":
+			nginx = image
+		}
+	}
+
+	if nginx == nil {
+		t.Fatal("no nginx image")
+	}
+	if got := strings.Join(nginx.Containers, " "); got != "canary-web nginx-web" {
+		t.Errorf("nginx used by %s, want both containers by name", got)
+	}
+	if want := time.Unix(containers[0].Created.Unix()-86400, 0); !nginx.Created.Equal(want) {
+		t.Errorf("nginx created %s, want %s", nginx.Created, want)
+	}
+	if nginx.Size <= 0 || !strings.HasPrefix(nginx.ID, "sha256:") {
+		t.Errorf("nginx = %+v", nginx)
+	}
+
+	if dangling == nil {
+		t.Fatal("no dangling image")
+	}
+	if len(dangling.Containers) != 0 {
+		t.Errorf("dangling image used by %v", dangling.Containers)
+	}
+}
+
+func TestImagesPrune(t *testing.T) {
+	c, server := newTestClient(t)
+
+	report, err := c.ImagesPrune()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Deleted != 1 || report.SpaceReclaimed != 120*1024*1024 {
+		t.Errorf("ImagesPrune() = %+v, want the dangling image", report)
+	}
+
+	images, err := c.ImageList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, image := range images {
+		if len(image.RepoTags) == 0 {
+			t.Errorf("dangling image %s left after pruning", image.ID)
+		}
+	}
+	if requests := strings.Join(server.Requests(), "\n"); !strings.Contains(requests, "POST /v1.51/images/prune") {
+		t.Errorf("requests =\n%s\nwant a prune", requests)
+	}
+}
@@ -0,0 +1,79 @@
+package docker
+
+import (
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// cgroup v2 memory.stat has no "cache"/"rss" keys; "anon" and "file"
+// only exist there, so their presence is enough to tell the two apart.
+func detectCgroupVersion(stats map[string]uint64) int {
+	if _, ok := stats["anon"]; ok {
+		return 2
+	}
+	if _, ok := stats["file"]; ok {
+		return 2
+	}
+	return 1
+}
+
+func (c *Client) convertMemory(stats *dockerStats) models.ContainerMemory {
+	raw := stats.MemoryStats.Stats
+	version := detectCgroupVersion(raw)
+
+	memory := models.ContainerMemory{
+		Limit:         stats.MemoryStats.Limit,
+		MaxUsage:      stats.MemoryStats.MaxUsage,
+		RawUsage:      stats.MemoryStats.Usage,
+		CgroupVersion: version,
+	}
+
+	if version == 2 {
+		memory.RSS = statValue(raw, "anon")
+		memory.Cache = statValue(raw, "file")
+		memory.InactiveFile = statValue(raw, "inactive_file")
+		// Swap lives in memory.swap.current on cgroup v2, which the stats
+		// API does not return, so it stays unreported; see SwapReported.
+	} else {
+		memory.RSS = firstStatValue(raw, "total_rss", "rss")
+		memory.Cache = firstStatValue(raw, "total_cache", "cache")
+		memory.InactiveFile = firstStatValue(raw, "total_inactive_file", "inactive_file")
+		memory.Swap = firstStatValue(raw, "total_swap", "swap")
+		memory.SwapLimit = swapLimit(statValue(raw, "hierarchical_memsw_limit"), stats.MemoryStats.Limit)
+	}
+
+	memory.Usage = usageWithoutCache(stats.MemoryStats.Usage, memory.InactiveFile)
+	return memory
+}
+
+// usageWithoutCache mirrors the Docker CLI: inactive page cache can be
+// reclaimed at any time, so it is not reported as used memory.
+func usageWithoutCache(usage, inactiveFile int64) int64 {
+	if inactiveFile > 0 && inactiveFile < usage {
+		return usage - inactiveFile
+	}
+	return usage
+}
+
+func swapLimit(memswLimit, memLimit int64) int64 {
+	if memswLimit <= memLimit || memLimit <= 0 {
+		return 0
+	}
+	return memswLimit - memLimit
+}
+
+func statValue(stats map[string]uint64, key string) int64 {
+	value, ok := stats[key]
+	if !ok || value > uint64(1<<63-1) {
+		return 0
+	}
+	return int64(value)
+}
+
+func firstStatValue(stats map[string]uint64, keys ...string) int64 {
+	for _, key := range keys {
+		if _, ok := stats[key]; ok {
+			return statValue(stats, key)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,98 @@
+package docker
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kqnd/kernus/internal/docker/dockertest"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func fixtureStats(t *testing.T, name string) *dockerStats {
+	t.Helper()
+	var stats dockerStats
+	if err := json.Unmarshal(dockertest.Fixture(name), &stats); err != nil {
+		t.Fatal(err)
+	}
+	return &stats
+}
+
+func TestConvertMemory(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		want    models.ContainerMemory
+	}{
+		{
+			name:    "cgroup v1",
+			fixture: "stats_cgroup_v1.json",
+			want: models.ContainerMemory{
+				Usage:         52756480,
+				Limit:         536870912,
+				Cache:         41943040,
+				RSS:           31457280,
+				Swap:          4194304,
+				SwapLimit:     536870912,
+				MaxUsage:      110592000,
+				RawUsage:      73728000,
+				InactiveFile:  20971520,
+				CgroupVersion: 1,
+			},
+		},
+		{
+			name:    "cgroup v2",
+			fixture: "stats_cgroup_v2.json",
+			want: models.ContainerMemory{
+				Usage:         236978176,
+				Limit:         1073741824,
+				Cache:         94371840,
+				RSS:           167772160,
+				RawUsage:      268435456,
+				InactiveFile:  31457280,
+				CgroupVersion: 2,
+			},
+		},
+	}
+
+	c := &Client{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.convertMemory(fixtureStats(t, tt.fixture))
+			if got != tt.want {
+				t.Errorf("convertMemory() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertMemorySwap(t *testing.T) {
+	c := &Client{}
+	v1 := c.convertMemory(fixtureStats(t, "stats_cgroup_v1.json"))
+	if !v1.SwapReported() || v1.SwapPercentage() != 100*4194304.0/536870912 {
+		t.Errorf("v1 swap = %d of %d, reported %v", v1.Swap, v1.SwapLimit, v1.SwapReported())
+	}
+
+	v2 := c.convertMemory(fixtureStats(t, "stats_cgroup_v2.json"))
+	if v2.SwapReported() || v2.Swap != 0 {
+		t.Errorf("v2 swap = %d, reported %v, want unreported", v2.Swap, v2.SwapReported())
+	}
+}
+
+func TestUsageWithoutCache(t *testing.T) {
+	tests := []struct {
+		name                string
+		usage, inactiveFile int64
+		want                int64
+	}{
+		{"inactive cache subtracted", 100, 30, 70},
+		{"no inactive cache", 100, 0, 100},
+		{"inactive cache above usage", 100, 150, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := usageWithoutCache(tt.usage, tt.inactiveFile); got != tt.want {
+				t.Errorf("usageWithoutCache(%d, %d) = %d, want %d", tt.usage, tt.inactiveFile, got, tt.want)
+			}
+		})
+	}
+}
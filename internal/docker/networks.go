@@ -0,0 +1,95 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// NetworkList returns every network with the containers attached to it.
+// Listing networks does not include endpoints, so they are taken from the
+// container list instead of inspecting each network.
+func (c *Client) NetworkList() ([]models.DockerNetwork, error) {
+	networks, err := c.cli.NetworkList(c.ctx, network.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := c.cli.ContainerList(c.ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string][]models.NetworkEndpoint)
+	byName := make(map[string][]models.NetworkEndpoint)
+	for _, ctr := range containers {
+		if ctr.NetworkSettings == nil {
+			continue
+		}
+
+		name := ctr.ID
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		for networkName, settings := range ctr.NetworkSettings.Networks {
+			if settings == nil {
+				continue
+			}
+			endpoint := models.NetworkEndpoint{
+				Container:   name,
+				ContainerID: ctr.ID,
+				Running:     ctr.State == "running",
+				IPv4Address: settings.IPAddress,
+				IPv6Address: settings.GlobalIPv6Address,
+				MacAddress:  settings.MacAddress,
+			}
+			if settings.NetworkID != "" {
+				byID[settings.NetworkID] = append(byID[settings.NetworkID], endpoint)
+			} else {
+				byName[networkName] = append(byName[networkName], endpoint)
+			}
+		}
+	}
+
+	result := make([]models.DockerNetwork, 0, len(networks))
+	for _, n := range networks {
+		endpoints := make([]models.NetworkEndpoint, 0, len(byID[n.ID])+len(byName[n.Name]))
+		endpoints = append(endpoints, byID[n.ID]...)
+		endpoints = append(endpoints, byName[n.Name]...)
+		sort.Slice(endpoints, func(i, j int) bool {
+			return endpoints[i].Container < endpoints[j].Container
+		})
+
+		var subnets, gateways []string
+		for _, config := range n.IPAM.Config {
+			if config.Subnet != "" {
+				subnets = append(subnets, config.Subnet)
+			}
+			if config.Gateway != "" {
+				gateways = append(gateways, config.Gateway)
+			}
+		}
+
+		result = append(result, models.DockerNetwork{
+			ID:        n.ID,
+			Name:      n.Name,
+			Driver:    n.Driver,
+			Scope:     n.Scope,
+			Subnets:   subnets,
+			Gateways:  gateways,
+			Internal:  n.Internal,
+			IPv6:      n.EnableIPv6,
+			Created:   n.Created,
+			Labels:    n.Labels,
+			Endpoints: endpoints,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
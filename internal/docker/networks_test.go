@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func TestNetworkList(t *testing.T) {
+	containers := models.MockContainers()
+	containers[0].Networks = []models.Network{{Name: "frontend", IPAddress: "10.1.0.2", MacAddress: "02:42:0a:01:00:02"}}
+	containers[1].Networks = []models.Network{{Name: "backend", IPAddress: "10.2.0.2"}}
+	containers[2].Networks = []models.Network{{Name: "backend", IPAddress: "10.2.0.4"}}
+	containers[3].Networks = []models.Network{
+		{Name: "frontend", IPAddress: "10.1.0.3"},
+		{Name: "backend", IPAddress: "10.2.0.3"},
+	}
+	c, server := newServerClient(t, containers)
+	// app-worker's endpoints only name their networks, so they are matched
+	// by name and merged with the others.
+	server.ClearNetworkIDs(containers[3].ID)
+
+	networks, err := c.NetworkList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]models.DockerNetwork)
+	var names []string
+	for _, n := range networks {
+		byName[n.Name] = n
+		names = append(names, n.Name)
+	}
+	if got := strings.Join(names, " "); got != "backend bridge frontend host none" {
+		t.Fatalf("NetworkList() = %s, want the used and predefined networks by name", got)
+	}
+
+	tests := []struct {
+		network string
+		want    []models.NetworkEndpoint
+	}{
+		{"frontend", []models.NetworkEndpoint{
+			{Container: "app-worker", ContainerID: "jkl012345678", Running: true, IPv4Address: "10.1.0.3"},
+			{Container: "nginx-web", ContainerID: "abc123456789", Running: true, IPv4Address: "10.1.0.2", MacAddress: "02:42:0a:01:00:02"},
+		}},
+		{"backend", []models.NetworkEndpoint{
+			{Container: "app-worker", ContainerID: "jkl012345678", Running: true, IPv4Address: "10.2.0.3"},
+			{Container: "postgres-db", ContainerID: "def456789012", Running: true, IPv4Address: "10.2.0.2"},
+			// Stopped containers keep their endpoint without an address.
+			{Container: "redis-cache", ContainerID: "ghi789012345"},
+		}},
+		{"bridge", []models.NetworkEndpoint{
+			{Container: "monitoring-grafana", ContainerID: "mno345678901", IPv4Address: "172.17.0.6", MacAddress: "02:42:ac:11:00:06"},
+		}},
+		{"host", nil},
+	}
+	for _, tt := range tests {
+		endpoints := byName[tt.network].Endpoints
+		if len(endpoints) != len(tt.want) {
+			t.Errorf("%s endpoints = %+v, want %+v", tt.network, endpoints, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if endpoints[i] != tt.want[i] {
+				t.Errorf("%s endpoint %d = %+v, want %+v", tt.network, i, endpoints[i], tt.want[i])
+			}
+		}
+	}
+
+	bridge := byName["bridge"]
+	if strings.Join(bridge.Subnets, ",") != "172.17.0.0/16" || strings.Join(bridge.Gateways, ",") != "172.17.0.1" {
+		t.Errorf("bridge IPAM = %v %v", bridge.Subnets, bridge.Gateways)
+	}
+	if none := byName["none"]; none.Driver != "null" || len(none.Subnets) != 0 {
+		t.Errorf("none = %+v", none)
+	}
+}
@@ -0,0 +1,146 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+const PodmanContext = "podman"
+
+type podmanPod struct {
+	ID         string `json:"Id"`
+	Name       string `json:"Name"`
+	Status     string `json:"Status"`
+	InfraID    string `json:"InfraId"`
+	Containers []struct {
+		ID     string `json:"Id"`
+		Names  string `json:"Names"`
+		Status string `json:"Status"`
+	} `json:"Containers"`
+}
+
+// podmanSocketPath returns the Docker compatible socket of rootless Podman,
+// or of rootful Podman when there is no rootless one.
+func podmanSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		if path := existingFile(filepath.Join(dir, "podman", "podman.sock")); path != "" {
+			return path
+		}
+	}
+	return existingFile("/run/podman/podman.sock")
+}
+
+// IsPodman reports whether the daemon is Podman's Docker compatible service.
+// The answer is cached once the daemon has answered.
+func (c *Client) IsPodman() bool {
+	c.engineMu.Lock()
+	defer c.engineMu.Unlock()
+
+	if c.engine == "" {
+		version, err := c.cli.ServerVersion(c.ctx)
+		if err != nil {
+			return false
+		}
+		c.engine = "docker"
+		for _, component := range version.Components {
+			if strings.Contains(strings.ToLower(component.Name), "podman") {
+				c.engine = "podman"
+			}
+		}
+	}
+	return c.engine == "podman"
+}
+
+// podmanPods maps container IDs to the name of their pod. Pods are only
+// exposed by the libpod API, which Podman serves on the same socket.
+func (c *Client) podmanPods() (map[string]string, error) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, c.libpodURL("/pods/json"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.cli.HTTPClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing pods: %s", resp.Status)
+	}
+
+	var pods []podmanPod
+	if err := json.NewDecoder(resp.Body).Decode(&pods); err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]string)
+	for _, pod := range pods {
+		for _, container := range pod.Containers {
+			members[container.ID] = pod.Name
+		}
+	}
+	return members, nil
+}
+
+func (c *Client) libpodURL(path string) string {
+	scheme, host := "http", "d"
+	if u, err := url.Parse(c.cli.DaemonHost()); err == nil {
+		switch u.Scheme {
+		case "tcp", "http", "https":
+			host = u.Host
+		}
+	}
+	if c.tls {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%s://%s/v4.0.0/libpod%s", scheme, host, path)
+}
+
+func (c *Client) assignPods(containers []models.Container) {
+	if !c.IsPodman() {
+		return
+	}
+
+	pods, err := c.podmanPods()
+	if err != nil {
+		return
+	}
+	for i := range containers {
+		containers[i].Pod = pods[containers[i].ID]
+	}
+}
+
+// normalizeState maps the libpod states Podman passes through its Docker
+// compatible API onto the Docker ones.
+func normalizeState(state string) string {
+	switch state {
+	case "configured", "initialized":
+		return string(models.StatusCreated)
+	case "stopping":
+		return string(models.StatusRunning)
+	}
+	return state
+}
+
+// parseHealth reads the health suffix of a container status such as
+// "Up 5 minutes (unhealthy)". Podman writes it the same way but without
+// the "health: " prefix while starting.
+func parseHealth(status string) models.HealthStatus {
+	switch {
+	case strings.Contains(status, "(unhealthy)"):
+		return models.HealthStatusUnhealthy
+	case strings.Contains(status, "(healthy)"):
+		return models.HealthStatusHealthy
+	case strings.Contains(status, "starting)"):
+		return models.HealthStatusStarting
+	}
+	return models.HealthStatusNone
+}
@@ -0,0 +1,151 @@
+package docker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/kqnd/kernus/internal/docker/dockertest"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func TestPodmanListing(t *testing.T) {
+	server := dockertest.NewPodmanServer()
+	defer server.Close()
+
+	c, err := NewEndpointClient(Endpoint{Name: PodmanContext, Host: server.Host()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if !c.IsPodman() {
+		t.Fatal("IsPodman() = false for the recorded Podman version")
+	}
+
+	containers, err := c.ListContainers(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		pod    string
+		status models.ContainerStatus
+		health models.HealthStatus
+	}{
+		{"b1e2c3d4a5f6-infra", "webapp", models.StatusRunning, models.HealthStatusNone},
+		{"webapp-nginx", "webapp", models.StatusRunning, models.HealthStatusHealthy},
+		{"webapp-api", "webapp", models.StatusRunning, models.HealthStatusUnhealthy},
+		{"webapp-worker", "webapp", models.StatusRunning, models.HealthStatusStarting},
+		{"postgres", "", models.StatusExited, models.HealthStatusNone},
+		{"migrate", "", models.StatusCreated, models.HealthStatusNone},
+	}
+	if len(containers) != len(tests) {
+		t.Fatalf("ListContainers() returned %d containers, want %d", len(containers), len(tests))
+	}
+
+	byName := make(map[string]models.Container, len(containers))
+	for _, c := range containers {
+		byName[c.ShortName()] = c
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, ok := byName[tt.name]
+			if !ok {
+				t.Fatalf("container %s not listed", tt.name)
+			}
+			if c.Pod != tt.pod {
+				t.Errorf("Pod = %q, want %q", c.Pod, tt.pod)
+			}
+			if c.Status != tt.status {
+				t.Errorf("Status = %q, want %q", c.Status, tt.status)
+			}
+			if got := c.HealthStatus(); got != tt.health {
+				t.Errorf("HealthStatus() = %q, want %q", got, tt.health)
+			}
+			if c.Host != PodmanContext {
+				t.Errorf("Host = %q, want %q", c.Host, PodmanContext)
+			}
+		})
+	}
+}
+
+func TestNormalizeState(t *testing.T) {
+	tests := []struct {
+		state string
+		want  string
+	}{
+		{"running", "running"},
+		{"exited", "exited"},
+		{"created", "created"},
+		{"configured", "created"},
+		{"initialized", "created"},
+		{"stopping", "running"},
+		{"paused", "paused"},
+	}
+	for _, tt := range tests {
+		if got := normalizeState(tt.state); got != tt.want {
+			t.Errorf("normalizeState(%q) = %q, want %q", tt.state, got, tt.want)
+		}
+	}
+}
+
+func TestParseHealth(t *testing.T) {
+	tests := []struct {
+		status string
+		want   models.HealthStatus
+	}{
+		{"Up 2 hours (healthy)", models.HealthStatusHealthy},
+		{"Up 2 hours (unhealthy)", models.HealthStatusUnhealthy},
+		{"Up 3 seconds (health: starting)", models.HealthStatusStarting},
+		{"Up 3 seconds (starting)", models.HealthStatusStarting},
+		{"Up 2 hours", models.HealthStatusNone},
+		{"Exited (0) 20 minutes ago", models.HealthStatusNone},
+	}
+	for _, tt := range tests {
+		if got := parseHealth(tt.status); got != tt.want {
+			t.Errorf("parseHealth(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestFillPreCPU(t *testing.T) {
+	c := newClient(nil, PodmanContext)
+	const id = "a7c9e1f3b5d7"
+
+	first := fixtureStats(t, "podman_stats.json")
+	c.fillPreCPU(id, first)
+	if first.PreCPUStats.SystemCPUUsage != 0 {
+		t.Fatalf("first read got precpu %+v, want none to stand in", first.PreCPUStats)
+	}
+
+	// One second later on 4 CPUs, with the container busy for half a CPU
+	// second.
+	second := fixtureStats(t, "podman_stats.json")
+	second.CPUStats.SystemCPUUsage += 4e9
+	second.CPUStats.CPUUsage.TotalUsage += 5e8
+	second.CPUStats.CPUUsage.UsageInUsermode += 4e8
+	second.CPUStats.CPUUsage.UsageInKernelmode += 1e8
+	c.fillPreCPU(id, second)
+
+	if second.PreCPUStats.SystemCPUUsage != first.CPUStats.SystemCPUUsage {
+		t.Fatalf("precpu system usage = %d, want the first read %d",
+			second.PreCPUStats.SystemCPUUsage, first.CPUStats.SystemCPUUsage)
+	}
+	cpu := c.convertCPU(second)
+	if !near(cpu.Usage, 50) || !near(cpu.User, 40) || !near(cpu.System, 10) {
+		t.Errorf("usage = %.2f user %.2f system %.2f, want 50 40 10", cpu.Usage, cpu.User, cpu.System)
+	}
+
+	// A read that is not newer, such as the same sample twice, keeps the
+	// empty precpu instead of a negative delta.
+	same := fixtureStats(t, "podman_stats.json")
+	c.fillPreCPU(id, same)
+	if same.PreCPUStats.SystemCPUUsage != 0 {
+		t.Errorf("older read got precpu %+v, want none", same.PreCPUStats)
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,50 @@
+package docker
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// ContainerRuntime is everything the TUI asks of a container engine. Client
+// implements it against a real daemon; the fake package implements it in
+// memory so the interface can be exercised without one.
+type ContainerRuntime interface {
+	Name() string
+	Ping() error
+	Close() error
+
+	ListContainers(onlyRunning bool) ([]models.Container, error)
+	ListContainersWith(options ListOptions) ([]models.Container, error)
+	GetContainerStats(containerID string) (*models.ContainerStats, error)
+	GetContainerLogs(containerID string, lines int) ([]string, error)
+	RefreshContainerLogs(containerID string, lines int) ([]string, error)
+	InspectContainer(containerID string) (*types.ContainerJSON, error)
+	LoadContainerConfig(container *models.Container) error
+
+	StartContainer(containerID string) error
+	StopContainer(containerID string) error
+	RestartContainer(containerID string) error
+	PauseContainer(containerID string) error
+	UnpauseContainer(containerID string) error
+	RemoveContainer(containerID string, force bool) error
+
+	ImageList() ([]models.Image, error)
+	ImageHistory(imageID string) ([]models.ImageLayer, error)
+	ImageRemove(imageID string, force bool) error
+	ImagesPrune() (models.PruneReport, error)
+
+	VolumeList() ([]models.Volume, error)
+	VolumeRemove(name string, force bool) error
+	VolumesPrune() (models.PruneReport, error)
+
+	NetworkList() ([]models.DockerNetwork, error)
+}
+
+// ListOptions chooses what a listing reads. ListContainers reads everything
+// the TUI shows; collectors that only want states and stats skip the logs.
+type ListOptions struct {
+	OnlyRunning bool
+	SkipLogs    bool
+}
+
+var _ ContainerRuntime = (*Client)(nil)
@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// sshDialer connects to a remote daemon the same way the Docker CLI does,
+// by running "docker system dial-stdio" over ssh and speaking HTTP over the
+// command's stdin and stdout.
+func sshDialer(host string) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("no host in ssh endpoint %q", host)
+	}
+
+	// BatchMode keeps ssh from prompting for a password on the terminal the
+	// TUI is drawing on; keys or an agent are required.
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=10"}
+	if u.User != nil {
+		args = append(args, "-l", u.User.Username())
+	}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+	args = append(args, "--", u.Hostname(), "docker")
+	if socket := strings.TrimSuffix(u.Path, "/"); socket != "" {
+		args = append(args, "--host", "unix://"+socket)
+	}
+	args = append(args, "system", "dial-stdio")
+
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return newCommandConn(ctx, "ssh", args...)
+	}, nil
+}
+
+type commandConn struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	stderr *lockedBuffer
+
+	// answered is set once the command wrote anything, which means the
+	// remote end was reached.
+	answered  atomic.Bool
+	closeOnce sync.Once
+}
+
+// newCommandConn starts the command, killing it if ctx is done before it
+// answered. The HTTP transport also cancels ctx once a dialed connection
+// goes to another request, so a connection that answered is left running.
+func newCommandConn(ctx context.Context, name string, args ...string) (net.Conn, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	c := &commandConn{cmd: cmd, stderr: &lockedBuffer{}}
+	cmd.Cancel = func() error {
+		if c.answered.Load() {
+			return nil
+		}
+		return cmd.Process.Kill()
+	}
+
+	var err error
+	c.stdin, err = cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	c.stdout, err = cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = c.stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *commandConn) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if n > 0 {
+		c.answered.Store(true)
+	}
+	if err == io.EOF {
+		if message := strings.TrimSpace(c.stderr.String()); message != "" {
+			return n, fmt.Errorf("ssh: %s", message)
+		}
+	}
+	return n, err
+}
+
+func (c *commandConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.stdin.Close()
+		if c.cmd.Process != nil {
+			c.cmd.Process.Kill()
+		}
+		c.cmd.Wait()
+	})
+	return nil
+}
+
+func (c *commandConn) LocalAddr() net.Addr                { return commandAddr{} }
+func (c *commandConn) RemoteAddr() net.Addr               { return commandAddr{} }
+func (c *commandConn) SetDeadline(t time.Time) error      { return nil }
+func (c *commandConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *commandConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type commandAddr struct{}
+
+func (commandAddr) Network() string { return "cmd" }
+func (commandAddr) String() string  { return "cmd" }
+
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
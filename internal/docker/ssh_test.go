@@ -0,0 +1,57 @@
+package docker
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestCommandConnKilledWhenDialCancelled(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	conn, err := newCommandConn(ctx, "sleep", "10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != io.EOF {
+			t.Errorf("Read() error = %v, want io.EOF once the command is killed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("command still running after the dial context was cancelled")
+	}
+}
+
+func TestCommandConnOutlivesDialOnceAnswered(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	conn, err := newCommandConn(ctx, "sh", "-c", "echo ready; cat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	buf := make([]byte, 6)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ready\n" {
+		t.Fatalf("Read() = %q, %v", buf, err)
+	}
+	cancel()
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("Write() after cancel: %v", err)
+	}
+	buf = make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping\n" {
+		t.Errorf("Read() after cancel = %q, %v, want the echoed ping", buf, err)
+	}
+}
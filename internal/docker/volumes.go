@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/versions"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// VolumeList returns the volumes known to the daemon with their size as
+// reported by /system/df and the containers that mount them.
+func (c *Client) VolumeList() ([]models.Volume, error) {
+	usage, err := c.cli.DiskUsage(c.ctx, types.DiskUsageOptions{
+		Types: []types.DiskUsageObject{types.VolumeObject},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := c.cli.ContainerList(c.ctx, container.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[string][]models.VolumeUser)
+	for _, ctr := range containers {
+		name := ctr.ID
+		if len(ctr.Names) > 0 {
+			name = strings.TrimPrefix(ctr.Names[0], "/")
+		}
+		for _, m := range ctr.Mounts {
+			if m.Type != mount.TypeVolume || m.Name == "" {
+				continue
+			}
+			users[m.Name] = append(users[m.Name], models.VolumeUser{
+				Container:   name,
+				Running:     ctr.State == "running",
+				Destination: m.Destination,
+				RW:          m.RW,
+			})
+		}
+	}
+
+	result := make([]models.Volume, 0, len(usage.Volumes))
+	for _, vol := range usage.Volumes {
+		if vol == nil {
+			continue
+		}
+
+		size := int64(-1)
+		if vol.UsageData != nil {
+			size = vol.UsageData.Size
+		}
+		created, _ := time.Parse(time.RFC3339, vol.CreatedAt)
+
+		volumeUsers := users[vol.Name]
+		sort.Slice(volumeUsers, func(i, j int) bool {
+			return volumeUsers[i].Container < volumeUsers[j].Container
+		})
+
+		result = append(result, models.Volume{
+			Name:       vol.Name,
+			Driver:     vol.Driver,
+			Mountpoint: vol.Mountpoint,
+			Scope:      vol.Scope,
+			Created:    created,
+			Labels:     vol.Labels,
+			Size:       size,
+			Users:      volumeUsers,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, nil
+}
+
+func (c *Client) VolumeRemove(name string, force bool) error {
+	return c.cli.VolumeRemove(c.ctx, name, force)
+}
+
+// VolumesPrune removes every volume no container references. Since API 1.42
+// the daemon only prunes anonymous volumes unless asked for all of them, and
+// older daemons reject the filter, so the version is negotiated first.
+func (c *Client) VolumesPrune() (models.PruneReport, error) {
+	c.cli.NegotiateAPIVersion(c.ctx)
+	pruneFilters := filters.NewArgs()
+	if versions.GreaterThanOrEqualTo(c.cli.ClientVersion(), "1.42") {
+		pruneFilters.Add("all", "true")
+	}
+
+	report, err := c.cli.VolumesPrune(c.ctx, pruneFilters)
+	if err != nil {
+		return models.PruneReport{}, err
+	}
+
+	return models.PruneReport{
+		Deleted:        len(report.VolumesDeleted),
+		SpaceReclaimed: report.SpaceReclaimed,
+	}, nil
+}
@@ -0,0 +1,136 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/volume"
+	"github.com/kqnd/kernus/internal/docker/dockertest"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+const anonymousVolume = "3f2a9c1b7d4e"
+
+func volumeMount(name, destination string, rw bool) models.Mount {
+	return models.Mount{
+		Type:        "volume",
+		Source:      "/var/lib/docker/volumes/" + name + "/_data",
+		Destination: destination,
+		RW:          rw,
+	}
+}
+
+// newVolumeClient serves the mock containers with volumes mounted, plus an
+// unused named volume and an unused anonymous one without a known size.
+func newVolumeClient(t *testing.T, apiVersion string) (*Client, *dockertest.Server) {
+	t.Helper()
+	containers := models.MockContainers()
+	containers[0].Mounts = []models.Mount{volumeMount("shared", "/srv", false)}
+	containers[1].Mounts = []models.Mount{volumeMount("pgdata", "/var/lib/postgresql/data", true)}
+	containers[2].Mounts = []models.Mount{volumeMount("shared", "/data", true)}
+	containers[3].Mounts = []models.Mount{volumeMount("shared", "/app/shared", true)}
+
+	server := dockertest.NewServer(containers)
+	t.Cleanup(server.Close)
+	server.SetAPIVersion(apiVersion)
+	server.AddVolume(volume.Volume{
+		Name:      "old-data",
+		Driver:    "local",
+		Scope:     "local",
+		UsageData: &volume.UsageData{Size: 10},
+	})
+	server.AddVolume(volume.Volume{
+		Name:   anonymousVolume,
+		Driver: "local",
+		Scope:  "local",
+		Labels: map[string]string{"com.docker.volume.anonymous": ""},
+	})
+
+	c, err := NewEndpointClient(Endpoint{Name: "local", Host: server.Host()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c, server
+}
+
+func volumeNames(volumes []models.Volume) string {
+	names := make([]string, len(volumes))
+	for i, v := range volumes {
+		names[i] = v.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestVolumeList(t *testing.T) {
+	c, _ := newVolumeClient(t, "1.51")
+
+	volumes, err := c.VolumeList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := volumeNames(volumes), anonymousVolume+" old-data pgdata shared"; got != want {
+		t.Fatalf("VolumeList() = %s, want %s", got, want)
+	}
+	anonymous, unused, pgdata, shared := volumes[0], volumes[1], volumes[2], volumes[3]
+
+	if anonymous.Size != -1 {
+		t.Errorf("%s: Size = %d, want -1 when the daemon has no size", anonymous.Name, anonymous.Size)
+	}
+	if unused.Size != 10 || len(unused.Users) != 0 {
+		t.Errorf("old-data = %+v, want 10 bytes and no users", unused)
+	}
+	if pgdata.Size != 6*4*1024*1024 || pgdata.Created.IsZero() {
+		t.Errorf("pgdata = %+v, want its size and creation time", pgdata)
+	}
+
+	// Users are sorted by container name, not listing order.
+	want := []models.VolumeUser{
+		{Container: "app-worker", Running: true, Destination: "/app/shared", RW: true},
+		{Container: "nginx-web", Running: true, Destination: "/srv", RW: false},
+		{Container: "redis-cache", Running: false, Destination: "/data", RW: true},
+	}
+	if len(shared.Users) != len(want) {
+		t.Fatalf("shared users = %+v, want %+v", shared.Users, want)
+	}
+	for i := range want {
+		if shared.Users[i] != want[i] {
+			t.Errorf("shared user %d = %+v, want %+v", i, shared.Users[i], want[i])
+		}
+	}
+}
+
+func TestVolumesPrune(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiVersion string
+	}{
+		// Without all=true, the daemon would keep the named volume.
+		{"asks for named volumes too", "1.51"},
+		// The daemon rejects the all filter before 1.42.
+		{"old daemon", "1.41"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, server := newVolumeClient(t, tt.apiVersion)
+
+			report, err := c.VolumesPrune()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Deleted != 2 || report.SpaceReclaimed != 10 {
+				t.Errorf("VolumesPrune() = %+v, want both unused volumes", report)
+			}
+			volumes, err := c.VolumeList()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := volumeNames(volumes); got != "pgdata shared" {
+				t.Errorf("volumes left = %s, want the used ones", got)
+			}
+			if requests := strings.Join(server.Requests(), "\n"); !strings.Contains(requests, "POST /v"+tt.apiVersion+"/volumes/prune") {
+				t.Errorf("requests =\n%s\nwant a prune at API %s", requests, tt.apiVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+// maxDatagram keeps UDP packets within a common MTU, so they are not
+// fragmented on the way.
+const maxDatagram = 1400
+
+// splitName splits a family name after its second word, such as
+// kern_container_cpu_usage_percent into kern_container and
+// cpu_usage_percent.
+func splitName(name string) (string, string) {
+	rest, ok := strings.CutPrefix(name, "kern_")
+	if !ok {
+		return "kern", name
+	}
+	group, field, ok := strings.Cut(rest, "_")
+	if !ok {
+		return "kern", rest
+	}
+	return "kern_" + group, field
+}
+
+// InfluxLines converts a snapshot to InfluxDB line protocol. Families become
+// fields of a measurement named after their first two words, so samples
+// with the same tags share a line, such as
+//
+//	kern_container,host=local,name=web cpu_usage_percent=2.5,pids=4 1700000000000000000
+//
+// Empty labels are left out, as line protocol has no empty tag values.
+func InfluxLines(snapshot metrics.Snapshot) []string {
+	type line struct {
+		series string
+		fields []string
+	}
+	var lines []*line
+	index := make(map[string]*line)
+
+	for _, family := range snapshot.Families {
+		measurement, field := splitName(family.Name)
+		for _, sample := range family.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			series := influxSeries(measurement, sample.Labels)
+			l := index[series]
+			if l == nil {
+				l = &line{series: series}
+				index[series] = l
+				lines = append(lines, l)
+			}
+			l.fields = append(l.fields, influxKeyEscaper.Replace(field)+"="+strconv.FormatFloat(sample.Value, 'f', -1, 64))
+		}
+	}
+
+	timestamp := " " + strconv.FormatInt(snapshot.Time.UnixNano(), 10)
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, l.series+" "+strings.Join(l.fields, ",")+timestamp)
+	}
+	return out
+}
+
+var influxMeasurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, "\n", `\n`)
+var influxKeyEscaper = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `, "\n", `\n`)
+
+// influxSeries is the measurement and tags of a line, with tags sorted by
+// key as InfluxDB prefers.
+func influxSeries(measurement string, labels []metrics.Label) string {
+	tags := make([]metrics.Label, 0, len(labels))
+	for _, label := range labels {
+		if label.Value != "" {
+			tags = append(tags, label)
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	var b strings.Builder
+	b.WriteString(influxMeasurementEscaper.Replace(measurement))
+	for _, tag := range tags {
+		b.WriteString("," + influxKeyEscaper.Replace(tag.Name) + "=" + influxKeyEscaper.Replace(tag.Value))
+	}
+	return b.String()
+}
+
+// Influx writes snapshots as line protocol to the HTTP write API of InfluxDB
+// or Telegraf, or to a UDP listener.
+type Influx struct {
+	name     string
+	url      string
+	token    string
+	username string
+	password string
+	http     *http.Client
+	conn     net.Conn
+}
+
+// NewInflux sets up the writer of the config. A UDP address is dialed
+// right away; nothing is sent before the first push.
+func NewInflux(cfg config.InfluxDBConfig) (*Influx, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid influxdb url %q, expected for instance http://localhost:8086 or udp://localhost:8089", cfg.URL)
+	}
+
+	switch u.Scheme {
+	case "udp":
+		conn, err := net.Dial("udp", u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("influxdb %s: %w", u.Host, err)
+		}
+		return &Influx{name: "influxdb udp://" + u.Host, conn: conn}, nil
+	case "http", "https":
+	default:
+		return nil, fmt.Errorf("unknown influxdb url scheme %q, expected http, https or udp", u.Scheme)
+	}
+
+	query := u.Query()
+	switch {
+	case cfg.Bucket != "":
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/api/v2/write"
+		}
+		query.Set("bucket", cfg.Bucket)
+		if cfg.Org != "" {
+			query.Set("org", cfg.Org)
+		}
+		query.Set("precision", "ns")
+	case cfg.Database != "":
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/write"
+		}
+		query.Set("db", cfg.Database)
+	default:
+		return nil, fmt.Errorf("influxdb %s needs a bucket, or a database for InfluxDB 1.x", cfg.URL)
+	}
+	u.RawQuery = query.Encode()
+
+	return &Influx{
+		name:     "influxdb " + u.Scheme + "://" + u.Host + u.Path,
+		url:      u.String(),
+		token:    cfg.Token,
+		username: cfg.Username,
+		password: cfg.Password,
+		http:     &http.Client{},
+	}, nil
+}
+
+func (i *Influx) Name() string {
+	return i.name
+}
+
+func (i *Influx) Push(ctx context.Context, snapshot metrics.Snapshot) error {
+	lines := InfluxLines(snapshot)
+	if len(lines) == 0 {
+		return nil
+	}
+	if i.conn != nil {
+		return writeDatagrams(ctx, i.conn, lines)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, i.url, strings.NewReader(strings.Join(lines, "\n")+"\n"))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	request.Header.Set("User-Agent", "kernus")
+	if i.token != "" {
+		request.Header.Set("Authorization", "Token "+i.token)
+	} else if i.username != "" {
+		request.SetBasicAuth(i.username, i.password)
+	}
+
+	response, err := i.http.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		if text := strings.TrimSpace(string(message)); text != "" {
+			return fmt.Errorf("answered %s: %s", response.Status, text)
+		}
+		return fmt.Errorf("answered %s", response.Status)
+	}
+	io.Copy(io.Discard, response.Body)
+	return nil
+}
+
+func (i *Influx) Close() error {
+	if i.conn != nil {
+		return i.conn.Close()
+	}
+	return nil
+}
+
+// writeDatagrams sends lines packed into as few datagrams as fit within
+// maxDatagram; a longer line goes alone.
+func writeDatagrams(ctx context.Context, conn net.Conn, lines []string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	}
+	var packet []byte
+	for _, line := range lines {
+		if len(packet) > 0 && len(packet)+1+len(line) > maxDatagram {
+			if _, err := conn.Write(packet); err != nil {
+				return err
+			}
+			packet = packet[:0]
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+	}
+	if len(packet) > 0 {
+		if _, err := conn.Write(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
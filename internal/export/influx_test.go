@@ -0,0 +1,182 @@
+package export
+
+import (
+	"context"
+	"encoding/base64"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/export/influxtest"
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+// eventually waits for check to hold, as UDP datagrams arrive on their own
+// time.
+func eventually(t *testing.T, what string, check func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatalf("gave up waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func label(name, value string) metrics.Label {
+	return metrics.Label{Name: name, Value: value}
+}
+
+// lineSnapshot has two container families sharing tags, a host family and
+// values line protocol cannot carry.
+func lineSnapshot() metrics.Snapshot {
+	web := []metrics.Label{label("name", "my web,1"), label("host", "local"), label("compose_project", "")}
+	return metrics.Snapshot{Time: collected, Families: []metrics.Family{
+		{Name: "kern_container_cpu_usage_percent", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Labels: web, Value: 2.5},
+			{Labels: []metrics.Label{label("host", "local"), label("name", "a=b")}, Value: math.NaN()},
+		}},
+		{Name: "kern_container_pids", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Labels: web, Value: 4},
+			{Labels: []metrics.Label{label("host", "local"), label("name", "a=b")}, Value: 1},
+		}},
+		{Name: "kern_host_up", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Labels: []metrics.Label{label("host", "local")}, Value: 1},
+			{Labels: []metrics.Label{label("host", "remote")}, Value: math.Inf(1)},
+		}},
+	}}
+}
+
+func TestInfluxLines(t *testing.T) {
+	timestamp := " " + strconv.FormatInt(collected.UnixNano(), 10)
+	want := []string{
+		`kern_container,host=local,name=my\ web\,1 cpu_usage_percent=2.5,pids=4` + timestamp,
+		`kern_container,host=local,name=a\=b pids=1` + timestamp,
+		`kern_host,host=local up=1` + timestamp,
+	}
+	got := InfluxLines(lineSnapshot())
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("InfluxLines() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func newInfluxServer(t *testing.T) *influxtest.Server {
+	t.Helper()
+	server, err := influxtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestInfluxHTTP(t *testing.T) {
+	server := newInfluxServer(t)
+	basic := "Basic " + base64.StdEncoding.EncodeToString([]byte("kern:secret"))
+
+	tests := []struct {
+		name      string
+		cfg       config.InfluxDBConfig
+		wantPath  string
+		wantQuery map[string]string
+		wantAuth  string
+	}{
+		{
+			name:      "v2",
+			cfg:       config.InfluxDBConfig{URL: server.URL(), Bucket: "docker", Org: "ops", Token: "secret"},
+			wantPath:  "/api/v2/write",
+			wantQuery: map[string]string{"bucket": "docker", "org": "ops", "precision": "ns"},
+			wantAuth:  "Token secret",
+		},
+		{
+			name:      "v1",
+			cfg:       config.InfluxDBConfig{URL: server.URL() + "/", Database: "docker", Username: "kern", Password: "secret"},
+			wantPath:  "/write",
+			wantQuery: map[string]string{"db": "docker"},
+			wantAuth:  basic,
+		},
+		{
+			name:      "v1 without credentials",
+			cfg:       config.InfluxDBConfig{URL: server.URL(), Database: "docker"},
+			wantPath:  "/write",
+			wantQuery: map[string]string{"db": "docker"},
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			influx, err := NewInflux(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer influx.Close()
+			if err := influx.Push(context.Background(), lineSnapshot()); err != nil {
+				t.Fatal(err)
+			}
+
+			writes := server.Writes()
+			if len(writes) != i+1 {
+				t.Fatalf("server has %d writes, want %d", len(writes), i+1)
+			}
+			write := writes[i]
+			if write.Path != tt.wantPath {
+				t.Errorf("wrote to %s, want %s", write.Path, tt.wantPath)
+			}
+			for key, want := range tt.wantQuery {
+				if got := write.Query[key]; len(got) != 1 || got[0] != want {
+					t.Errorf("query %s = %v, want %s", key, got, want)
+				}
+			}
+			if got := write.Header.Get("Authorization"); got != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", got, tt.wantAuth)
+			}
+			if len(write.Lines) != len(InfluxLines(lineSnapshot())) {
+				t.Errorf("wrote %d lines, want %d", len(write.Lines), len(InfluxLines(lineSnapshot())))
+			}
+		})
+	}
+
+	server.Fail(http.StatusUnauthorized)
+	influx, err := NewInflux(tests[0].cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer influx.Close()
+	if err := influx.Push(context.Background(), lineSnapshot()); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Push() to a failing server = %v, want the status", err)
+	}
+}
+
+func TestInfluxUDP(t *testing.T) {
+	server := newInfluxServer(t)
+	influx, err := NewInflux(config.InfluxDBConfig{URL: server.UDPURL()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer influx.Close()
+	if err := influx.Push(context.Background(), lineSnapshot()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := InfluxLines(lineSnapshot())
+	eventually(t, "the lines over UDP", func() bool { return len(server.Lines()) == len(want) })
+	if got := server.Lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("received %q, want %q", got, want)
+	}
+}
+
+func TestNewInfluxRejects(t *testing.T) {
+	for _, cfg := range []config.InfluxDBConfig{
+		{URL: "http://localhost:8086"},
+		{URL: "ftp://localhost:8086", Database: "docker"},
+		{URL: "localhost:8086", Database: "docker"},
+	} {
+		if _, err := NewInflux(cfg); err == nil {
+			t.Errorf("NewInflux(%+v) succeeded", cfg)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package influxtest runs an in-process InfluxDB stand-in, accepting line
+// protocol on the HTTP write APIs and over UDP, that records the lines
+// written to it.
+package influxtest
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Write is one HTTP write or UDP datagram received.
+type Write struct {
+	Protocol string
+	Path     string
+	Query    map[string][]string
+	Header   http.Header
+	Lines    []string
+}
+
+type Server struct {
+	httpServer *httptest.Server
+	udp        net.PacketConn
+
+	mu     sync.Mutex
+	writes []Write
+	delay  time.Duration
+	status int
+}
+
+func NewServer() (*Server, error) {
+	udp, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{udp: udp}
+	go s.readUDP()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v2/write", s.handleWrite)
+	mux.HandleFunc("POST /write", s.handleWrite)
+	s.httpServer = httptest.NewServer(mux)
+	return s, nil
+}
+
+// URL is the base URL of the HTTP write APIs.
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// UDPURL is the udp:// URL of the UDP listener.
+func (s *Server) UDPURL() string {
+	return "udp://" + s.udp.LocalAddr().String()
+}
+
+// Slow delays every HTTP answer by d, to stand in for an overloaded
+// server.
+func (s *Server) Slow(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// Fail answers every HTTP write with status without recording it, until
+// called with zero.
+func (s *Server) Fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *Server) handleWrite(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	delay, status := s.delay, s.status
+	s.mu.Unlock()
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if status != 0 {
+		http.Error(w, `{"code":"invalid","message":"failing on purpose"}`, status)
+		return
+	}
+
+	s.record(Write{Protocol: "http", Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header.Clone(), Lines: lines(string(body))})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) readUDP() {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := s.udp.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		s.record(Write{Protocol: "udp", Lines: lines(string(buf[:n]))})
+	}
+}
+
+func lines(body string) []string {
+	var out []string
+	for _, line := range strings.Split(body, "\n") {
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func (s *Server) record(write Write) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writes = append(s.writes, write)
+}
+
+// Writes returns the writes received so far.
+func (s *Server) Writes() []Write {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Write(nil), s.writes...)
+}
+
+// Lines returns every line received so far.
+func (s *Server) Lines() []string {
+	var out []string
+	for _, write := range s.Writes() {
+		out = append(out, write.Lines...)
+	}
+	return out
+}
+
+func (s *Server) Close() {
+	s.httpServer.Close()
+	s.udp.Close()
+}
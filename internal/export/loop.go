@@ -0,0 +1,172 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+const (
+	// pushTimeout bounds one push of a snapshot.
+	pushTimeout = 10 * time.Second
+
+	// pushBuffer is how many snapshots wait for a slow pusher. Beyond it the
+	// oldest waiting snapshot is dropped, so a slow pusher never holds up
+	// the collection or the other pushers.
+	pushBuffer = 4
+)
+
+// Pusher sends snapshots to a monitoring system.
+type Pusher interface {
+	Name() string
+	Push(ctx context.Context, snapshot metrics.Snapshot) error
+	Close() error
+}
+
+type scheduled struct {
+	pusher  Pusher
+	every   time.Duration
+	last    time.Time
+	queue   chan metrics.Snapshot
+	dropped int
+}
+
+// enqueue queues snapshot for the pusher, dropping the oldest queued
+// snapshot when the queue is full. It reports whether one was dropped.
+func (p *scheduled) enqueue(snapshot metrics.Snapshot) bool {
+	dropped := false
+	for {
+		select {
+		case p.queue <- snapshot:
+			return dropped
+		default:
+		}
+		select {
+		case <-p.queue:
+			dropped = true
+		default:
+		}
+	}
+}
+
+// run pushes queued snapshots until ctx is done.
+func (p *scheduled) run(ctx context.Context, report func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case snapshot := <-p.queue:
+			pushCtx, cancel := context.WithTimeout(ctx, pushTimeout)
+			err := p.pusher.Push(pushCtx, snapshot)
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				report(fmt.Errorf("%s: %w", p.pusher.Name(), err))
+			}
+		}
+	}
+}
+
+// Loop collects at an interval, keeps the latest snapshot for handlers and
+// pushes snapshots to pushers, so every exporter shares one collection.
+type Loop struct {
+	collector *metrics.Collector
+	interval  time.Duration
+	latest    Latest
+	pushers   []*scheduled
+}
+
+// NewLoop collects every interval, or as often as the pushers need for
+// zero.
+func NewLoop(collector *metrics.Collector, interval time.Duration) *Loop {
+	return &Loop{collector: collector, interval: interval}
+}
+
+func (l *Loop) Latest() *Latest {
+	return &l.latest
+}
+
+// AddPusher pushes to p every interval, or on every collection for zero. A
+// pusher interval shorter than the loop's makes the loop collect that
+// often.
+func (l *Loop) AddPusher(p Pusher, interval time.Duration) {
+	l.pushers = append(l.pushers, &scheduled{pusher: p, every: interval})
+}
+
+func (l *Loop) tick() time.Duration {
+	tick := l.interval
+	for _, p := range l.pushers {
+		if p.every > 0 && (tick <= 0 || p.every < tick) {
+			tick = p.every
+		}
+	}
+	return tick
+}
+
+// Run collects and pushes until ctx is done, then closes the pushers.
+// Each pusher pushes from its own goroutine, so report may be called
+// concurrently. Collection failures are reported when they change, failed
+// pushes every time and dropped snapshots when a pusher falls behind and
+// once it caught up.
+func (l *Loop) Run(ctx context.Context, report func(error)) {
+	tick := l.tick()
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	var workers sync.WaitGroup
+	for _, p := range l.pushers {
+		p.queue = make(chan metrics.Snapshot, pushBuffer)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			p.run(ctx, report)
+		}()
+	}
+	defer func() {
+		workers.Wait()
+		for _, p := range l.pushers {
+			if err := p.pusher.Close(); err != nil {
+				report(fmt.Errorf("%s: %w", p.pusher.Name(), err))
+			}
+		}
+	}()
+
+	var failures string
+	for {
+		now := time.Now()
+		snapshot, errs := l.collector.Collect(now)
+		l.latest.Set(snapshot)
+		if joined := errors.Join(errs...); joined == nil && failures != "" {
+			failures = ""
+		} else if joined != nil && joined.Error() != failures {
+			failures = joined.Error()
+			report(joined)
+		}
+
+		for _, p := range l.pushers {
+			if !p.last.IsZero() && now.Sub(p.last) < p.every-tick/2 {
+				continue
+			}
+			p.last = now
+			if p.dropped > 0 && len(p.queue) == 0 {
+				report(fmt.Errorf("%s: caught up after dropping %d snapshots", p.pusher.Name(), p.dropped))
+				p.dropped = 0
+			}
+			if p.enqueue(snapshot) {
+				if p.dropped == 0 {
+					report(fmt.Errorf("%s: falling behind, dropping the oldest snapshots", p.pusher.Name()))
+				}
+				p.dropped++
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package export
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+// blockedPusher holds its first push until released and records the time
+// of every snapshot it is given.
+type blockedPusher struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	pushed []time.Time
+	closed bool
+}
+
+func (p *blockedPusher) Name() string {
+	return "blocked"
+}
+
+func (p *blockedPusher) Push(ctx context.Context, snapshot metrics.Snapshot) error {
+	p.mu.Lock()
+	p.pushed = append(p.pushed, snapshot.Time)
+	first := len(p.pushed) == 1
+	p.mu.Unlock()
+	if first {
+		select {
+		case <-p.release:
+		case <-ctx.Done():
+		}
+	}
+	return nil
+}
+
+func (p *blockedPusher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *blockedPusher) snapshots() []time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]time.Time(nil), p.pushed...)
+}
+
+// reports collects what the loop reports, from any goroutine.
+type reports struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (r *reports) add(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, err.Error())
+}
+
+func (r *reports) contains(text string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, message := range r.messages {
+		if strings.Contains(message, text) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoopDropsOldestSnapshotsForABlockedPusher(t *testing.T) {
+	const interval = 5 * time.Millisecond
+	collector := metrics.NewCollector([]docker.ContainerRuntime{fake.NewRuntime("local")}, "")
+	loop := NewLoop(collector, interval)
+	pusher := &blockedPusher{release: make(chan struct{})}
+	loop.AddPusher(pusher, 0)
+
+	var reported reports
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		loop.Run(ctx, reported.add)
+		close(done)
+	}()
+	stop := sync.OnceFunc(func() {
+		cancel()
+		<-done
+	})
+	defer stop()
+
+	// Collection goes on while the pusher is stuck.
+	eventually(t, "the loop to fall behind", func() bool { return reported.contains("blocked: falling behind") })
+	if _, ok := loop.Latest().Get(); !ok {
+		t.Error("no snapshot kept while a pusher is blocked")
+	}
+	time.Sleep(20 * interval)
+	close(pusher.release)
+
+	eventually(t, "the queued snapshots", func() bool { return len(pusher.snapshots()) >= 1+pushBuffer })
+	pushed := pusher.snapshots()
+	// The snapshots that waited are the latest ones, collected just before
+	// the release rather than just after the blocked push.
+	if waited := pushed[1].Sub(pushed[0]); waited < 10*interval {
+		t.Errorf("next snapshot pushed was collected %s after the blocked one, want one of the latest", waited)
+	}
+	eventually(t, "the pusher to catch up", func() bool { return reported.contains("blocked: caught up after dropping") })
+
+	stop()
+	pusher.mu.Lock()
+	defer pusher.mu.Unlock()
+	if !pusher.closed {
+		t.Error("pusher not closed when the loop stopped")
+	}
+}
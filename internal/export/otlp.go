@@ -0,0 +1,374 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/metrics"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+
+	defaultGRPCEndpoint = "localhost:4317"
+	defaultHTTPEndpoint = "http://localhost:4318/v1/metrics"
+
+	scopeName = "github.com/kqnd/kernus"
+)
+
+// otelMetric is the semantic convention name of a family, where there is
+// one. The value is multiplied by scale and the attributes added.
+type otelMetric struct {
+	name  string
+	unit  string
+	scale float64
+	attrs []metrics.Label
+}
+
+var otelMetrics = map[string]otelMetric{
+	"kern_container_cpu_usage_percent":            {"container.cpu.usage", "{cpu}", 0.01, nil},
+	"kern_container_memory_usage_bytes":           {"container.memory.usage", "By", 1, nil},
+	"kern_container_network_receive_bytes_total":  {"container.network.io", "By", 1, []metrics.Label{{Name: "network.io.direction", Value: "receive"}}},
+	"kern_container_network_transmit_bytes_total": {"container.network.io", "By", 1, []metrics.Label{{Name: "network.io.direction", Value: "transmit"}}},
+	"kern_container_blkio_read_bytes_total":       {"container.disk.io", "By", 1, []metrics.Label{{Name: "disk.io.direction", Value: "read"}}},
+	"kern_container_blkio_write_bytes_total":      {"container.disk.io", "By", 1, []metrics.Label{{Name: "disk.io.direction", Value: "write"}}},
+	"kern_host_cpus":                              {"system.cpu.logical.count", "{cpu}", 1, nil},
+	"kern_host_cpu_seconds_total":                 {"system.cpu.time", "s", 1, nil},
+	"kern_host_load1":                             {"system.cpu.load_average.1m", "{thread}", 1, nil},
+	"kern_host_load5":                             {"system.cpu.load_average.5m", "{thread}", 1, nil},
+	"kern_host_load15":                            {"system.cpu.load_average.15m", "{thread}", 1, nil},
+	"kern_host_memory_total_bytes":                {"system.memory.limit", "By", 1, nil},
+	"kern_host_uptime_seconds":                    {"system.uptime", "s", 1, nil},
+}
+
+// otelAttributes renames the labels that stay on data points.
+var otelAttributes = map[string]string{
+	"mode":   "cpu.mode",
+	"state":  "container.state",
+	"health": "container.health",
+}
+
+var otelUnits = map[string]string{
+	"bytes":   "By",
+	"seconds": "s",
+	"percent": "%",
+}
+
+// otelName maps a family to its OpenTelemetry metric. Families without a
+// semantic convention become kern.* metrics, such as
+// kern_container_restarts_total to kern.container.restarts.
+func otelName(family *metrics.Family) otelMetric {
+	if metric, ok := otelMetrics[family.Name]; ok {
+		return metric
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(family.Name, "kern_"), "_total")
+	if family.Unit != "" {
+		name = strings.TrimSuffix(name, "_"+family.Unit)
+	}
+	return otelMetric{name: "kern." + strings.ReplaceAll(name, "_", "."), unit: otelUnits[family.Unit], scale: 1}
+}
+
+// OTLPRequest converts a snapshot to an OTLP export request, with one
+// resource per host and per container. Container counters start when the
+// container last started, others at start.
+func OTLPRequest(snapshot metrics.Snapshot, start time.Time) *colmetricpb.ExportMetricsServiceRequest {
+	type resourceMetrics struct {
+		resource  *resourcepb.Resource
+		metrics   []*metricpb.Metric
+		byName    map[string]*metricpb.Metric
+		startNano uint64
+	}
+	var order []string
+	resources := make(map[string]*resourceMetrics)
+
+	now := uint64(snapshot.Time.UnixNano())
+	startNano := uint64(start.UnixNano())
+	for i := range snapshot.Families {
+		family := &snapshot.Families[i]
+		otel := otelName(family)
+		for _, sample := range family.Samples {
+			key, resource, rest := splitResource(sample.Labels)
+			rm := resources[key]
+			if rm == nil {
+				rm = &resourceMetrics{resource: resource, byName: make(map[string]*metricpb.Metric), startNano: startNano}
+				if started, ok := snapshot.Started[containerID(sample.Labels)]; ok && !started.After(snapshot.Time) {
+					rm.startNano = uint64(started.UnixNano())
+				}
+				resources[key] = rm
+				order = append(order, key)
+			}
+
+			metric := rm.byName[otel.name]
+			if metric == nil {
+				metric = &metricpb.Metric{Name: otel.name, Description: family.Help, Unit: otel.unit}
+				if family.Kind == metrics.Counter {
+					metric.Data = &metricpb.Metric_Sum{Sum: &metricpb.Sum{
+						AggregationTemporality: metricpb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+						IsMonotonic:            true,
+					}}
+				} else {
+					metric.Data = &metricpb.Metric_Gauge{Gauge: &metricpb.Gauge{}}
+				}
+				rm.byName[otel.name] = metric
+				rm.metrics = append(rm.metrics, metric)
+			}
+
+			point := &metricpb.NumberDataPoint{
+				Attributes:   attributes(append(rest, otel.attrs...)),
+				TimeUnixNano: now,
+				Value:        &metricpb.NumberDataPoint_AsDouble{AsDouble: sample.Value * otel.scale},
+			}
+			switch data := metric.Data.(type) {
+			case *metricpb.Metric_Sum:
+				point.StartTimeUnixNano = rm.startNano
+				data.Sum.DataPoints = append(data.Sum.DataPoints, point)
+			case *metricpb.Metric_Gauge:
+				data.Gauge.DataPoints = append(data.Gauge.DataPoints, point)
+			}
+		}
+	}
+
+	request := &colmetricpb.ExportMetricsServiceRequest{}
+	for _, key := range order {
+		rm := resources[key]
+		request.ResourceMetrics = append(request.ResourceMetrics, &metricpb.ResourceMetrics{
+			Resource: rm.resource,
+			ScopeMetrics: []*metricpb.ScopeMetrics{{
+				Scope:   &commonpb.InstrumentationScope{Name: scopeName},
+				Metrics: rm.metrics,
+			}},
+		})
+	}
+	return request
+}
+
+// splitResource takes the labels naming a host or container out of a
+// sample as resource attributes, following the semantic conventions, and
+// returns the rest.
+func splitResource(labels []metrics.Label) (string, *resourcepb.Resource, []metrics.Label) {
+	resource := []metrics.Label{{Name: "service.name", Value: "kern"}}
+	var rest []metrics.Label
+	key := ""
+	for _, label := range labels {
+		switch label.Name {
+		case "host":
+			resource = append(resource, metrics.Label{Name: "host.name", Value: label.Value})
+			key += "host=" + label.Value + ";"
+		case "id":
+			resource = append(resource, metrics.Label{Name: "container.id", Value: label.Value})
+			key += "id=" + label.Value + ";"
+		case "name":
+			resource = append(resource, metrics.Label{Name: "container.name", Value: label.Value})
+		case "image":
+			name, tag := splitImage(label.Value)
+			resource = append(resource, metrics.Label{Name: "container.image.name", Value: name})
+			if tag != "" {
+				resource = append(resource, metrics.Label{Name: "container.image.tags", Value: tag})
+			}
+		case "compose_project":
+			if label.Value != "" {
+				resource = append(resource, metrics.Label{Name: "container.label.com.docker.compose.project", Value: label.Value})
+			}
+		default:
+			rest = append(rest, label)
+		}
+	}
+	return key, &resourcepb.Resource{Attributes: attributes(resource)}, rest
+}
+
+// containerID is the id label of a container sample, empty for hosts.
+func containerID(labels []metrics.Label) string {
+	for _, label := range labels {
+		if label.Name == "id" {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+// splitImage splits "registry:5000/app:1.2" into "registry:5000/app" and
+// "1.2".
+func splitImage(image string) (string, string) {
+	slash := strings.LastIndex(image, "/")
+	if colon := strings.LastIndex(image, ":"); colon > slash {
+		return image[:colon], image[colon+1:]
+	}
+	return image, ""
+}
+
+func attributes(labels []metrics.Label) []*commonpb.KeyValue {
+	attrs := make([]*commonpb.KeyValue, 0, len(labels))
+	for _, label := range labels {
+		name := label.Name
+		if renamed, ok := otelAttributes[name]; ok {
+			name = renamed
+		}
+		value := &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: label.Value}}
+		if name == "container.image.tags" {
+			value = &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{
+				Values: []*commonpb.AnyValue{value},
+			}}}
+		}
+		attrs = append(attrs, &commonpb.KeyValue{Key: name, Value: value})
+	}
+	return attrs
+}
+
+// OTLP pushes snapshots to an OpenTelemetry collector over gRPC or HTTP.
+type OTLP struct {
+	protocol string
+	endpoint string
+	headers  map[string]string
+	start    time.Time
+
+	conn   *grpc.ClientConn
+	client colmetricpb.MetricsServiceClient
+	http   *http.Client
+}
+
+// NewOTLP sets up the exporter of the config; nothing is sent before the
+// first push.
+func NewOTLP(cfg config.OTLPConfig) (*OTLP, error) {
+	o := &OTLP{protocol: cfg.Protocol, endpoint: cfg.Endpoint, headers: cfg.Headers, start: time.Now()}
+	if o.protocol == "" {
+		o.protocol = ProtocolGRPC
+	}
+
+	switch o.protocol {
+	case ProtocolGRPC:
+		if o.endpoint == "" {
+			o.endpoint = defaultGRPCEndpoint
+		}
+		plaintext := cfg.Insecure
+		if u, err := url.Parse(o.endpoint); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			o.endpoint = u.Host
+			plaintext = plaintext || u.Scheme == "http"
+		}
+		creds := credentials.NewTLS(&tls.Config{})
+		if plaintext {
+			creds = insecure.NewCredentials()
+		}
+		conn, err := grpc.NewClient(o.endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("otlp endpoint %s: %w", o.endpoint, err)
+		}
+		o.conn = conn
+		o.client = colmetricpb.NewMetricsServiceClient(conn)
+	case ProtocolHTTP, "http":
+		o.protocol = ProtocolHTTP
+		if o.endpoint == "" {
+			o.endpoint = defaultHTTPEndpoint
+		}
+		if !strings.Contains(o.endpoint, "://") {
+			scheme := "https://"
+			if cfg.Insecure {
+				scheme = "http://"
+			}
+			o.endpoint = scheme + o.endpoint
+		}
+		u, err := url.Parse(o.endpoint)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid otlp endpoint %q, expected a URL such as %s", cfg.Endpoint, defaultHTTPEndpoint)
+		}
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/v1/metrics"
+		}
+		o.endpoint = u.String()
+		o.http = &http.Client{}
+	default:
+		return nil, fmt.Errorf("unknown otlp protocol %q, expected grpc or http/protobuf", cfg.Protocol)
+	}
+	return o, nil
+}
+
+func (o *OTLP) Name() string {
+	return "otlp " + o.endpoint
+}
+
+func (o *OTLP) Push(ctx context.Context, snapshot metrics.Snapshot) error {
+	request := OTLPRequest(snapshot, o.start)
+	var response *colmetricpb.ExportMetricsServiceResponse
+	var err error
+	if o.protocol == ProtocolGRPC {
+		response, err = o.pushGRPC(ctx, request)
+	} else {
+		response, err = o.pushHTTP(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+
+	if partial := response.GetPartialSuccess(); partial.GetRejectedDataPoints() > 0 {
+		return fmt.Errorf("collector rejected %d data points: %s", partial.GetRejectedDataPoints(), partial.GetErrorMessage())
+	}
+	return nil
+}
+
+func (o *OTLP) pushGRPC(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	if len(o.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.headers))
+	}
+	return o.client.Export(ctx, request)
+}
+
+func (o *OTLP) pushHTTP(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, o.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/x-protobuf")
+	httpRequest.Header.Set("User-Agent", "kernus")
+	for key, value := range o.headers {
+		httpRequest.Header.Set(key, value)
+	}
+
+	httpResponse, err := o.http.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(httpResponse.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s answered %s", o.endpoint, httpResponse.Status)
+	}
+
+	response := &colmetricpb.ExportMetricsServiceResponse{}
+	if len(data) > 0 && strings.HasPrefix(httpResponse.Header.Get("Content-Type"), "application/x-protobuf") {
+		if err := proto.Unmarshal(data, response); err != nil {
+			return nil, fmt.Errorf("reading the answer of %s: %w", o.endpoint, err)
+		}
+	}
+	return response, nil
+}
+
+func (o *OTLP) Close() error {
+	if o.conn != nil {
+		return o.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/export/otlptest"
+	"github.com/kqnd/kernus/internal/metrics"
+	"github.com/kqnd/kernus/internal/models"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+)
+
+var collected = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+// testSnapshot lists the mock containers on one host. nginx started an
+// hour before the collection; redis never ran.
+func testSnapshot() (metrics.Snapshot, time.Time) {
+	containers := models.MockContainers()
+	nginxStarted := collected.Add(-time.Hour)
+	containers[0].Started = nginxStarted
+	for i := 1; i < len(containers); i++ {
+		if !containers[i].Started.IsZero() {
+			containers[i].Started = collected.Add(-24 * time.Hour)
+		}
+	}
+	return metrics.Build(collected, []metrics.Host{{Name: "local", Containers: containers}}), nginxStarted
+}
+
+// sumStarts maps the container of every cumulative data point to its start
+// time; host points are under "".
+func sumStarts(t *testing.T, request *colmetricpb.ExportMetricsServiceRequest) map[string]time.Time {
+	t.Helper()
+	starts := make(map[string]time.Time)
+	for _, rm := range request.GetResourceMetrics() {
+		id := ""
+		for _, kv := range rm.GetResource().GetAttributes() {
+			if kv.GetKey() == "container.id" {
+				id = kv.GetValue().GetStringValue()
+			}
+		}
+		for _, sm := range rm.GetScopeMetrics() {
+			for _, metric := range sm.GetMetrics() {
+				for _, point := range metric.GetSum().GetDataPoints() {
+					start := time.Unix(0, int64(point.GetStartTimeUnixNano())).UTC()
+					if previous, ok := starts[id]; ok && !previous.Equal(start) {
+						t.Errorf("%s: counters start at %s and %s", id, previous, start)
+					}
+					starts[id] = start
+				}
+			}
+		}
+	}
+	return starts
+}
+
+func TestOTLPRequestStartsCountersWhenContainersStarted(t *testing.T) {
+	snapshot, nginxStarted := testSnapshot()
+	exporterStart := collected.Add(-time.Minute)
+
+	starts := sumStarts(t, OTLPRequest(snapshot, exporterStart))
+	if got := starts["abc123456789"]; !got.Equal(nginxStarted) {
+		t.Errorf("nginx counters start at %s, want when it started at %s", got, nginxStarted)
+	}
+	if got := starts["def456789012"]; !got.Equal(collected.Add(-24 * time.Hour)) {
+		t.Errorf("postgres counters start at %s, want a day ago", got)
+	}
+	// Without a start time the exporter start is all there is.
+	if got := starts["ghi789012345"]; !got.Equal(exporterStart) {
+		t.Errorf("redis counters start at %s, want the exporter start %s", got, exporterStart)
+	}
+}
+
+func TestOTLPPush(t *testing.T) {
+	receiver, err := otlptest.NewReceiver()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(receiver.Close)
+
+	tests := []struct {
+		protocol string
+		endpoint string
+	}{
+		{ProtocolGRPC, receiver.GRPCEndpoint()},
+		{ProtocolHTTP, receiver.HTTPEndpoint()},
+	}
+	for i, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			exporter, err := NewOTLP(config.OTLPConfig{
+				Protocol: tt.protocol,
+				Endpoint: tt.endpoint,
+				Insecure: true,
+				Headers:  map[string]string{"authorization": "Bearer secret"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer exporter.Close()
+
+			snapshot, nginxStarted := testSnapshot()
+			if err := exporter.Push(context.Background(), snapshot); err != nil {
+				t.Fatal(err)
+			}
+
+			requests := receiver.Requests()
+			if len(requests) != i+1 {
+				t.Fatalf("receiver has %d requests, want %d", len(requests), i+1)
+			}
+			request := requests[i]
+			wantProtocol := "grpc"
+			if tt.protocol == ProtocolHTTP {
+				wantProtocol = "http"
+			}
+			if request.Protocol != wantProtocol {
+				t.Errorf("received over %s, want %s", request.Protocol, wantProtocol)
+			}
+			if got := request.Header["authorization"]; len(got) != 1 || got[0] != "Bearer secret" {
+				t.Errorf("authorization = %v, want the configured header", got)
+			}
+			if got := sumStarts(t, request.Metrics)["abc123456789"]; !got.Equal(nginxStarted) {
+				t.Errorf("nginx counters start at %s, want %s", got, nginxStarted)
+			}
+		})
+	}
+
+	// Both pushes carry the nginx receive counter, with the container as
+	// its resource.
+	var received []otlptest.Point
+	for _, point := range receiver.Points("container.network.io") {
+		if point.Resource["container.name"] == "nginx-web" && point.Attributes["network.io.direction"] == "receive" {
+			received = append(received, point)
+		}
+	}
+	want := float64(models.MockContainers()[0].Stats.Network.RxBytes)
+	if len(received) != len(tests) {
+		t.Fatalf("%d nginx receive points, want one per push", len(received))
+	}
+	for _, point := range received {
+		if point.Value != want || point.Resource["host.name"] != "local" || point.Resource["container.id"] != "abc123456789" {
+			t.Errorf("point = %+v, want %g bytes for nginx on local", point, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package otlptest runs an in-process OTLP metrics receiver, serving gRPC
+// and HTTP on local ports, that records what exporters push to it.
+package otlptest
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricpb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// Request is one export received over either protocol. Header holds the
+// gRPC metadata or the HTTP headers, with lower-case keys.
+type Request struct {
+	Protocol string
+	Header   map[string][]string
+	Metrics  *colmetricpb.ExportMetricsServiceRequest
+}
+
+// Point is a received data point with the attributes of its resource.
+type Point struct {
+	Metric     string
+	Resource   map[string]string
+	Attributes map[string]string
+	Value      float64
+}
+
+type Receiver struct {
+	colmetricpb.UnimplementedMetricsServiceServer
+
+	listener   net.Listener
+	grpcServer *grpc.Server
+	httpServer *httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+func NewReceiver() (*Receiver, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Receiver{listener: listener, grpcServer: grpc.NewServer()}
+	colmetricpb.RegisterMetricsServiceServer(r.grpcServer, r)
+	go r.grpcServer.Serve(listener)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/metrics", r.handleHTTP)
+	r.httpServer = httptest.NewServer(mux)
+	return r, nil
+}
+
+// GRPCEndpoint is the host:port of the gRPC service, without TLS.
+func (r *Receiver) GRPCEndpoint() string {
+	return r.listener.Addr().String()
+}
+
+// HTTPEndpoint is the URL to post metrics to.
+func (r *Receiver) HTTPEndpoint() string {
+	return r.httpServer.URL + "/v1/metrics"
+}
+
+func (r *Receiver) Export(ctx context.Context, request *colmetricpb.ExportMetricsServiceRequest) (*colmetricpb.ExportMetricsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	r.record(Request{Protocol: "grpc", Header: md, Metrics: request})
+	return &colmetricpb.ExportMetricsServiceResponse{}, nil
+}
+
+func (r *Receiver) handleHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("Content-Type") != "application/x-protobuf" {
+		http.Error(w, "expected application/x-protobuf", http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := &colmetricpb.ExportMetricsServiceRequest{}
+	if err := proto.Unmarshal(body, request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	header := make(map[string][]string, len(req.Header))
+	for key, values := range req.Header {
+		header[strings.ToLower(key)] = values
+	}
+	r.record(Request{Protocol: "http", Header: header, Metrics: request})
+
+	response, _ := proto.Marshal(&colmetricpb.ExportMetricsServiceResponse{})
+	w.Header().Set("Content-Type", "application/x-protobuf")
+	w.Write(response)
+}
+
+func (r *Receiver) record(request Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+}
+
+// Requests returns the exports received so far.
+func (r *Receiver) Requests() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Request(nil), r.requests...)
+}
+
+// Points returns the data points of the metric called name in every export
+// received so far.
+func (r *Receiver) Points(name string) []Point {
+	var points []Point
+	for _, request := range r.Requests() {
+		for _, rm := range request.Metrics.GetResourceMetrics() {
+			resource := attributes(rm.GetResource().GetAttributes())
+			for _, sm := range rm.GetScopeMetrics() {
+				for _, metric := range sm.GetMetrics() {
+					if metric.GetName() != name {
+						continue
+					}
+					for _, dp := range dataPoints(metric) {
+						points = append(points, Point{
+							Metric:     name,
+							Resource:   resource,
+							Attributes: attributes(dp.GetAttributes()),
+							Value:      dp.GetAsDouble() + float64(dp.GetAsInt()),
+						})
+					}
+				}
+			}
+		}
+	}
+	return points
+}
+
+func dataPoints(metric *metricpb.Metric) []*metricpb.NumberDataPoint {
+	if gauge := metric.GetGauge(); gauge != nil {
+		return gauge.GetDataPoints()
+	}
+	return metric.GetSum().GetDataPoints()
+}
+
+// attributes flattens attributes to strings; arrays are joined with commas.
+func attributes(kvs []*commonpb.KeyValue) map[string]string {
+	attrs := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		if array := kv.GetValue().GetArrayValue(); array != nil {
+			values := make([]string, 0, len(array.GetValues()))
+			for _, value := range array.GetValues() {
+				values = append(values, value.GetStringValue())
+			}
+			attrs[kv.GetKey()] = strings.Join(values, ",")
+			continue
+		}
+		attrs[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return attrs
+}
+
+func (r *Receiver) Close() {
+	r.grpcServer.Stop()
+	r.httpServer.Close()
+}
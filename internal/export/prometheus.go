@@ -0,0 +1,99 @@
+// Package export writes metric snapshots out for monitoring systems.
+package export
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+// PrometheusContentType is the content type of the text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// WritePrometheus writes a snapshot in the Prometheus text exposition
+// format. Families without samples are left out.
+func WritePrometheus(w io.Writer, snapshot metrics.Snapshot) error {
+	buf := bufio.NewWriter(w)
+	for _, family := range snapshot.Families {
+		if len(family.Samples) == 0 {
+			continue
+		}
+		buf.WriteString("# HELP " + family.Name + " " + helpEscaper.Replace(family.Help) + "\n")
+		buf.WriteString("# TYPE " + family.Name + " " + string(family.Kind) + "\n")
+		for _, sample := range family.Samples {
+			buf.WriteString(family.Name)
+			if len(sample.Labels) > 0 {
+				buf.WriteByte('{')
+				for i, label := range sample.Labels {
+					if i > 0 {
+						buf.WriteByte(',')
+					}
+					buf.WriteString(label.Name + `="` + labelEscaper.Replace(label.Value) + `"`)
+				}
+				buf.WriteByte('}')
+			}
+			buf.WriteByte(' ')
+			buf.WriteString(formatValue(sample.Value))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Flush()
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// Latest holds the most recent snapshot of a collection loop, for the
+// handlers that serve it. It is safe for concurrent use.
+type Latest struct {
+	mu       sync.RWMutex
+	snapshot *metrics.Snapshot
+}
+
+func (l *Latest) Set(snapshot metrics.Snapshot) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.snapshot = &snapshot
+}
+
+// Get returns the latest snapshot, or false before the first collection.
+func (l *Latest) Get() (metrics.Snapshot, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if l.snapshot == nil {
+		return metrics.Snapshot{}, false
+	}
+	return *l.snapshot, true
+}
+
+// PrometheusHandler serves the latest snapshot on every request, and 503
+// until there is one.
+func PrometheusHandler(latest *Latest) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshot, ok := latest.Get()
+		if !ok {
+			http.Error(w, "no collection yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", PrometheusContentType)
+		WritePrometheus(w, snapshot)
+	})
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kqnd/kernus/internal/metrics"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	snapshot := metrics.Snapshot{Time: collected, Families: []metrics.Family{
+		{Name: "kern_host_up", Help: `Whether the host \ daemon answered.` + "\nSee kern export.", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Labels: []metrics.Label{label("host", "local")}, Value: 1},
+			{Labels: []metrics.Label{label("host", `C:\docker "win"`+"\nbox")}, Value: 0},
+		}},
+		{Name: "kern_container_restarts_total", Help: "Restarts.", Kind: metrics.Counter},
+		{Name: "kern_container_cpu_usage_percent", Help: "CPU.", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Labels: []metrics.Label{label("host", "local"), label("name", "web")}, Value: 2.5e-7},
+			{Labels: []metrics.Label{label("host", "local"), label("name", "db")}, Value: math.NaN()},
+			{Labels: []metrics.Label{label("host", "local"), label("name", "idle")}, Value: math.Inf(-1)},
+		}},
+		{Name: "kern_collection_duration_seconds", Help: "Time.", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Value: 0.25},
+		}},
+	}}
+
+	want := `# HELP kern_host_up Whether the host \\ daemon answered.\nSee kern export.
+# TYPE kern_host_up gauge
+kern_host_up{host="local"} 1
+kern_host_up{host="C:\\docker \"win\"\nbox"} 0
+# HELP kern_container_cpu_usage_percent CPU.
+# TYPE kern_container_cpu_usage_percent gauge
+kern_container_cpu_usage_percent{host="local",name="web"} 2.5e-07
+kern_container_cpu_usage_percent{host="local",name="db"} NaN
+kern_container_cpu_usage_percent{host="local",name="idle"} -Inf
+# HELP kern_collection_duration_seconds Time.
+# TYPE kern_collection_duration_seconds gauge
+kern_collection_duration_seconds 0.25
+`
+	var out strings.Builder
+	if err := WritePrometheus(&out, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+// TestWritePrometheusBuilt writes what metrics.Build makes of a host with
+// containers and a host that is down.
+func TestWritePrometheusBuilt(t *testing.T) {
+	containers := models.MockContainers()
+	snapshot := metrics.Build(collected, []metrics.Host{
+		{Name: "local", Containers: containers[:1]},
+		{Name: "remote", Err: errors.New("connection refused")},
+	})
+
+	var out strings.Builder
+	if err := WritePrometheus(&out, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(out.String(), "\n")
+	nginx := `host="local",id="abc123456789",name="nginx-web",image="nginx:latest",compose_project=""`
+	for _, want := range []string{
+		`# TYPE kern_host_up gauge`,
+		`kern_host_up{host="local"} 1`,
+		`kern_host_up{host="remote"} 0`,
+		`kern_host_containers{host="local",state="running"} 1`,
+		`# TYPE kern_container_restarts_total counter`,
+		`kern_container_state{` + nginx + `,state="running"} 1`,
+		`kern_container_pids{` + nginx + `} 12`,
+	} {
+		found := false
+		for _, line := range lines {
+			if line == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("missing %s in\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), `host="remote",`) {
+		t.Errorf("samples other than kern_host_up for a host that is down:\n%s", out.String())
+	}
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	var latest Latest
+	handler := PrometheusHandler(&latest)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("status before the first collection = %d, want 503", recorder.Code)
+	}
+
+	latest.Set(metrics.Snapshot{Time: collected, Families: []metrics.Family{
+		{Name: "kern_host_up", Help: "Up.", Kind: metrics.Gauge, Samples: []metrics.Sample{
+			{Labels: []metrics.Label{label("host", "local")}, Value: 1},
+		}},
+	}})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != PrometheusContentType {
+		t.Errorf("status %d with content type %q", recorder.Code, recorder.Header().Get("Content-Type"))
+	}
+	if !strings.HasSuffix(string(body), "kern_host_up{host=\"local\"} 1\n") {
+		t.Errorf("body =\n%s", body)
+	}
+}
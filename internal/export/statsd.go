@@ -0,0 +1,174 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+const (
+	FlavorStatsD    = "statsd"
+	FlavorDogStatsD = "dogstatsd"
+
+	defaultStatsDAddress = "localhost:8125"
+)
+
+// statsdSkippedLabels are left out of plain StatsD names, which would
+// otherwise grow long and change with every image update.
+var statsdSkippedLabels = map[string]bool{"id": true, "image": true, "compose_project": true}
+
+// StatsD sends snapshots to a StatsD or DogStatsD server over UDP. Gauges
+// are sent as gauges and counters as the increase since the previous push.
+type StatsD struct {
+	address   string
+	prefix    string
+	dogstatsd bool
+	conn      net.Conn
+
+	// previous holds the counter values of the previous push by series.
+	previous map[string]float64
+}
+
+// NewStatsD sets up the client of the config; nothing is sent before the
+// first push.
+func NewStatsD(cfg config.StatsDConfig) (*StatsD, error) {
+	s := &StatsD{address: cfg.Address, prefix: strings.TrimSuffix(cfg.Prefix, ".")}
+	if s.address == "" {
+		s.address = defaultStatsDAddress
+	}
+	switch cfg.Flavor {
+	case "", FlavorStatsD:
+	case FlavorDogStatsD:
+		s.dogstatsd = true
+	default:
+		return nil, fmt.Errorf("unknown statsd flavor %q, expected statsd or dogstatsd", cfg.Flavor)
+	}
+
+	conn, err := net.Dial("udp", s.address)
+	if err != nil {
+		return nil, fmt.Errorf("statsd %s: %w", s.address, err)
+	}
+	s.conn = conn
+	return s, nil
+}
+
+func (s *StatsD) Name() string {
+	if s.dogstatsd {
+		return "dogstatsd " + s.address
+	}
+	return "statsd " + s.address
+}
+
+func (s *StatsD) Push(ctx context.Context, snapshot metrics.Snapshot) error {
+	lines := s.Lines(snapshot)
+	if len(lines) == 0 {
+		return nil
+	}
+	return writeDatagrams(ctx, s.conn, lines)
+}
+
+// Lines converts a snapshot to StatsD lines and remembers its counters for
+// the next call. A counter seen for the first time is only remembered; one
+// that went down, such as after a restart, counts from zero.
+func (s *StatsD) Lines(snapshot metrics.Snapshot) []string {
+	var lines []string
+	counters := make(map[string]float64, len(s.previous))
+	for _, family := range snapshot.Families {
+		group, field := splitName(family.Name)
+		for _, sample := range family.Samples {
+			if math.IsNaN(sample.Value) || math.IsInf(sample.Value, 0) {
+				continue
+			}
+			series := s.series(group, field, sample.Labels)
+
+			if family.Kind == metrics.Counter {
+				counters[series] = sample.Value
+				previous, ok := s.previous[series]
+				if !ok {
+					continue
+				}
+				delta := sample.Value - previous
+				if delta < 0 {
+					delta = sample.Value
+				}
+				lines = append(lines, s.line(series, delta, "c"))
+				continue
+			}
+
+			if sample.Value < 0 && !s.dogstatsd {
+				// A signed gauge changes the current value in StatsD, so
+				// it is reset first.
+				lines = append(lines, s.line(series, 0, "g"))
+			}
+			lines = append(lines, s.line(series, sample.Value, "g"))
+		}
+	}
+	s.previous = counters
+	return lines
+}
+
+// series is the name of a sample, with the labels as tags after a | for
+// DogStatsD, or in the name for StatsD, such as
+// kern.container.local.web.cpu_usage_percent.
+func (s *StatsD) series(group, field string, labels []metrics.Label) string {
+	parts := make([]string, 0, len(labels)+3)
+	if s.prefix != "" {
+		parts = append(parts, s.prefix)
+	}
+	parts = append(parts, strings.ReplaceAll(group, "_", "."))
+
+	if s.dogstatsd {
+		tags := make([]string, 0, len(labels))
+		for _, label := range labels {
+			if label.Value != "" {
+				tags = append(tags, label.Name+":"+dogstatsdTagEscaper.Replace(label.Value))
+			}
+		}
+		name := strings.Join(append(parts, field), ".")
+		if len(tags) == 0 {
+			return name
+		}
+		return name + "|#" + strings.Join(tags, ",")
+	}
+
+	for _, label := range labels {
+		if statsdSkippedLabels[label.Name] || label.Value == "" {
+			continue
+		}
+		parts = append(parts, statsdName(label.Value))
+	}
+	return strings.Join(append(parts, field), ".")
+}
+
+// line formats a value of series, moving DogStatsD tags after the type.
+func (s *StatsD) line(series string, value float64, kind string) string {
+	name, tags, _ := strings.Cut(series, "|")
+	line := name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + kind
+	if tags != "" {
+		line += "|" + tags
+	}
+	return line
+}
+
+var dogstatsdTagEscaper = strings.NewReplacer(",", "_", "|", "_", "\n", "_")
+
+// statsdName keeps a label value from splitting or ending a StatsD name.
+func statsdName(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/export/statsdtest"
+	"github.com/kqnd/kernus/internal/metrics"
+)
+
+func newStatsDServer(t *testing.T) *statsdtest.Server {
+	t.Helper()
+	server, err := statsdtest.NewServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newStatsD(t *testing.T, cfg config.StatsDConfig) *StatsD {
+	t.Helper()
+	s, err := NewStatsD(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// statsdSnapshot has a CPU gauge and a receive counter of one container.
+func statsdSnapshot(cpu, received float64) metrics.Snapshot {
+	labels := []metrics.Label{
+		label("host", "local"),
+		label("id", "abc123456789"),
+		label("name", "my web,1"),
+		label("image", "nginx:1.27"),
+		label("compose_project", ""),
+	}
+	return metrics.Snapshot{Time: collected, Families: []metrics.Family{
+		{Name: "kern_container_cpu_usage_percent", Kind: metrics.Gauge, Samples: []metrics.Sample{{Labels: labels, Value: cpu}}},
+		{Name: "kern_container_network_receive_bytes_total", Kind: metrics.Counter, Samples: []metrics.Sample{{Labels: labels, Value: received}}},
+	}}
+}
+
+func TestStatsDCounterDeltas(t *testing.T) {
+	s := newStatsD(t, config.StatsDConfig{Address: newStatsDServer(t).Addr(), Prefix: "ops."})
+	const cpu = "ops.kern.container.local.my_web_1.cpu_usage_percent"
+	const received = "ops.kern.container.local.my_web_1.network_receive_bytes_total"
+
+	tests := []struct {
+		name     string
+		snapshot metrics.Snapshot
+		want     []string
+	}{
+		{"first push only remembers counters", statsdSnapshot(2.5, 100), []string{cpu + ":2.5|g"}},
+		{"increase", statsdSnapshot(3, 150), []string{cpu + ":3|g", received + ":50|c"}},
+		{"no change", statsdSnapshot(3, 150), []string{cpu + ":3|g", received + ":0|c"}},
+		{"reset counts from zero", statsdSnapshot(3, 30), []string{cpu + ":3|g", received + ":30|c"}},
+		{"negative gauge is reset first", statsdSnapshot(-1, 40), []string{cpu + ":0|g", cpu + ":-1|g", received + ":10|c"}},
+	}
+	for _, tt := range tests {
+		if got := s.Lines(tt.snapshot); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: Lines() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDogStatsDTags(t *testing.T) {
+	s := newStatsD(t, config.StatsDConfig{Address: newStatsDServer(t).Addr(), Flavor: FlavorDogStatsD})
+	const tags = "|#host:local,id:abc123456789,name:my web_1,image:nginx:1.27"
+
+	s.Lines(statsdSnapshot(2.5, 100))
+	want := []string{
+		"kern.container.cpu_usage_percent:-1|g" + tags,
+		"kern.container.network_receive_bytes_total:20|c" + tags,
+	}
+	// DogStatsD takes signed gauges as they are.
+	if got := s.Lines(statsdSnapshot(-1, 120)); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+	if name := s.Name(); !strings.HasPrefix(name, "dogstatsd ") {
+		t.Errorf("Name() = %q", name)
+	}
+}
+
+func TestStatsDPush(t *testing.T) {
+	server := newStatsDServer(t)
+	s := newStatsD(t, config.StatsDConfig{Address: server.Addr()})
+
+	for _, received := range []float64{100, 175} {
+		if err := s.Push(context.Background(), statsdSnapshot(2.5, received)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eventually(t, "both pushes", func() bool { return len(server.Packets()) == 2 })
+	want := []string{
+		"kern.container.local.my_web_1.cpu_usage_percent:2.5|g",
+		"kern.container.local.my_web_1.cpu_usage_percent:2.5|g",
+		"kern.container.local.my_web_1.network_receive_bytes_total:75|c",
+	}
+	if got := server.Lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("received %q, want %q", got, want)
+	}
+
+	if _, err := NewStatsD(config.StatsDConfig{Address: server.Addr(), Flavor: "graphite"}); err == nil {
+		t.Error("NewStatsD() accepted an unknown flavor")
+	}
+}
@@ -0,0 +1,68 @@
+// Package statsdtest runs an in-process StatsD stand-in on a local UDP port
+// that records the metrics sent to it.
+package statsdtest
+
+import (
+	"net"
+	"strings"
+	"sync"
+)
+
+type Server struct {
+	conn net.PacketConn
+
+	mu      sync.Mutex
+	packets []string
+}
+
+func NewServer() (*Server, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{conn: conn}
+	go s.read()
+	return s, nil
+}
+
+// Addr is the host:port to send to.
+func (s *Server) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *Server) read() {
+	buf := make([]byte, 65536)
+	for {
+		n, _, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.packets = append(s.packets, string(buf[:n]))
+		s.mu.Unlock()
+	}
+}
+
+// Packets returns the datagrams received so far.
+func (s *Server) Packets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.packets...)
+}
+
+// Lines returns every metric line received so far.
+func (s *Server) Lines() []string {
+	var lines []string
+	for _, packet := range s.Packets() {
+		for _, line := range strings.Split(packet, "\n") {
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+func (s *Server) Close() {
+	s.conn.Close()
+}
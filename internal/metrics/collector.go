@@ -0,0 +1,75 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+)
+
+// DefaultProcRoot is where the machine is read from.
+const DefaultProcRoot = "/proc"
+
+// Collector lists the containers of several runtimes, with their stats, and
+// reads the machine it runs on for the host named machineHost. Listing is
+// expensive, so exporters collect at an interval rather than on demand.
+type Collector struct {
+	runtimes    []docker.ContainerRuntime
+	machineHost string
+	procRoot    string
+
+	previous *Machine
+}
+
+// NewCollector collects from runtimes. An empty machineHost leaves the
+// machine out, for instance when every daemon is remote.
+func NewCollector(runtimes []docker.ContainerRuntime, machineHost string) *Collector {
+	return &Collector{runtimes: runtimes, machineHost: machineHost, procRoot: DefaultProcRoot}
+}
+
+// Collect takes a snapshot as of now. Runtimes are listed in parallel; the
+// errors of those that fail are returned alongside the snapshot, in which
+// they are down.
+func (c *Collector) Collect(now time.Time) (Snapshot, []error) {
+	start := time.Now()
+	hosts := make([]Host, len(c.runtimes))
+	var wg sync.WaitGroup
+	for i, runtime := range c.runtimes {
+		wg.Add(1)
+		go func(i int, runtime docker.ContainerRuntime) {
+			defer wg.Done()
+			containers, err := runtime.ListContainersWith(docker.ListOptions{SkipLogs: true})
+			hosts[i] = Host{Name: runtime.Name(), Err: err, Containers: containers}
+		}(i, runtime)
+	}
+	wg.Wait()
+
+	var errs []error
+	for i := range hosts {
+		if hosts[i].Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", hosts[i].Name, hosts[i].Err))
+			continue
+		}
+		if hosts[i].Name == c.machineHost {
+			machine, err := ReadMachine(c.procRoot)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("reading the machine: %w", err))
+				continue
+			}
+			machine.usageSince(c.previous)
+			c.previous = machine
+			hosts[i].Machine = machine
+		}
+	}
+
+	snapshot := Build(now, hosts)
+	snapshot.Families = append(snapshot.Families, Family{
+		Name:    "kern_collection_duration_seconds",
+		Help:    "Time the last collection took.",
+		Kind:    Gauge,
+		Unit:    "seconds",
+		Samples: []Sample{{Value: time.Since(start).Seconds()}},
+	})
+	return snapshot, errs
+}
@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// userHZ is the unit of the CPU times in /proc/stat.
+const userHZ = 100
+
+// cpuModes names the columns of the cpu line of /proc/stat.
+var cpuModes = []string{"user", "nice", "system", "idle", "iowait", "irq", "softirq", "steal"}
+
+// Machine holds readings of the machine itself, taken from /proc.
+type Machine struct {
+	CPUs int
+
+	// CPUSeconds is the time all CPUs spent in each mode since boot.
+	CPUSeconds map[string]float64
+
+	// CPUUsage is the busy share of the CPUs since the previous reading, 100
+	// per fully used core; zero on the first reading.
+	CPUUsage float64
+
+	Load1  float64
+	Load5  float64
+	Load15 float64
+
+	MemoryTotal     int64
+	MemoryAvailable int64
+	SwapTotal       int64
+	SwapFree        int64
+
+	UptimeSeconds float64
+}
+
+// ReadMachine reads the machine from a proc file system, usually /proc. It
+// fails where there is none, such as on macOS.
+func ReadMachine(root string) (*Machine, error) {
+	m := &Machine{CPUSeconds: make(map[string]float64)}
+	if err := m.readStat(filepath.Join(root, "stat")); err != nil {
+		return nil, err
+	}
+	if err := m.readMeminfo(filepath.Join(root, "meminfo")); err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "loadavg")); err == nil {
+		fmt.Sscanf(string(data), "%f %f %f", &m.Load1, &m.Load5, &m.Load15)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "uptime")); err == nil {
+		fmt.Sscanf(string(data), "%f", &m.UptimeSeconds)
+	}
+	return m, nil
+}
+
+func (m *Machine) readStat(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "cpu":
+			for i, mode := range cpuModes {
+				if i+1 >= len(fields) {
+					break
+				}
+				ticks, _ := strconv.ParseFloat(fields[i+1], 64)
+				m.CPUSeconds[mode] = ticks / userHZ
+			}
+		case strings.HasPrefix(fields[0], "cpu"):
+			m.CPUs++
+		}
+	}
+	return scanner.Err()
+}
+
+func (m *Machine) readMeminfo(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.ParseInt(strings.TrimSuffix(strings.TrimSpace(value), " kB"), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "MemTotal":
+			m.MemoryTotal = kb * 1024
+		case "MemAvailable":
+			m.MemoryAvailable = kb * 1024
+		case "SwapTotal":
+			m.SwapTotal = kb * 1024
+		case "SwapFree":
+			m.SwapFree = kb * 1024
+		}
+	}
+	return scanner.Err()
+}
+
+// busy is the CPU time not spent idle.
+func (m *Machine) busy() float64 {
+	busy := 0.0
+	for mode, seconds := range m.CPUSeconds {
+		if mode != "idle" && mode != "iowait" {
+			busy += seconds
+		}
+	}
+	return busy
+}
+
+func (m *Machine) total() float64 {
+	total := 0.0
+	for _, seconds := range m.CPUSeconds {
+		total += seconds
+	}
+	return total
+}
+
+// usageSince sets CPUUsage from the CPU times of a previous reading.
+func (m *Machine) usageSince(previous *Machine) {
+	if previous == nil {
+		return
+	}
+	total := m.total() - previous.total()
+	if total <= 0 {
+		return
+	}
+	m.CPUUsage = (m.busy() - previous.busy()) / total * 100 * float64(m.CPUs)
+}
+
+func addMachine(b *builder, hostLabel Label, m *Machine) {
+	b.add("kern_host_cpus", "CPUs of the machine.", Gauge, "", float64(m.CPUs), hostLabel)
+	for _, mode := range cpuModes {
+		if seconds, ok := m.CPUSeconds[mode]; ok {
+			b.add("kern_host_cpu_seconds_total", "Time all CPUs spent in each mode.", Counter, "seconds",
+				seconds, hostLabel, Label{"mode", mode})
+		}
+	}
+	b.add("kern_host_cpu_usage_percent", "CPU usage since the previous collection, 100 per fully used core.", Gauge, "percent",
+		m.CPUUsage, hostLabel)
+	b.add("kern_host_load1", "Load average over 1 minute.", Gauge, "", m.Load1, hostLabel)
+	b.add("kern_host_load5", "Load average over 5 minutes.", Gauge, "", m.Load5, hostLabel)
+	b.add("kern_host_load15", "Load average over 15 minutes.", Gauge, "", m.Load15, hostLabel)
+	b.add("kern_host_memory_total_bytes", "Memory of the machine.", Gauge, "bytes", float64(m.MemoryTotal), hostLabel)
+	b.add("kern_host_memory_available_bytes", "Memory available without swapping.", Gauge, "bytes",
+		float64(m.MemoryAvailable), hostLabel)
+	b.add("kern_host_swap_total_bytes", "Swap space.", Gauge, "bytes", float64(m.SwapTotal), hostLabel)
+	b.add("kern_host_swap_free_bytes", "Unused swap space.", Gauge, "bytes", float64(m.SwapFree), hostLabel)
+	b.add("kern_host_uptime_seconds", "Time since the machine booted.", Gauge, "seconds", m.UptimeSeconds, hostLabel)
+}
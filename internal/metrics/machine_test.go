@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeProc writes a proc file system with two CPUs to a temporary
+// directory.
+func writeProc(t *testing.T, stat string) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"stat": stat,
+		"meminfo": "MemTotal:       16384000 kB\n" +
+			"MemFree:         1024000 kB\n" +
+			"MemAvailable:    8192000 kB\n" +
+			"HugePages_Total:       0\n" +
+			"SwapTotal:       2048000 kB\n" +
+			"SwapFree:        1024000 kB\n",
+		"loadavg": "0.52 0.40 0.31 2/345 6789\n",
+		"uptime":  "12345.67 20000.00\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+const procStat = `cpu  1000 50 500 8000 200 10 20 0 0 0
+cpu0 500 25 250 4000 100 5 10 0 0 0
+cpu1 500 25 250 4000 100 5 10 0 0 0
+intr 123456 0 0
+ctxt 987654
+btime 1700000000
+`
+
+func TestReadMachine(t *testing.T) {
+	m, err := ReadMachine(writeProc(t, procStat))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.CPUs != 2 {
+		t.Errorf("CPUs = %d, want 2", m.CPUs)
+	}
+	want := map[string]float64{"user": 10, "nice": 0.5, "system": 5, "idle": 80, "iowait": 2, "irq": 0.1, "softirq": 0.2, "steal": 0}
+	for mode, seconds := range want {
+		if got := m.CPUSeconds[mode]; math.Abs(got-seconds) > 1e-9 {
+			t.Errorf("CPUSeconds[%s] = %g, want %g", mode, got, seconds)
+		}
+	}
+	if m.MemoryTotal != 16384000*1024 || m.MemoryAvailable != 8192000*1024 || m.SwapTotal != 2048000*1024 || m.SwapFree != 1024000*1024 {
+		t.Errorf("memory %d, %d available, swap %d, %d free", m.MemoryTotal, m.MemoryAvailable, m.SwapTotal, m.SwapFree)
+	}
+	if m.Load1 != 0.52 || m.Load5 != 0.40 || m.Load15 != 0.31 || m.UptimeSeconds != 12345.67 {
+		t.Errorf("load %g %g %g, uptime %g", m.Load1, m.Load5, m.Load15, m.UptimeSeconds)
+	}
+	if m.CPUUsage != 0 {
+		t.Errorf("CPUUsage = %g on the first reading, want 0", m.CPUUsage)
+	}
+
+	// Half of the CPU time since the first reading was busy.
+	later, err := ReadMachine(writeProc(t, "cpu  1100 50 600 8200 200 10 20 0\ncpu0 0\ncpu1 0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	later.usageSince(m)
+	if math.Abs(later.CPUUsage-100) > 1e-9 {
+		t.Errorf("CPUUsage = %g, want 100 of 200", later.CPUUsage)
+	}
+}
+
+func TestReadMachineFailsWithoutProc(t *testing.T) {
+	root := writeProc(t, procStat)
+	if err := os.Remove(filepath.Join(root, "meminfo")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadMachine(root); err == nil {
+		t.Error("ReadMachine() succeeded without meminfo")
+	}
+	if _, err := ReadMachine(t.TempDir()); err == nil {
+		t.Error("ReadMachine() succeeded without stat")
+	}
+
+	// Load and uptime are optional.
+	root = writeProc(t, procStat)
+	os.Remove(filepath.Join(root, "loadavg"))
+	os.Remove(filepath.Join(root, "uptime"))
+	if m, err := ReadMachine(root); err != nil || m.Load1 != 0 || m.UptimeSeconds != 0 {
+		t.Errorf("ReadMachine() without loadavg and uptime = %+v, %v", m, err)
+	}
+}
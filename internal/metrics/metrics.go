@@ -0,0 +1,220 @@
+// Package metrics turns container stats and host readings into metric
+// families, which exporters write out in their own formats.
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+type Kind string
+
+const (
+	Gauge   Kind = "gauge"
+	Counter Kind = "counter"
+)
+
+// Family is a metric and its samples, one per label set.
+type Family struct {
+	Name    string
+	Help    string
+	Kind    Kind
+	Unit    string
+	Samples []Sample
+}
+
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Snapshot holds every family of one collection.
+type Snapshot struct {
+	Time     time.Time
+	Families []Family
+
+	// Started holds when each container last started, by ID, which is
+	// when its counters began.
+	Started map[string]time.Time
+}
+
+// Family returns the family called name, or nil.
+func (s *Snapshot) Family(name string) *Family {
+	for i := range s.Families {
+		if s.Families[i].Name == name {
+			return &s.Families[i]
+		}
+	}
+	return nil
+}
+
+// Host is what one collection saw of a Docker host. Machine holds the
+// readings of the machine the collector runs on, for the host it runs on.
+type Host struct {
+	Name       string
+	Err        error
+	Containers []models.Container
+	Machine    *Machine
+}
+
+// builder collects samples into families, keeping the order in which
+// families are first declared.
+type builder struct {
+	families []*Family
+	index    map[string]*Family
+}
+
+func newBuilder() *builder {
+	return &builder{index: make(map[string]*Family)}
+}
+
+func (b *builder) add(name, help string, kind Kind, unit string, value float64, labels ...Label) {
+	family := b.index[name]
+	if family == nil {
+		family = &Family{Name: name, Help: help, Kind: kind, Unit: unit}
+		b.index[name] = family
+		b.families = append(b.families, family)
+	}
+	family.Samples = append(family.Samples, Sample{Labels: labels, Value: value})
+}
+
+func (b *builder) snapshot(now time.Time) Snapshot {
+	snapshot := Snapshot{Time: now, Families: make([]Family, 0, len(b.families))}
+	for _, family := range b.families {
+		snapshot.Families = append(snapshot.Families, *family)
+	}
+	return snapshot
+}
+
+// Build converts hosts into families. Resource metrics are only given for
+// containers with stats, which are the running ones.
+func Build(now time.Time, hosts []Host) Snapshot {
+	b := newBuilder()
+	for _, host := range hosts {
+		hostLabel := Label{"host", host.Name}
+		up := 0.0
+		if host.Err == nil {
+			up = 1
+		}
+		b.add("kern_host_up", "Whether the Docker host answered the last collection.", Gauge, "", up, hostLabel)
+		if host.Err != nil {
+			continue
+		}
+
+		states := make(map[models.ContainerStatus]int)
+		for _, c := range host.Containers {
+			states[c.Status]++
+		}
+		for _, state := range sortedStates(states) {
+			b.add("kern_host_containers", "Containers on the host by state.", Gauge, "",
+				float64(states[state]), hostLabel, Label{"state", string(state)})
+		}
+		if host.Machine != nil {
+			addMachine(b, hostLabel, host.Machine)
+		}
+	}
+
+	started := make(map[string]time.Time)
+	for _, host := range hosts {
+		for i := range host.Containers {
+			addContainer(b, host.Name, &host.Containers[i])
+			if c := &host.Containers[i]; !c.Started.IsZero() {
+				started[c.ID] = c.Started
+			}
+		}
+	}
+	snapshot := b.snapshot(now)
+	snapshot.Started = started
+	return snapshot
+}
+
+func sortedStates(states map[models.ContainerStatus]int) []models.ContainerStatus {
+	sorted := make([]models.ContainerStatus, 0, len(states))
+	for state := range states {
+		sorted = append(sorted, state)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// ContainerLabels are the labels every container sample carries.
+func ContainerLabels(host string, c *models.Container) []Label {
+	return []Label{
+		{"host", host},
+		{"id", c.ID},
+		{"name", c.ShortName()},
+		{"image", c.Image},
+		{"compose_project", c.ComposeProject()},
+	}
+}
+
+func with(labels []Label, extra ...Label) []Label {
+	return append(append(make([]Label, 0, len(labels)+len(extra)), labels...), extra...)
+}
+
+func addContainer(b *builder, host string, c *models.Container) {
+	labels := ContainerLabels(host, c)
+	b.add("kern_container_state", "Current state of the container, 1 for the state it is in.", Gauge, "",
+		1, with(labels, Label{"state", string(c.Status)})...)
+	b.add("kern_container_health", "Current health of the container, 1 for the status it has.", Gauge, "",
+		1, with(labels, Label{"health", string(c.HealthStatus())})...)
+	b.add("kern_container_restarts_total", "Times Docker restarted the container.", Counter, "",
+		float64(c.RestartCount), labels...)
+
+	s := c.Stats
+	if s == nil {
+		return
+	}
+	b.add("kern_container_cpu_usage_percent", "CPU usage, 100 per fully used core.", Gauge, "percent", s.CPU.Usage, labels...)
+	b.add("kern_container_cpu_user_percent", "CPU usage in user mode.", Gauge, "percent", s.CPU.User, labels...)
+	b.add("kern_container_cpu_system_percent", "CPU usage in kernel mode.", Gauge, "percent", s.CPU.System, labels...)
+	b.add("kern_container_cpu_limit_cores", "CPU limit in cores, 0 without a limit.", Gauge, "", s.CPU.Limit, labels...)
+	b.add("kern_container_cpu_throttled_periods_total", "CPU periods in which the container was throttled.", Counter, "",
+		float64(s.CPU.Throttling.ThrottledPeriods), labels...)
+	b.add("kern_container_cpu_throttled_seconds_total", "Time the container was throttled.", Counter, "seconds",
+		float64(s.CPU.Throttling.ThrottledTime)/1e9, labels...)
+
+	b.add("kern_container_memory_usage_bytes", "Memory usage, cache excluded like docker stats.", Gauge, "bytes",
+		float64(s.Memory.Usage), labels...)
+	b.add("kern_container_memory_limit_bytes", "Memory limit.", Gauge, "bytes", float64(s.Memory.Limit), labels...)
+	b.add("kern_container_memory_cache_bytes", "Page cache.", Gauge, "bytes", float64(s.Memory.Cache), labels...)
+	b.add("kern_container_memory_rss_bytes", "Resident set size.", Gauge, "bytes", float64(s.Memory.RSS), labels...)
+	if s.Memory.SwapReported() {
+		b.add("kern_container_memory_swap_bytes", "Swap usage, not reported on cgroup v2.", Gauge, "bytes", float64(s.Memory.Swap), labels...)
+	}
+
+	b.add("kern_container_network_receive_bytes_total", "Bytes received on every interface.", Counter, "bytes",
+		float64(s.Network.RxBytes), labels...)
+	b.add("kern_container_network_transmit_bytes_total", "Bytes sent on every interface.", Counter, "bytes",
+		float64(s.Network.TxBytes), labels...)
+	b.add("kern_container_network_receive_packets_total", "Packets received.", Counter, "",
+		float64(s.Network.RxPackets), labels...)
+	b.add("kern_container_network_transmit_packets_total", "Packets sent.", Counter, "",
+		float64(s.Network.TxPackets), labels...)
+	b.add("kern_container_network_receive_errors_total", "Receive errors.", Counter, "",
+		float64(s.Network.RxErrors), labels...)
+	b.add("kern_container_network_transmit_errors_total", "Transmit errors.", Counter, "",
+		float64(s.Network.TxErrors), labels...)
+	b.add("kern_container_network_receive_dropped_total", "Received packets dropped.", Counter, "",
+		float64(s.Network.RxDropped), labels...)
+	b.add("kern_container_network_transmit_dropped_total", "Sent packets dropped.", Counter, "",
+		float64(s.Network.TxDropped), labels...)
+
+	b.add("kern_container_blkio_read_bytes_total", "Bytes read from block devices.", Counter, "bytes",
+		float64(s.BlockIO.ReadBytes), labels...)
+	b.add("kern_container_blkio_write_bytes_total", "Bytes written to block devices.", Counter, "bytes",
+		float64(s.BlockIO.WriteBytes), labels...)
+	b.add("kern_container_blkio_read_ops_total", "Block device reads.", Counter, "",
+		float64(s.BlockIO.ReadOps), labels...)
+	b.add("kern_container_blkio_write_ops_total", "Block device writes.", Counter, "",
+		float64(s.BlockIO.WriteOps), labels...)
+
+	b.add("kern_container_pids", "Processes and threads in the container.", Gauge, "", float64(s.PIDs), labels...)
+}
@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+var collected = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+// sampleOf returns the value of the sample of family name whose labels
+// include every label given.
+func sampleOf(t *testing.T, s Snapshot, name string, labels ...Label) (float64, bool) {
+	t.Helper()
+	family := s.Family(name)
+	if family == nil {
+		return 0, false
+	}
+	for _, sample := range family.Samples {
+		matched := 0
+		for _, want := range labels {
+			for _, label := range sample.Labels {
+				if label == want {
+					matched++
+				}
+			}
+		}
+		if matched == len(labels) {
+			return sample.Value, true
+		}
+	}
+	return 0, false
+}
+
+func TestBuild(t *testing.T) {
+	containers := models.MockContainers()
+	started := time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+	containers[0].Started = started
+	containers[0].RestartCount = 3
+	containers[1].Stats.Memory.CgroupVersion = 2
+
+	snapshot := Build(collected, []Host{
+		{Name: "local", Containers: containers},
+		{Name: "remote", Err: errors.New("connection refused")},
+	})
+	if !snapshot.Time.Equal(collected) {
+		t.Errorf("Time = %s", snapshot.Time)
+	}
+
+	tests := []struct {
+		name   string
+		labels []Label
+		want   float64
+	}{
+		{"kern_host_up", []Label{{"host", "local"}}, 1},
+		{"kern_host_up", []Label{{"host", "remote"}}, 0},
+		{"kern_host_containers", []Label{{"host", "local"}, {"state", "running"}}, 3},
+		{"kern_host_containers", []Label{{"host", "local"}, {"state", "exited"}}, 1},
+		{"kern_host_containers", []Label{{"host", "local"}, {"state", "paused"}}, 1},
+		{"kern_container_restarts_total", []Label{{"name", "nginx-web"}}, 3},
+		{"kern_container_state", []Label{{"name", "redis-cache"}, {"state", "exited"}}, 1},
+		{"kern_container_health", []Label{{"name", "app-worker"}, {"health", "unhealthy"}}, 1},
+		{"kern_container_pids", []Label{{"host", "local"}, {"id", "abc123456789"}, {"name", "nginx-web"}, {"image", "nginx:latest"}, {"compose_project", ""}}, 12},
+	}
+	for _, tt := range tests {
+		got, ok := sampleOf(t, snapshot, tt.name, tt.labels...)
+		if !ok || got != tt.want {
+			t.Errorf("%s%v = %g (found %v), want %g", tt.name, tt.labels, got, ok, tt.want)
+		}
+	}
+
+	// A host that is down has only kern_host_up.
+	for _, family := range snapshot.Families {
+		for _, sample := range family.Samples {
+			if len(sample.Labels) > 0 && sample.Labels[0] == (Label{"host", "remote"}) && family.Name != "kern_host_up" {
+				t.Errorf("%s sample for the host that is down", family.Name)
+			}
+		}
+	}
+	// Resource metrics only come with stats; swap only where it is reported.
+	if _, ok := sampleOf(t, snapshot, "kern_container_cpu_usage_percent", Label{"name", "redis-cache"}); ok {
+		t.Error("CPU usage of a stopped container")
+	}
+	if _, ok := sampleOf(t, snapshot, "kern_container_memory_swap_bytes", Label{"name", "postgres-db"}); ok {
+		t.Error("swap of a container on cgroup v2")
+	}
+	if _, ok := sampleOf(t, snapshot, "kern_container_memory_swap_bytes", Label{"name", "nginx-web"}); !ok {
+		t.Error("no swap of a container on cgroup v1")
+	}
+
+	if got := snapshot.Started["abc123456789"]; !got.Equal(started) {
+		t.Errorf("Started = %s, want %s", got, started)
+	}
+	if snapshot.Family("kern_no_such_metric") != nil {
+		t.Error("Family() found a family that was not built")
+	}
+}
+
+func TestBuildKeepsFamilyOrder(t *testing.T) {
+	snapshot := Build(collected, []Host{{Name: "local", Containers: models.MockContainers()}})
+	var names []string
+	seen := make(map[string]bool)
+	for _, family := range snapshot.Families {
+		if seen[family.Name] {
+			t.Errorf("%s built twice", family.Name)
+		}
+		seen[family.Name] = true
+		names = append(names, family.Name)
+	}
+	if got := strings.Join(names[:4], " "); got != "kern_host_up kern_host_containers kern_container_state kern_container_health" {
+		t.Errorf("families start with %s, want host families first", got)
+	}
+}
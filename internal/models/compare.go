@@ -0,0 +1,117 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type FieldDiff struct {
+	Field   string
+	Left    string
+	Right   string
+	Differs bool
+}
+
+const missingValue = "<unset>"
+
+// CompareContainers lines up the configuration of two containers field by
+// field. Env vars and labels are compared per key so a single changed
+// variable stands out instead of the whole list.
+func CompareContainers(left, right *Container) []FieldDiff {
+	diffs := []FieldDiff{
+		newFieldDiff("Image", left.ImageName(), right.ImageName()),
+		newFieldDiff("Tag", left.ImageTag(), right.ImageTag()),
+		newFieldDiff("Status", string(left.Status), string(right.Status)),
+		newFieldDiff("Health", string(left.HealthStatus()), string(right.HealthStatus())),
+		newFieldDiff("Command", left.Command, right.Command),
+		newFieldDiff("Restart Policy", left.RestartPolicy.Name, right.RestartPolicy.Name),
+		newFieldDiff("Restart Count", fmt.Sprintf("%d", left.RestartCount), fmt.Sprintf("%d", right.RestartCount)),
+		newFieldDiff("Memory Limit", memoryLimitString(left), memoryLimitString(right)),
+		newFieldDiff("CPU Limit", cpuLimitString(left), cpuLimitString(right)),
+		newFieldDiff("Ports", portsString(left), portsString(right)),
+	}
+
+	diffs = append(diffs, compareMaps("Env", envMap(left.Env), envMap(right.Env))...)
+	diffs = append(diffs, compareMaps("Label", left.Labels, right.Labels)...)
+	return diffs
+}
+
+func CountDifferences(diffs []FieldDiff) int {
+	count := 0
+	for _, diff := range diffs {
+		if diff.Differs {
+			count++
+		}
+	}
+	return count
+}
+
+func newFieldDiff(field, left, right string) FieldDiff {
+	return FieldDiff{Field: field, Left: left, Right: right, Differs: left != right}
+}
+
+func compareMaps(prefix string, left, right map[string]string) []FieldDiff {
+	keys := make(map[string]bool, len(left)+len(right))
+	for key := range left {
+		keys[key] = true
+	}
+	for key := range right {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+
+	diffs := make([]FieldDiff, 0, len(sorted))
+	for _, key := range sorted {
+		leftValue, inLeft := left[key]
+		rightValue, inRight := right[key]
+		if !inLeft {
+			leftValue = missingValue
+		}
+		if !inRight {
+			rightValue = missingValue
+		}
+		diffs = append(diffs, newFieldDiff(prefix+" "+key, leftValue, rightValue))
+	}
+	return diffs
+}
+
+func envMap(env []string) map[string]string {
+	result := make(map[string]string, len(env))
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		result[key] = value
+	}
+	return result
+}
+
+func memoryLimitString(c *Container) string {
+	if c.Stats == nil || c.Stats.Memory.Limit == 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.1fMB", float64(c.Stats.Memory.Limit)/1024/1024)
+}
+
+func cpuLimitString(c *Container) string {
+	if c.Stats == nil {
+		return "unknown"
+	}
+	return c.Stats.CPU.LimitString()
+}
+
+func portsString(c *Container) string {
+	if len(c.Ports) == 0 {
+		return "None"
+	}
+	ports := make([]string, 0, len(c.Ports))
+	for _, port := range c.Ports {
+		ports = append(ports, port.String())
+	}
+	sort.Strings(ports)
+	return strings.Join(ports, ", ")
+}
@@ -20,11 +20,15 @@ type Container struct {
 	Networks      []Network         `json:"networks"`
 	Labels        map[string]string `json:"labels"`
 	Command       string            `json:"command"`
+	Env           []string          `json:"env"`
+	RestartCount  int               `json:"restart_count"`
 	Stats         *ContainerStats   `json:"stats"`
 	Health        *ContainerHealth  `json:"health"`
 	RestartPolicy RestartPolicy     `json:"restart_policy"`
 	ExitCode      int               `json:"exit_code"`
 	Logs          []string          `json:"logs"`
+	Host          string            `json:"host,omitempty"`
+	Pod           string            `json:"pod,omitempty"`
 }
 
 type ContainerStats struct {
@@ -37,9 +41,12 @@ type ContainerStats struct {
 }
 
 type ContainerCPU struct {
-	Usage      float64 `json:"usage"`
-	System     float64 `json:"system"`
-	Cores      int     `json:"cores"`
+	Usage      float64   `json:"usage"`
+	System     float64   `json:"system"`
+	User       float64   `json:"user"`
+	Cores      int       `json:"cores"`
+	Limit      float64   `json:"limit"`
+	PerCore    []float64 `json:"per_core"`
 	Throttling struct {
 		Periods          int64 `json:"periods"`
 		ThrottledPeriods int64 `json:"throttled_periods"`
@@ -48,31 +55,64 @@ type ContainerCPU struct {
 }
 
 type ContainerMemory struct {
-	Usage     int64 `json:"usage"`
-	Limit     int64 `json:"limit"`
-	Cache     int64 `json:"cache"`
-	RSS       int64 `json:"rss"`
-	Swap      int64 `json:"swap"`
-	SwapLimit int64 `json:"swap_limit"`
-	MaxUsage  int64 `json:"max_usage"`
+	Usage         int64 `json:"usage"`
+	Limit         int64 `json:"limit"`
+	Cache         int64 `json:"cache"`
+	RSS           int64 `json:"rss"`
+	Swap          int64 `json:"swap"`
+	SwapLimit     int64 `json:"swap_limit"`
+	MaxUsage      int64 `json:"max_usage"`
+	RawUsage      int64 `json:"raw_usage"`
+	InactiveFile  int64 `json:"inactive_file"`
+	CgroupVersion int   `json:"cgroup_version"`
 }
 
 type ContainerNetwork struct {
-	RxBytes   int64 `json:"rx_bytes"`
-	RxPackets int64 `json:"rx_packets"`
-	RxErrors  int64 `json:"rx_errors"`
-	RxDropped int64 `json:"rx_dropped"`
-	TxBytes   int64 `json:"tx_bytes"`
-	TxPackets int64 `json:"tx_packets"`
-	TxErrors  int64 `json:"tx_errors"`
-	TxDropped int64 `json:"tx_dropped"`
+	RxBytes    int64              `json:"rx_bytes"`
+	RxPackets  int64              `json:"rx_packets"`
+	RxErrors   int64              `json:"rx_errors"`
+	RxDropped  int64              `json:"rx_dropped"`
+	TxBytes    int64              `json:"tx_bytes"`
+	TxPackets  int64              `json:"tx_packets"`
+	TxErrors   int64              `json:"tx_errors"`
+	TxDropped  int64              `json:"tx_dropped"`
+	Rate       NetworkRate        `json:"rate"`
+	Interfaces []NetworkInterface `json:"interfaces"`
+}
+
+type NetworkInterface struct {
+	Name      string      `json:"name"`
+	RxBytes   int64       `json:"rx_bytes"`
+	RxPackets int64       `json:"rx_packets"`
+	RxErrors  int64       `json:"rx_errors"`
+	RxDropped int64       `json:"rx_dropped"`
+	TxBytes   int64       `json:"tx_bytes"`
+	TxPackets int64       `json:"tx_packets"`
+	TxErrors  int64       `json:"tx_errors"`
+	TxDropped int64       `json:"tx_dropped"`
+	Rate      NetworkRate `json:"rate"`
+}
+
+type NetworkRate struct {
+	RxBytes   float64 `json:"rx_bytes"`
+	RxPackets float64 `json:"rx_packets"`
+	TxBytes   float64 `json:"tx_bytes"`
+	TxPackets float64 `json:"tx_packets"`
 }
 
 type ContainerBlockIO struct {
-	ReadBytes  int64 `json:"read_bytes"`
-	WriteBytes int64 `json:"write_bytes"`
-	ReadOps    int64 `json:"read_ops"`
-	WriteOps   int64 `json:"write_ops"`
+	ReadBytes  int64       `json:"read_bytes"`
+	WriteBytes int64       `json:"write_bytes"`
+	ReadOps    int64       `json:"read_ops"`
+	WriteOps   int64       `json:"write_ops"`
+	Rate       BlockIORate `json:"rate"`
+}
+
+type BlockIORate struct {
+	ReadBytes  float64 `json:"read_bytes"`
+	WriteBytes float64 `json:"write_bytes"`
+	ReadOps    float64 `json:"read_ops"`
+	WriteOps   float64 `json:"write_ops"`
 }
 
 type ContainerHealth struct {
@@ -90,16 +130,12 @@ const (
 	HealthStatusNone      HealthStatus = "none"
 )
 
-func (h HealthStatus) Color() string {
+func (h HealthStatus) Role() string {
 	switch h {
-	case HealthStatusHealthy:
-		return "green"
-	case HealthStatusUnhealthy:
-		return "red"
-	case HealthStatusStarting:
-		return "yellow"
+	case HealthStatusHealthy, HealthStatusUnhealthy, HealthStatusStarting:
+		return "health." + string(h)
 	default:
-		return "gray"
+		return "health.none"
 	}
 }
 
@@ -141,22 +177,13 @@ const (
 	StatusDead       ContainerStatus = "dead"
 )
 
-func (s ContainerStatus) Color() string {
+func (s ContainerStatus) Role() string {
 	switch s {
-	case StatusRunning:
-		return "green"
-	case StatusExited, StatusStopped, StatusDead:
-		return "red"
-	case StatusPaused:
-		return "yellow"
-	case StatusCreated:
-		return "blue"
-	case StatusRestarting:
-		return "orange"
-	case StatusRemoving:
-		return "purple"
+	case StatusRunning, StatusExited, StatusStopped, StatusDead, StatusPaused,
+		StatusCreated, StatusRestarting, StatusRemoving:
+		return "status." + string(s)
 	default:
-		return "white"
+		return "status.unknown"
 	}
 }
 
@@ -254,10 +281,52 @@ func (m ContainerMemory) CacheString() string {
 		float64(m.RSS)/1024/1024)
 }
 
+// SwapReported tells whether Swap means anything: the stats API has no swap
+// usage for cgroup v2.
+func (m ContainerMemory) SwapReported() bool {
+	return m.CgroupVersion != 2
+}
+
+func (m ContainerMemory) SwapPercentage() float64 {
+	if m.SwapLimit == 0 {
+		return 0
+	}
+	return float64(m.Swap) / float64(m.SwapLimit) * 100
+}
+
+func (m ContainerMemory) SwapString() string {
+	if m.SwapLimit == 0 {
+		return fmt.Sprintf("Swap: %.1fMB", float64(m.Swap)/1024/1024)
+	}
+	return fmt.Sprintf("Swap: %.1fMB / %.1fMB",
+		float64(m.Swap)/1024/1024,
+		float64(m.SwapLimit)/1024/1024)
+}
+
 func (c ContainerCPU) String() string {
 	return fmt.Sprintf("%.2f%% (%d cores)", c.Usage, c.Cores)
 }
 
+// QuotaPercentage is the usage relative to the container's CPU limit, where
+// 100% means the quota is exhausted. Without a limit it falls back to the
+// share of all host cores.
+func (c ContainerCPU) QuotaPercentage() float64 {
+	if c.Limit > 0 {
+		return c.Usage / c.Limit
+	}
+	if c.Cores > 0 {
+		return c.Usage / float64(c.Cores)
+	}
+	return c.Usage
+}
+
+func (c ContainerCPU) LimitString() string {
+	if c.Limit <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%.2f cores", c.Limit)
+}
+
 func (c ContainerCPU) ThrottleString() string {
 	if c.Throttling.ThrottledPeriods == 0 {
 		return "No throttling"
@@ -282,6 +351,12 @@ func (n ContainerNetwork) ErrorsString() string {
 	return fmt.Sprintf("Errors: ↓ %d ↑ %d", n.RxErrors, n.TxErrors)
 }
 
+func (n ContainerNetwork) RateString() string {
+	return fmt.Sprintf("↓ %s/s ↑ %s/s",
+		FormatBytes(int64(n.Rate.RxBytes)),
+		FormatBytes(int64(n.Rate.TxBytes)))
+}
+
 func (b ContainerBlockIO) String() string {
 	return fmt.Sprintf("Read: %.1fMB | Write: %.1fMB",
 		float64(b.ReadBytes)/1024/1024,
@@ -292,6 +367,79 @@ func (b ContainerBlockIO) OpsString() string {
 	return fmt.Sprintf("Read: %d ops | Write: %d ops", b.ReadOps, b.WriteOps)
 }
 
+func (b ContainerBlockIO) RateString() string {
+	return fmt.Sprintf("Read: %s/s | Write: %s/s",
+		FormatBytes(int64(b.Rate.ReadBytes)),
+		FormatBytes(int64(b.Rate.WriteBytes)))
+}
+
+func (b ContainerBlockIO) IOPS() float64 {
+	return b.Rate.ReadOps + b.Rate.WriteOps
+}
+
+// ComputeRates derives per-second rates from the counter deltas against prev.
+func (s *ContainerStats) ComputeRates(prev *ContainerStats) {
+	if prev == nil {
+		return
+	}
+
+	elapsed := s.Timestamp.Sub(prev.Timestamp).Seconds()
+	if elapsed <= 0 {
+		s.Network.Rate = prev.Network.Rate
+		s.BlockIO.Rate = prev.BlockIO.Rate
+		for i := range s.Network.Interfaces {
+			if p := prev.Network.Interface(s.Network.Interfaces[i].Name); p != nil {
+				s.Network.Interfaces[i].Rate = p.Rate
+			}
+		}
+		return
+	}
+
+	s.Network.Rate = NetworkRate{
+		RxBytes:   counterRate(s.Network.RxBytes, prev.Network.RxBytes, elapsed),
+		RxPackets: counterRate(s.Network.RxPackets, prev.Network.RxPackets, elapsed),
+		TxBytes:   counterRate(s.Network.TxBytes, prev.Network.TxBytes, elapsed),
+		TxPackets: counterRate(s.Network.TxPackets, prev.Network.TxPackets, elapsed),
+	}
+
+	for i := range s.Network.Interfaces {
+		iface := &s.Network.Interfaces[i]
+		p := prev.Network.Interface(iface.Name)
+		if p == nil {
+			continue
+		}
+		iface.Rate = NetworkRate{
+			RxBytes:   counterRate(iface.RxBytes, p.RxBytes, elapsed),
+			RxPackets: counterRate(iface.RxPackets, p.RxPackets, elapsed),
+			TxBytes:   counterRate(iface.TxBytes, p.TxBytes, elapsed),
+			TxPackets: counterRate(iface.TxPackets, p.TxPackets, elapsed),
+		}
+	}
+
+	s.BlockIO.Rate = BlockIORate{
+		ReadBytes:  counterRate(s.BlockIO.ReadBytes, prev.BlockIO.ReadBytes, elapsed),
+		WriteBytes: counterRate(s.BlockIO.WriteBytes, prev.BlockIO.WriteBytes, elapsed),
+		ReadOps:    counterRate(s.BlockIO.ReadOps, prev.BlockIO.ReadOps, elapsed),
+		WriteOps:   counterRate(s.BlockIO.WriteOps, prev.BlockIO.WriteOps, elapsed),
+	}
+}
+
+func (n ContainerNetwork) Interface(name string) *NetworkInterface {
+	for i := range n.Interfaces {
+		if n.Interfaces[i].Name == name {
+			return &n.Interfaces[i]
+		}
+	}
+	return nil
+}
+
+func counterRate(current, previous int64, elapsed float64) float64 {
+	if current < previous {
+		return 0
+	}
+	return float64(current-previous) / elapsed
+}
+
 func (c *Container) ShortID() string {
 	if len(c.ID) > 12 {
 		return c.ID[:12]
@@ -316,7 +464,7 @@ func (c *Container) Uptime() time.Duration {
 
 func (c *Container) FormatAge() string {
 	age := c.Age()
-	return formatDuration(age)
+	return FormatDuration(age)
 }
 
 func (c *Container) FormatUptime() string {
@@ -324,7 +472,7 @@ func (c *Container) FormatUptime() string {
 	if uptime == 0 {
 		return "Not running"
 	}
-	return formatDuration(uptime)
+	return FormatDuration(uptime)
 }
 
 func (c *Container) MainPort() string {
@@ -380,6 +528,17 @@ func (c *Container) ImageName() string {
 	return parts[0]
 }
 
+func (c *Container) ComposeProject() string {
+	return c.Labels["com.docker.compose.project"]
+}
+
+func (c *Container) HealthStatus() HealthStatus {
+	if c.Health == nil {
+		return HealthStatusNone
+	}
+	return c.Health.Status
+}
+
 func (c *Container) IsHealthy() bool {
 	if c.Health == nil {
 		return true
@@ -408,7 +567,8 @@ func (c *Container) GetMemoryLimit() int64 {
 	return 0
 }
 
-func formatDuration(d time.Duration) string {
+// FormatDuration formats a duration in its largest whole unit, such as "5m".
+func FormatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%ds", int(d.Seconds()))
 	} else if d < time.Hour {
@@ -419,7 +579,8 @@ func formatDuration(d time.Duration) string {
 	return fmt.Sprintf("%dd", int(d.Hours()/24))
 }
 
-func formatBytes(bytes int64) string {
+// FormatBytes formats a byte count in binary units, such as "1.5 MB".
+func FormatBytes(bytes int64) string {
 	const unit = 1024
 	if bytes < unit {
 		return fmt.Sprintf("%d B", bytes)
@@ -451,7 +612,8 @@ func MockContainers() []Container {
 			Stats: &ContainerStats{
 				CPU: ContainerCPU{
 					Usage:  5.2,
-					System: 12.5,
+					User:   3.6,
+					System: 1.6,
 					Cores:  4,
 				},
 				Memory: ContainerMemory{
@@ -503,7 +665,8 @@ func MockContainers() []Container {
 			Stats: &ContainerStats{
 				CPU: ContainerCPU{
 					Usage:  15.7,
-					System: 8.3,
+					User:   11.0,
+					System: 4.7,
 					Cores:  2,
 				},
 				Memory: ContainerMemory{
@@ -576,7 +739,8 @@ func MockContainers() []Container {
 			Stats: &ContainerStats{
 				CPU: ContainerCPU{
 					Usage:  25.4,
-					System: 15.2,
+					User:   17.8,
+					System: 7.6,
 					Cores:  1,
 					Throttling: struct {
 						Periods          int64 `json:"periods"`
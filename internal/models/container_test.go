@@ -0,0 +1,106 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+var read = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+// statsAt is a read at offset with every counter at n, and an eth0 carrying
+// all of the traffic.
+func statsAt(offset time.Duration, n int64) *ContainerStats {
+	return &ContainerStats{
+		Timestamp: read.Add(offset),
+		Network: ContainerNetwork{
+			RxBytes: n, RxPackets: n, TxBytes: n, TxPackets: n,
+			Interfaces: []NetworkInterface{{Name: "eth0", RxBytes: n, RxPackets: n, TxBytes: n, TxPackets: n}},
+		},
+		BlockIO: ContainerBlockIO{ReadBytes: n, WriteBytes: n, ReadOps: n, WriteOps: n},
+	}
+}
+
+func TestComputeRates(t *testing.T) {
+	prev := statsAt(0, 1000)
+	s := statsAt(2*time.Second, 3000)
+	s.ComputeRates(prev)
+
+	want := NetworkRate{RxBytes: 1000, RxPackets: 1000, TxBytes: 1000, TxPackets: 1000}
+	if s.Network.Rate != want {
+		t.Errorf("network rate = %+v, want %+v", s.Network.Rate, want)
+	}
+	if got := s.Network.Interfaces[0].Rate; got != want {
+		t.Errorf("eth0 rate = %+v, want %+v", got, want)
+	}
+	if want := (BlockIORate{ReadBytes: 1000, WriteBytes: 1000, ReadOps: 1000, WriteOps: 1000}); s.BlockIO.Rate != want {
+		t.Errorf("block IO rate = %+v, want %+v", s.BlockIO.Rate, want)
+	}
+	if got := s.BlockIO.IOPS(); got != 2000 {
+		t.Errorf("IOPS() = %g, want 2000", got)
+	}
+}
+
+func TestComputeRatesAfterCounterReset(t *testing.T) {
+	// A restarted container counts from zero again; that is no traffic,
+	// not a negative rate.
+	s := statsAt(time.Second, 10)
+	s.ComputeRates(statsAt(0, 1000))
+
+	if s.Network.Rate != (NetworkRate{}) || s.Network.Interfaces[0].Rate != (NetworkRate{}) {
+		t.Errorf("network rate after reset = %+v, eth0 %+v, want zero", s.Network.Rate, s.Network.Interfaces[0].Rate)
+	}
+	if s.BlockIO.Rate != (BlockIORate{}) {
+		t.Errorf("block IO rate after reset = %+v, want zero", s.BlockIO.Rate)
+	}
+}
+
+func TestComputeRatesWithoutElapsedTime(t *testing.T) {
+	prev := statsAt(0, 1000)
+	prev.Network.Rate = NetworkRate{RxBytes: 42}
+	prev.Network.Interfaces[0].Rate = NetworkRate{RxBytes: 42}
+	prev.BlockIO.Rate = BlockIORate{ReadBytes: 7}
+
+	// The same read again, or one stamped earlier, keeps the previous
+	// rates rather than dividing by zero or going negative.
+	for _, offset := range []time.Duration{0, -time.Second} {
+		s := statsAt(offset, 5000)
+		s.ComputeRates(prev)
+		if s.Network.Rate != prev.Network.Rate || s.Network.Interfaces[0].Rate != prev.Network.Interfaces[0].Rate ||
+			s.BlockIO.Rate != prev.BlockIO.Rate {
+			t.Errorf("offset %s: rates = %+v %+v, want the previous ones", offset, s.Network.Rate, s.BlockIO.Rate)
+		}
+	}
+}
+
+func TestComputeRatesWithoutPrevious(t *testing.T) {
+	s := statsAt(time.Second, 1000)
+	s.ComputeRates(nil)
+	if s.Network.Rate != (NetworkRate{}) || s.BlockIO.Rate != (BlockIORate{}) {
+		t.Errorf("rates without a previous read = %+v %+v, want none", s.Network.Rate, s.BlockIO.Rate)
+	}
+
+	// An interface that just appeared has no rate yet.
+	prev := statsAt(0, 1000)
+	prev.Network.Interfaces = nil
+	s.ComputeRates(prev)
+	if s.Network.Interfaces[0].Rate != (NetworkRate{}) {
+		t.Errorf("new interface rate = %+v, want none", s.Network.Interfaces[0].Rate)
+	}
+}
+
+func TestCounterRate(t *testing.T) {
+	tests := []struct {
+		current, previous int64
+		elapsed           float64
+		want              float64
+	}{
+		{300, 100, 2, 100},
+		{100, 100, 1, 0},
+		{50, 100, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := counterRate(tt.current, tt.previous, tt.elapsed); got != tt.want {
+			t.Errorf("counterRate(%d, %d, %g) = %g, want %g", tt.current, tt.previous, tt.elapsed, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type Image struct {
+	ID         string            `json:"id"`
+	RepoTags   []string          `json:"repo_tags"`
+	Size       int64             `json:"size"`
+	SharedSize int64             `json:"shared_size"`
+	Created    time.Time         `json:"created"`
+	Containers []string          `json:"containers"`
+	Labels     map[string]string `json:"labels"`
+}
+
+type ImageLayer struct {
+	ID        string    `json:"id"`
+	CreatedBy string    `json:"created_by"`
+	Created   time.Time `json:"created"`
+	Size      int64     `json:"size"`
+	Comment   string    `json:"comment"`
+	Tags      []string  `json:"tags"`
+}
+
+type PruneReport struct {
+	Deleted        int    `json:"deleted"`
+	SpaceReclaimed uint64 `json:"space_reclaimed"`
+}
+
+func (i *Image) ShortID() string {
+	id := strings.TrimPrefix(i.ID, "sha256:")
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+// Dangling images have lost all their tags, usually because a newer build
+// took the tag over.
+func (i *Image) Dangling() bool {
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+func (i *Image) RepoTag() string {
+	if i.Dangling() {
+		return "<none>:<none>"
+	}
+	for _, tag := range i.RepoTags {
+		if tag != "<none>:<none>" {
+			return tag
+		}
+	}
+	return "<none>:<none>"
+}
+
+func (i *Image) InUse() bool {
+	return len(i.Containers) > 0
+}
+
+// UniqueSize is the part of the image that is not shared with other images,
+// i.e. what removing it would actually free.
+func (i *Image) UniqueSize() int64 {
+	if i.SharedSize < 0 || i.SharedSize > i.Size {
+		return i.Size
+	}
+	return i.Size - i.SharedSize
+}
+
+func (i *Image) FormatAge() string {
+	return FormatDuration(time.Since(i.Created))
+}
+
+func (l *ImageLayer) ShortID() string {
+	id := strings.TrimPrefix(l.ID, "sha256:")
+	if id == "<missing>" || id == "" {
+		return "<missing>"
+	}
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func (l *ImageLayer) Instruction() string {
+	instruction := strings.TrimSpace(l.CreatedBy)
+	instruction = strings.TrimPrefix(instruction, "/bin/sh -c #(nop) ")
+	instruction = strings.TrimPrefix(instruction, "/bin/sh -c ")
+	return strings.Join(strings.Fields(instruction), " ")
+}
@@ -27,16 +27,12 @@ const (
 	StatusError   Status = "error"
 )
 
-func (s Status) Color() string {
+func (s Status) Role() string {
 	switch s {
-	case StatusOnline:
-		return "green"
-	case StatusOffline:
-		return "red"
-	case StatusError:
-		return "red"
+	case StatusOnline, StatusOffline, StatusError:
+		return "machine." + string(s)
 	default:
-		return "white"
+		return "status.unknown"
 	}
 }
 
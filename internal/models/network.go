@@ -0,0 +1,98 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// DockerNetwork is a network as seen by the daemon, as opposed to Network
+// which is one container's attachment to it.
+type DockerNetwork struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Driver    string            `json:"driver"`
+	Scope     string            `json:"scope"`
+	Subnets   []string          `json:"subnets"`
+	Gateways  []string          `json:"gateways"`
+	Internal  bool              `json:"internal"`
+	IPv6      bool              `json:"ipv6"`
+	Created   time.Time         `json:"created"`
+	Labels    map[string]string `json:"labels"`
+	Endpoints []NetworkEndpoint `json:"endpoints"`
+}
+
+type NetworkEndpoint struct {
+	Container   string `json:"container"`
+	ContainerID string `json:"container_id"`
+	Running     bool   `json:"running"`
+	IPv4Address string `json:"ipv4_address"`
+	IPv6Address string `json:"ipv6_address"`
+	MacAddress  string `json:"mac_address"`
+}
+
+func (n *DockerNetwork) ShortID() string {
+	if len(n.ID) > 12 {
+		return n.ID[:12]
+	}
+	return n.ID
+}
+
+func (n *DockerNetwork) SubnetString() string {
+	if len(n.Subnets) == 0 {
+		return "-"
+	}
+	return strings.Join(n.Subnets, ", ")
+}
+
+func (n *DockerNetwork) GatewayString() string {
+	if len(n.Gateways) == 0 {
+		return "-"
+	}
+	return strings.Join(n.Gateways, ", ")
+}
+
+// Predefined networks are created by the daemon and cannot be removed.
+func (n *DockerNetwork) Predefined() bool {
+	switch n.Name {
+	case "bridge", "host", "none":
+		return true
+	}
+	return false
+}
+
+func (n *DockerNetwork) HasContainer(name string) bool {
+	for _, endpoint := range n.Endpoints {
+		if endpoint.Container == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e NetworkEndpoint) Address() string {
+	switch {
+	case e.IPv4Address != "" && e.IPv6Address != "":
+		return e.IPv4Address + ", " + e.IPv6Address
+	case e.IPv4Address != "":
+		return e.IPv4Address
+	case e.IPv6Address != "":
+		return e.IPv6Address
+	}
+	return "-"
+}
+
+// NetworkPeers maps every container attached to at least one of the given
+// networks to the sorted names of the networks it is attached to.
+func NetworkPeers(networks []DockerNetwork) map[string][]string {
+	peers := make(map[string][]string)
+	for _, network := range networks {
+		for _, endpoint := range network.Endpoints {
+			peers[endpoint.Container] = append(peers[endpoint.Container], network.Name)
+		}
+	}
+	for name := range peers {
+		sort.Strings(peers[name])
+	}
+	return peers
+}
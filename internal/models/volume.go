@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+type Volume struct {
+	Name       string            `json:"name"`
+	Driver     string            `json:"driver"`
+	Mountpoint string            `json:"mountpoint"`
+	Scope      string            `json:"scope"`
+	Created    time.Time         `json:"created"`
+	Labels     map[string]string `json:"labels"`
+	Size       int64             `json:"size"`
+	Users      []VolumeUser      `json:"users"`
+}
+
+type VolumeUser struct {
+	Container   string `json:"container"`
+	Running     bool   `json:"running"`
+	Destination string `json:"destination"`
+	RW          bool   `json:"rw"`
+}
+
+// Orphaned volumes are not referenced by any container, running or not.
+func (v *Volume) Orphaned() bool {
+	return len(v.Users) == 0
+}
+
+// Anonymous volumes get a 64 character hex name instead of a user supplied
+// one.
+func (v *Volume) Anonymous() bool {
+	if len(v.Name) != 64 {
+		return false
+	}
+	for _, r := range v.Name {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func (v *Volume) ShortName() string {
+	if v.Anonymous() {
+		return v.Name[:12]
+	}
+	return v.Name
+}
+
+// SizeKnown is false when the driver does not report usage, which Docker
+// signals with -1.
+func (v *Volume) SizeKnown() bool {
+	return v.Size >= 0
+}
+
+func (v *Volume) RunningUsers() int {
+	count := 0
+	for _, user := range v.Users {
+		if user.Running {
+			count++
+		}
+	}
+	return count
+}
+
+func (v *Volume) ComposeProject() string {
+	return v.Labels["com.docker.compose.project"]
+}
+
+func (v *Volume) FormatAge() string {
+	if v.Created.IsZero() {
+		return "n/a"
+	}
+	return FormatDuration(time.Since(v.Created))
+}
@@ -0,0 +1,365 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+)
+
+const (
+	GroupByRule = "rule"
+	GroupByHost = "host"
+	GroupByNone = "none"
+)
+
+const (
+	defaultGroupWait = 30 * time.Second
+	defaultAttempts  = 4
+	defaultBackoff   = time.Second
+
+	// sendTimeout bounds a single delivery attempt.
+	sendTimeout = 30 * time.Second
+)
+
+type Options struct {
+	// GroupBy is rule, host or none; alerts of one group that change within
+	// GroupWait go out as one notification.
+	GroupBy   string
+	GroupWait time.Duration
+
+	// Attempts is how many times a notification is tried in one round;
+	// retries wait Backoff, doubled after each one.
+	Attempts int
+	Backoff  time.Duration
+
+	// RateLimit caps the notifications of each sink, zero for no cap.
+	// Alerts over the cap wait for the next notification.
+	RateLimit Rate
+	Silences  []Silence
+}
+
+// Rate allows Count notifications per sink in any window of Per.
+type Rate struct {
+	Count int
+	Per   time.Duration
+}
+
+// ParseRate reads a rate such as "10/1h" or "5/m".
+func ParseRate(text string) (Rate, error) {
+	count, per, ok := strings.Cut(text, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if !ok || err != nil || n <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate_limit %q, expected a count per duration such as 10/1h", text)
+	}
+	per = strings.TrimSpace(per)
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+	duration, err := time.ParseDuration(per)
+	if err != nil || duration <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate_limit %q, expected a count per duration such as 10/1h", text)
+	}
+	return Rate{Count: n, Per: duration}, nil
+}
+
+// Delivery is the outcome of sending one notification to one sink.
+type Delivery struct {
+	Sink         string
+	Notification Notification
+	Attempts     int
+	Err          error
+}
+
+// queue holds the alerts waiting for one sink.
+type queue struct {
+	sink    Sink
+	pending map[string]alert.Alert
+
+	// notified holds the alerts whose firing was sent to the sink; only
+	// those are sent again when they resolve.
+	notified map[string]bool
+	sent     []time.Time
+}
+
+// Dispatcher queues the alerts that fire or resolve and delivers them to
+// every sink. A notification that still fails after its attempts goes back
+// in the queue for the next round, unless the sink rejected it for good or
+// the alert changed meanwhile. It is safe for concurrent use.
+type Dispatcher struct {
+	opts Options
+
+	mu     sync.Mutex
+	queues []*queue
+}
+
+func NewDispatcher(sinks []Sink, opts Options) *Dispatcher {
+	if opts.GroupBy == "" {
+		opts.GroupBy = GroupByRule
+	}
+	if opts.GroupWait <= 0 {
+		opts.GroupWait = defaultGroupWait
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = defaultAttempts
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = defaultBackoff
+	}
+
+	d := &Dispatcher{opts: opts}
+	for _, sink := range sinks {
+		d.queues = append(d.queues, &queue{
+			sink:     sink,
+			pending:  make(map[string]alert.Alert),
+			notified: make(map[string]bool),
+		})
+	}
+	return d
+}
+
+func (d *Dispatcher) Sinks() []Sink {
+	sinks := make([]Sink, 0, len(d.queues))
+	for _, q := range d.queues {
+		sinks = append(sinks, q.sink)
+	}
+	return sinks
+}
+
+// Add queues alerts that started firing or resolved, as returned by
+// alert.Engine.Evaluate, and returns those a silence muted. An alert that
+// resolves before its firing was sent is dropped altogether.
+func (d *Dispatcher) Add(now time.Time, alerts []alert.Alert) []alert.Alert {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var silenced []alert.Alert
+	for _, a := range alerts {
+		if d.silenced(a, now) {
+			silenced = append(silenced, a)
+			continue
+		}
+		key := alertKey(a)
+		for _, q := range d.queues {
+			if a.State == alert.StateResolved && !q.notified[key] {
+				delete(q.pending, key)
+				continue
+			}
+			q.pending[key] = a
+		}
+	}
+	return silenced
+}
+
+func (d *Dispatcher) silenced(a alert.Alert, now time.Time) bool {
+	for _, s := range d.opts.Silences {
+		if s.Matches(a, now) {
+			return true
+		}
+	}
+	return false
+}
+
+func alertKey(a alert.Alert) string {
+	return a.Rule + "|" + a.Host + "|" + a.ContainerID
+}
+
+// Run delivers the queued alerts every group wait until ctx is done,
+// passing every delivery to report. Each sink has a loop of its own, so one
+// that is slow or retrying holds back no other; report may be called from
+// several of them at once.
+func (d *Dispatcher) Run(ctx context.Context, report func(Delivery)) {
+	var wg sync.WaitGroup
+	for _, q := range d.queues {
+		wg.Add(1)
+		go func(q *queue) {
+			defer wg.Done()
+			d.deliver(ctx, q, report)
+		}(q)
+	}
+	wg.Wait()
+}
+
+// deliver is the loop of one sink.
+func (d *Dispatcher) deliver(ctx context.Context, q *queue, report func(Delivery)) {
+	ticker := time.NewTicker(d.opts.GroupWait)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.flushQueue(ctx, q, now, report)
+		}
+	}
+}
+
+// Flush sends the queued alerts to every sink, grouped into notifications,
+// as far as the rate limit allows, and waits for all of them, as when
+// shutting down. Sinks are sent to in parallel.
+func (d *Dispatcher) Flush(ctx context.Context, now time.Time) []Delivery {
+	var mu sync.Mutex
+	var deliveries []Delivery
+	var wg sync.WaitGroup
+	for _, q := range d.queues {
+		wg.Add(1)
+		go func(q *queue) {
+			defer wg.Done()
+			d.flushQueue(ctx, q, now, func(delivery Delivery) {
+				mu.Lock()
+				deliveries = append(deliveries, delivery)
+				mu.Unlock()
+			})
+		}(q)
+	}
+	wg.Wait()
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].Sink < deliveries[j].Sink
+	})
+	return deliveries
+}
+
+// flushQueue sends what the queue of one sink holds at now, reporting each
+// delivery as it completes.
+func (d *Dispatcher) flushQueue(ctx context.Context, q *queue, now time.Time, report func(Delivery)) {
+	d.mu.Lock()
+	notifications := d.take(q, now)
+	d.mu.Unlock()
+
+	for _, n := range notifications {
+		attempts, err := d.send(ctx, q.sink, n)
+		if err != nil {
+			d.failed(q, n, isPermanent(err))
+		}
+		report(Delivery{Sink: q.sink.Name(), Notification: n, Attempts: attempts, Err: err})
+	}
+}
+
+// take removes the alerts that can be sent now from the queue of a sink and
+// groups them. The caller holds d.mu.
+func (d *Dispatcher) take(q *queue, now time.Time) []Notification {
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	notifications := d.group(q.pending)
+	if rate := d.opts.RateLimit; rate.Count > 0 {
+		recent := q.sent[:0]
+		for _, sent := range q.sent {
+			if now.Sub(sent) < rate.Per {
+				recent = append(recent, sent)
+			}
+		}
+		q.sent = recent
+
+		allowed := rate.Count - len(q.sent)
+		if allowed <= 0 {
+			return nil
+		}
+		if len(notifications) > allowed {
+			notifications = notifications[:allowed]
+		}
+	}
+
+	for _, n := range notifications {
+		for _, a := range n.Alerts {
+			key := alertKey(a)
+			delete(q.pending, key)
+			if a.State == alert.StateFiring {
+				q.notified[key] = true
+			} else {
+				delete(q.notified, key)
+			}
+		}
+		q.sent = append(q.sent, now)
+	}
+	return notifications
+}
+
+// group splits alerts into notifications by the group-by option, firing
+// alerts first within each.
+func (d *Dispatcher) group(alerts map[string]alert.Alert) []Notification {
+	groups := make(map[string][]alert.Alert)
+	for _, a := range alerts {
+		var group string
+		switch d.opts.GroupBy {
+		case GroupByHost:
+			group = a.Host
+		case GroupByNone:
+			group = a.Rule + " on " + a.Subject()
+		default:
+			group = a.Rule
+		}
+		groups[group] = append(groups[group], a)
+	}
+
+	notifications := make([]Notification, 0, len(groups))
+	for group, alerts := range groups {
+		sort.Slice(alerts, func(i, j int) bool {
+			a, b := alerts[i], alerts[j]
+			if a.State != b.State {
+				return a.State == alert.StateFiring
+			}
+			if a.Rule != b.Rule {
+				return a.Rule < b.Rule
+			}
+			return a.Subject() < b.Subject()
+		})
+		notifications = append(notifications, Notification{Group: group, Alerts: alerts})
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].Group < notifications[j].Group
+	})
+	return notifications
+}
+
+// send tries a notification until it is delivered, fails for good or runs
+// out of attempts, and returns the attempts it took.
+func (d *Dispatcher) send(ctx context.Context, sink Sink, n Notification) (int, error) {
+	backoff := d.opts.Backoff
+	for attempt := 1; ; attempt++ {
+		sendCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := sink.Send(sendCtx, n)
+		cancel()
+		if err == nil || isPermanent(err) || attempt >= d.opts.Attempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// failed queues the alerts of a notification that could not be delivered
+// again, unless a newer state of the alert is queued already. A firing that
+// was rejected for good is dropped and no longer counts as notified, so its
+// resolving is not sent either.
+func (d *Dispatcher) failed(q *queue, n Notification, permanent bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, a := range n.Alerts {
+		key := alertKey(a)
+		if a.State == alert.StateFiring {
+			delete(q.notified, key)
+		}
+		if permanent {
+			continue
+		}
+		if _, newer := q.pending[key]; !newer {
+			q.pending[key] = a
+			if a.State == alert.StateResolved {
+				q.notified[key] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/notify/notifytest"
+)
+
+// newWebhookDispatcher dispatches to one webhook on a test server, retrying
+// quickly.
+func newWebhookDispatcher(t *testing.T, opts Options) (*Dispatcher, *notifytest.WebhookServer) {
+	t.Helper()
+	server := newWebhookServer(t)
+	sink := newSink(t, config.SinkConfig{Name: "hook", Type: SinkWebhook, URL: server.URL()})
+	if opts.Attempts == 0 {
+		opts.Attempts = 3
+	}
+	opts.Backoff = time.Millisecond
+	return NewDispatcher([]Sink{sink}, opts), server
+}
+
+func groups(deliveries []Delivery) string {
+	var names []string
+	for _, delivery := range deliveries {
+		names = append(names, delivery.Notification.Group)
+	}
+	return strings.Join(names, ", ")
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		status       int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"server error", 2, http.StatusServiceUnavailable, 3, false},
+		{"rate limited", 2, http.StatusTooManyRequests, 3, false},
+		{"client error", 1, http.StatusBadRequest, 1, true},
+		{"out of attempts", 5, http.StatusInternalServerError, 3, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, server := newWebhookDispatcher(t, Options{})
+			server.FailNext(tt.failures, tt.status)
+			d.Add(since, []alert.Alert{firingAlert("busy", "local", "nginx-web")})
+
+			deliveries := d.Flush(context.Background(), since)
+			if len(deliveries) != 1 {
+				t.Fatalf("%d deliveries, want 1", len(deliveries))
+			}
+			delivery := deliveries[0]
+			if delivery.Attempts != tt.wantAttempts || (delivery.Err != nil) != tt.wantErr {
+				t.Errorf("delivered in %d attempts with %v, want %d attempts", delivery.Attempts, delivery.Err, tt.wantAttempts)
+			}
+			wantRequests := 1
+			if tt.wantErr {
+				wantRequests = 0
+			}
+			if got := len(server.Requests()); got != wantRequests {
+				t.Errorf("server received %d requests, want %d", got, wantRequests)
+			}
+
+			// The resolving of an alert whose firing never got through is
+			// not sent either.
+			if tt.wantErr {
+				d.Add(since, []alert.Alert{resolvedAlert("busy", "local", "nginx-web")})
+				if deliveries := d.Flush(context.Background(), since); len(deliveries) != 0 {
+					t.Errorf("resolve sent after a failed firing: %+v", deliveries)
+				}
+			}
+		})
+	}
+}
+
+func TestFailedDeliveryIsQueuedAgain(t *testing.T) {
+	d, server := newWebhookDispatcher(t, Options{})
+	server.FailNext(3, http.StatusServiceUnavailable)
+	d.Add(since, []alert.Alert{firingAlert("busy", "local", "nginx-web")})
+	if deliveries := d.Flush(context.Background(), since); len(deliveries) != 1 || deliveries[0].Err == nil {
+		t.Fatalf("deliveries = %+v, want one out of attempts", deliveries)
+	}
+
+	// The next round sends the firing, then its resolving.
+	deliveries := d.Flush(context.Background(), since.Add(time.Minute))
+	if len(deliveries) != 1 || deliveries[0].Err != nil {
+		t.Fatalf("deliveries = %+v, want the firing sent again", deliveries)
+	}
+	server.FailNext(3, http.StatusServiceUnavailable)
+	d.Add(since, []alert.Alert{resolvedAlert("busy", "local", "nginx-web")})
+	d.Flush(context.Background(), since.Add(2*time.Minute))
+	deliveries = d.Flush(context.Background(), since.Add(3*time.Minute))
+	if len(deliveries) != 1 || deliveries[0].Err != nil || deliveries[0].Notification.Alerts[0].State != alert.StateResolved {
+		t.Fatalf("deliveries = %+v, want the resolving sent again", deliveries)
+	}
+	if len(server.Requests()) != 2 {
+		t.Errorf("server received %d requests, want the firing and the resolving", len(server.Requests()))
+	}
+
+	// A rejected notification is not tried again.
+	server.FailNext(1, http.StatusBadRequest)
+	d.Add(since, []alert.Alert{firingAlert("memory", "local", "nginx-web")})
+	d.Flush(context.Background(), since.Add(4*time.Minute))
+	if deliveries := d.Flush(context.Background(), since.Add(5*time.Minute)); len(deliveries) != 0 {
+		t.Errorf("deliveries = %+v after the sink rejected the notification", deliveries)
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	alerts := []alert.Alert{
+		firingAlert("busy", "local", "nginx-web"),
+		firingAlert("busy", "remote", "postgres-db"),
+		firingAlert("memory", "local", "nginx-web"),
+	}
+	tests := []struct {
+		groupBy string
+		want    string
+	}{
+		{GroupByRule, "busy, memory"},
+		{GroupByHost, "local, remote"},
+		{GroupByNone, "busy on local/nginx-web, busy on remote/postgres-db, memory on local/nginx-web"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.groupBy, func(t *testing.T) {
+			d, server := newWebhookDispatcher(t, Options{GroupBy: tt.groupBy})
+			d.Add(since, alerts)
+			deliveries := d.Flush(context.Background(), since)
+			if got := groups(deliveries); got != tt.want {
+				t.Errorf("groups = %s, want %s", got, tt.want)
+			}
+			if len(server.Requests()) != len(deliveries) {
+				t.Errorf("server received %d requests for %d notifications", len(server.Requests()), len(deliveries))
+			}
+		})
+	}
+}
+
+func TestGroupingPutsFiringFirst(t *testing.T) {
+	d, _ := newWebhookDispatcher(t, Options{})
+	d.Add(since, []alert.Alert{firingAlert("busy", "local", "app-worker")})
+	d.Flush(context.Background(), since)
+
+	d.Add(since, []alert.Alert{
+		resolvedAlert("busy", "local", "app-worker"),
+		firingAlert("busy", "local", "nginx-web"),
+	})
+	deliveries := d.Flush(context.Background(), since)
+	if len(deliveries) != 1 {
+		t.Fatalf("%d deliveries, want the two alerts in one", len(deliveries))
+	}
+	if title := deliveries[0].Notification.Title(); title != "[FIRING:1, RESOLVED:1] busy" {
+		t.Errorf("Title() = %q", title)
+	}
+	if first := deliveries[0].Notification.Alerts[0]; first.State != alert.StateFiring {
+		t.Errorf("first alert is %s, want the firing one", first.State)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	d, server := newWebhookDispatcher(t, Options{RateLimit: Rate{Count: 1, Per: time.Hour}})
+	d.Add(since, []alert.Alert{
+		firingAlert("busy", "local", "nginx-web"),
+		firingAlert("memory", "local", "nginx-web"),
+	})
+
+	if got := groups(d.Flush(context.Background(), since)); got != "busy" {
+		t.Errorf("first flush sent %q, want only busy", got)
+	}
+	if got := groups(d.Flush(context.Background(), since.Add(30*time.Minute))); got != "" {
+		t.Errorf("flush within the hour sent %q, want nothing", got)
+	}
+	if got := groups(d.Flush(context.Background(), since.Add(time.Hour))); got != "memory" {
+		t.Errorf("flush an hour later sent %q, want the held back memory", got)
+	}
+	if len(server.Requests()) != 2 {
+		t.Errorf("server received %d requests, want 2", len(server.Requests()))
+	}
+}
+
+func TestSilences(t *testing.T) {
+	d, server := newWebhookDispatcher(t, Options{Silences: []Silence{
+		{Container: "nginx-*"},
+		{Host: "remote", Until: since.Add(time.Hour)},
+	}})
+
+	muted := d.Add(since, []alert.Alert{
+		firingAlert("busy", "local", "nginx-web"),
+		firingAlert("busy", "remote", "postgres-db"),
+		firingAlert("busy", "local", "app-worker"),
+	})
+	if len(muted) != 2 {
+		t.Errorf("Add() silenced %d alerts, want 2", len(muted))
+	}
+	deliveries := d.Flush(context.Background(), since)
+	if len(deliveries) != 1 || len(deliveries[0].Notification.Alerts) != 1 ||
+		deliveries[0].Notification.Alerts[0].Container != "app-worker" {
+		t.Errorf("deliveries = %+v, want app-worker alone", deliveries)
+	}
+
+	// Once the silence of the host expires its alerts go out again.
+	later := since.Add(2 * time.Hour)
+	if muted := d.Add(later, []alert.Alert{firingAlert("memory", "remote", "postgres-db")}); len(muted) != 0 {
+		t.Errorf("Add() after the silence expired silenced %d alerts", len(muted))
+	}
+	if got := groups(d.Flush(context.Background(), later)); got != "memory" {
+		t.Errorf("flush after the silence expired sent %q", got)
+	}
+	if len(server.Requests()) != 2 {
+		t.Errorf("server received %d requests, want 2", len(server.Requests()))
+	}
+}
+
+func TestResolvedBeforeSentIsDropped(t *testing.T) {
+	d, server := newWebhookDispatcher(t, Options{})
+	d.Add(since, []alert.Alert{firingAlert("busy", "local", "nginx-web")})
+	d.Add(since, []alert.Alert{resolvedAlert("busy", "local", "nginx-web")})
+
+	if deliveries := d.Flush(context.Background(), since); len(deliveries) != 0 {
+		t.Errorf("deliveries = %+v, want nothing for an alert that came and went", deliveries)
+	}
+	if len(server.Requests()) != 0 {
+		t.Errorf("server received %d requests", len(server.Requests()))
+	}
+}
+
+// stuckSink never answers until its context is done.
+type stuckSink struct{}
+
+func (stuckSink) Name() string {
+	return "stuck"
+}
+
+func (stuckSink) Send(ctx context.Context, n Notification) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunDeliversToEachSinkOnItsOwn(t *testing.T) {
+	server := newWebhookServer(t)
+	hook := newSink(t, config.SinkConfig{Name: "hook", Type: SinkWebhook, URL: server.URL()})
+	d := NewDispatcher([]Sink{stuckSink{}, hook}, Options{GroupWait: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	delivered := make(chan Delivery, 10)
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx, func(delivery Delivery) { delivered <- delivery })
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	d.Add(time.Now(), []alert.Alert{firingAlert("busy", "local", "nginx-web")})
+	select {
+	case delivery := <-delivered:
+		if delivery.Sink != "hook" || delivery.Err != nil {
+			t.Errorf("delivery = %+v, want the webhook delivered", delivery)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook held back by a sink that does not answer")
+	}
+	if len(server.Requests()) != 1 {
+		t.Errorf("server received %d requests, want 1", len(server.Requests()))
+	}
+}
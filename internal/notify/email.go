@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Email sends notifications through an SMTP server, upgrading to TLS when
+// the server offers STARTTLS.
+type Email struct {
+	name     string
+	addr     string
+	username string
+	password string
+	from     string
+	to       []string
+}
+
+func (e *Email) Name() string {
+	return e.name
+}
+
+func (e *Email) Send(ctx context.Context, n Notification) error {
+	host, _, err := net.SplitHostPort(e.addr)
+	if err != nil {
+		return permanentError{fmt.Errorf("invalid smtp address %q, expected host:port", e.addr)}
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.username, e.password, host)); err != nil {
+			return smtpError(err)
+		}
+	}
+	if err := client.Mail(e.from); err != nil {
+		return smtpError(err)
+	}
+	for _, to := range e.to {
+		if err := client.Rcpt(to); err != nil {
+			return smtpError(err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(e.message(n, time.Now())); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(err)
+	}
+	return client.Quit()
+}
+
+func (e *Email) message(n Notification, now time.Time) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Title()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+
+	for _, a := range n.Alerts {
+		msg.WriteString(describe(a) + "\r\n")
+		fmt.Fprintf(&msg, "  Rule: %s\r\n", a.Expr)
+		if a.ContainerID != "" {
+			fmt.Fprintf(&msg, "  Container: %s\r\n", a.ContainerID)
+		}
+		msg.WriteString("\r\n")
+	}
+	return []byte(msg.String())
+}
+
+// smtpError makes 5xx replies, which the server will keep giving,
+// permanent.
+func smtpError(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return permanentError{err}
+	}
+	return err
+}
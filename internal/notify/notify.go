@@ -0,0 +1,193 @@
+// Package notify delivers alerts to webhooks, Slack or Mattermost channels
+// and email, grouped, rate limited, retried and silenced as configured.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/config"
+)
+
+const (
+	SinkWebhook    = "webhook"
+	SinkSlack      = "slack"
+	SinkMattermost = "mattermost"
+	SinkEmail      = "email"
+)
+
+// Sink delivers notifications to one target.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, n Notification) error
+}
+
+// Notification is a group of alerts that fired or resolved, sent as one
+// message.
+type Notification struct {
+	Group  string
+	Alerts []alert.Alert
+}
+
+// Firing counts the firing alerts of the notification.
+func (n Notification) Firing() int {
+	firing := 0
+	for _, a := range n.Alerts {
+		if a.State == alert.StateFiring {
+			firing++
+		}
+	}
+	return firing
+}
+
+// Status is firing while any alert of the notification fires, resolved
+// otherwise.
+func (n Notification) Status() alert.State {
+	if n.Firing() > 0 {
+		return alert.StateFiring
+	}
+	return alert.StateResolved
+}
+
+// Title reads like "[FIRING:2] busy" or "[FIRING:1, RESOLVED:1] busy".
+func (n Notification) Title() string {
+	firing := n.Firing()
+	resolved := len(n.Alerts) - firing
+	var counts []string
+	if firing > 0 {
+		counts = append(counts, fmt.Sprintf("FIRING:%d", firing))
+	}
+	if resolved > 0 {
+		counts = append(counts, fmt.Sprintf("RESOLVED:%d", resolved))
+	}
+	return fmt.Sprintf("[%s] %s", strings.Join(counts, ", "), n.Group)
+}
+
+// Text lists the alerts of the notification, one per line.
+func (n Notification) Text() string {
+	var text strings.Builder
+	for _, a := range n.Alerts {
+		text.WriteString(describe(a))
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
+func describe(a alert.Alert) string {
+	line := fmt.Sprintf("%s: %s on %s", strings.ToUpper(string(a.State)), a.Rule, a.Subject())
+	if a.Value != "" {
+		line += " (" + a.Value + ")"
+	}
+	if a.State == alert.StateResolved {
+		return line + fmt.Sprintf(", resolved at %s", a.ResolvedAt.Format(time.TimeOnly))
+	}
+	return line + fmt.Sprintf(", since %s", a.Since.Format(time.TimeOnly))
+}
+
+// permanentError is a failure that retrying cannot fix, such as a rejected
+// payload or recipient.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+func isPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// NewSink builds the sink of a config entry.
+func NewSink(cfg config.SinkConfig) (Sink, error) {
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Type
+	}
+
+	switch cfg.Type {
+	case SinkWebhook:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("sink %q: a webhook needs a url", name)
+		}
+		return &Webhook{name: name, url: cfg.URL, headers: cfg.Headers}, nil
+	case SinkSlack, SinkMattermost:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("sink %q: a %s sink needs the url of an incoming webhook", name, cfg.Type)
+		}
+		return &Slack{name: name, url: cfg.URL, channel: cfg.Channel, username: cfg.Username}, nil
+	case SinkEmail:
+		if cfg.SMTP == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, fmt.Errorf("sink %q: an email sink needs smtp, from and to", name)
+		}
+		return &Email{
+			name:     name,
+			addr:     cfg.SMTP,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+			from:     cfg.From,
+			to:       cfg.To,
+		}, nil
+	case "":
+		return nil, fmt.Errorf("sink %q has no type, expected webhook, slack, mattermost or email", name)
+	}
+	return nil, fmt.Errorf("sink %q: unknown type %q, expected webhook, slack, mattermost or email", name, cfg.Type)
+}
+
+// FromConfig builds a dispatcher for the notify section of the config.
+func FromConfig(cfg config.NotifyConfig) (*Dispatcher, error) {
+	var opts Options
+	var err error
+
+	switch cfg.GroupBy {
+	case "", GroupByRule, GroupByHost, GroupByNone:
+		opts.GroupBy = cfg.GroupBy
+	default:
+		return nil, fmt.Errorf("unknown group_by %q, expected rule, host or none", cfg.GroupBy)
+	}
+	if cfg.GroupWait != "" {
+		if opts.GroupWait, err = time.ParseDuration(cfg.GroupWait); err != nil || opts.GroupWait <= 0 {
+			return nil, fmt.Errorf("invalid group_wait %q, expected for instance 30s", cfg.GroupWait)
+		}
+	}
+	if cfg.Attempts < 0 {
+		return nil, fmt.Errorf("attempts cannot be negative")
+	}
+	opts.Attempts = cfg.Attempts
+	if cfg.RateLimit != "" {
+		if opts.RateLimit, err = ParseRate(cfg.RateLimit); err != nil {
+			return nil, err
+		}
+	}
+	for _, silence := range cfg.Silences {
+		s, err := ParseSilence(silence)
+		if err != nil {
+			return nil, err
+		}
+		opts.Silences = append(opts.Silences, s)
+	}
+
+	names := make(map[string]bool)
+	var sinks []Sink
+	for _, sinkConfig := range cfg.Sinks {
+		sink, err := NewSink(sinkConfig)
+		if err != nil {
+			return nil, err
+		}
+		if names[sink.Name()] {
+			return nil, fmt.Errorf("sink %q is defined twice, give each one a name", sink.Name())
+		}
+		names[sink.Name()] = true
+		sinks = append(sinks, sink)
+	}
+	return NewDispatcher(sinks, opts), nil
+}
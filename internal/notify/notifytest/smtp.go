@@ -0,0 +1,185 @@
+package notifytest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+)
+
+// Message is one mail received by an SMTPServer.
+type Message struct {
+	From string
+	To   []string
+	Data string
+
+	// Username is the user the client authenticated as, if any.
+	Username string
+}
+
+// SMTPServer speaks enough SMTP for net/smtp clients: EHLO, AUTH PLAIN,
+// MAIL, RCPT, DATA, RSET, NOOP and QUIT, without TLS. Recipients listed in
+// Reject are refused with a 550.
+type SMTPServer struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	messages []Message
+	reject   map[string]bool
+}
+
+func NewSMTPServer() (*SMTPServer, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &SMTPServer{listener: listener, conns: make(map[net.Conn]bool), reject: make(map[string]bool)}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Addr is the host:port the server listens on.
+func (s *SMTPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *SMTPServer) Reject(recipient string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject[recipient] = true
+}
+
+// Messages returns the mail received so far.
+func (s *SMTPServer) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close stops the server and drops the connections still open.
+func (s *SMTPServer) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *SMTPServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.session(textproto.NewConn(conn))
+			conn.Close()
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+func (s *SMTPServer) session(conn *textproto.Conn) {
+	reply := func(code int, text string) bool {
+		return conn.PrintfLine("%d %s", code, text) == nil
+	}
+	if !reply(220, "kernus test SMTP ready") {
+		return
+	}
+
+	var msg Message
+	var username string
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			conn.PrintfLine("250-localhost")
+			conn.PrintfLine("250-8BITMIME")
+			reply(250, "AUTH PLAIN")
+		case "HELO":
+			reply(250, "localhost")
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			if !strings.EqualFold(mechanism, "PLAIN") {
+				reply(504, "unrecognized authentication type")
+				continue
+			}
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			parts := strings.Split(string(decoded), "\x00")
+			if err != nil || len(parts) != 3 {
+				reply(501, "malformed credentials")
+				continue
+			}
+			username = parts[1]
+			reply(235, "authenticated")
+		case "MAIL":
+			msg = Message{From: address(arg), Username: username}
+			reply(250, "OK")
+		case "RCPT":
+			to := address(arg)
+			s.mu.Lock()
+			rejected := s.reject[to]
+			s.mu.Unlock()
+			if rejected {
+				reply(550, fmt.Sprintf("no such user %s", to))
+				continue
+			}
+			msg.To = append(msg.To, to)
+			reply(250, "OK")
+		case "DATA":
+			if len(msg.To) == 0 {
+				reply(503, "no recipients")
+				continue
+			}
+			reply(354, "end data with <CR><LF>.<CR><LF>")
+			data, err := conn.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			msg = Message{}
+			reply(250, "queued")
+		case "RSET":
+			msg = Message{}
+			reply(250, "OK")
+		case "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(502, "command not implemented")
+		}
+	}
+}
+
+// address takes the address out of "FROM:<a@b>" or "TO:<a@b>".
+func address(arg string) string {
+	_, value, _ := strings.Cut(arg, ":")
+	value, _, _ = strings.Cut(strings.TrimSpace(value), " ")
+	return strings.Trim(value, "<>")
+}
@@ -0,0 +1,71 @@
+// Package notifytest provides stand-ins for the targets of notification
+// sinks: an HTTP server recording webhook posts and an SMTP server
+// recording mail, both on local ports.
+package notifytest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// Request is one post received by a WebhookServer.
+type Request struct {
+	Header http.Header
+	Body   []byte
+}
+
+// WebhookServer records the requests posted to it and answers 200, or the
+// status set with FailNext.
+type WebhookServer struct {
+	httpServer *httptest.Server
+
+	mu       sync.Mutex
+	requests []Request
+	failures int
+	status   int
+}
+
+func NewWebhookServer() *WebhookServer {
+	s := &WebhookServer{}
+	s.httpServer = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *WebhookServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		http.Error(w, http.StatusText(s.status), s.status)
+		return
+	}
+	s.requests = append(s.requests, Request{Header: r.Header.Clone(), Body: body})
+	w.WriteHeader(http.StatusOK)
+}
+
+// FailNext answers the next n requests with status without recording them.
+func (s *WebhookServer) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.status = status
+}
+
+func (s *WebhookServer) URL() string {
+	return s.httpServer.URL
+}
+
+// Requests returns the requests recorded so far.
+func (s *WebhookServer) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *WebhookServer) Close() {
+	s.httpServer.Close()
+}
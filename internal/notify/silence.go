@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/config"
+)
+
+// Silence mutes the alerts it matches. Empty patterns match anything; a
+// zero Until never expires.
+type Silence struct {
+	Rule      string
+	Host      string
+	Container string
+	Until     time.Time
+	Comment   string
+}
+
+// ParseSilence checks the patterns of a configured silence and reads its
+// expiry, an RFC 3339 time such as 2025-06-01T18:00:00Z.
+func ParseSilence(cfg config.SilenceConfig) (Silence, error) {
+	s := Silence{Rule: cfg.Rule, Host: cfg.Host, Container: cfg.Container, Comment: cfg.Comment}
+	for _, pattern := range []string{s.Rule, s.Host, s.Container} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return Silence{}, fmt.Errorf("silence: invalid pattern %q", pattern)
+		}
+	}
+	if cfg.Until != "" {
+		until, err := time.Parse(time.RFC3339, cfg.Until)
+		if err != nil {
+			return Silence{}, fmt.Errorf("silence: invalid until %q, expected a time such as 2025-06-01T18:00:00Z", cfg.Until)
+		}
+		s.Until = until
+	}
+	return s, nil
+}
+
+// Matches reports whether the silence mutes a at now. The container pattern
+// matches the container name or a prefix of its ID.
+func (s Silence) Matches(a alert.Alert, now time.Time) bool {
+	if !s.Until.IsZero() && now.After(s.Until) {
+		return false
+	}
+	if !glob(s.Rule, a.Rule) || !glob(s.Host, a.Host) {
+		return false
+	}
+	if s.Container == "" {
+		return true
+	}
+	if a.ContainerID == "" {
+		return false
+	}
+	return glob(s.Container, a.Container) || strings.HasPrefix(a.ContainerID, s.Container)
+}
+
+func glob(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, value)
+	return ok
+}
@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/notify/notifytest"
+)
+
+var since = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+func firingAlert(rule, host, container string) alert.Alert {
+	a := alert.Alert{
+		Rule:    rule,
+		Expr:    "cpu > 90% for 2m",
+		Host:    host,
+		State:   alert.StateFiring,
+		Value:   "cpu 93.0%",
+		Since:   since,
+		FiredAt: since.Add(2 * time.Minute),
+	}
+	if container != "" {
+		a.ContainerID = container + "-0123456789"
+		a.Container = container
+	}
+	return a
+}
+
+func resolvedAlert(rule, host, container string) alert.Alert {
+	a := firingAlert(rule, host, container)
+	a.State = alert.StateResolved
+	a.ResolvedAt = since.Add(5 * time.Minute)
+	return a
+}
+
+func newSink(t *testing.T, cfg config.SinkConfig) Sink {
+	t.Helper()
+	sink, err := NewSink(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sink
+}
+
+func newWebhookServer(t *testing.T) *notifytest.WebhookServer {
+	server := notifytest.NewWebhookServer()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestWebhookPayload(t *testing.T) {
+	server := newWebhookServer(t)
+	sink := newSink(t, config.SinkConfig{
+		Type:    SinkWebhook,
+		URL:     server.URL(),
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+	n := Notification{Group: "busy", Alerts: []alert.Alert{
+		firingAlert("busy", "local", "nginx-web"),
+		resolvedAlert("busy", "remote", "postgres-db"),
+	}}
+	if err := sink.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("received %d requests, want 1", len(requests))
+	}
+	header := requests[0].Header
+	if header.Get("Authorization") != "Bearer secret" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want the configured authorization and JSON", header)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(requests[0].Body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Version != "1" || payload.Group != "busy" || payload.Status != "firing" ||
+		payload.Title != "[FIRING:1, RESOLVED:1] busy" || payload.Firing != 1 || payload.Resolved != 1 {
+		t.Errorf("payload = %+v", payload)
+	}
+	if len(payload.Alerts) != 2 {
+		t.Fatalf("payload has %d alerts, want 2", len(payload.Alerts))
+	}
+	firing, resolved := payload.Alerts[0], payload.Alerts[1]
+	if firing.Container != "nginx-web" || firing.ContainerID != "nginx-web-0123456789" ||
+		firing.Value != "cpu 93.0%" || firing.FiredAt == nil || firing.ResolvedAt != nil {
+		t.Errorf("firing alert = %+v", firing)
+	}
+	if resolved.State != "resolved" || resolved.Host != "remote" || resolved.ResolvedAt == nil {
+		t.Errorf("resolved alert = %+v", resolved)
+	}
+}
+
+func TestSlackPayload(t *testing.T) {
+	server := newWebhookServer(t)
+	sink := newSink(t, config.SinkConfig{
+		Name:     "ops",
+		Type:     SinkSlack,
+		URL:      server.URL(),
+		Channel:  "#ops",
+		Username: "kernus",
+	})
+	if sink.Name() != "ops" {
+		t.Errorf("Name() = %q, want the configured name", sink.Name())
+	}
+	n := Notification{Group: "busy", Alerts: []alert.Alert{
+		firingAlert("busy", "local", "nginx-web"),
+		resolvedAlert("busy", "local", "app-worker"),
+	}}
+	if err := sink.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("received %d requests, want 1", len(requests))
+	}
+	var payload slackPayload
+	if err := json.Unmarshal(requests[0].Body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Text != "[FIRING:1, RESOLVED:1] busy" || payload.Channel != "#ops" || payload.Username != "kernus" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if len(payload.Attachments) != 2 {
+		t.Fatalf("payload has %d attachments, want 2", len(payload.Attachments))
+	}
+	firing, resolved := payload.Attachments[0], payload.Attachments[1]
+	if firing.Color != "danger" || firing.Title != "busy on local/nginx-web" ||
+		!strings.HasPrefix(firing.Text, "FIRING: busy on local/nginx-web (cpu 93.0%)") {
+		t.Errorf("firing attachment = %+v", firing)
+	}
+	if resolved.Color != "good" || !strings.HasSuffix(resolved.Text, "resolved at 14:05:00") {
+		t.Errorf("resolved attachment = %+v", resolved)
+	}
+}
+
+func newSMTPServer(t *testing.T) *notifytest.SMTPServer {
+	server, err := notifytest.NewSMTPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func TestEmail(t *testing.T) {
+	server := newSMTPServer(t)
+	sink := newSink(t, config.SinkConfig{
+		Type:         SinkEmail,
+		SMTP:         server.Addr(),
+		SMTPUsername: "alerts",
+		SMTPPassword: "secret",
+		From:         "kernus@example.com",
+		To:           []string{"ops@example.com", "oncall@example.com"},
+	})
+	n := Notification{Group: "busy", Alerts: []alert.Alert{firingAlert("busy", "local", "nginx-web")}}
+	if err := sink.Send(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("received %d messages, want 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.From != "kernus@example.com" || strings.Join(msg.To, " ") != "ops@example.com oncall@example.com" {
+		t.Errorf("sent from %s to %v", msg.From, msg.To)
+	}
+	if msg.Username != "alerts" {
+		t.Errorf("authenticated as %q, want alerts", msg.Username)
+	}
+	// The server reads the data as text, with plain line ends.
+	for _, want := range []string{
+		"Subject: [FIRING:1] busy\n",
+		"FIRING: busy on local/nginx-web (cpu 93.0%), since 14:00:00\n",
+		"  Rule: cpu > 90% for 2m\n",
+		"  Container: nginx-web-0123456789\n",
+	} {
+		if !strings.Contains(msg.Data, want) {
+			t.Errorf("message lacks %q:\n%s", want, msg.Data)
+		}
+	}
+}
+
+func TestEmailRejectedRecipientIsPermanent(t *testing.T) {
+	server := newSMTPServer(t)
+	server.Reject("gone@example.com")
+	sink := newSink(t, config.SinkConfig{
+		Type: SinkEmail,
+		SMTP: server.Addr(),
+		From: "kernus@example.com",
+		To:   []string{"gone@example.com"},
+	})
+	d := NewDispatcher([]Sink{sink}, Options{Attempts: 3, Backoff: time.Millisecond})
+	d.Add(since, []alert.Alert{firingAlert("busy", "local", "nginx-web")})
+
+	deliveries := d.Flush(context.Background(), since)
+	if len(deliveries) != 1 || deliveries[0].Err == nil || deliveries[0].Attempts != 1 {
+		t.Fatalf("deliveries = %+v, want one failure without retries", deliveries)
+	}
+	if len(server.Messages()) != 0 {
+		t.Error("mail delivered to a rejected recipient")
+	}
+}
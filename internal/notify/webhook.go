@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+)
+
+// Webhook posts notifications as JSON to a URL.
+type Webhook struct {
+	name    string
+	url     string
+	headers map[string]string
+}
+
+// WebhookPayload is the JSON body of a webhook notification.
+type WebhookPayload struct {
+	Version  string         `json:"version"`
+	Group    string         `json:"group"`
+	Status   string         `json:"status"`
+	Title    string         `json:"title"`
+	Firing   int            `json:"firing"`
+	Resolved int            `json:"resolved"`
+	Alerts   []AlertPayload `json:"alerts"`
+}
+
+type AlertPayload struct {
+	Rule        string     `json:"rule"`
+	Expr        string     `json:"expr"`
+	State       string     `json:"state"`
+	Host        string     `json:"host"`
+	ContainerID string     `json:"container_id,omitempty"`
+	Container   string     `json:"container,omitempty"`
+	Value       string     `json:"value,omitempty"`
+	Since       time.Time  `json:"since"`
+	FiredAt     *time.Time `json:"fired_at,omitempty"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+func (w *Webhook) Name() string {
+	return w.name
+}
+
+func (w *Webhook) Send(ctx context.Context, n Notification) error {
+	firing := n.Firing()
+	payload := WebhookPayload{
+		Version:  "1",
+		Group:    n.Group,
+		Status:   string(n.Status()),
+		Title:    n.Title(),
+		Firing:   firing,
+		Resolved: len(n.Alerts) - firing,
+	}
+	for _, a := range n.Alerts {
+		payload.Alerts = append(payload.Alerts, alertPayload(a))
+	}
+	return postJSON(ctx, w.url, w.headers, payload)
+}
+
+func alertPayload(a alert.Alert) AlertPayload {
+	payload := AlertPayload{
+		Rule:        a.Rule,
+		Expr:        a.Expr,
+		State:       string(a.State),
+		Host:        a.Host,
+		ContainerID: a.ContainerID,
+		Container:   a.Container,
+		Value:       a.Value,
+		Since:       a.Since,
+	}
+	if !a.FiredAt.IsZero() {
+		payload.FiredAt = &a.FiredAt
+	}
+	if !a.ResolvedAt.IsZero() {
+		payload.ResolvedAt = &a.ResolvedAt
+	}
+	return payload
+}
+
+// Slack posts notifications to a Slack or Mattermost incoming webhook,
+// which take the same payload.
+type Slack struct {
+	name     string
+	url      string
+	channel  string
+	username string
+}
+
+type slackPayload struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Username    string            `json:"username,omitempty"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color    string `json:"color"`
+	Title    string `json:"title"`
+	Text     string `json:"text"`
+	Fallback string `json:"fallback"`
+}
+
+func (s *Slack) Name() string {
+	return s.name
+}
+
+func (s *Slack) Send(ctx context.Context, n Notification) error {
+	payload := slackPayload{
+		Text:     n.Title(),
+		Channel:  s.channel,
+		Username: s.username,
+	}
+	for _, a := range n.Alerts {
+		color := "danger"
+		if a.State == alert.StateResolved {
+			color = "good"
+		}
+		line := describe(a)
+		payload.Attachments = append(payload.Attachments, slackAttachment{
+			Color:    color,
+			Title:    fmt.Sprintf("%s on %s", a.Rule, a.Subject()),
+			Text:     line,
+			Fallback: line,
+		})
+	}
+	return postJSON(ctx, s.url, nil, payload)
+}
+
+// postJSON posts body to url. Client errors other than timeouts and rate
+// limits are permanent.
+func postJSON(ctx context.Context, url string, headers map[string]string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return permanentError{err}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return permanentError{err}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "kernus")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 300 {
+		io.Copy(io.Discard, response.Body)
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+	err = fmt.Errorf("%s answered %s", url, response.Status)
+	if text := strings.TrimSpace(string(message)); text != "" {
+		err = fmt.Errorf("%w: %s", err, text)
+	}
+	switch {
+	case response.StatusCode == http.StatusRequestTimeout, response.StatusCode == http.StatusTooManyRequests:
+		return err
+	case response.StatusCode >= 400 && response.StatusCode < 500:
+		return permanentError{err}
+	}
+	return err
+}
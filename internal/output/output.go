@@ -0,0 +1,111 @@
+// Package output writes command results for scripts: as JSON, as YAML or
+// through a Go template.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/kqnd/kernus/internal/models"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats every command accepts. Anything containing "{{" is a template.
+const (
+	FormatTable = "table"
+	FormatWide  = "wide"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// IsTemplate reports whether format is a Go template rather than a named
+// format.
+func IsTemplate(format string) bool {
+	return strings.Contains(format, "{{")
+}
+
+// JSON writes v as indented JSON.
+func JSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// YAML writes v as YAML using its JSON field names, so both formats read
+// the same. Fields keep their declaration order.
+func YAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// JSON is valid YAML; decoding it into a node keeps the key order.
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow style and quoting the JSON came with; strings
+// that need quotes are still quoted.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// Template parses a Go template with a few helpers:
+//
+//	json    the value as JSON
+//	join    joins a list of strings
+//	upper   upper-cases a string
+//	lower   lower-cases a string
+//	bytes   a byte count in binary units, such as "1.5 MB"
+//
+// A trailing newline is added when executed through Execute.
+func Template(text string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json": func(v any) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"bytes": func(n int64) string {
+			return models.FormatBytes(n)
+		},
+	}
+	tmpl, err := template.New("format").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Execute runs tmpl once per item, one line each. Nothing is written when an
+// item fails, so a broken template does not leave half a line behind.
+func Execute[T any](w io.Writer, tmpl *template.Template, items []T) error {
+	var out bytes.Buffer
+	for _, item := range items {
+		if err := tmpl.Execute(&out, item); err != nil {
+			return err
+		}
+		out.WriteByte('\n')
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,153 @@
+package sample
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Recorder writes samples as they are collected. Each Write is flushed, so
+// a recording cut short still holds every sample up to that point.
+type Recorder interface {
+	Write(samples []Sample) error
+	Close() error
+}
+
+// FormatFor picks the recording format from a file extension: .csv for CSV,
+// .ndjson, .jsonl or .json for newline-delimited JSON.
+func FormatFor(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".ndjson", ".jsonl", ".json":
+		return FormatNDJSON, nil
+	}
+	return "", fmt.Errorf("cannot tell the format of %s, use a .csv or .ndjson file or set it explicitly", path)
+}
+
+// Create starts a recording at path, replacing any file there. An empty
+// format is taken from the extension.
+func Create(path, format string) (Recorder, error) {
+	if format == "" {
+		var err error
+		if format, err = FormatFor(path); err != nil {
+			return nil, err
+		}
+	}
+	if format != FormatCSV && format != FormatNDJSON {
+		return nil, fmt.Errorf("unknown recording format %q, expected csv or ndjson", format)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if format == FormatCSV {
+		return NewCSVRecorder(file), nil
+	}
+	return NewNDJSONRecorder(file), nil
+}
+
+// CSVHeader names the columns of a CSV recording. Sizes are in bytes, rates
+// per second and CPU in percent of one core.
+var CSVHeader = []string{
+	"time", "host", "id", "name", "image",
+	"cpu_percent", "cpu_cores",
+	"memory_usage", "memory_limit", "memory_percent",
+	"net_rx_bytes", "net_tx_bytes", "net_rx_rate", "net_tx_rate",
+	"block_read_bytes", "block_write_bytes", "block_read_rate", "block_write_rate",
+	"pids",
+}
+
+type csvRecorder struct {
+	closer io.Closer
+	writer *csv.Writer
+	header bool
+}
+
+func NewCSVRecorder(w io.WriteCloser) Recorder {
+	return &csvRecorder{closer: w, writer: csv.NewWriter(w)}
+}
+
+func (r *csvRecorder) Write(samples []Sample) error {
+	if !r.header {
+		if err := r.writer.Write(CSVHeader); err != nil {
+			return err
+		}
+		r.header = true
+	}
+
+	for _, s := range samples {
+		stats := s.Stats
+		record := []string{
+			s.Time.UTC().Format(time.RFC3339Nano), s.Host, s.ID, s.Name, s.Image,
+			formatFloat(stats.CPU.Usage), strconv.Itoa(stats.CPU.Cores),
+			strconv.FormatInt(stats.Memory.Usage, 10), strconv.FormatInt(stats.Memory.Limit, 10),
+			formatFloat(stats.Memory.Percentage()),
+			strconv.FormatInt(stats.Network.RxBytes, 10), strconv.FormatInt(stats.Network.TxBytes, 10),
+			formatFloat(stats.Network.Rate.RxBytes), formatFloat(stats.Network.Rate.TxBytes),
+			strconv.FormatInt(stats.BlockIO.ReadBytes, 10), strconv.FormatInt(stats.BlockIO.WriteBytes, 10),
+			formatFloat(stats.BlockIO.Rate.ReadBytes), formatFloat(stats.BlockIO.Rate.WriteBytes),
+			strconv.Itoa(stats.PIDs),
+		}
+		if err := r.writer.Write(record); err != nil {
+			return err
+		}
+	}
+	r.writer.Flush()
+	return r.writer.Error()
+}
+
+func (r *csvRecorder) Close() error {
+	r.writer.Flush()
+	if err := r.writer.Error(); err != nil {
+		r.closer.Close()
+		return err
+	}
+	return r.closer.Close()
+}
+
+type ndjsonRecorder struct {
+	closer io.Closer
+	buffer *bufio.Writer
+}
+
+// NewNDJSONRecorder writes one JSON object per sample and line, using the
+// JSON fields of models.ContainerStats.
+func NewNDJSONRecorder(w io.WriteCloser) Recorder {
+	return &ndjsonRecorder{closer: w, buffer: bufio.NewWriter(w)}
+}
+
+func (r *ndjsonRecorder) Write(samples []Sample) error {
+	encoder := json.NewEncoder(r.buffer)
+	for _, s := range samples {
+		if err := encoder.Encode(s); err != nil {
+			return err
+		}
+	}
+	return r.buffer.Flush()
+}
+
+func (r *ndjsonRecorder) Close() error {
+	if err := r.buffer.Flush(); err != nil {
+		r.closer.Close()
+		return err
+	}
+	return r.closer.Close()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
@@ -0,0 +1,150 @@
+package sample
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+type nopCloser struct{ *bytes.Buffer }
+
+func (nopCloser) Close() error { return nil }
+
+func testSample(name string, pids int) Sample {
+	return Sample{
+		Time:  time.Date(2026, 3, 4, 5, 6, 7, 500_000_000, time.FixedZone("CET", 3600)),
+		Host:  "build",
+		ID:    "abc123456789",
+		Name:  name,
+		Image: "nginx:1.25",
+		Stats: models.ContainerStats{
+			CPU:     models.ContainerCPU{Usage: 12.346, Cores: 4},
+			Memory:  models.ContainerMemory{Usage: 256, Limit: 1024},
+			Network: models.ContainerNetwork{RxBytes: 10, TxBytes: 20, Rate: models.NetworkRate{RxBytes: 1.5, TxBytes: 2}},
+			BlockIO: models.ContainerBlockIO{ReadBytes: 30, WriteBytes: 40, Rate: models.BlockIORate{ReadBytes: 3, WriteBytes: 4.126}},
+			PIDs:    pids,
+		},
+	}
+}
+
+func TestCSVRecorder(t *testing.T) {
+	var out bytes.Buffer
+	recorder := NewCSVRecorder(nopCloser{&out})
+	if err := recorder.Write([]Sample{testSample("web", 3)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Write(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Write([]Sample{testSample(`web, "canary"`, 4)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), `"web, ""canary"""`) {
+		t.Errorf("name not quoted:\n%s", out.String())
+	}
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("%d records, want the header once and two samples", len(records))
+	}
+	if got := strings.Join(records[0], ","); got != strings.Join(CSVHeader, ",") {
+		t.Errorf("header = %s", got)
+	}
+
+	want := []string{
+		"2026-03-04T04:06:07.5Z", "build", "abc123456789", "web", "nginx:1.25",
+		"12.35", "4",
+		"256", "1024", "25.00",
+		"10", "20", "1.50", "2.00",
+		"30", "40", "3.00", "4.13",
+		"3",
+	}
+	if got := strings.Join(records[1], ","); got != strings.Join(want, ",") {
+		t.Errorf("record =\n%s\nwant\n%s", got, strings.Join(want, ","))
+	}
+	if name := records[2][3]; name != `web, "canary"` {
+		t.Errorf("name = %s after reading it back", name)
+	}
+}
+
+func TestNDJSONRecorder(t *testing.T) {
+	var out bytes.Buffer
+	recorder := NewNDJSONRecorder(nopCloser{&out})
+	samples := []Sample{testSample("web", 3), testSample("db\n1", 4)}
+	if err := recorder.Write(samples); err != nil {
+		t.Fatal(err)
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(samples) {
+		t.Fatalf("%d lines for %d samples:\n%s", len(lines), len(samples), out.String())
+	}
+	for i, line := range lines {
+		var got Sample
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d: %v", i+1, err)
+		}
+		if got.Name != samples[i].Name || got.Stats.PIDs != samples[i].Stats.PIDs || !got.Time.Equal(samples[i].Time) {
+			t.Errorf("line %d = %+v, want %+v", i+1, got, samples[i])
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		file    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{file: "run.CSV", want: "time,host,"},
+		{file: "run.jsonl", want: `{"time":`},
+		{file: "run.log", format: FormatNDJSON, want: `{"time":`},
+		{file: "run.csv", format: "xml", wantErr: `unknown recording format "xml"`},
+		{file: "run.log", wantErr: "cannot tell the format of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file+" "+tt.format, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			recorder, err := Create(path, tt.format)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Create() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := recorder.Write([]Sample{testSample("web", 1)}); err != nil {
+				t.Fatal(err)
+			}
+			// Each Write reaches the file before Close.
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			recorder.Close()
+			if !strings.HasPrefix(string(data), tt.want) {
+				t.Errorf("%s starts with %.20q, want %q", tt.file, data, tt.want)
+			}
+		})
+	}
+}
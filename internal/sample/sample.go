@@ -0,0 +1,161 @@
+// Package sample collects container stats at an interval and records them
+// for later analysis.
+package sample
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// relistEvery is how many collections pass before the containers are listed
+// again, to pick up containers that started since. Listing is much more
+// expensive than reading stats.
+const relistEvery = 10
+
+// Sample is one stats reading of a container.
+type Sample struct {
+	Time  time.Time             `json:"time"`
+	Host  string                `json:"host,omitempty"`
+	ID    string                `json:"id"`
+	Name  string                `json:"name"`
+	Image string                `json:"image"`
+	Stats models.ContainerStats `json:"stats"`
+}
+
+type target struct {
+	runtime   docker.ContainerRuntime
+	container models.Container
+}
+
+// Collector reads the stats of the running containers of several runtimes,
+// or of the containers matching the given names or ID prefixes.
+type Collector struct {
+	runtimes []docker.ContainerRuntime
+	match    []string
+
+	targets     []target
+	collections int
+}
+
+func NewCollector(runtimes []docker.ContainerRuntime, match []string) *Collector {
+	return &Collector{runtimes: runtimes, match: match}
+}
+
+// Collect takes one sample of every target container as of now. Errors of
+// single runtimes or containers are returned alongside the samples of the
+// others; containers that stopped are dropped until they run again.
+func (c *Collector) Collect(now time.Time) ([]Sample, []error) {
+	var errs []error
+	if c.collections%relistEvery == 0 || len(c.targets) == 0 {
+		errs = c.list()
+	} else {
+		errs = c.refresh()
+	}
+	c.collections++
+
+	samples := make([]Sample, 0, len(c.targets))
+	for _, t := range c.targets {
+		if t.container.Stats == nil {
+			continue
+		}
+		samples = append(samples, Sample{
+			Time:  now,
+			Host:  t.container.Host,
+			ID:    t.container.ID,
+			Name:  t.container.ShortName(),
+			Image: t.container.Image,
+			Stats: *t.container.Stats,
+		})
+	}
+	return samples, errs
+}
+
+// Unmatched returns the names given to the collector that matched no
+// container at the last listing.
+func (c *Collector) Unmatched() []string {
+	var unmatched []string
+	for _, name := range c.match {
+		found := false
+		for _, t := range c.targets {
+			if matches(&t.container, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unmatched = append(unmatched, name)
+		}
+	}
+	return unmatched
+}
+
+// list finds the target containers; listing already reads their stats.
+func (c *Collector) list() []error {
+	var errs []error
+	var targets []target
+	for _, runtime := range c.runtimes {
+		containers, err := runtime.ListContainersWith(docker.ListOptions{OnlyRunning: true, SkipLogs: true})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", runtime.Name(), err))
+			continue
+		}
+		for _, container := range containers {
+			if c.wanted(&container) {
+				targets = append(targets, target{runtime: runtime, container: container})
+			}
+		}
+	}
+	c.targets = targets
+	return errs
+}
+
+// refresh reads the stats of the known targets in parallel.
+func (c *Collector) refresh() []error {
+	var mu sync.Mutex
+	var errs []error
+	var wg sync.WaitGroup
+	for i := range c.targets {
+		wg.Add(1)
+		go func(t *target) {
+			defer wg.Done()
+			stats, err := t.runtime.GetContainerStats(t.container.ID)
+			if err != nil {
+				t.container.Stats = nil
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", t.container.ShortName(), err))
+				mu.Unlock()
+				return
+			}
+			// A stopped container reads as stats without processes.
+			if stats.PIDs == 0 {
+				stats = nil
+			}
+			t.container.Stats = stats
+		}(&c.targets[i])
+	}
+	wg.Wait()
+	return errs
+}
+
+func (c *Collector) wanted(container *models.Container) bool {
+	if len(c.match) == 0 {
+		return true
+	}
+	for _, name := range c.match {
+		if matches(container, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches follows docker stats: a container is named by its name or by a
+// prefix of its ID.
+func matches(container *models.Container, name string) bool {
+	return container.ShortName() == name || strings.HasPrefix(container.ID, name)
+}
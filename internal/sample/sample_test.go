@@ -0,0 +1,112 @@
+package sample
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+)
+
+var start = time.Date(2026, 1, 2, 14, 0, 0, 0, time.UTC)
+
+func sampleNames(samples []Sample) string {
+	names := make([]string, len(samples))
+	for i, s := range samples {
+		names[i] = s.Host + "/" + s.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func collect(t *testing.T, c *Collector, n int) []Sample {
+	t.Helper()
+	samples, errs := c.Collect(start.Add(time.Duration(n) * time.Second))
+	for _, err := range errs {
+		t.Errorf("collection %d: %v", n, err)
+	}
+	return samples
+}
+
+func TestCollectorFollowsStartsAndStops(t *testing.T) {
+	local := fake.NewRuntime("local")
+	collector := NewCollector([]docker.ContainerRuntime{local}, nil)
+
+	samples := collect(t, collector, 0)
+	if got := sampleNames(samples); got != "local/nginx-web local/postgres-db local/app-worker" {
+		t.Fatalf("first collection = %s, want the running containers", got)
+	}
+	if s := samples[0]; !s.Time.Equal(start) || s.ID != "abc123456789" || s.Image == "" || s.Stats.PIDs == 0 {
+		t.Errorf("sample = %+v", s)
+	}
+
+	if err := local.StopContainer("postgres-db"); err != nil {
+		t.Fatal(err)
+	}
+	if err := local.StartContainer("redis-cache"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A stopped container drops out at once; one that started shows up at
+	// the next listing.
+	for n := 1; n < relistEvery; n++ {
+		if got := sampleNames(collect(t, collector, n)); got != "local/nginx-web local/app-worker" {
+			t.Fatalf("collection %d = %s", n, got)
+		}
+	}
+	if got := sampleNames(collect(t, collector, relistEvery)); got != "local/nginx-web local/redis-cache local/app-worker" {
+		t.Errorf("collection %d = %s, want redis-cache after relisting", relistEvery, got)
+	}
+
+	// A container that runs again before the next listing is back at once.
+	if err := local.StopContainer("nginx-web"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sampleNames(collect(t, collector, relistEvery+1)); got != "local/redis-cache local/app-worker" {
+		t.Fatalf("samples = %s after stopping nginx-web", got)
+	}
+	if err := local.StartContainer("nginx-web"); err != nil {
+		t.Fatal(err)
+	}
+	if got := sampleNames(collect(t, collector, relistEvery+2)); got != "local/nginx-web local/redis-cache local/app-worker" {
+		t.Errorf("samples = %s after starting nginx-web again", got)
+	}
+}
+
+func TestCollectorRuntimeErrors(t *testing.T) {
+	local, remote := fake.NewRuntime("local"), fake.NewRuntime("remote")
+	remote.SetError(errors.New("connection refused"))
+	collector := NewCollector([]docker.ContainerRuntime{local, remote}, []string{"nginx-web"})
+
+	samples, errs := collector.Collect(start)
+	if got := sampleNames(samples); got != "local/nginx-web" {
+		t.Errorf("samples = %s, want the reachable runtime", got)
+	}
+	if len(errs) != 1 || errs[0].Error() != "remote: connection refused" {
+		t.Errorf("errors = %v, want the failing runtime named", errs)
+	}
+}
+
+func TestUnmatched(t *testing.T) {
+	local := fake.NewRuntime("local")
+	collector := NewCollector([]docker.ContainerRuntime{local}, []string{"nginx-web", "def456", "redis-cache", "missing"})
+
+	samples := collect(t, collector, 0)
+	if got := sampleNames(samples); got != "local/nginx-web local/postgres-db" {
+		t.Errorf("samples = %s, want containers by name and ID prefix", got)
+	}
+	if got := strings.Join(collector.Unmatched(), " "); got != "redis-cache missing" {
+		t.Errorf("Unmatched() = %s, want the stopped and the unknown container", got)
+	}
+
+	if err := local.StartContainer("redis-cache"); err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n <= relistEvery; n++ {
+		collect(t, collector, n)
+	}
+	if got := strings.Join(collector.Unmatched(), " "); got != "missing" {
+		t.Errorf("Unmatched() = %s after redis-cache started", got)
+	}
+}
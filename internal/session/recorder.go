@@ -0,0 +1,320 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// Recorder appends what wrapped runtimes return to a session file. Logs are
+// recorded once per line rather than with every listing, which keeps the
+// file small.
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	gz      *gzip.Writer
+	encoder *json.Encoder
+	err     error
+
+	// logs holds the last log lines seen per host and container, to find
+	// the new ones.
+	logs map[string][]string
+}
+
+// Create starts a new recording at the end of the session file at path.
+// A recording that was killed before it ended is repaired first, since a
+// new gzip member after an unterminated one cannot be read back.
+func Create(path string) (*Recorder, error) {
+	if err := repair(path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(file)
+	r := &Recorder{
+		file:    file,
+		gz:      gz,
+		encoder: json.NewEncoder(gz),
+		logs:    make(map[string][]string),
+	}
+	r.write(record{Kind: kindSession, Version: version})
+	if r.err != nil {
+		file.Close()
+		return nil, r.err
+	}
+	return r, nil
+}
+
+// repair rewrites the session file at path when its last recording was cut
+// short, keeping every complete record. Files that end cleanly, and missing
+// or empty ones, are left alone; anything else that does not read back is
+// refused rather than appended to.
+func repair(path string) error {
+	data, truncated, err := readRecords(path)
+	if err != nil || !truncated {
+		return err
+	}
+
+	// Records end in a newline; a partial one at the cut is dropped.
+	data = data[:bytes.LastIndexByte(data, '\n')+1]
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if _, err := gz.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readRecords decompresses the session file at path and reports whether its
+// last gzip member is unterminated. A missing file reads as empty.
+func readRecords(path string) ([]byte, bool, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if errors.Is(err, io.EOF) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("%s is not a session file: %w", path, err)
+	}
+	defer gz.Close()
+
+	var data bytes.Buffer
+	_, err = io.Copy(&data, gz)
+	switch {
+	case err == nil:
+		return data.Bytes(), false, nil
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return data.Bytes(), true, nil
+	}
+	return nil, false, fmt.Errorf("%s is damaged, not appending to it: %w", path, err)
+}
+
+// Err returns the first write error. Recording stops at that point, while
+// the wrapped runtimes keep working.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// Close ends the recording.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.gz.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+// Wrap returns a runtime that records what runtime returns.
+func (r *Recorder) Wrap(runtime docker.ContainerRuntime) docker.ContainerRuntime {
+	return &recordingRuntime{ContainerRuntime: runtime, recorder: r}
+}
+
+// write stamps and appends a record. It is flushed right away, so a session
+// cut short by a crash is still readable. The caller holds r.mu.
+func (r *Recorder) write(rec record) {
+	if r.err != nil {
+		return
+	}
+	rec.Time = time.Now()
+	if err := r.encoder.Encode(rec); err != nil {
+		r.err = err
+		return
+	}
+	r.err = r.gz.Flush()
+}
+
+func (r *Recorder) recordContainers(host string, containers []models.Container, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.write(record{Kind: kindContainers, Host: host, Error: err.Error()})
+		return
+	}
+
+	stripped := make([]models.Container, len(containers))
+	for i, c := range containers {
+		r.appendLogs(host, c.ID, c.Logs)
+		c.Logs = nil
+		stripped[i] = c
+	}
+	r.write(record{Kind: kindContainers, Host: host, Containers: stripped})
+}
+
+func (r *Recorder) recordStats(host, id string, stats *models.ContainerStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.write(record{Kind: kindStats, Host: host, ID: id, Stats: stats})
+}
+
+func (r *Recorder) recordLogs(host, id string, lines []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appendLogs(host, id, lines)
+}
+
+// appendLogs writes the lines that were not seen in the previous logs of the
+// container. The caller holds r.mu.
+func (r *Recorder) appendLogs(host, id string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	key := host + "/" + id
+	fresh := newLines(r.logs[key], lines)
+	r.logs[key] = lines
+	if len(fresh) > 0 {
+		r.write(record{Kind: kindLogs, Host: host, ID: id, Lines: fresh})
+	}
+}
+
+func (r *Recorder) recordAction(host, action, id string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rec := record{Kind: kindAction, Host: host, ID: id, Action: action}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	r.write(rec)
+}
+
+// newLines returns the lines of current that come after the last line of
+// previous. Logs are read as a window of the latest lines, so everything is
+// new when that line scrolled out of the window.
+func newLines(previous, current []string) []string {
+	if len(previous) == 0 {
+		return current
+	}
+	last := previous[len(previous)-1]
+	for i := len(current) - 1; i >= 0; i-- {
+		if current[i] == last {
+			return current[i+1:]
+		}
+	}
+	return current
+}
+
+type recordingRuntime struct {
+	docker.ContainerRuntime
+	recorder *Recorder
+}
+
+func (r *recordingRuntime) ListContainers(onlyRunning bool) ([]models.Container, error) {
+	containers, err := r.ContainerRuntime.ListContainers(onlyRunning)
+	r.recorder.recordContainers(r.Name(), containers, err)
+	return containers, err
+}
+
+func (r *recordingRuntime) ListContainersWith(options docker.ListOptions) ([]models.Container, error) {
+	containers, err := r.ContainerRuntime.ListContainersWith(options)
+	r.recorder.recordContainers(r.Name(), containers, err)
+	return containers, err
+}
+
+func (r *recordingRuntime) GetContainerStats(containerID string) (*models.ContainerStats, error) {
+	stats, err := r.ContainerRuntime.GetContainerStats(containerID)
+	if err == nil {
+		r.recorder.recordStats(r.Name(), containerID, stats)
+	}
+	return stats, err
+}
+
+func (r *recordingRuntime) GetContainerLogs(containerID string, lines int) ([]string, error) {
+	logs, err := r.ContainerRuntime.GetContainerLogs(containerID, lines)
+	if err == nil {
+		r.recorder.recordLogs(r.Name(), containerID, logs)
+	}
+	return logs, err
+}
+
+func (r *recordingRuntime) RefreshContainerLogs(containerID string, lines int) ([]string, error) {
+	logs, err := r.ContainerRuntime.RefreshContainerLogs(containerID, lines)
+	if err == nil {
+		r.recorder.recordLogs(r.Name(), containerID, logs)
+	}
+	return logs, err
+}
+
+func (r *recordingRuntime) StartContainer(containerID string) error {
+	err := r.ContainerRuntime.StartContainer(containerID)
+	r.recorder.recordAction(r.Name(), "start", containerID, err)
+	return err
+}
+
+func (r *recordingRuntime) StopContainer(containerID string) error {
+	err := r.ContainerRuntime.StopContainer(containerID)
+	r.recorder.recordAction(r.Name(), "stop", containerID, err)
+	return err
+}
+
+func (r *recordingRuntime) RestartContainer(containerID string) error {
+	err := r.ContainerRuntime.RestartContainer(containerID)
+	r.recorder.recordAction(r.Name(), "restart", containerID, err)
+	return err
+}
+
+func (r *recordingRuntime) PauseContainer(containerID string) error {
+	err := r.ContainerRuntime.PauseContainer(containerID)
+	r.recorder.recordAction(r.Name(), "pause", containerID, err)
+	return err
+}
+
+func (r *recordingRuntime) UnpauseContainer(containerID string) error {
+	err := r.ContainerRuntime.UnpauseContainer(containerID)
+	r.recorder.recordAction(r.Name(), "unpause", containerID, err)
+	return err
+}
+
+func (r *recordingRuntime) RemoveContainer(containerID string, force bool) error {
+	err := r.ContainerRuntime.RemoveContainer(containerID, force)
+	r.recorder.recordAction(r.Name(), "remove", containerID, err)
+	return err
+}
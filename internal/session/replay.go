@@ -0,0 +1,288 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+// maxReplayLogs bounds the log lines a replayed container carries, like the
+// window a live listing reads.
+const maxReplayLogs = 100
+
+// eventShown is how long past an event it stays in the playback status.
+const eventShown = 30 * time.Second
+
+// maxEventText keeps the status to one header line.
+const maxEventText = 48
+
+// maxIdle is how much of the time between two recordings of a session file
+// plays back, one refresh of the monitoring interface; the rest is skipped.
+const maxIdle = time.Second
+
+var speeds = []float64{0.25, 0.5, 1, 2, 4, 8, 16, 32, 64}
+
+var errReadOnly = errors.New("not available while replaying a session")
+
+// Player is the playback clock of a session. It moves at the chosen speed
+// from the start of the session, skips most of the time between recordings
+// and pauses at its end.
+type Player struct {
+	session *Session
+
+	mu       sync.Mutex
+	position time.Time
+	anchor   time.Time
+	speed    int
+	paused   bool
+}
+
+// NewPlayer starts playing s from its start at the given speed, rounded
+// down to a supported one between 0.25 and 64.
+func NewPlayer(s *Session, speed float64) *Player {
+	p := &Player{session: s, position: s.Start, anchor: time.Now()}
+	for i, candidate := range speeds {
+		if speed >= candidate {
+			p.speed = i
+		}
+	}
+	return p
+}
+
+// Now returns the playback time.
+func (p *Player) Now() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.now(time.Now())
+}
+
+// now advances the position to wall time and re-anchors it. The caller holds
+// p.mu.
+func (p *Player) now(wall time.Time) time.Time {
+	if !p.paused {
+		elapsed := time.Duration(float64(wall.Sub(p.anchor)) * speeds[p.speed])
+		p.position = p.session.skipIdle(p.position, p.position.Add(elapsed))
+		if !p.position.Before(p.session.End) {
+			p.position = p.session.End
+			p.paused = true
+		}
+	}
+	p.anchor = wall
+	return p.position
+}
+
+// TogglePause pauses or resumes playback. Resuming at the end starts over.
+func (p *Player) TogglePause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.now(time.Now())
+	if p.paused && !p.position.Before(p.session.End) {
+		p.position = p.session.Start
+	}
+	p.paused = !p.paused
+}
+
+// Seek moves playback by offset, scaled by the speed so a step always
+// covers about the same share of what plays in a second.
+func (p *Player) Seek(offset time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	position := p.now(time.Now()).Add(time.Duration(float64(offset) * speeds[p.speed]))
+	switch {
+	case position.Before(p.session.Start):
+		position = p.session.Start
+	case position.After(p.session.End):
+		position = p.session.End
+	}
+	p.position = position
+}
+
+func (p *Player) Faster() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now(time.Now())
+	if p.speed < len(speeds)-1 {
+		p.speed++
+	}
+}
+
+func (p *Player) Slower() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.now(time.Now())
+	if p.speed > 0 {
+		p.speed--
+	}
+}
+
+// Status describes the playback for the header, such as
+// "14:03:10 (2m10s / 25m0s) x4 paused", plus the latest recorded event.
+func (p *Player) Status() string {
+	p.mu.Lock()
+	position := p.now(time.Now())
+	speed := speeds[p.speed]
+	paused := p.paused
+	p.mu.Unlock()
+
+	status := fmt.Sprintf("%s (%s / %s) x%g",
+		position.Local().Format("15:04:05"),
+		position.Sub(p.session.Start).Round(time.Second),
+		p.session.Duration().Round(time.Second),
+		speed)
+	if paused {
+		status += " paused"
+	}
+	if event, ok := p.session.LastEvent(position); ok && position.Sub(event.Time) < eventShown {
+		text := fmt.Sprintf("%s: %s", event.Host, event)
+		if runes := []rune(text); len(runes) > maxEventText {
+			text = string(runes[:maxEventText-3]) + "..."
+		}
+		status += " | " + text
+	}
+	return status
+}
+
+// Runtimes returns a runtime per recorded host that serves what the host
+// looked like at the playback time.
+func (s *Session) Runtimes(player *Player) []docker.ContainerRuntime {
+	runtimes := make([]docker.ContainerRuntime, 0, len(s.hosts))
+	for _, host := range s.hosts {
+		runtimes = append(runtimes, &replayRuntime{name: host, timeline: s.byHost[host], player: player})
+	}
+	return runtimes
+}
+
+// replayRuntime is a read-only runtime backed by the recording of one host.
+type replayRuntime struct {
+	name     string
+	timeline *hostTimeline
+	player   *Player
+}
+
+var _ docker.ContainerRuntime = (*replayRuntime)(nil)
+
+func (r *replayRuntime) Name() string { return r.name }
+func (r *replayRuntime) Close() error { return nil }
+
+// Ping fails only when the host could not be listed at the playback time.
+func (r *replayRuntime) Ping() error {
+	if frame, ok := r.timeline.frameAt(r.player.Now()); ok && frame.err != "" {
+		return errors.New(frame.err)
+	}
+	return nil
+}
+
+func (r *replayRuntime) ListContainers(onlyRunning bool) ([]models.Container, error) {
+	now := r.player.Now()
+	frame, ok := r.timeline.frameAt(now)
+	if !ok {
+		return []models.Container{}, nil
+	}
+	if frame.err != "" {
+		return nil, errors.New(frame.err)
+	}
+
+	containers := make([]models.Container, 0, len(frame.containers))
+	for _, c := range frame.containers {
+		if onlyRunning && c.Status != models.StatusRunning {
+			continue
+		}
+		if read, ok := r.timeline.statsAt(c.ID, now); ok && read.time.After(frame.time) {
+			stats := read.stats
+			c.Stats = &stats
+		} else if c.Stats != nil {
+			stats := *c.Stats
+			c.Stats = &stats
+		}
+		c.Logs = r.timeline.logsAt(c.ID, now, maxReplayLogs)
+		containers = append(containers, c)
+	}
+	return containers, nil
+}
+
+func (r *replayRuntime) ListContainersWith(options docker.ListOptions) ([]models.Container, error) {
+	containers, err := r.ListContainers(options.OnlyRunning)
+	if options.SkipLogs {
+		for i := range containers {
+			containers[i].Logs = nil
+		}
+	}
+	return containers, err
+}
+
+func (r *replayRuntime) GetContainerStats(containerID string) (*models.ContainerStats, error) {
+	now := r.player.Now()
+	read, hasRead := r.timeline.statsAt(containerID, now)
+	frame, hasFrame := r.timeline.frameAt(now)
+
+	if hasFrame {
+		for _, c := range frame.containers {
+			if c.ID == containerID && c.Stats != nil && (!hasRead || frame.time.After(read.time)) {
+				stats := *c.Stats
+				return &stats, nil
+			}
+		}
+	}
+	if hasRead {
+		stats := read.stats
+		return &stats, nil
+	}
+	return nil, fmt.Errorf("no stats recorded for %s", containerID)
+}
+
+func (r *replayRuntime) GetContainerLogs(containerID string, lines int) ([]string, error) {
+	return r.timeline.logsAt(containerID, r.player.Now(), lines), nil
+}
+
+func (r *replayRuntime) RefreshContainerLogs(containerID string, lines int) ([]string, error) {
+	return r.GetContainerLogs(containerID, lines)
+}
+
+func (r *replayRuntime) InspectContainer(containerID string) (*types.ContainerJSON, error) {
+	return nil, errReadOnly
+}
+
+// LoadContainerConfig has nothing to add; the recorded listing already holds
+// what was loaded live.
+func (r *replayRuntime) LoadContainerConfig(container *models.Container) error {
+	return nil
+}
+
+func (r *replayRuntime) StartContainer(containerID string) error   { return errReadOnly }
+func (r *replayRuntime) StopContainer(containerID string) error    { return errReadOnly }
+func (r *replayRuntime) RestartContainer(containerID string) error { return errReadOnly }
+func (r *replayRuntime) PauseContainer(containerID string) error   { return errReadOnly }
+func (r *replayRuntime) UnpauseContainer(containerID string) error { return errReadOnly }
+
+func (r *replayRuntime) RemoveContainer(containerID string, force bool) error {
+	return errReadOnly
+}
+
+func (r *replayRuntime) ImageList() ([]models.Image, error) { return nil, errReadOnly }
+
+func (r *replayRuntime) ImageHistory(imageID string) ([]models.ImageLayer, error) {
+	return nil, errReadOnly
+}
+
+func (r *replayRuntime) ImageRemove(imageID string, force bool) error { return errReadOnly }
+
+func (r *replayRuntime) ImagesPrune() (models.PruneReport, error) {
+	return models.PruneReport{}, errReadOnly
+}
+
+func (r *replayRuntime) VolumeList() ([]models.Volume, error) { return nil, errReadOnly }
+
+func (r *replayRuntime) VolumeRemove(name string, force bool) error { return errReadOnly }
+
+func (r *replayRuntime) VolumesPrune() (models.PruneReport, error) {
+	return models.PruneReport{}, errReadOnly
+}
+
+func (r *replayRuntime) NetworkList() ([]models.DockerNetwork, error) { return nil, errReadOnly }
@@ -0,0 +1,270 @@
+// Package session records what kernus sees of its hosts into an append-only
+// file and replays it later as if the hosts were live.
+//
+// A session file is a gzip stream of JSON records, one per line: container
+// listings without logs, stats reads, new log lines and container actions.
+// Every recording appends a new gzip member, so one file may hold several
+// recordings back to back. A recording killed before its member was closed
+// is repaired when the next one starts.
+package session
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/kqnd/kernus/internal/models"
+)
+
+const version = 1
+
+const (
+	kindSession    = "session"
+	kindContainers = "containers"
+	kindStats      = "stats"
+	kindLogs       = "logs"
+	kindAction     = "action"
+)
+
+type record struct {
+	Kind       string                 `json:"kind"`
+	Time       time.Time              `json:"t"`
+	Version    int                    `json:"version,omitempty"`
+	Host       string                 `json:"host,omitempty"`
+	ID         string                 `json:"id,omitempty"`
+	Containers []models.Container     `json:"containers,omitempty"`
+	Stats      *models.ContainerStats `json:"stats,omitempty"`
+	Lines      []string               `json:"lines,omitempty"`
+	Action     string                 `json:"action,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Event is a container action taken during the recording, or a host that
+// failed to list its containers.
+type Event struct {
+	Time   time.Time
+	Host   string
+	ID     string
+	Action string
+	Error  string
+}
+
+func (e Event) String() string {
+	text := e.Action
+	if id := e.ID; id != "" {
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		text += " " + id
+	}
+	if e.Error != "" {
+		text += " failed: " + e.Error
+	}
+	return text
+}
+
+type frame struct {
+	time       time.Time
+	containers []models.Container
+	err        string
+}
+
+type logLine struct {
+	time time.Time
+	text string
+}
+
+type statsRead struct {
+	time  time.Time
+	stats models.ContainerStats
+}
+
+// gap is the time between the last record of one recording and the start of
+// the next in the same session file.
+type gap struct {
+	from time.Time
+	to   time.Time
+}
+
+type hostTimeline struct {
+	frames []frame
+	stats  map[string][]statsRead
+	logs   map[string][]logLine
+}
+
+// Session is a recording loaded into memory.
+type Session struct {
+	Start time.Time
+	End   time.Time
+
+	hosts  []string
+	byHost map[string]*hostTimeline
+	events []Event
+	gaps   []gap
+}
+
+// Open reads every recording in the session file at path.
+func Open(path string) (*Session, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a session file: %w", path, err)
+	}
+	defer gz.Close()
+
+	s := &Session{byHost: make(map[string]*hostTimeline)}
+	decoder := json.NewDecoder(gz)
+	for {
+		var r record
+		if err := decoder.Decode(&r); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			// A recording cut short ends in a partial record; keep
+			// everything before it.
+			if errors.Is(err, io.ErrUnexpectedEOF) && !s.Start.IsZero() {
+				break
+			}
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if r.Kind == kindSession && r.Version > version {
+			return nil, fmt.Errorf("%s was recorded by a newer kernus (version %d)", path, r.Version)
+		}
+		s.add(r)
+	}
+
+	if s.Start.IsZero() {
+		return nil, fmt.Errorf("%s holds no recording", path)
+	}
+	sort.SliceStable(s.events, func(i, j int) bool { return s.events[i].Time.Before(s.events[j].Time) })
+	return s, nil
+}
+
+func (s *Session) add(r record) {
+	if r.Kind == kindSession && !s.End.IsZero() && r.Time.Sub(s.End) > maxIdle {
+		s.gaps = append(s.gaps, gap{from: s.End, to: r.Time})
+	}
+	if s.Start.IsZero() || r.Time.Before(s.Start) {
+		s.Start = r.Time
+	}
+	if r.Time.After(s.End) {
+		s.End = r.Time
+	}
+	if r.Kind == kindSession {
+		return
+	}
+
+	host := s.timeline(r.Host)
+	switch r.Kind {
+	case kindContainers:
+		host.frames = append(host.frames, frame{time: r.Time, containers: r.Containers, err: r.Error})
+		if r.Error != "" {
+			s.events = append(s.events, Event{Time: r.Time, Host: r.Host, Action: "list", Error: r.Error})
+		}
+	case kindStats:
+		if r.Stats != nil {
+			host.stats[r.ID] = append(host.stats[r.ID], statsRead{time: r.Time, stats: *r.Stats})
+		}
+	case kindLogs:
+		for _, line := range r.Lines {
+			host.logs[r.ID] = append(host.logs[r.ID], logLine{time: r.Time, text: line})
+		}
+	case kindAction:
+		s.events = append(s.events, Event{Time: r.Time, Host: r.Host, ID: r.ID, Action: r.Action, Error: r.Error})
+	}
+}
+
+func (s *Session) timeline(host string) *hostTimeline {
+	timeline, ok := s.byHost[host]
+	if !ok {
+		timeline = &hostTimeline{
+			stats: make(map[string][]statsRead),
+			logs:  make(map[string][]logLine),
+		}
+		s.byHost[host] = timeline
+		s.hosts = append(s.hosts, host)
+	}
+	return timeline
+}
+
+// Hosts returns the recorded hosts in the order they first appeared.
+func (s *Session) Hosts() []string {
+	return s.hosts
+}
+
+// Duration is the time between the first and the last record.
+func (s *Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// LastEvent returns the latest event at or before t.
+func (s *Session) LastEvent(t time.Time) (Event, bool) {
+	i := sort.Search(len(s.events), func(i int) bool { return s.events[i].Time.After(t) })
+	if i == 0 {
+		return Event{}, false
+	}
+	return s.events[i-1], true
+}
+
+// skipIdle returns where playback moving from one time to another ends up
+// once the idle part of every gap it crosses is skipped: a gap plays for
+// maxIdle, then playback jumps to the next recording.
+func (s *Session) skipIdle(from, to time.Time) time.Time {
+	for _, gap := range s.gaps {
+		skipFrom := gap.from.Add(maxIdle)
+		if from.Before(gap.to) && to.After(skipFrom) {
+			if from.After(skipFrom) {
+				skipFrom = from
+			}
+			to = to.Add(gap.to.Sub(skipFrom))
+		}
+	}
+	return to
+}
+
+// frameAt returns the latest listing of host at or before t.
+func (h *hostTimeline) frameAt(t time.Time) (frame, bool) {
+	i := sort.Search(len(h.frames), func(i int) bool { return h.frames[i].time.After(t) })
+	if i == 0 {
+		return frame{}, false
+	}
+	return h.frames[i-1], true
+}
+
+// statsAt returns the latest stats read of a container at or before t.
+func (h *hostTimeline) statsAt(id string, t time.Time) (statsRead, bool) {
+	reads := h.stats[id]
+	i := sort.Search(len(reads), func(i int) bool { return reads[i].time.After(t) })
+	if i == 0 {
+		return statsRead{}, false
+	}
+	return reads[i-1], true
+}
+
+// logsAt returns up to the last n log lines of a container written at or
+// before t.
+func (h *hostTimeline) logsAt(id string, t time.Time, n int) []string {
+	lines := h.logs[id]
+	end := sort.Search(len(lines), func(i int) bool { return lines[i].time.After(t) })
+	start := 0
+	if n > 0 && end > n {
+		start = end - n
+	}
+
+	result := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		result = append(result, line.text)
+	}
+	return result
+}
@@ -0,0 +1,341 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/models"
+)
+
+const nginxID = "abc123456789"
+
+// recordActivity drives a runtime wrapped by rec the way the TUI would: a listing,
+// a stats read, logs, the given action on nginx and a listing after it.
+func recordActivity(t *testing.T, rec *Recorder, host, action string) {
+	t.Helper()
+	runtime := rec.Wrap(fake.NewRuntime(host))
+	if _, err := runtime.ListContainers(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runtime.GetContainerStats(nginxID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runtime.GetContainerLogs(nginxID, 100); err != nil {
+		t.Fatal(err)
+	}
+	switch action {
+	case "stop":
+		runtime.StopContainer(nginxID)
+	case "restart":
+		runtime.RestartContainer(nginxID)
+	}
+	if _, err := runtime.ListContainers(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func findContainer(containers []models.Container, id string) *models.Container {
+	for i := range containers {
+		if containers[i].ID == id {
+			return &containers[i]
+		}
+	}
+	return nil
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.kern")
+	rec, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordActivity(t, rec, "local", "stop")
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hosts := s.Hosts(); len(hosts) != 1 || hosts[0] != "local" {
+		t.Fatalf("Hosts() = %v, want [local]", hosts)
+	}
+	event, ok := s.LastEvent(s.End)
+	if !ok || event.Action != "stop" || event.ID != nginxID || event.Host != "local" {
+		t.Errorf("LastEvent() = %+v, %v, want the stop of nginx", event, ok)
+	}
+
+	player := NewPlayer(s, 1)
+	player.Seek(time.Hour)
+	runtime := s.Runtimes(player)[0]
+
+	containers, err := runtime.ListContainers(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != len(models.MockContainers()) {
+		t.Fatalf("replayed %d containers, want %d", len(containers), len(models.MockContainers()))
+	}
+	nginx := findContainer(containers, nginxID)
+	if nginx == nil || nginx.Status != models.StatusExited {
+		t.Fatalf("nginx replayed as %+v, want it stopped by the end", nginx)
+	}
+	if len(nginx.Logs) == 0 {
+		t.Error("nginx replayed without the recorded logs")
+	}
+
+	running, _ := runtime.ListContainers(true)
+	if findContainer(running, nginxID) != nil {
+		t.Error("stopped nginx listed among running containers")
+	}
+	if err := runtime.StartContainer(nginxID); err == nil {
+		t.Error("StartContainer() succeeded on a replayed runtime")
+	}
+}
+
+func TestCreateRepairsKilledRecording(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.kern")
+	first, err := Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordActivity(t, first, "local", "stop")
+	// Killed: the records are flushed but the gzip member never ends, and
+	// the last write is cut off part way.
+	first.file.Close()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := Create(path)
+	if err != nil {
+		t.Fatalf("Create() after a killed recording: %v", err)
+	}
+	recordActivity(t, second, "remote", "restart")
+	if err := second.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() after appending to a repaired recording: %v", err)
+	}
+	if hosts := s.Hosts(); strings.Join(hosts, " ") != "local remote" {
+		t.Errorf("Hosts() = %v, want both recordings", hosts)
+	}
+	var actions []string
+	for _, event := range s.events {
+		actions = append(actions, event.Host+" "+event.Action)
+	}
+	if strings.Join(actions, ", ") != "local stop, remote restart" {
+		t.Errorf("events = %v, want the stop of the killed recording and the restart", actions)
+	}
+}
+
+func TestCreateRefusesDamagedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("not a session\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(path); err == nil {
+		t.Fatal("Create() appended to a file that is not a session")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "not a session\n" {
+		t.Errorf("file changed to %q", data)
+	}
+}
+
+// testSession is ten minutes long with one listing of nginx a minute in and
+// its restart at five minutes.
+func testSession() (*Session, time.Time) {
+	start := time.Date(2026, 1, 2, 14, 0, 0, 0, time.Local)
+	s := &Session{byHost: make(map[string]*hostTimeline)}
+	s.add(record{Kind: kindSession, Time: start, Version: version})
+	s.add(record{Kind: kindContainers, Time: start.Add(time.Minute), Host: "local",
+		Containers: []models.Container{{ID: nginxID, Name: "nginx-web", Status: models.StatusRunning}}})
+	s.add(record{Kind: kindAction, Time: start.Add(5 * time.Minute), Host: "local", ID: nginxID, Action: "restart"})
+	s.add(record{Kind: kindStats, Time: start.Add(10 * time.Minute), Host: "local", ID: nginxID,
+		Stats: &models.ContainerStats{PIDs: 3}})
+	return s, start
+}
+
+func TestPlayerSeek(t *testing.T) {
+	s, start := testSession()
+	// Paused right away, so only the moments before it count; seconds
+	// are compared.
+	p := NewPlayer(s, 1)
+	p.TogglePause()
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   time.Duration
+	}{
+		{"forward", 2 * time.Minute, 2 * time.Minute},
+		{"back", -30 * time.Second, 90 * time.Second},
+		{"before the start", -time.Hour, 0},
+		{"past the end", time.Hour, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		p.Seek(tt.offset)
+		if got := p.Now().Sub(start).Round(time.Second); got != tt.want {
+			t.Errorf("%s: at %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// Listings follow the playback time.
+	p.Seek(-10 * time.Minute)
+	runtime := s.Runtimes(p)[0]
+	if containers, _ := runtime.ListContainers(false); len(containers) != 0 {
+		t.Errorf("listed %d containers before the first listing", len(containers))
+	}
+	p.Seek(time.Minute)
+	if containers, _ := runtime.ListContainers(false); len(containers) != 1 {
+		t.Errorf("listed %d containers after the first listing, want 1", len(containers))
+	}
+}
+
+func TestPlayerSpeed(t *testing.T) {
+	s, start := testSession()
+
+	p := NewPlayer(s, 3)
+	p.TogglePause()
+	if !strings.Contains(p.Status(), " x2 ") {
+		t.Errorf("Status() = %q, want speed 3 rounded down to x2", p.Status())
+	}
+
+	// A seek step covers the same share of what plays in a second.
+	p.Faster()
+	p.Seek(10 * time.Second)
+	if got := p.Now().Sub(start).Round(time.Second); got != 40*time.Second {
+		t.Errorf("seek of 10s at x4 moved %s, want 40s", got)
+	}
+
+	for i := 0; i < len(speeds)+1; i++ {
+		p.Slower()
+	}
+	if !strings.Contains(p.Status(), " x0.25 ") {
+		t.Errorf("Status() = %q, want the slowest speed x0.25", p.Status())
+	}
+	for i := 0; i < len(speeds)+1; i++ {
+		p.Faster()
+	}
+	if !strings.Contains(p.Status(), " x64 ") {
+		t.Errorf("Status() = %q, want the fastest speed x64", p.Status())
+	}
+
+	// Playing moves the clock at the chosen speed.
+	p.TogglePause()
+	before := p.Now()
+	time.Sleep(20 * time.Millisecond)
+	if moved := p.Now().Sub(before); moved < 64*20*time.Millisecond {
+		t.Errorf("moved %s in 20ms at x64", moved)
+	}
+}
+
+func TestPlayerPausesAtEnd(t *testing.T) {
+	s, start := testSession()
+	p := NewPlayer(s, 64)
+	p.mu.Lock()
+	p.position = s.End.Add(-time.Millisecond)
+	p.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+	if got := p.Now(); !got.Equal(s.End) {
+		t.Errorf("Now() = %s, want it held at the end %s", got.Sub(start), s.Duration())
+	}
+	status := p.Status()
+	if !strings.HasSuffix(status, " paused") || !strings.Contains(status, "(10m0s / 10m0s)") {
+		t.Errorf("Status() = %q, want paused at 10m0s", status)
+	}
+
+	// Resuming at the end starts over.
+	p.TogglePause()
+	if got := p.Now().Sub(start); got < 0 || got > time.Minute {
+		t.Errorf("resumed at %s, want the start", got)
+	}
+}
+
+func TestPlayerStatusShowsRecentEvent(t *testing.T) {
+	s, start := testSession()
+	p := NewPlayer(s, 1)
+	p.TogglePause()
+
+	p.Seek(5*time.Minute + 10*time.Second)
+	want := start.Add(5*time.Minute + 10*time.Second).Format("15:04:05")
+	if status := p.Status(); status != want+" (5m10s / 10m0s) x1 paused | local: restart "+nginxID {
+		t.Errorf("Status() = %q", status)
+	}
+
+	p.Seek(time.Minute)
+	if status := p.Status(); strings.Contains(status, "restart") {
+		t.Errorf("Status() = %q, want the event gone after %s", status, eventShown)
+	}
+}
+
+func TestPlayerStatusCutsEventByRune(t *testing.T) {
+	s, start := testSession()
+	s.add(record{Kind: kindAction, Time: start.Add(6 * time.Minute), Host: "büro", ID: nginxID, Action: "stop",
+		Error: "Fehler: Zeitüberschreitung beim Anhalten des Containers über SSH"})
+	p := NewPlayer(s, 1)
+	p.TogglePause()
+	p.Seek(6 * time.Minute)
+
+	status := p.Status()
+	text := status[strings.Index(status, " | ")+len(" | "):]
+	if !utf8.ValidString(text) || utf8.RuneCountInString(text) != maxEventText || !strings.HasSuffix(text, "...") {
+		t.Errorf("event = %q, want %d runes ending in ...", text, maxEventText)
+	}
+}
+
+// TestPlayerSkipsIdleBetweenRecordings plays a file with a second recording
+// made three hours after the first ended.
+func TestPlayerSkipsIdleBetweenRecordings(t *testing.T) {
+	s, start := testSession()
+	second := s.End.Add(3 * time.Hour)
+	s.add(record{Kind: kindSession, Time: second, Version: version})
+	s.add(record{Kind: kindContainers, Time: second.Add(time.Minute), Host: "local",
+		Containers: []models.Container{{ID: nginxID, Name: "nginx-web", Status: models.StatusRunning}}})
+	if len(s.gaps) != 1 || !s.gaps[0].from.Equal(start.Add(10*time.Minute)) || !s.gaps[0].to.Equal(second) {
+		t.Fatalf("gaps = %v, want the three hours between the recordings", s.gaps)
+	}
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		elapsed time.Duration
+		want    time.Time
+	}{
+		{"before the gap", start, time.Minute, start.Add(time.Minute)},
+		{"into the gap", s.gaps[0].from, maxIdle / 2, s.gaps[0].from.Add(maxIdle / 2)},
+		{"across the gap", s.gaps[0].from.Add(-time.Second), time.Second + maxIdle + time.Second, second.Add(time.Second)},
+		{"from a seek into the gap", second.Add(-time.Hour), time.Second, second.Add(time.Second)},
+		{"after the gap", second, time.Second, second.Add(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewPlayer(s, 1)
+			wall := time.Now()
+			p.mu.Lock()
+			p.position, p.anchor = tt.from, wall
+			got := p.now(wall.Add(tt.elapsed))
+			p.mu.Unlock()
+			if !got.Equal(tt.want) {
+				t.Errorf("now() = %s, want %s", got.Sub(start), tt.want.Sub(start))
+			}
+		})
+	}
+}
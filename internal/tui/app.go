@@ -2,42 +2,110 @@ package tui
 
 import (
 	"fmt"
-	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/config"
 	"github.com/kqnd/kernus/internal/docker"
 	"github.com/kqnd/kernus/internal/models"
 	"github.com/kqnd/kernus/internal/tui/components"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
 	nundb "github.com/kqnd/nun-db-go"
 	"github.com/rivo/tview"
 )
 
 type Config struct {
-	Server        string
-	Group         string
-	RefreshRate   time.Duration
-	MaxLogEntries int
-	DockerHost    string
+	Server         string
+	Group          string
+	RefreshRate    time.Duration
+	MaxLogEntries  int
+	DockerHost     string
+	DockerContexts []string
+	ConfigPath     string
+	TUI            config.TUIConfig
+	Alerts         []config.AlertRule
+
+	// Runtimes replaces the Docker connection when set, for instance with
+	// fake runtimes that need no daemon.
+	Runtimes []docker.ContainerRuntime
+
+	// WrapRuntime, when set, wraps the runtime of every host, for instance
+	// to record what it returns.
+	WrapRuntime func(docker.ContainerRuntime) docker.ContainerRuntime
+
+	// Screen replaces the terminal when set, for instance with a
+	// tcell.SimulationScreen to run headless.
+	Screen tcell.Screen
+
+	// Playback controls the clock of replayed runtimes. Setting it enables
+	// the replay keys and blocks container actions.
+	Playback Playback
 }
 
+// Playback is the clock of a replayed session.
+type Playback interface {
+	TogglePause()
+	Seek(offset time.Duration)
+	Faster()
+	Slower()
+	Status() string
+}
+
+// seekStep is how far one replay seek key moves at normal speed.
+const seekStep = 10 * time.Second
+
 type App struct {
 	tviewApp *tview.Application
 	config   *Config
 	nundb    *nundb.Client
-	docker   *docker.Client
-
-	header     *components.Header
-	containers *components.ContainerList
-	details    *components.Details
+	hosts    []*dockerHost
+
+	header         *components.Header
+	containers     components.ContainerView
+	containerList  *components.ContainerList
+	containerTable *components.ContainerTable
+	details        *components.Details
+	logPane        *components.LogPane
+	compare        *components.CompareView
+	images         *components.ImagesView
+	volumes        *components.VolumesView
+	networks       *components.NetworksView
+	alertsView     *components.AlertsView
+	palette        *components.CommandPalette
+	help           *components.HelpView
+
+	keys   *keymap.Keymap
+	alerts *alert.Engine
+
+	commands       []paletteCommand
+	allContainers  []*models.Container
+	compareMarked  *models.Container
+	comparing      bool
+	compareLoading bool
+	resource       string
 
 	stopChan chan struct{}
 	mainGrid *tview.Grid
+	pages    *tview.Pages
 
 	isRunning     bool
 	refreshTicker *time.Ticker
 	focusIndex    int
 	focusables    []tview.Primitive
+
+	publishMu sync.Mutex
+
+	// configSaves holds TUI settings waiting for writeTUIConfig, the only
+	// goroutine writing the config file.
+	configSaves chan config.TUIConfig
+
+	screenWidth  int
+	screenHeight int
+	stacked      bool
 }
 
 func NewApp(config *Config) *App {
@@ -47,6 +115,9 @@ func NewApp(config *Config) *App {
 	if config.MaxLogEntries == 0 {
 		config.MaxLogEntries = 1000
 	}
+	if config.TUI.ContainerView == "" {
+		config.TUI.ContainerView = components.ViewModeList
+	}
 
 	app := &App{
 		tviewApp:   tview.NewApplication(),
@@ -67,21 +138,6 @@ func toPtrSlice(containers []models.Container) []*models.Container {
 	return out
 }
 
-func (a *App) initializeDocker() error {
-	var err error
-	a.docker, err = docker.NewClient(a.config.DockerHost)
-
-	if err != nil {
-		return fmt.Errorf("failed to connect to Docker daemon: %w", err)
-	}
-
-	if err := a.docker.Ping(); err != nil {
-		a.docker.Close()
-		return fmt.Errorf("docker daemon not responding: %w", err)
-	}
-	return nil
-}
-
 func (a *App) startAutoRefresh() {
 	if a.refreshTicker != nil {
 		a.refreshTicker.Stop()
@@ -104,19 +160,38 @@ func (a *App) startAutoRefresh() {
 }
 
 func (a *App) performRefresh(_ bool) {
-	var selectedID string
-	if currentSelected := a.containers.GetSelectedContainer(); currentSelected != nil {
-		selectedID = currentSelected.ID
+	for _, host := range a.hosts {
+		go func(host *dockerHost) {
+			if host.load() {
+				a.publishContainers()
+			}
+		}(host)
 	}
+}
 
-	containers, err := a.loadContainers()
-	if err != nil {
-		fmt.Printf("Error loading containers: %v\n", err)
-		os.Exit(1)
-	}
+// publishContainers shows the latest containers of all hosts. Hosts finish
+// loading independently, so publishing is serialized.
+func (a *App) publishContainers() {
+	a.publishMu.Lock()
+	defer a.publishMu.Unlock()
+
+	containers := a.loadContainers()
+	hosts := a.hostStates()
+	alertHosts := a.alertHosts()
 
 	a.tviewApp.QueueUpdateDraw(func() {
-		a.containers.UpdateContainersPreserveSelection(containers, selectedID)
+		var selectedID string
+		if currentSelected := a.containers.GetSelectedContainer(); currentSelected != nil {
+			selectedID = currentSelected.ID
+		}
+
+		a.allContainers = containers
+		a.refreshCompare(containers)
+		a.header.SetHosts(hosts)
+		a.evaluateAlerts(alertHosts)
+		a.containerList.SetHosts(hosts)
+		a.containerList.UpdateContainersPreserveSelection(containers, selectedID)
+		a.containerTable.UpdateContainersPreserveSelection(containers, selectedID)
 
 		if a.nundb != nil {
 			a.nundb.Set("a", "b")
@@ -130,141 +205,334 @@ func (a *App) performRefresh(_ bool) {
 
 func (a *App) initializeComponents() {
 	a.header = components.NewHeader(a.tviewApp, a.config.Server, a.config.Group)
-
-	containers, err := a.loadContainers()
-	if err != nil {
-		fmt.Printf("Error loading containers, using mock data: %v\n", err)
-		os.Exit(1)
+	if a.config.Playback != nil {
+		a.header.SetPlayback(a.config.Playback.Status)
 	}
 
-	a.containers = components.NewContainerList(containers)
-	a.details = components.NewDetails(a.docker)
+	a.loadAllHosts()
+	containers := a.loadContainers()
+	hosts := a.hostStates()
+	a.header.SetHosts(hosts)
+
+	a.allContainers = containers
+	a.containerList = components.NewContainerList(containers, a.keys)
+	a.containerList.SetHosts(hosts)
+	a.containerList.UpdateContainers(containers)
+	a.containerTable = components.NewContainerTable(containers, a.config.TUI.TableColumns, a.keys)
+	a.containerTable.SetFocusFunc(func(p tview.Primitive) {
+		a.tviewApp.SetFocus(p)
+	})
+	a.containerTable.SetColumnsChangedFunc(func(columns []string) {
+		a.config.TUI.TableColumns = columns
+		a.saveTUIConfig()
+	})
+	a.details = components.NewDetails(a.keys)
+	a.details.SetClientFunc(a.clientFor)
+	a.logPane = components.NewLogPane(a.keys)
+	a.compare = components.NewCompareView(a.keys)
 
-	a.containers.SetSelectedFunc(func(c *models.Container) {
-		a.details.ShowContainer(c)
+	onSelected := func(c *models.Container) {
+		a.showContainer(c)
 		a.refreshContainerStats(c)
-	})
+	}
+	a.containerList.SetSelectedFunc(onSelected)
+	a.containerTable.SetSelectedFunc(onSelected)
 
-	a.focusables = []tview.Primitive{
-		a.containers.GetView(),
-		a.details.GetView(),
+	a.containers = a.containerList
+	if a.config.TUI.ContainerView == components.ViewModeTable {
+		a.containers = a.containerTable
 	}
+
+	a.commands = a.buildCommands()
+	a.palette = components.NewCommandPalette()
+	a.palette.SetSuggestFunc(a.suggest)
+	a.palette.SetCloseFunc(a.closePalette)
+	a.help = components.NewHelpView(a.keys)
+	a.setupResourceViews()
+	a.evaluateAlerts(a.alertHosts())
 }
 
-func (a *App) loadContainers() ([]*models.Container, error) {
-	if a.docker == nil {
-		return nil, fmt.Errorf("docker client not initialized")
+// evaluateAlerts runs the alert rules on the event loop, where container
+// stats are updated.
+func (a *App) evaluateAlerts(hosts []alert.Host) {
+	a.alerts.Evaluate(time.Now(), hosts)
+	a.header.SetAlerts(a.alerts.Firing())
+	a.alertsView.SetAlerts(a.alerts.Alerts())
+}
+
+func (a *App) loadKeymap() error {
+	a.keys = keymap.Default()
+	if err := a.keys.Apply(a.config.TUI.KeyBindings); err != nil {
+		return err
 	}
 
-	containers, err := a.docker.ListContainers(false)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list containers: %w", err)
+	conflicts := a.keys.Conflicts()
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		messages = append(messages, conflict.String())
+	}
+	return fmt.Errorf("conflicting key bindings:\n  %s", strings.Join(messages, "\n  "))
+}
+
+func (a *App) focusedScope() keymap.Scope {
+	if a.resource != "" {
+		return a.resourceScope()
+	}
+	if a.focusables[a.focusIndex] != a.containers.GetView() {
+		return keymap.ScopeDetails
+	}
+	if a.config.TUI.ContainerView == components.ViewModeTable {
+		return keymap.ScopeTable
+	}
+	return keymap.ScopeList
+}
+
+func (a *App) openHelp() {
+	var extra []keymap.Scope
+	if a.config.Playback != nil {
+		extra = append(extra, keymap.ScopeReplay)
 	}
+	a.help.Show(a.focusedScope(), extra...)
+	a.pages.AddPage("help", a.help.GetView(), true, true)
+	a.tviewApp.SetFocus(a.help.GetFocusTarget())
+}
 
-	return toPtrSlice(containers), nil
+func (a *App) closeHelp() {
+	a.pages.RemovePage("help")
+	a.tviewApp.SetFocus(a.focusables[a.focusIndex])
+}
+
+func (a *App) toggleContainerView() {
+	var selectedID string
+	if selected := a.containers.GetSelectedContainer(); selected != nil {
+		selectedID = selected.ID
+	}
+
+	previous := a.containers
+	if a.config.TUI.ContainerView == components.ViewModeTable {
+		a.config.TUI.ContainerView = components.ViewModeList
+		a.containers = a.containerList
+	} else {
+		a.config.TUI.ContainerView = components.ViewModeTable
+		a.containers = a.containerTable
+	}
+
+	if selectedID != "" {
+		a.containers.SelectContainer(selectedID)
+	}
+
+	if a.tviewApp.GetFocus() == previous.GetView() {
+		a.tviewApp.SetFocus(a.containers.GetView())
+	}
+	a.applyLayout()
+	a.saveTUIConfig()
+}
+
+// saveTUIConfig queues the TUI settings for writeTUIConfig. Settings still
+// waiting are replaced, so the latest change is the one saved.
+func (a *App) saveTUIConfig() {
+	if a.config.ConfigPath == "" || a.configSaves == nil {
+		return
+	}
+
+	select {
+	case <-a.configSaves:
+	default:
+	}
+	a.configSaves <- a.config.TUI
+}
+
+// writeTUIConfig saves queued TUI settings one at a time until the app
+// stops, then saves what is still waiting and returns the error of that
+// last write. Failures while the app runs show in the header.
+func (a *App) writeTUIConfig() error {
+	write := func(tuiConfig config.TUIConfig) error {
+		return config.Update(a.config.ConfigPath, func(cfg *config.JSONConfig) {
+			cfg.TUI = tuiConfig
+		})
+	}
+
+	for {
+		select {
+		case <-a.stopChan:
+			select {
+			case tuiConfig := <-a.configSaves:
+				return write(tuiConfig)
+			default:
+			}
+			return nil
+		case tuiConfig := <-a.configSaves:
+			if err := write(tuiConfig); err != nil {
+				a.tviewApp.QueueUpdateDraw(func() {
+					a.header.SetNotice(fmt.Sprintf("Failed to save settings: %v", err))
+				})
+			}
+		}
+	}
 }
 
 func (a *App) refreshContainerStats(container *models.Container) {
-	if a.docker == nil || container.Status != models.StatusRunning {
+	client := a.clientFor(container)
+	if client == nil || container.Status != models.StatusRunning {
 		return
 	}
 
 	go func() {
-		if stats, err := a.docker.GetContainerStats(container.ID); err == nil {
-			container.Stats = stats
+		if stats, err := client.GetContainerStats(container.ID); err == nil {
 			a.tviewApp.QueueUpdateDraw(func() {
-				a.details.ShowContainer(container)
+				container.Stats = stats
+				a.showContainer(container)
 			})
 		}
 	}()
 }
 
+func (a *App) showContainer(container *models.Container) {
+	a.details.ShowContainer(container)
+	if a.bottomPaneVisible() {
+		a.logPane.ShowContainer(container)
+	}
+}
+
 func (a *App) SetNunDBClient(client *nundb.Client) {
 	a.nundb = client
 }
 
 func (a *App) setupLayout() {
-	a.mainGrid = tview.NewGrid().
-		SetRows(3, 0).
-		SetColumns(40, 0).
-		SetBorders(false)
-
-	a.mainGrid.AddItem(a.header.GetView(), 0, 0, 1, 2, 0, 0, false)
-	a.mainGrid.AddItem(a.containers.GetView(), 1, 0, 1, 1, 0, 0, true)
-	a.mainGrid.AddItem(a.details.GetView(), 1, 1, 1, 1, 0, 0, false)
+	a.mainGrid = tview.NewGrid().SetBorders(false)
+	a.pages = tview.NewPages().AddPage("main", a.mainGrid, true, true)
+	a.tviewApp.SetRoot(a.pages, true).EnableMouse(true)
 
-	a.tviewApp.SetRoot(a.mainGrid, true).EnableMouse(true)
+	a.tviewApp.SetFocus(a.containers.GetView())
+	a.applyLayout()
+	a.watchScreenSize()
 }
 
 func (a *App) setupKeyBindings() {
 	a.tviewApp.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEscape:
-			a.quit()
-			return nil
-		case tcell.KeyTab:
-			a.switchFocus()
-			return nil
-		case tcell.KeyF1, tcell.KeyF2, tcell.KeyF3, tcell.KeyF4, tcell.KeyF6:
-			a.switchDetailsTab(event.Key())
-			return nil
+		if a.pages.HasPage("help") {
+			if event.Key() != tcell.KeyUp && event.Key() != tcell.KeyDown &&
+				event.Key() != tcell.KeyPgUp && event.Key() != tcell.KeyPgDn {
+				a.closeHelp()
+				return nil
+			}
+			return event
 		}
 
-		switch event.Rune() {
-		case 'q', 'Q':
-			a.quit()
-			return nil
-		case '1', '2', '3', '4', '5':
-			tabIndex := int(event.Rune() - '1')
-			a.details.SwitchTab(tabIndex)
-			return nil
-		case 's', 'S':
-			a.handleContainerAction("start")
-			return nil
-		case 't', 'T':
-			a.handleContainerAction("stop")
-			return nil
-		case 'p', 'P':
-			a.handleContainerAction("pause")
-			return nil
-		case 'u', 'U':
-			a.handleContainerAction("unpause")
-			return nil
-		case 'd', 'D':
-			a.handleContainerAction("remove")
-			return nil
+		if a.pages.HasPage("confirm") {
+			return event
 		}
 
-		return event
+		if _, typing := a.tviewApp.GetFocus().(*tview.InputField); typing || a.containerTable.IsCapturingInput() {
+			return event
+		}
+
+		if a.config.Playback != nil {
+			if action, ok := a.keys.Match(keymap.ScopeReplay, event); ok {
+				a.controlPlayback(action)
+				return nil
+			}
+		}
+
+		action, ok := a.keys.Match(keymap.ScopeGlobal, event)
+		if !ok {
+			return event
+		}
+		if a.resource != "" && !allowedInResource(action) {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionQuit:
+			a.quit()
+		case keymap.ActionFocusNext:
+			a.switchFocus()
+		case keymap.ActionHelp:
+			a.openHelp()
+		case keymap.ActionPalette:
+			a.openPalette("")
+		case keymap.ActionFind:
+			a.openPalette("goto ")
+		case keymap.ActionToggleView:
+			a.toggleContainerView()
+		case keymap.ActionShrinkPane:
+			a.resizeFocusedPane(-paneStep)
+		case keymap.ActionGrowPane:
+			a.resizeFocusedPane(paneStep)
+		case keymap.ActionZoom:
+			a.toggleZoom()
+		case keymap.ActionLayout:
+			a.cycleLayout()
+		case keymap.ActionLogsPane:
+			a.toggleLogsPane()
+		case keymap.ActionCompare:
+			a.toggleCompare()
+		case keymap.ActionViewImages:
+			a.toggleResource(components.ResourceImages)
+		case keymap.ActionViewVolumes:
+			a.toggleResource(components.ResourceVolumes)
+		case keymap.ActionViewNetworks:
+			a.toggleResource(components.ResourceNetworks)
+		case keymap.ActionViewAlerts:
+			a.toggleResource(components.ResourceAlerts)
+		case keymap.ActionStart, keymap.ActionStop, keymap.ActionRestart,
+			keymap.ActionPause, keymap.ActionUnpause, keymap.ActionRemove:
+			a.handleContainerAction(string(action))
+		case keymap.ActionTabOverview:
+			a.switchTab(components.TAB_OVERVIEW)
+		case keymap.ActionTabStats:
+			a.switchTab(components.TAB_STATS)
+		case keymap.ActionTabNetwork:
+			a.switchTab(components.TAB_NETWORK)
+		case keymap.ActionTabStorage:
+			a.switchTab(components.TAB_STORAGE)
+		case keymap.ActionTabLogs:
+			a.switchTab(components.TAB_LOGS)
+		default:
+			return event
+		}
+		return nil
 	})
 }
 
-func (a *App) switchDetailsTab(key tcell.Key) {
-	var tabIndex int
-	switch key {
-	case tcell.KeyF1:
-		tabIndex = 0
-	case tcell.KeyF2:
-		tabIndex = 1
-	case tcell.KeyF3:
-		tabIndex = 2
-	case tcell.KeyF4:
-		tabIndex = 3
-	case tcell.KeyF6:
-		tabIndex = 4
-	default:
-		return
+func (a *App) controlPlayback(action keymap.Action) {
+	playback := a.config.Playback
+	switch action {
+	case keymap.ActionReplayPause:
+		playback.TogglePause()
+	case keymap.ActionReplayBack:
+		playback.Seek(-seekStep)
+	case keymap.ActionReplayForward:
+		playback.Seek(seekStep)
+	case keymap.ActionReplaySlower:
+		playback.Slower()
+	case keymap.ActionReplayFaster:
+		playback.Faster()
 	}
-	a.details.SwitchTab(tabIndex)
+	a.header.Refresh()
+	a.forceRefresh()
+}
+
+func (a *App) switchTab(tab int) {
+	a.details.SwitchTab(tab)
+	a.compare.SwitchTab(tab)
 }
 
 func (a *App) handleContainerAction(action string) {
-	if a.docker == nil {
+	a.handleContainerActionOn(action, a.containers.GetSelectedContainer())
+}
+
+func (a *App) handleContainerActionOn(action string, selected *models.Container) {
+	client := a.clientFor(selected)
+	if client == nil || selected == nil {
 		return
 	}
-
-	selected := a.containers.GetSelectedContainer()
-	if selected == nil {
+	if a.config.Playback != nil {
+		a.header.SetNotice(fmt.Sprintf("Cannot %s containers while replaying", action))
 		return
 	}
 
@@ -273,28 +541,32 @@ func (a *App) handleContainerAction(action string) {
 		switch action {
 		case "start":
 			if selected.Status != models.StatusRunning {
-				err = a.docker.StartContainer(selected.ID)
+				err = client.StartContainer(selected.ID)
 			}
 		case "stop":
 			if selected.Status == models.StatusRunning {
-				err = a.docker.StopContainer(selected.ID)
+				err = client.StopContainer(selected.ID)
 			}
+		case "restart":
+			err = client.RestartContainer(selected.ID)
 		case "pause":
 			if selected.Status == models.StatusRunning {
-				err = a.docker.PauseContainer(selected.ID)
+				err = client.PauseContainer(selected.ID)
 			}
 		case "unpause":
 			if selected.Status == models.StatusPaused {
-				err = a.docker.UnpauseContainer(selected.ID)
+				err = client.UnpauseContainer(selected.ID)
 			}
 		case "remove":
 			if selected.Status != models.StatusRunning {
-				err = a.docker.RemoveContainer(selected.ID, false)
+				err = client.RemoveContainer(selected.ID, false)
 			}
 		}
 
 		if err != nil {
-			fmt.Printf("Error performing %s action: %v\n", action, err)
+			a.tviewApp.QueueUpdateDraw(func() {
+				a.header.SetNotice(fmt.Sprintf("Failed to %s %s: %v", action, selected.ShortName(), err))
+			})
 		} else {
 			time.Sleep(500 * time.Millisecond)
 			a.forceRefresh()
@@ -314,12 +586,8 @@ func (a *App) switchFocus() {
 func (a *App) quit() {
 	a.isRunning = false
 
-	if a.stopChan != nil {
-		select {
-		case <-a.stopChan:
-		default:
-			close(a.stopChan)
-		}
+	if !a.closeStop() {
+		return
 	}
 
 	if a.refreshTicker != nil {
@@ -335,28 +603,71 @@ func (a *App) quit() {
 	}
 }
 
+// closeStop closes stopChan, reporting false if it was already closed.
+func (a *App) closeStop() bool {
+	select {
+	case <-a.stopChan:
+		return false
+	default:
+		close(a.stopChan)
+		return true
+	}
+}
+
+// Application returns the underlying tview application, to queue updates
+// from other goroutines.
+func (a *App) Application() *tview.Application {
+	return a.tviewApp
+}
+
+// Stop quits a running App as if the user pressed the quit key.
+func (a *App) Stop() {
+	a.quit()
+}
+
 func (a *App) Run() error {
+	if err := a.loadKeymap(); err != nil {
+		return err
+	}
+
+	rules, err := alert.ParseRules(a.config.Alerts)
+	if err != nil {
+		return err
+	}
+	a.alerts = alert.NewEngine(rules)
+
+	activeTheme, err := theme.Load(a.config.TUI.Theme, a.config.TUI.Themes)
+	if err != nil {
+		return err
+	}
+	theme.Use(activeTheme)
+
 	if err := a.initializeDocker(); err != nil {
 		return fmt.Errorf("docker initialization failed: %w", err)
 	}
-
-	defer func() {
-		if a.docker != nil {
-			a.docker.Close()
-		}
-	}()
+	defer a.closeHosts()
 
 	a.initializeComponents()
 	a.setupLayout()
 	a.setupKeyBindings()
 
-	a.tviewApp.SetFocus(a.focusables[0])
-
 	a.isRunning = true
 	a.startAutoRefresh()
+	a.configSaves = make(chan config.TUIConfig, 1)
+	configWritten := make(chan error, 1)
+	go func() {
+		configWritten <- a.writeTUIConfig()
+	}()
 
-	if err := a.tviewApp.Run(); err != nil {
-		return err
+	if a.config.Screen != nil {
+		a.tviewApp.SetScreen(a.config.Screen)
+	}
+
+	runErr := a.tviewApp.Run()
+	// The screen may fail before the user ever quits.
+	a.closeStop()
+	if err := <-configWritten; err != nil && runErr == nil {
+		return fmt.Errorf("failed to save settings: %w", err)
 	}
-	return nil
+	return runErr
 }
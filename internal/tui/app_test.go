@@ -0,0 +1,187 @@
+package tui_test
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/config"
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/tui"
+	"github.com/kqnd/kernus/internal/tui/components"
+	"github.com/kqnd/kernus/internal/tui/tuitest"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+func newHarness(t *testing.T, config *tui.Config) *tuitest.Harness {
+	return tuitest.New(t, config, tuitest.Width, tuitest.Height)
+}
+
+func TestContainerList(t *testing.T) {
+	h := newHarness(t, nil)
+	h.Golden("container_list", *update)
+}
+
+func TestStatsTab(t *testing.T) {
+	h := newHarness(t, nil)
+	h.Press(tcell.KeyEnter)
+	h.Type("2")
+	h.Golden("stats_tab", *update)
+}
+
+func TestDetailTabs(t *testing.T) {
+	tabs := []struct {
+		key    string
+		golden string
+	}{
+		{"1", "overview_tab"},
+		{"3", "network_tab"},
+		{"4", "storage_tab"},
+		{"5", "logs_tab"},
+	}
+	for _, tab := range tabs {
+		t.Run(tab.golden, func(t *testing.T) {
+			h := newHarness(t, nil)
+			h.Press(tcell.KeyEnter)
+			h.Type(tab.key)
+			h.Golden(tab.golden, *update)
+		})
+	}
+}
+
+func TestSettingsSavedOnQuit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	h := newHarness(t, &tui.Config{ConfigPath: path})
+
+	// Quitting right after a change still saves it.
+	h.Type("v")
+	h.Close()
+
+	cfg, err := config.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TUI.ContainerView != components.ViewModeTable {
+		t.Errorf("saved container view = %q, want %q", cfg.TUI.ContainerView, components.ViewModeTable)
+	}
+}
+
+func TestCommandPalette(t *testing.T) {
+	h := newHarness(t, nil)
+	h.Type(":")
+	h.Golden("command_palette", *update)
+
+	h.Type("sto")
+	h.Golden("command_palette_filtered", *update)
+}
+
+func TestCommandPaletteRunsAction(t *testing.T) {
+	runtime := fake.NewRuntime("local")
+	h := newHarness(t, &tui.Config{Runtimes: []docker.ContainerRuntime{runtime}})
+
+	h.Type(":stop nginx-web")
+	h.Press(tcell.KeyEnter)
+	h.Wait(50 * time.Millisecond)
+
+	calls := runtime.Calls()
+	if len(calls) != 1 || calls[0] != "stop abc123456789" {
+		t.Errorf("calls = %v, want nginx-web stopped", calls)
+	}
+}
+
+func TestContainerActionFailureShowsInHeader(t *testing.T) {
+	// Wide enough for the whole notice on one line.
+	h := tuitest.New(t, nil, 200, tuitest.Height)
+
+	h.Type(":start monitoring-grafana")
+	h.Press(tcell.KeyEnter)
+	h.Wait(50 * time.Millisecond)
+
+	if screen := h.Screen(); !strings.Contains(screen, "Failed to start monitoring-grafana: cannot start a paused container") {
+		t.Errorf("header does not show the failed start:\n%s", screen)
+	}
+}
+
+func TestHelpOverlay(t *testing.T) {
+	h := newHarness(t, nil)
+	h.Type("?")
+	h.Golden("help_overlay", *update)
+
+	// Any key closes the overlay without acting on it.
+	h.Type("q")
+	if screen := h.Screen(); strings.Contains(screen, "Key Bindings") {
+		t.Errorf("help overlay still open after a key:\n%s", screen)
+	}
+}
+
+// stubPlayback is a playback clock that only moves when keys move it, so
+// the header it shows is the same on every run.
+type stubPlayback struct {
+	mu       sync.Mutex
+	position time.Duration
+	speed    float64
+	paused   bool
+}
+
+func (p *stubPlayback) TogglePause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = !p.paused
+}
+
+func (p *stubPlayback) Seek(offset time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.position = max(p.position+offset, 0)
+}
+
+func (p *stubPlayback) Faster() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed *= 2
+}
+
+func (p *stubPlayback) Slower() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.speed /= 2
+}
+
+func (p *stubPlayback) Status() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := fmt.Sprintf("12:00:00 (%s / 5m0s) x%g", p.position, p.speed)
+	if p.paused {
+		status += " paused"
+	}
+	return status
+}
+
+func TestReplayHeader(t *testing.T) {
+	playback := &stubPlayback{position: time.Minute, speed: 1}
+	runtime := fake.NewRuntime("local")
+	h := newHarness(t, &tui.Config{
+		Runtimes: []docker.ContainerRuntime{runtime},
+		Playback: playback,
+	})
+	h.Golden("replay_header", *update)
+
+	h.Type(" ..+")
+	h.Golden("replay_header_paused", *update)
+
+	// Container actions are refused while replaying.
+	h.Type("t")
+	if calls := runtime.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %v while replaying, want none", calls)
+	}
+	if screen := h.Screen(); !strings.Contains(screen, "Cannot stop containers while replaying") {
+		t.Errorf("header does not explain the refused stop:\n%s", screen)
+	}
+}
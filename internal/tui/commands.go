@@ -0,0 +1,284 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/rivo/tview"
+)
+
+const (
+	argNone      = ""
+	argContainer = "container"
+	argTab       = "tab"
+)
+
+type paletteCommand struct {
+	name        string
+	description string
+	action      keymap.Action
+	arg         string
+	run         func(target *models.Container, arg string)
+}
+
+func (a *App) buildCommands() []paletteCommand {
+	containerAction := func(action string) func(*models.Container, string) {
+		return func(target *models.Container, _ string) {
+			a.handleContainerActionOn(action, target)
+		}
+	}
+
+	return []paletteCommand{
+		{name: "help", description: "Show key bindings", action: keymap.ActionHelp, run: func(*models.Container, string) {
+			a.openHelp()
+		}},
+		{name: "start", description: "Start container", action: keymap.ActionStart, arg: argContainer, run: containerAction("start")},
+		{name: "stop", description: "Stop container", action: keymap.ActionStop, arg: argContainer, run: containerAction("stop")},
+		{name: "restart", description: "Restart container", action: keymap.ActionRestart, arg: argContainer, run: containerAction("restart")},
+		{name: "pause", description: "Pause container", action: keymap.ActionPause, arg: argContainer, run: containerAction("pause")},
+		{name: "unpause", description: "Unpause container", action: keymap.ActionUnpause, arg: argContainer, run: containerAction("unpause")},
+		{name: "remove", description: "Remove stopped container", action: keymap.ActionRemove, arg: argContainer, run: containerAction("remove")},
+		{name: "logs", description: "Show container logs", action: keymap.ActionTabLogs, arg: argContainer, run: func(target *models.Container, _ string) {
+			a.jumpToContainer(target)
+			a.details.SwitchTabByName("logs")
+			a.compare.SwitchTabByName("logs")
+		}},
+		{name: "goto", description: "Jump to container", action: keymap.ActionFind, arg: argContainer, run: func(target *models.Container, _ string) {
+			a.jumpToContainer(target)
+		}},
+		{name: "tab", description: "Switch details tab", arg: argTab, run: func(_ *models.Container, arg string) {
+			a.details.SwitchTabByName(arg)
+			a.compare.SwitchTabByName(arg)
+		}},
+		{name: "view", description: "Toggle list/table view", action: keymap.ActionToggleView, run: func(*models.Container, string) {
+			a.toggleContainerView()
+		}},
+		{name: "compare", description: "Compare selected container with another", action: keymap.ActionCompare, arg: argContainer, run: func(target *models.Container, _ string) {
+			if a.comparing {
+				a.stopCompare()
+			}
+			a.startCompare(a.containers.GetSelectedContainer(), target)
+		}},
+		{name: "images", description: "Toggle images view", action: keymap.ActionViewImages, run: func(*models.Container, string) {
+			a.toggleResource(components.ResourceImages)
+		}},
+		{name: "volumes", description: "Toggle volumes view", action: keymap.ActionViewVolumes, run: func(*models.Container, string) {
+			a.toggleResource(components.ResourceVolumes)
+		}},
+		{name: "networks", description: "Toggle networks view", action: keymap.ActionViewNetworks, run: func(*models.Container, string) {
+			a.toggleResource(components.ResourceNetworks)
+		}},
+		{name: "alerts", description: "Toggle alerts view", action: keymap.ActionViewAlerts, run: func(*models.Container, string) {
+			a.toggleResource(components.ResourceAlerts)
+		}},
+		{name: "zoom", description: "Maximize/restore details pane", action: keymap.ActionZoom, run: func(*models.Container, string) {
+			a.toggleZoom()
+		}},
+		{name: "layout", description: "Cycle auto/side-by-side/stacked layout", action: keymap.ActionLayout, run: func(*models.Container, string) {
+			a.cycleLayout()
+		}},
+		{name: "logpane", description: "Toggle logs pane under details", action: keymap.ActionLogsPane, run: func(*models.Container, string) {
+			a.toggleLogsPane()
+		}},
+		{name: "focus", description: "Switch focused pane", action: keymap.ActionFocusNext, run: func(*models.Container, string) {
+			a.switchFocus()
+		}},
+		{name: "quit", description: "Quit kernus", action: keymap.ActionQuit, run: func(*models.Container, string) {
+			a.quit()
+		}},
+	}
+}
+
+func (a *App) suggest(query string) []components.PaletteItem {
+	name, arg, hasArg := strings.Cut(strings.TrimLeft(query, " "), " ")
+
+	if !hasArg {
+		items := a.suggestCommands(name)
+		return append(items, a.suggestContainers(name, nil)...)
+	}
+
+	command := a.findCommand(name)
+	if command == nil {
+		return nil
+	}
+
+	arg = strings.TrimSpace(arg)
+	switch command.arg {
+	case argContainer:
+		return a.suggestContainers(arg, command)
+	case argTab:
+		return a.suggestTabs(arg, command)
+	default:
+		return a.suggestCommands(name)
+	}
+}
+
+func (a *App) suggestCommands(query string) []components.PaletteItem {
+	type scored struct {
+		command paletteCommand
+		score   int
+	}
+
+	var matches []scored
+	for _, command := range a.commands {
+		if score, ok := components.FuzzyScore(query, command.name); ok {
+			matches = append(matches, scored{command, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	items := make([]components.PaletteItem, 0, len(matches))
+	for _, match := range matches {
+		command := match.command
+		label := fmt.Sprintf("[title]%s[text]", command.name)
+		if command.arg != argNone {
+			label += fmt.Sprintf(" [muted]<%s>[text]", command.arg)
+		}
+
+		hint := command.description
+		if keys := a.keys.KeysFor(keymap.ScopeGlobal, command.action); keys != "" {
+			hint += "  " + tview.Escape("["+keys+"]")
+		}
+
+		item := components.PaletteItem{
+			Label:      label,
+			Hint:       hint,
+			Completion: command.name + " ",
+		}
+		if command.arg != argTab {
+			item.Run = func() {
+				command.run(a.containers.GetSelectedContainer(), "")
+			}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (a *App) suggestContainers(query string, command *paletteCommand) []components.PaletteItem {
+	type scored struct {
+		container *models.Container
+		score     int
+	}
+
+	var matches []scored
+	for _, container := range a.allContainers {
+		best, found := 0, false
+		for _, field := range []string{container.ShortName(), container.ShortID(), container.Image} {
+			if score, ok := components.FuzzyScore(query, field); ok && (!found || score > best) {
+				best, found = score, true
+			}
+		}
+		if found {
+			matches = append(matches, scored{container, best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	items := make([]components.PaletteItem, 0, len(matches))
+	for _, match := range matches {
+		container := match.container
+		label := fmt.Sprintf("[%s]%s[text] %s", container.Status.Role(), container.Status.Icon(), container.ShortName())
+		hint := fmt.Sprintf("%s  %s", container.ShortID(), container.Image)
+
+		if command == nil {
+			items = append(items, components.PaletteItem{
+				Label:      "→ " + label,
+				Hint:       hint,
+				Completion: "goto " + container.ShortName(),
+				Run:        func() { a.jumpToContainer(container) },
+			})
+			continue
+		}
+
+		run := command.run
+		items = append(items, components.PaletteItem{
+			Label:      fmt.Sprintf("[title]%s[text] %s", command.name, label),
+			Hint:       hint,
+			Completion: command.name + " " + container.ShortName(),
+			Run:        func() { run(container, "") },
+		})
+	}
+	return items
+}
+
+func (a *App) suggestTabs(query string, command *paletteCommand) []components.PaletteItem {
+	tabActions := []keymap.Action{
+		keymap.ActionTabOverview,
+		keymap.ActionTabStats,
+		keymap.ActionTabNetwork,
+		keymap.ActionTabStorage,
+		keymap.ActionTabLogs,
+	}
+
+	var items []components.PaletteItem
+	for i, tab := range a.details.TabNames() {
+		if _, ok := components.FuzzyScore(query, tab); !ok {
+			continue
+		}
+		tabName := tab
+		run := command.run
+		items = append(items, components.PaletteItem{
+			Label:      fmt.Sprintf("[title]%s[text] %s", command.name, strings.ToLower(tab)),
+			Hint:       tview.Escape("[" + a.keys.KeysFor(keymap.ScopeGlobal, tabActions[i]) + "]"),
+			Completion: command.name + " " + strings.ToLower(tab),
+			Run:        func() { run(nil, tabName) },
+		})
+	}
+	return items
+}
+
+func (a *App) findCommand(name string) *paletteCommand {
+	for i := range a.commands {
+		if a.commands[i].name == name {
+			return &a.commands[i]
+		}
+	}
+
+	var best *paletteCommand
+	bestScore := 0
+	for i := range a.commands {
+		if score, ok := components.FuzzyScore(name, a.commands[i].name); ok && (best == nil || score > bestScore) {
+			best, bestScore = &a.commands[i], score
+		}
+	}
+	return best
+}
+
+func (a *App) jumpToContainer(container *models.Container) {
+	if container == nil {
+		return
+	}
+
+	a.containers.SelectContainer(container.ID)
+	a.showContainer(container)
+	a.refreshContainerStats(container)
+}
+
+// jumpToAlert leaves the alerts view for the container an alert is about.
+func (a *App) jumpToAlert(target alert.Alert) {
+	for _, container := range a.allContainers {
+		if container.ID == target.ContainerID && container.Host == target.Host {
+			a.toggleResource(components.ResourceAlerts)
+			a.jumpToContainer(container)
+			return
+		}
+	}
+	a.header.SetNotice(fmt.Sprintf("%s is gone", target.Subject()))
+}
+
+func (a *App) openPalette(prefix string) {
+	a.palette.Open(prefix)
+	a.pages.AddPage("palette", a.palette.GetView(), true, true)
+	a.tviewApp.SetFocus(a.palette.GetInput())
+}
+
+func (a *App) closePalette() {
+	a.pages.RemovePage("palette")
+	a.tviewApp.SetFocus(a.focusables[a.focusIndex])
+}
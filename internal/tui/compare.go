@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/rivo/tview"
+)
+
+func (a *App) detailsView() tview.Primitive {
+	if a.comparing {
+		return a.compare.GetView()
+	}
+	return a.details.GetView()
+}
+
+// toggleCompare drives compare mode from a single key: the first press marks
+// the selected container, a press on a different container opens the split
+// view and a press while comparing closes it.
+func (a *App) toggleCompare() {
+	if a.comparing {
+		a.stopCompare()
+		return
+	}
+
+	selected := a.containers.GetSelectedContainer()
+	if selected == nil {
+		return
+	}
+
+	if a.compareMarked == nil || a.compareMarked.ID == selected.ID {
+		a.compareMarked = selected
+		a.header.SetNotice(fmt.Sprintf("Marked %s, select another container and press %s to compare",
+			selected.ShortName(), a.keys.KeysFor(keymap.ScopeGlobal, keymap.ActionCompare)))
+		return
+	}
+
+	a.startCompare(a.compareMarked, selected)
+}
+
+func (a *App) startCompare(left, right *models.Container) {
+	if left == nil || right == nil || left.ID == right.ID {
+		return
+	}
+
+	a.compareMarked = nil
+	a.comparing = true
+	a.compare.SetContainers(left, right)
+	a.header.SetNotice(fmt.Sprintf("Comparing %s with %s (%s to exit)",
+		left.ShortName(), right.ShortName(), a.keys.KeysFor(keymap.ScopeGlobal, keymap.ActionCompare)))
+
+	a.tviewApp.SetFocus(a.compare.GetView())
+	a.applyLayout()
+	a.loadCompare(left, right)
+}
+
+func (a *App) stopCompare() {
+	a.comparing = false
+	a.header.SetNotice("")
+	a.tviewApp.SetFocus(a.details.GetView())
+	a.applyLayout()
+}
+
+// loadCompare inspects copies of the compared containers off the event
+// loop, so the table and details never see them change, and shows the
+// copies once loaded if the same pair is still compared. It must run on the
+// event loop; a load already in progress makes it a no-op.
+func (a *App) loadCompare(left, right *models.Container) {
+	if a.compareLoading {
+		return
+	}
+	a.compareLoading = true
+
+	loadedLeft, loadedRight := *left, *right
+	leftClient, rightClient := a.clientFor(left), a.clientFor(right)
+	go func() {
+		if leftClient != nil {
+			leftClient.LoadContainerConfig(&loadedLeft)
+		}
+		if rightClient != nil {
+			rightClient.LoadContainerConfig(&loadedRight)
+		}
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.compareLoading = false
+			if !a.comparing {
+				return
+			}
+			if current, other := a.compare.Containers(); current.ID == loadedLeft.ID && other.ID == loadedRight.ID {
+				a.compare.SetContainers(&loadedLeft, &loadedRight)
+			}
+		})
+	}()
+}
+
+// refreshCompare reloads the compared containers from their freshly listed
+// copies, which already carry live stats, so restart counts and
+// configuration stay current. It must run on the event loop.
+func (a *App) refreshCompare(containers []*models.Container) {
+	if !a.comparing {
+		return
+	}
+
+	left, right := a.compare.Containers()
+	var freshLeft, freshRight *models.Container
+	for _, container := range containers {
+		switch container.ID {
+		case left.ID:
+			freshLeft = container
+		case right.ID:
+			freshRight = container
+		}
+	}
+	if freshLeft == nil || freshRight == nil {
+		return
+	}
+	a.loadCompare(freshLeft, freshRight)
+}
@@ -0,0 +1,245 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+const ResourceAlerts = "alerts"
+
+type AlertsView struct {
+	layout    *tview.Flex
+	table     *tview.Table
+	info      *tview.TextView
+	formatter *details.Formatter
+	keys      *keymap.Keymap
+
+	rules  []alert.Rule
+	alerts []alert.Alert
+
+	onSelected func(alert.Alert)
+}
+
+func NewAlertsView(keys *keymap.Keymap, rules []alert.Rule) *AlertsView {
+	av := &AlertsView{
+		table:     tview.NewTable(),
+		info:      tview.NewTextView(),
+		formatter: details.NewFormatter(),
+		keys:      keys,
+		rules:     rules,
+	}
+
+	av.setupView()
+	av.setupKeyBindings()
+	av.refreshView()
+	return av
+}
+
+func (av *AlertsView) setupView() {
+	av.table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	av.table.SetBorder(true)
+
+	av.table.SetSelectionChangedFunc(func(row, column int) {
+		if a := av.alertAt(row); a != nil {
+			av.showAlert(a)
+		}
+	})
+
+	av.info.SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetBorder(true).
+		SetTitle(" Alert ")
+
+	av.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(av.table, 0, 3, true).
+		AddItem(av.info, 0, 2, false)
+}
+
+func (av *AlertsView) setupKeyBindings() {
+	av.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := av.keys.Match(keymap.ScopeAlerts, event)
+		if !ok {
+			return event
+		}
+
+		if action == keymap.ActionSelect {
+			if a := av.GetSelectedAlert(); a != nil && a.ContainerID != "" && av.onSelected != nil {
+				av.onSelected(*a)
+			}
+			return nil
+		}
+		return event
+	})
+}
+
+// SetSelectedFunc is called with the alert picked with the select key, for
+// alerts about a container.
+func (av *AlertsView) SetSelectedFunc(fn func(alert.Alert)) {
+	av.onSelected = fn
+}
+
+func (av *AlertsView) SetAlerts(alerts []alert.Alert) {
+	var selected string
+	if a := av.GetSelectedAlert(); a != nil {
+		selected = alertKey(a)
+	}
+
+	av.alerts = alerts
+	av.refreshView()
+
+	for i := range av.alerts {
+		if alertKey(&av.alerts[i]) == selected {
+			av.table.Select(i+1, 0)
+			av.showAlert(&av.alerts[i])
+			return
+		}
+	}
+	if a := av.GetSelectedAlert(); a != nil {
+		av.showAlert(a)
+	}
+}
+
+func alertKey(a *alert.Alert) string {
+	return a.Rule + "|" + a.Host + "|" + a.ContainerID
+}
+
+func (av *AlertsView) refreshView() {
+	headers := []string{"STATE", "RULE", "SUBJECT", "VALUE", "FOR"}
+	now := time.Now()
+
+	av.table.Clear()
+	for col, header := range headers {
+		av.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(theme.TcellColor("title")).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	counts := make(map[alert.State]int)
+	for row := range av.alerts {
+		a := &av.alerts[row]
+		counts[a.State]++
+		cells := []string{
+			string(a.State),
+			a.Rule,
+			a.Subject(),
+			a.Value,
+			models.FormatDuration(a.Duration(now)),
+		}
+		for col, text := range cells {
+			cell := tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(theme.TcellColor("text")).
+				SetExpansion(1)
+			if col == 0 {
+				cell.SetTextColor(theme.TcellColor(stateRole(a.State)))
+			}
+			av.table.SetCell(row+1, col, cell)
+		}
+	}
+
+	av.table.SetTitle(fmt.Sprintf(" Alerts (%d firing, %d pending, %d resolved, %d rules) ",
+		counts[alert.StateFiring], counts[alert.StatePending], counts[alert.StateResolved], len(av.rules)))
+
+	if len(av.alerts) > 0 {
+		row, _ := av.table.GetSelection()
+		if row < 1 || row > len(av.alerts) {
+			av.table.Select(1, 0)
+		}
+		return
+	}
+
+	av.info.SetTitle(" Alert ")
+	if len(av.rules) == 0 {
+		av.info.SetText(theme.Apply(`[muted]No alert rules. Add them to "alerts" in config.json, for instance:[text]
+
+  {"name": "busy", "rule": "cpu > 90% for 2m"}
+  {"rule": "memory > 85% of limit"}
+  {"rule": "health == unhealthy"}
+  {"rule": "restarts increased"}
+  {"rule": "machine offline > 60s"}`))
+		return
+	}
+
+	var rules strings.Builder
+	rules.WriteString("[success]No alerts[text]\n\n[title]Rules[text]\n")
+	for _, rule := range av.rules {
+		rules.WriteString(fmt.Sprintf("  [accent]%s[text] %s\n", tview.Escape(rule.Name), tview.Escape(rule.Expr)))
+	}
+	av.info.SetText(theme.Apply(rules.String()))
+}
+
+func stateRole(state alert.State) string {
+	switch state {
+	case alert.StateFiring:
+		return "error"
+	case alert.StatePending:
+		return "warning"
+	default:
+		return "success"
+	}
+}
+
+func (av *AlertsView) showAlert(a *alert.Alert) {
+	av.info.SetTitle(fmt.Sprintf(" Alert - %s ", tview.Escape(a.Rule)))
+	av.info.SetText(theme.Apply(av.renderAlert(a)))
+	av.info.ScrollToBeginning()
+}
+
+func (av *AlertsView) renderAlert(a *alert.Alert) string {
+	now := time.Now()
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[%s]%s[text] [title]%s[text]\n", stateRole(a.State), a.State, tview.Escape(a.Rule)))
+	result.WriteString(fmt.Sprintf("  Rule     : %s\n", tview.Escape(a.Expr)))
+	result.WriteString(fmt.Sprintf("  Host     : %s\n", tview.Escape(a.Host)))
+	if a.Container != "" {
+		result.WriteString(fmt.Sprintf("  Container: %s (%s)\n", tview.Escape(a.Container), shortAlertID(a.ContainerID)))
+	}
+	result.WriteString(fmt.Sprintf("  Value    : %s\n", tview.Escape(a.Value)))
+	result.WriteString(fmt.Sprintf("  Since    : %s (%s ago)\n", av.formatter.FormatTime(a.Since), models.FormatDuration(now.Sub(a.Since))))
+	if !a.FiredAt.IsZero() {
+		result.WriteString(fmt.Sprintf("  Fired    : %s (pending %s)\n", av.formatter.FormatTime(a.FiredAt), models.FormatDuration(a.FiredAt.Sub(a.Since))))
+	}
+	if a.State == alert.StateResolved {
+		result.WriteString(fmt.Sprintf("  Resolved : %s (fired %s)\n", av.formatter.FormatTime(a.ResolvedAt), models.FormatDuration(a.Duration(now))))
+	}
+	if a.ContainerID != "" {
+		result.WriteString(fmt.Sprintf("\n[dim]Press %s to go to the container[text]",
+			av.keys.KeysFor(keymap.ScopeAlerts, keymap.ActionSelect)))
+	}
+	return result.String()
+}
+
+func shortAlertID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
+
+func (av *AlertsView) alertAt(row int) *alert.Alert {
+	if row < 1 || row > len(av.alerts) {
+		return nil
+	}
+	return &av.alerts[row-1]
+}
+
+func (av *AlertsView) GetSelectedAlert() *alert.Alert {
+	row, _ := av.table.GetSelection()
+	return av.alertAt(row)
+}
+
+func (av *AlertsView) GetView() tview.Primitive {
+	return av.layout
+}
@@ -0,0 +1,157 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+type comparePane struct {
+	view      *tview.TextView
+	container *models.Container
+
+	overviewTab *details.OverviewTab
+	statsTab    *details.StatsTab
+	networkTab  *details.NetworkTab
+	storageTab  *details.StorageTab
+	logsTab     *details.LogsTab
+}
+
+type CompareView struct {
+	layout     *tview.Flex
+	left       *comparePane
+	right      *comparePane
+	compareTab *details.CompareTab
+	tabs       []string
+	currentTab int
+	keys       *keymap.Keymap
+}
+
+func newComparePane() *comparePane {
+	p := &comparePane{
+		view: tview.NewTextView().
+			SetDynamicColors(true).
+			SetWordWrap(true).
+			SetScrollable(true),
+		overviewTab: details.NewOverviewTab(),
+		statsTab:    details.NewStatsTab(),
+		networkTab:  details.NewNetworkTab(),
+		storageTab:  details.NewStorageTab(),
+		logsTab:     details.NewLogsTab(),
+	}
+	p.view.SetBorder(true)
+	return p
+}
+
+func NewCompareView(keys *keymap.Keymap) *CompareView {
+	cv := &CompareView{
+		left:       newComparePane(),
+		right:      newComparePane(),
+		compareTab: details.NewCompareTab(),
+		tabs:       []string{"Overview", "Stats", "Network", "Storage", "Logs"},
+		currentTab: TAB_OVERVIEW,
+		keys:       keys,
+	}
+
+	cv.layout = tview.NewFlex().
+		AddItem(cv.left.view, 0, 1, true).
+		AddItem(cv.right.view, 0, 1, false)
+
+	cv.layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := cv.keys.Match(keymap.ScopeDetails, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionPrevTab:
+			cv.SwitchTab((cv.currentTab - 1 + len(cv.tabs)) % len(cv.tabs))
+			return nil
+		case keymap.ActionNextTab:
+			cv.SwitchTab((cv.currentTab + 1) % len(cv.tabs))
+			return nil
+		}
+		return event
+	})
+
+	return cv
+}
+
+func (cv *CompareView) SetContainers(left, right *models.Container) {
+	cv.left.container = left
+	cv.right.container = right
+	cv.updateView()
+}
+
+func (cv *CompareView) Containers() (*models.Container, *models.Container) {
+	return cv.left.container, cv.right.container
+}
+
+func (cv *CompareView) SwitchTab(tab int) {
+	if tab >= 0 && tab < len(cv.tabs) {
+		cv.currentTab = tab
+		cv.updateView()
+	}
+}
+
+func (cv *CompareView) SwitchTabByName(name string) bool {
+	for i, tab := range cv.tabs {
+		if strings.EqualFold(tab, name) {
+			cv.SwitchTab(i)
+			return true
+		}
+	}
+	return false
+}
+
+func (cv *CompareView) updateView() {
+	if cv.left.container == nil || cv.right.container == nil {
+		return
+	}
+
+	cv.render(cv.left, cv.right.container, true)
+	cv.render(cv.right, cv.left.container, false)
+}
+
+func (cv *CompareView) render(pane *comparePane, other *models.Container, left bool) {
+	c := pane.container
+	pane.view.SetTitle(fmt.Sprintf(" %s - %s ", cv.tabs[cv.currentTab], c.ShortName()))
+
+	var content string
+	switch cv.currentTab {
+	case TAB_STATS:
+		content = cv.compareTab.RenderStatsOverlay(c, other) + "\n" + pane.statsTab.Render(c)
+	case TAB_NETWORK:
+		content = pane.networkTab.Render(c)
+	case TAB_STORAGE:
+		content = pane.storageTab.Render(c)
+	case TAB_LOGS:
+		content = pane.logsTab.Render(c)
+	default:
+		content = cv.compareTab.RenderDifferences(c, other, left) + "\n" + pane.overviewTab.Render(c)
+	}
+
+	pane.view.SetText(theme.Apply(cv.buildTabHeader() + content))
+}
+
+func (cv *CompareView) buildTabHeader() string {
+	var tabs []string
+	for i, tab := range cv.tabs {
+		if i == cv.currentTab {
+			tabs = append(tabs, fmt.Sprintf("[text]> %s <[text]", tab))
+		} else {
+			tabs = append(tabs, fmt.Sprintf("[muted]  %s  [text]", tab))
+		}
+	}
+	return fmt.Sprintf("%s\n\n", strings.Join(tabs, ""))
+}
+
+func (cv *CompareView) GetView() tview.Primitive {
+	return cv.layout
+}
@@ -0,0 +1,570 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+type TableColumn struct {
+	ID    string
+	Title string
+	Align int
+	Text  func(c *models.Container) string
+	Color func(c *models.Container) tcell.Color
+	Less  func(a, b *models.Container) bool
+}
+
+var DefaultTableColumns = []string{"name", "image", "status", "health", "cpu", "mem", "net", "ports", "uptime", "project"}
+
+type ContainerTable struct {
+	layout *tview.Flex
+	table  *tview.Table
+	filter *tview.InputField
+	pages  *tview.Pages
+
+	containers []*models.Container
+	rows       []*models.Container
+	columns    []*TableColumn
+	visible    []string
+
+	sortColumn  string
+	sortDesc    bool
+	filterQuery string
+
+	onSelected       func(*models.Container)
+	onColumnsChanged func([]string)
+	setFocus         func(tview.Primitive)
+	keys             *keymap.Keymap
+}
+
+func NewContainerTable(containers []*models.Container, visibleColumns []string, keys *keymap.Keymap) *ContainerTable {
+	ct := &ContainerTable{
+		keys:       keys,
+		table:      tview.NewTable(),
+		filter:     tview.NewInputField(),
+		pages:      tview.NewPages(),
+		containers: containers,
+		columns:    buildTableColumns(),
+		sortColumn: "name",
+	}
+
+	ct.SetVisibleColumns(visibleColumns)
+	ct.setupView()
+	ct.setupKeyBindings()
+	ct.refreshView()
+	return ct
+}
+
+func buildTableColumns() []*TableColumn {
+	formatter := details.NewFormatter()
+
+	return []*TableColumn{
+		{
+			ID:    "name",
+			Title: "NAME",
+			Text:  func(c *models.Container) string { return c.ShortName() },
+			Less:  func(a, b *models.Container) bool { return a.ShortName() < b.ShortName() },
+		},
+		{
+			ID:    "image",
+			Title: "IMAGE",
+			Text:  func(c *models.Container) string { return formatter.TruncateString(c.Image, 30) },
+			Less:  func(a, b *models.Container) bool { return a.Image < b.Image },
+		},
+		{
+			ID:    "status",
+			Title: "STATUS",
+			Text:  func(c *models.Container) string { return fmt.Sprintf("%s %s", c.Status.Icon(), c.Status) },
+			Color: func(c *models.Container) tcell.Color { return theme.TcellColor(c.Status.Role()) },
+			Less:  func(a, b *models.Container) bool { return a.Status < b.Status },
+		},
+		{
+			ID:    "health",
+			Title: "HEALTH",
+			Text: func(c *models.Container) string {
+				health := c.HealthStatus()
+				return fmt.Sprintf("%s %s", health.Icon(), health)
+			},
+			Color: func(c *models.Container) tcell.Color { return theme.TcellColor(c.HealthStatus().Role()) },
+			Less:  func(a, b *models.Container) bool { return a.HealthStatus() < b.HealthStatus() },
+		},
+		{
+			ID:    "cpu",
+			Title: "CPU%",
+			Align: tview.AlignRight,
+			Text: func(c *models.Container) string {
+				if c.Stats == nil {
+					return "─"
+				}
+				return fmt.Sprintf("%.1f%%", c.Stats.CPU.Usage)
+			},
+			Color: func(c *models.Container) tcell.Color {
+				if c.Stats == nil {
+					return theme.TcellColor("muted")
+				}
+				return theme.TcellColor(formatter.GetUsageRole(c.Stats.CPU.QuotaPercentage()))
+			},
+			Less: func(a, b *models.Container) bool { return a.GetCPUUsage() < b.GetCPUUsage() },
+		},
+		{
+			ID:    "mem",
+			Title: "MEM",
+			Align: tview.AlignRight,
+			Text: func(c *models.Container) string {
+				if c.Stats == nil {
+					return "─"
+				}
+				return formatter.FormatBytes(c.Stats.Memory.Usage)
+			},
+			Color: func(c *models.Container) tcell.Color {
+				if c.Stats == nil {
+					return theme.TcellColor("muted")
+				}
+				return theme.TcellColor(formatter.GetUsageRole(c.Stats.Memory.Percentage()))
+			},
+			Less: func(a, b *models.Container) bool { return a.GetMemoryUsage() < b.GetMemoryUsage() },
+		},
+		{
+			ID:    "net",
+			Title: "NET I/O",
+			Align: tview.AlignRight,
+			Text: func(c *models.Container) string {
+				if c.Stats == nil {
+					return "─"
+				}
+				return fmt.Sprintf("↓%s ↑%s",
+					formatter.FormatRate(c.Stats.Network.Rate.RxBytes),
+					formatter.FormatRate(c.Stats.Network.Rate.TxBytes))
+			},
+			Less: func(a, b *models.Container) bool { return networkRate(a) < networkRate(b) },
+		},
+		{
+			ID:    "ports",
+			Title: "PORTS",
+			Text:  func(c *models.Container) string { return c.ShortPort() },
+			Less:  func(a, b *models.Container) bool { return mainPortNumber(a) < mainPortNumber(b) },
+		},
+		{
+			ID:    "uptime",
+			Title: "UPTIME",
+			Align: tview.AlignRight,
+			Text:  func(c *models.Container) string { return c.FormatUptime() },
+			Less:  func(a, b *models.Container) bool { return a.Uptime() < b.Uptime() },
+		},
+		{
+			ID:    "project",
+			Title: "PROJECT",
+			Text: func(c *models.Container) string {
+				if project := c.ComposeProject(); project != "" {
+					return project
+				}
+				return "─"
+			},
+			Less: func(a, b *models.Container) bool { return a.ComposeProject() < b.ComposeProject() },
+		},
+		{
+			ID:    "host",
+			Title: "HOST",
+			Text:  func(c *models.Container) string { return c.Host },
+			Less:  func(a, b *models.Container) bool { return a.Host < b.Host },
+		},
+		{
+			ID:    "pod",
+			Title: "POD",
+			Text: func(c *models.Container) string {
+				if c.Pod != "" {
+					return c.Pod
+				}
+				return "─"
+			},
+			Less: func(a, b *models.Container) bool { return a.Pod < b.Pod },
+		},
+	}
+}
+
+func networkRate(c *models.Container) float64 {
+	if c.Stats == nil {
+		return 0
+	}
+	return c.Stats.Network.Rate.RxBytes + c.Stats.Network.Rate.TxBytes
+}
+
+func mainPortNumber(c *models.Container) int {
+	if len(c.Ports) == 0 {
+		return 0
+	}
+	for _, port := range c.Ports {
+		if port.PublicPort > 0 {
+			return port.PublicPort
+		}
+	}
+	return c.Ports[0].PrivatePort
+}
+
+func (ct *ContainerTable) setupView() {
+	ct.table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 1)
+
+	ct.table.SetSelectedFunc(func(row, column int) {
+		if c := ct.containerAt(row); c != nil && ct.onSelected != nil {
+			ct.onSelected(c)
+		}
+	})
+
+	ct.filter.SetLabel("Filter: ").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetPlaceholder(fmt.Sprintf("name, image or label (press %s to edit)", ct.keys.KeysFor(keymap.ScopeTable, keymap.ActionFilter)))
+
+	ct.filter.SetChangedFunc(func(text string) {
+		ct.filterQuery = strings.TrimSpace(text)
+		ct.refreshView()
+	})
+
+	ct.filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			ct.filter.SetText("")
+		}
+		ct.focus(ct.table)
+	})
+
+	ct.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(ct.table, 0, 1, true).
+		AddItem(ct.filter, 1, 0, false)
+	ct.layout.SetBorder(true)
+
+	ct.pages.AddPage("table", ct.layout, true, true)
+}
+
+func (ct *ContainerTable) setupKeyBindings() {
+	ct.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := ct.keys.Match(keymap.ScopeTable, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionSelect:
+			if c := ct.GetSelectedContainer(); c != nil && ct.onSelected != nil {
+				ct.onSelected(c)
+			}
+			return nil
+		case keymap.ActionFilter:
+			ct.focus(ct.filter)
+			return nil
+		case keymap.ActionSortPrev:
+			ct.shiftSortColumn(-1)
+			return nil
+		case keymap.ActionSortNext:
+			ct.shiftSortColumn(1)
+			return nil
+		case keymap.ActionSortReverse:
+			ct.sortDesc = !ct.sortDesc
+			ct.refreshView()
+			return nil
+		case keymap.ActionColumns:
+			ct.showColumnChooser()
+			return nil
+		}
+		return event
+	})
+}
+
+func (ct *ContainerTable) focus(p tview.Primitive) {
+	if ct.setFocus != nil {
+		ct.setFocus(p)
+	}
+}
+
+func (ct *ContainerTable) shiftSortColumn(delta int) {
+	visible := ct.visibleColumns()
+	if len(visible) == 0 {
+		return
+	}
+
+	index := 0
+	for i, column := range visible {
+		if column.ID == ct.sortColumn {
+			index = i
+			break
+		}
+	}
+	index = (index + delta + len(visible)) % len(visible)
+	ct.sortColumn = visible[index].ID
+	ct.refreshView()
+}
+
+func (ct *ContainerTable) showColumnChooser() {
+	list := tview.NewList().ShowSecondaryText(false)
+	list.SetBorder(true).SetTitle(" Columns (Enter toggles, Esc closes) ")
+
+	var render func()
+	render = func() {
+		current := list.GetCurrentItem()
+		list.Clear()
+		for _, column := range ct.columns {
+			mark := "[ ]"
+			if ct.isVisible(column.ID) {
+				mark = "[x]"
+			}
+			list.AddItem(fmt.Sprintf("%s %s", tview.Escape(mark), column.Title), "", 0, nil)
+		}
+		list.SetCurrentItem(current)
+	}
+	render()
+
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		ct.toggleColumn(ct.columns[index].ID)
+		render()
+	})
+
+	list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			ct.pages.RemovePage("columns")
+			ct.focus(ct.table)
+			return nil
+		}
+		return event
+	})
+
+	modal := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(list, len(ct.columns)+2, 0, true).
+			AddItem(nil, 0, 1, false), 30, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	ct.pages.AddPage("columns", modal, true, true)
+	ct.focus(list)
+}
+
+func (ct *ContainerTable) toggleColumn(id string) {
+	visible := make([]string, 0, len(ct.visible))
+	removed := false
+	for _, v := range ct.visible {
+		if v == id {
+			removed = true
+			continue
+		}
+		visible = append(visible, v)
+	}
+
+	if !removed {
+		visible = visible[:0]
+		for _, column := range ct.columns {
+			if column.ID == id || ct.isVisible(column.ID) {
+				visible = append(visible, column.ID)
+			}
+		}
+	}
+
+	if len(visible) == 0 {
+		return
+	}
+
+	ct.visible = visible
+	ct.refreshView()
+
+	if ct.onColumnsChanged != nil {
+		ct.onColumnsChanged(append([]string(nil), ct.visible...))
+	}
+}
+
+func (ct *ContainerTable) isVisible(id string) bool {
+	for _, v := range ct.visible {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (ct *ContainerTable) visibleColumns() []*TableColumn {
+	result := make([]*TableColumn, 0, len(ct.visible))
+	for _, column := range ct.columns {
+		if ct.isVisible(column.ID) {
+			result = append(result, column)
+		}
+	}
+	return result
+}
+
+func (ct *ContainerTable) column(id string) *TableColumn {
+	for _, column := range ct.columns {
+		if column.ID == id {
+			return column
+		}
+	}
+	return nil
+}
+
+func (ct *ContainerTable) SetVisibleColumns(ids []string) {
+	ct.visible = make([]string, 0, len(ids))
+	for _, id := range ids {
+		if ct.column(id) != nil && !ct.isVisible(id) {
+			ct.visible = append(ct.visible, id)
+		}
+	}
+	if len(ct.visible) == 0 {
+		ct.visible = append(ct.visible, DefaultTableColumns...)
+	}
+}
+
+func (ct *ContainerTable) matchesFilter(c *models.Container) bool {
+	if ct.filterQuery == "" {
+		return true
+	}
+
+	query := strings.ToLower(ct.filterQuery)
+	if strings.Contains(strings.ToLower(c.ShortName()), query) ||
+		strings.Contains(strings.ToLower(c.Image), query) ||
+		strings.Contains(strings.ToLower(c.Host), query) {
+		return true
+	}
+
+	for key, value := range c.Labels {
+		label := strings.ToLower(key + "=" + value)
+		if strings.Contains(label, query) {
+			return true
+		}
+	}
+	return false
+}
+
+func (ct *ContainerTable) refreshView() {
+	var selectedID string
+	if selected := ct.GetSelectedContainer(); selected != nil {
+		selectedID = selected.ID
+	}
+
+	ct.rows = make([]*models.Container, 0, len(ct.containers))
+	for _, c := range ct.containers {
+		if ct.matchesFilter(c) {
+			ct.rows = append(ct.rows, c)
+		}
+	}
+
+	if sortBy := ct.column(ct.sortColumn); sortBy != nil {
+		sort.SliceStable(ct.rows, func(i, j int) bool {
+			if ct.sortDesc {
+				return sortBy.Less(ct.rows[j], ct.rows[i])
+			}
+			return sortBy.Less(ct.rows[i], ct.rows[j])
+		})
+	}
+
+	ct.table.Clear()
+	for col, column := range ct.visibleColumns() {
+		title := column.Title
+		if column.ID == ct.sortColumn {
+			if ct.sortDesc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		ct.table.SetCell(0, col, tview.NewTableCell(title).
+			SetTextColor(theme.TcellColor("title")).
+			SetAlign(column.Align).
+			SetSelectable(false).
+			SetExpansion(1))
+
+		for row, c := range ct.rows {
+			cell := tview.NewTableCell(tview.Escape(column.Text(c))).
+				SetAlign(column.Align).
+				SetExpansion(1)
+			if column.Color != nil {
+				cell.SetTextColor(column.Color(c))
+			}
+			ct.table.SetCell(row+1, col, cell)
+		}
+	}
+
+	ct.layout.SetTitle(ct.buildTitle())
+
+	if selectedID != "" {
+		ct.SelectContainer(selectedID)
+	} else if len(ct.rows) > 0 {
+		ct.table.Select(1, 0)
+	}
+}
+
+func (ct *ContainerTable) buildTitle() string {
+	if ct.filterQuery != "" {
+		return fmt.Sprintf(" Containers (%d of %d) ", len(ct.rows), len(ct.containers))
+	}
+	return fmt.Sprintf(" Containers (%d total) ", len(ct.containers))
+}
+
+func (ct *ContainerTable) containerAt(row int) *models.Container {
+	index := row - 1
+	if index >= 0 && index < len(ct.rows) {
+		return ct.rows[index]
+	}
+	return nil
+}
+
+func (ct *ContainerTable) SetSelectedFunc(fn func(*models.Container)) {
+	ct.onSelected = fn
+}
+
+func (ct *ContainerTable) SetColumnsChangedFunc(fn func([]string)) {
+	ct.onColumnsChanged = fn
+}
+
+func (ct *ContainerTable) SetFocusFunc(fn func(tview.Primitive)) {
+	ct.setFocus = fn
+}
+
+func (ct *ContainerTable) UpdateContainers(containers []*models.Container) {
+	ct.containers = containers
+	ct.refreshView()
+}
+
+func (ct *ContainerTable) UpdateContainersPreserveSelection(containers []*models.Container, selectedID string) {
+	ct.containers = containers
+	ct.refreshView()
+
+	if selectedID != "" {
+		ct.SelectContainer(selectedID)
+	}
+}
+
+func (ct *ContainerTable) GetSelectedContainer() *models.Container {
+	row, _ := ct.table.GetSelection()
+	return ct.containerAt(row)
+}
+
+func (ct *ContainerTable) SelectContainer(id string) {
+	for i, c := range ct.rows {
+		if c.ID == id {
+			ct.table.Select(i+1, 0)
+			return
+		}
+	}
+}
+
+func (ct *ContainerTable) GetContainerCount() int {
+	return len(ct.containers)
+}
+
+func (ct *ContainerTable) IsCapturingInput() bool {
+	return ct.filter.HasFocus() || ct.pages.HasPage("columns")
+}
+
+func (ct *ContainerTable) GetVisibleColumns() []string {
+	return append([]string(nil), ct.visible...)
+}
+
+func (ct *ContainerTable) GetView() tview.Primitive {
+	return ct.pages
+}
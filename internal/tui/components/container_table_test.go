@@ -0,0 +1,145 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/rivo/tview"
+)
+
+func newTestTable(t *testing.T, columns []string) *ContainerTable {
+	t.Helper()
+	containers := models.MockContainers()
+	return NewContainerTable(toPointers(containers), columns, keymap.Default())
+}
+
+func toPointers(containers []models.Container) []*models.Container {
+	out := make([]*models.Container, len(containers))
+	for i := range containers {
+		out[i] = &containers[i]
+	}
+	return out
+}
+
+// press sends keys to the table as if it had focus.
+func press(ct *ContainerTable, keys string) {
+	handler := ct.table.InputHandler()
+	for _, r := range keys {
+		handler(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), func(tview.Primitive) {})
+	}
+}
+
+func rowNames(ct *ContainerTable) string {
+	names := make([]string, len(ct.rows))
+	for i, c := range ct.rows {
+		names[i] = c.ShortName()
+	}
+	return strings.Join(names, " ")
+}
+
+func TestContainerTableSort(t *testing.T) {
+	tests := []struct {
+		name string
+		keys string
+		want string
+	}{
+		{"by name", "", "app-worker monitoring-grafana nginx-web postgres-db redis-cache"},
+		{"reversed", "!", "redis-cache postgres-db nginx-web monitoring-grafana app-worker"},
+		{"by image", ">", "monitoring-grafana app-worker nginx-web postgres-db redis-cache"},
+		{"by cpu", ">>>>", "redis-cache monitoring-grafana nginx-web postgres-db app-worker"},
+		{"by memory, largest first", ">>>>>!", "postgres-db app-worker monitoring-grafana nginx-web redis-cache"},
+		{"previous wraps to project, keeping ties in listed order", "<", "nginx-web postgres-db redis-cache app-worker monitoring-grafana"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newTestTable(t, nil)
+			press(ct, tt.keys)
+			if got := rowNames(ct); got != tt.want {
+				t.Errorf("rows = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerTableSortKeepsSelection(t *testing.T) {
+	ct := newTestTable(t, nil)
+	ct.SelectContainer("def456789012")
+	press(ct, "!")
+	if selected := ct.GetSelectedContainer(); selected == nil || selected.ShortName() != "postgres-db" {
+		t.Errorf("selected %v after reversing, want postgres-db", selected)
+	}
+	if title := ct.table.GetCell(0, 0).Text; title != "NAME ▼" {
+		t.Errorf("header = %q, want the descending mark", title)
+	}
+}
+
+func TestContainerTableFilter(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "app-worker monitoring-grafana nginx-web postgres-db redis-cache"},
+		{"NGINX", "nginx-web"},
+		{"grafana/", "monitoring-grafana"},
+		{"environment=prod", "nginx-web"},
+		{"service=", "monitoring-grafana nginx-web postgres-db redis-cache"},
+		{"1.2.3", "app-worker"},
+		{"nothing", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ct := newTestTable(t, nil)
+			ct.filter.SetText(tt.query)
+			if got := rowNames(ct); got != tt.want {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	ct := newTestTable(t, nil)
+	ct.filter.SetText("  redis ")
+	if title := ct.buildTitle(); title != " Containers (1 of 5) " {
+		t.Errorf("title = %q", title)
+	}
+}
+
+func TestContainerTableColumns(t *testing.T) {
+	ct := newTestTable(t, []string{"cpu", "bogus", "name", "cpu"})
+	if got := strings.Join(ct.GetVisibleColumns(), " "); got != "cpu name" {
+		t.Errorf("visible = %s, want known columns once each", got)
+	}
+	// Columns show in their table order, whatever the configured order.
+	if first := ct.table.GetCell(0, 0).Text; first != "NAME ▲" {
+		t.Errorf("first header = %q, want NAME", first)
+	}
+
+	var changed []string
+	ct.SetColumnsChangedFunc(func(columns []string) { changed = columns })
+
+	press(ct, "c")
+	if !ct.IsCapturingInput() {
+		t.Fatal("column chooser not open")
+	}
+
+	ct.toggleColumn("project")
+	if got := strings.Join(changed, " "); got != "name cpu project" {
+		t.Errorf("after adding project = %s", got)
+	}
+	ct.toggleColumn("cpu")
+	ct.toggleColumn("name")
+	if got := strings.Join(changed, " "); got != "project" {
+		t.Errorf("after removing cpu and name = %s", got)
+	}
+	// The last column cannot be hidden.
+	ct.toggleColumn("project")
+	if got := strings.Join(ct.GetVisibleColumns(), " "); got != "project" {
+		t.Errorf("visible = %s after hiding the last column", got)
+	}
+
+	if empty := newTestTable(t, []string{"bogus"}); strings.Join(empty.GetVisibleColumns(), ",") != strings.Join(DefaultTableColumns, ",") {
+		t.Errorf("visible = %v, want the defaults", empty.GetVisibleColumns())
+	}
+}
@@ -0,0 +1,20 @@
+package components
+
+import (
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/rivo/tview"
+)
+
+type ContainerView interface {
+	UpdateContainersPreserveSelection(containers []*models.Container, selectedID string)
+	GetSelectedContainer() *models.Container
+	SelectContainer(id string)
+	SetSelectedFunc(fn func(*models.Container))
+	GetContainerCount() int
+	GetView() tview.Primitive
+}
+
+const (
+	ViewModeList  = "list"
+	ViewModeTable = "table"
+)
@@ -6,6 +6,8 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
 	"github.com/rivo/tview"
 )
 
@@ -13,6 +15,16 @@ type ContainerGroup struct {
 	Name       string
 	Containers []*models.Container
 	IsExpanded bool
+	IsHost     bool
+	IsPod      bool
+	HostErr    error
+}
+
+// HostState is a Docker host of the session and, when it could not be
+// reached, the reason.
+type HostState struct {
+	Name string
+	Err  error
 }
 
 type ContainerItem struct {
@@ -28,13 +40,20 @@ type ContainerList struct {
 	groups       []*ContainerGroup
 	displayItems []*ContainerItem
 	onSelected   func(*models.Container)
+	keys         *keymap.Keymap
+
+	hosts     []HostState
+	collapsed map[string]bool
 }
 
-func NewContainerList(containers []*models.Container) *ContainerList {
+func NewContainerList(containers []*models.Container, keys *keymap.Keymap) *ContainerList {
 	cl := &ContainerList{
 		list:       tview.NewList(),
 		containers: containers,
 		groups:     make([]*ContainerGroup, 0),
+		keys:       keys,
+
+		collapsed: make(map[string]bool),
 	}
 
 	cl.setupView()
@@ -45,15 +64,25 @@ func NewContainerList(containers []*models.Container) *ContainerList {
 }
 
 func (cl *ContainerList) UpdateContainersPreserveSelection(containers []*models.Container, selectedID string) {
+	var selectedGroup string
+	if index := cl.list.GetCurrentItem(); index >= 0 && index < len(cl.displayItems) && cl.displayItems[index].IsGroup {
+		selectedGroup = cl.displayItems[index].Group.Name
+	}
+
 	cl.containers = containers
 	cl.buildGroups()
 	cl.refreshView()
 
 	if selectedID != "" {
 		cl.SelectContainer(selectedID)
+	} else if selectedGroup != "" && cl.selectGroup(selectedGroup) {
+		return
 	} else if len(cl.displayItems) > 0 {
-		cl.list.SetCurrentItem(0)
-		cl.handleItemSelection(0)
+		index := cl.initialItem()
+		cl.list.SetCurrentItem(index)
+		if item := cl.displayItems[index]; !item.IsGroup || !item.Group.IsHost {
+			cl.handleItemSelection(index)
+		}
 	}
 }
 
@@ -68,27 +97,32 @@ func (cl *ContainerList) setupView() {
 
 func (cl *ContainerList) setupKeyBindings() {
 	cl.list.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEnter:
+		action, ok := cl.keys.Match(keymap.ScopeList, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionSelect:
 			index := cl.list.GetCurrentItem()
 			cl.handleItemSelection(index)
 			return nil
-		case tcell.KeyRight:
+		case keymap.ActionExpand:
 			index := cl.list.GetCurrentItem()
 			if index >= 0 && index < len(cl.displayItems) {
 				item := cl.displayItems[index]
 				if item.IsGroup && !item.Group.IsExpanded {
-					item.Group.IsExpanded = true
+					cl.setExpanded(item.Group, true)
 					cl.refreshView()
 				}
 			}
 			return nil
-		case tcell.KeyLeft:
+		case keymap.ActionCollapse:
 			index := cl.list.GetCurrentItem()
 			if index >= 0 && index < len(cl.displayItems) {
 				item := cl.displayItems[index]
 				if item.IsGroup && item.Group.IsExpanded {
-					item.Group.IsExpanded = false
+					cl.setExpanded(item.Group, false)
 					cl.refreshView()
 				} else if !item.IsGroup && item.Level > 0 {
 					cl.selectParentGroup(item)
@@ -107,13 +141,45 @@ func (cl *ContainerList) handleItemSelection(index int) {
 
 	item := cl.displayItems[index]
 	if item.IsGroup {
-		item.Group.IsExpanded = !item.Group.IsExpanded
+		cl.setExpanded(item.Group, !item.Group.IsExpanded)
 		cl.refreshView()
 	} else if item.Container != nil && cl.onSelected != nil {
 		cl.onSelected(item.Container)
 	}
 }
 
+// setExpanded remembers host and pod groups the user collapsed, since
+// groups are rebuilt on every refresh and those start expanded.
+func (cl *ContainerList) setExpanded(group *ContainerGroup, expanded bool) {
+	group.IsExpanded = expanded
+	if group.IsHost || group.IsPod {
+		cl.collapsed[group.Name] = !expanded
+	}
+}
+
+// initialItem is the first entry, or with host groups the first container,
+// so that selecting it does not collapse a host.
+func (cl *ContainerList) initialItem() int {
+	if len(cl.hosts) > 1 {
+		for i, item := range cl.displayItems {
+			if !item.IsGroup {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+func (cl *ContainerList) selectGroup(name string) bool {
+	for i, item := range cl.displayItems {
+		if item.IsGroup && item.Group.Name == name {
+			cl.list.SetCurrentItem(i)
+			return true
+		}
+	}
+	return false
+}
+
 func (cl *ContainerList) selectParentGroup(item *ContainerItem) {
 	if item.Group == nil {
 		return
@@ -129,9 +195,14 @@ func (cl *ContainerList) selectParentGroup(item *ContainerItem) {
 
 func (cl *ContainerList) buildGroups() {
 	cl.groups = make([]*ContainerGroup, 0)
+	if len(cl.hosts) > 1 {
+		cl.buildHostGroups()
+		return
+	}
 
 	prefixMap := make(map[string][]*models.Container)
-	usedContainers := make(map[string]bool)
+	var prefixes []string
+	usedContainers := cl.buildPodGroups()
 
 	for _, container := range cl.containers {
 		if usedContainers[container.ID] {
@@ -152,6 +223,7 @@ func (cl *ContainerList) buildGroups() {
 
 			if len(groupContainers) > 1 {
 				prefixMap[prefix] = groupContainers
+				prefixes = append(prefixes, prefix)
 			} else if len(groupContainers) == 1 {
 				usedContainers[groupContainers[0].ID] = false
 			}
@@ -160,11 +232,18 @@ func (cl *ContainerList) buildGroups() {
 
 	for _, container := range cl.containers {
 		if !usedContainers[container.ID] {
-			prefixMap[container.ShortName()] = []*models.Container{container}
+			name := container.ShortName()
+			if _, ok := prefixMap[name]; !ok {
+				prefixes = append(prefixes, name)
+			}
+			prefixMap[name] = []*models.Container{container}
 		}
 	}
 
-	for prefix, containers := range prefixMap {
+	// Groups keep the order containers were listed in, so the list does not
+	// reshuffle on every refresh.
+	for _, prefix := range prefixes {
+		containers := prefixMap[prefix]
 		group := &ContainerGroup{
 			Name:       prefix,
 			Containers: containers,
@@ -174,6 +253,50 @@ func (cl *ContainerList) buildGroups() {
 	}
 }
 
+// buildHostGroups groups containers by the host they run on, keeping hosts
+// in session order and showing unreachable hosts as empty groups.
+func (cl *ContainerList) buildHostGroups() {
+	for _, host := range cl.hosts {
+		group := &ContainerGroup{
+			Name:       host.Name,
+			IsExpanded: !cl.collapsed[host.Name],
+			IsHost:     true,
+			HostErr:    host.Err,
+		}
+		for _, container := range cl.containers {
+			if container.Host == host.Name {
+				group.Containers = append(group.Containers, container)
+			}
+		}
+		cl.groups = append(cl.groups, group)
+	}
+}
+
+// buildPodGroups groups the containers of each Podman pod and returns the
+// containers it placed.
+func (cl *ContainerList) buildPodGroups() map[string]bool {
+	placed := make(map[string]bool)
+	pods := make(map[string]*ContainerGroup)
+	for _, container := range cl.containers {
+		if container.Pod == "" {
+			continue
+		}
+		group, ok := pods[container.Pod]
+		if !ok {
+			group = &ContainerGroup{
+				Name:       container.Pod,
+				IsExpanded: !cl.collapsed[container.Pod],
+				IsPod:      true,
+			}
+			pods[container.Pod] = group
+			cl.groups = append(cl.groups, group)
+		}
+		group.Containers = append(group.Containers, container)
+		placed[container.ID] = true
+	}
+	return placed
+}
+
 func (cl *ContainerList) extractPrefix(name string) string {
 
 	parts := strings.Split(name, "-")
@@ -204,10 +327,10 @@ func (cl *ContainerList) refreshView() {
 	cl.displayItems = make([]*ContainerItem, 0)
 
 	title := cl.buildTitle()
-	cl.list.SetTitle(title)
+	cl.list.SetTitle(theme.Apply(title))
 
 	for _, group := range cl.groups {
-		if len(group.Containers) > 1 {
+		if len(group.Containers) > 1 || group.IsHost || group.IsPod {
 			groupItem := &ContainerItem{
 				Group:   group,
 				IsGroup: true,
@@ -217,7 +340,7 @@ func (cl *ContainerList) refreshView() {
 
 			mainText := cl.formatGroupText(group)
 			secondaryText := cl.formatGroupSecondary(group)
-			cl.list.AddItem(mainText, secondaryText, 0, nil)
+			cl.list.AddItem(theme.Apply(mainText), theme.Apply(secondaryText), 0, nil)
 
 			if group.IsExpanded {
 				for _, container := range group.Containers {
@@ -231,7 +354,7 @@ func (cl *ContainerList) refreshView() {
 
 					mainText := cl.formatContainerInGroupText(container)
 					secondaryText := cl.formatSecondaryText(container)
-					cl.list.AddItem(mainText, secondaryText, 0, nil)
+					cl.list.AddItem(theme.Apply(mainText), theme.Apply(secondaryText), 0, nil)
 				}
 			}
 		} else {
@@ -245,7 +368,7 @@ func (cl *ContainerList) refreshView() {
 
 			mainText := cl.formatMainText(container)
 			secondaryText := cl.formatSecondaryText(container)
-			cl.list.AddItem(mainText, secondaryText, 0, nil)
+			cl.list.AddItem(theme.Apply(mainText), theme.Apply(secondaryText), 0, nil)
 		}
 	}
 }
@@ -270,6 +393,10 @@ func (cl *ContainerList) buildTitle() string {
 }
 
 func (cl *ContainerList) formatGroupText(group *ContainerGroup) string {
+	if group.IsHost && group.HostErr != nil {
+		return fmt.Sprintf("⚠ [title]%s[text] [error]unreachable[text]", tview.Escape(group.Name))
+	}
+
 	icon := "📁"
 	if group.IsExpanded {
 		icon = "📂"
@@ -282,22 +409,30 @@ func (cl *ContainerList) formatGroupText(group *ContainerGroup) string {
 		}
 	}
 
-	return fmt.Sprintf("%s [yellow]%s[white] (%d containers, %d running)",
-		icon, group.Name, len(group.Containers), runningCount)
+	kind := ""
+	if group.IsPod {
+		kind = " [muted]pod[text]"
+	}
+
+	return fmt.Sprintf("%s [title]%s[text]%s (%d containers, %d running)",
+		icon, group.Name, kind, len(group.Containers), runningCount)
 }
 
 func (cl *ContainerList) formatGroupSecondary(group *ContainerGroup) string {
+	if group.IsHost && group.HostErr != nil {
+		return fmt.Sprintf("[muted]%s[text]", tview.Escape(group.HostErr.Error()))
+	}
 	if group.IsExpanded {
-		return "[dim]Click or press Enter to collapse[white]"
+		return "[dim]Click or press Enter to collapse[text]"
 	}
-	return "[dim]Click or press Enter to expand[white]"
+	return "[dim]Click or press Enter to expand[text]"
 }
 
 func (cl *ContainerList) formatMainText(container *models.Container) string {
-	statusColor := container.Status.Color()
+	statusColor := container.Status.Role()
 	statusIcon := container.Status.Icon()
 
-	return fmt.Sprintf("[%s]%s %s[white] (%s)",
+	return fmt.Sprintf("[%s]%s %s[text] (%s)",
 		statusColor,
 		statusIcon,
 		container.ShortName(),
@@ -305,10 +440,10 @@ func (cl *ContainerList) formatMainText(container *models.Container) string {
 }
 
 func (cl *ContainerList) formatContainerInGroupText(container *models.Container) string {
-	statusColor := container.Status.Color()
+	statusColor := container.Status.Role()
 	statusIcon := container.Status.Icon()
 
-	return fmt.Sprintf("  [%s]%s %s[white] (%s)",
+	return fmt.Sprintf("  [%s]%s %s[text] (%s)",
 		statusColor,
 		statusIcon,
 		container.ShortName(),
@@ -316,29 +451,29 @@ func (cl *ContainerList) formatContainerInGroupText(container *models.Container)
 }
 
 func (cl *ContainerList) formatSecondaryText(container *models.Container) string {
-	statusColor := container.Status.Color()
+	statusColor := container.Status.Role()
 
 	switch container.Status {
 	case models.StatusRunning:
-		return fmt.Sprintf("[%s]%s[white] Port: %s",
+		return fmt.Sprintf("[%s]%s[text] Port: %s",
 			statusColor,
 			container.Status,
 			container.ShortPort())
 
 	case models.StatusExited, models.StatusStopped, models.StatusDead:
-		return fmt.Sprintf("[%s]%s[white] Age: %s",
+		return fmt.Sprintf("[%s]%s[text] Age: %s",
 			statusColor,
 			container.Status,
 			container.FormatAge())
 
 	case models.StatusPaused:
-		return fmt.Sprintf("[%s]%s[white] Port: %s",
+		return fmt.Sprintf("[%s]%s[text] Port: %s",
 			statusColor,
 			container.Status,
 			container.ShortPort())
 
 	default:
-		return fmt.Sprintf("[%s]%s[white] Age: %s",
+		return fmt.Sprintf("[%s]%s[text] Age: %s",
 			statusColor,
 			container.Status,
 			container.FormatAge())
@@ -349,6 +484,12 @@ func (cl *ContainerList) SetSelectedFunc(fn func(*models.Container)) {
 	cl.onSelected = fn
 }
 
+// SetHosts makes the list group containers by host when the session spans
+// more than one. It takes effect on the next update.
+func (cl *ContainerList) SetHosts(hosts []HostState) {
+	cl.hosts = hosts
+}
+
 func (cl *ContainerList) UpdateContainers(containers []*models.Container) {
 	cl.containers = containers
 	cl.buildGroups()
@@ -8,15 +8,18 @@ import (
 	"github.com/kqnd/kernus/internal/docker"
 	"github.com/kqnd/kernus/internal/models"
 	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
 	"github.com/rivo/tview"
 )
 
 type Details struct {
 	view             *tview.TextView
 	currentContainer *models.Container
-	docker           *docker.Client
+	clientFor        func(*models.Container) docker.ContainerRuntime
 	tabs             []string
 	currentTab       int
+	keys             *keymap.Keymap
 
 	overviewTab *details.OverviewTab
 	statsTab    *details.StatsTab
@@ -33,7 +36,7 @@ const (
 	TAB_LOGS
 )
 
-func NewDetails(docker *docker.Client) *Details {
+func NewDetails(keys *keymap.Keymap) *Details {
 	d := &Details{
 		view: tview.NewTextView().
 			SetDynamicColors(true).
@@ -43,7 +46,7 @@ func NewDetails(docker *docker.Client) *Details {
 			}),
 		tabs:       []string{"Overview", "Stats", "Network", "Storage", "Logs"},
 		currentTab: TAB_OVERVIEW,
-		docker:     docker,
+		keys:       keys,
 
 		overviewTab: details.NewOverviewTab(),
 		statsTab:    details.NewStatsTab(),
@@ -53,28 +56,26 @@ func NewDetails(docker *docker.Client) *Details {
 	}
 
 	d.view.SetBorder(true).SetTitle(" Container Details ")
-	d.view.SetText(d.buildEmptyState())
+	d.view.SetText(theme.Apply(d.buildEmptyState()))
+
+	d.logsTab.SetRefreshHint(keys.KeysFor(keymap.ScopeDetails, keymap.ActionRefreshLogs))
 
 	d.view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyLeft:
+		action, ok := d.keys.Match(keymap.ScopeDetails, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionPrevTab:
 			d.PrevTab()
 			return nil
-		case tcell.KeyRight:
+		case keymap.ActionNextTab:
 			d.NextTab()
 			return nil
-		}
-
-		switch event.Rune() {
-		case 'r', 'R':
+		case keymap.ActionRefreshLogs:
 			if d.currentContainer != nil {
-				go func() {
-					if d.currentTab == TAB_LOGS {
-						d.refreshLogs()
-					} else {
-						d.restartDocker()
-					}
-				}()
+				go d.refreshLogs()
 			}
 			return nil
 		}
@@ -84,22 +85,23 @@ func NewDetails(docker *docker.Client) *Details {
 	return d
 }
 
-func (d *Details) restartDocker() {
-	if d.currentContainer == nil {
-		return
-	}
-
-	d.currentContainer.Status = models.StatusRestarting
-	d.docker.RestartContainer(d.currentContainer.ID)
-	d.updateView()
+// SetClientFunc sets how the client of a container's host is found, since
+// containers of a session may come from several daemons.
+func (d *Details) SetClientFunc(fn func(*models.Container) docker.ContainerRuntime) {
+	d.clientFor = fn
 }
 
 func (d *Details) refreshLogs() {
-	if d.currentContainer == nil {
+	if d.currentContainer == nil || d.clientFor == nil {
 		return
 	}
 
-	if logs, err := d.docker.RefreshContainerLogs(d.currentContainer.ID, 100); err == nil {
+	client := d.clientFor(d.currentContainer)
+	if client == nil {
+		return
+	}
+
+	if logs, err := client.RefreshContainerLogs(d.currentContainer.ID, 100); err == nil {
 		d.currentContainer.Logs = logs
 		d.updateView()
 	}
@@ -117,6 +119,20 @@ func (d *Details) SwitchTab(tab int) {
 	}
 }
 
+func (d *Details) TabNames() []string {
+	return append([]string(nil), d.tabs...)
+}
+
+func (d *Details) SwitchTabByName(name string) bool {
+	for i, tab := range d.tabs {
+		if strings.EqualFold(tab, name) {
+			d.SwitchTab(i)
+			return true
+		}
+	}
+	return false
+}
+
 func (d *Details) NextTab() {
 	d.currentTab = (d.currentTab + 1) % len(d.tabs)
 	d.updateView()
@@ -130,7 +146,7 @@ func (d *Details) PrevTab() {
 func (d *Details) updateView() {
 	if d.currentContainer == nil {
 		d.view.SetTitle(" Container Details ")
-		d.view.SetText(d.buildEmptyState())
+		d.view.SetText(theme.Apply(d.buildEmptyState()))
 		return
 	}
 
@@ -153,35 +169,38 @@ func (d *Details) updateView() {
 		content = d.buildTabHeader() + d.overviewTab.Render(d.currentContainer)
 	}
 
-	d.view.SetText(content)
+	d.view.SetText(theme.Apply(content))
 }
 
 func (d *Details) buildEmptyState() string {
-	return `[yellow]Container Details[white]
-
-[gray]┌─────────────────────────────────────┐[white]
-[gray]│[white]  No container selected              [gray]│[white]
-[gray]│[white]                                     [gray]│[white]
-[gray]│[white]  Select a container from the list   [gray]│[white]
-[gray]│[white]  to view detailed information       [gray]│[white]
-[gray]│[white]                                     [gray]│[white]
-[gray]│[white]  Available tabs:                    [gray]│[white]
-[gray]│[white]  • Overview - Basic info & status   [gray]│[white]
-[gray]│[white]  • Stats    - Resource usage        [gray]│[white]
-[gray]│[white]  • Network  - Network configuration [gray]│[white]
-[gray]│[white]  • Storage  - Mounts & volumes      [gray]│[white]
-[gray]└─────────────────────────────────────┘[white]
-
-[darkgray]Use [white]Left/Right arrows[darkgray] to switch between tabs[white]`
+	return fmt.Sprintf(`[title]Container Details[text]
+
+[muted]┌─────────────────────────────────────┐[text]
+[muted]│[text]  No container selected              [muted]│[text]
+[muted]│[text]                                     [muted]│[text]
+[muted]│[text]  Select a container from the list   [muted]│[text]
+[muted]│[text]  to view detailed information       [muted]│[text]
+[muted]│[text]                                     [muted]│[text]
+[muted]│[text]  Available tabs:                    [muted]│[text]
+[muted]│[text]  • Overview - Basic info & status   [muted]│[text]
+[muted]│[text]  • Stats    - Resource usage        [muted]│[text]
+[muted]│[text]  • Network  - Network configuration [muted]│[text]
+[muted]│[text]  • Storage  - Mounts & volumes      [muted]│[text]
+[muted]└─────────────────────────────────────┘[text]
+
+[dim]Use [text]%s[dim] / [text]%s[dim] to switch between tabs, [text]%s[dim] for help[text]`,
+		d.keys.KeysFor(keymap.ScopeDetails, keymap.ActionPrevTab),
+		d.keys.KeysFor(keymap.ScopeDetails, keymap.ActionNextTab),
+		d.keys.KeysFor(keymap.ScopeGlobal, keymap.ActionHelp))
 }
 
 func (d *Details) buildTabHeader() string {
 	var tabs []string
 	for i, tab := range d.tabs {
 		if i == d.currentTab {
-			tabs = append(tabs, fmt.Sprintf("[white]> %s <[white]", tab))
+			tabs = append(tabs, fmt.Sprintf("[text]> %s <[text]", tab))
 		} else {
-			tabs = append(tabs, fmt.Sprintf("[gray]  %s  [white]", tab))
+			tabs = append(tabs, fmt.Sprintf("[muted]  %s  [text]", tab))
 		}
 	}
 	return fmt.Sprintf("%s\n\n", strings.Join(tabs, ""))
@@ -0,0 +1,81 @@
+package details
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/rivo/tview"
+)
+
+type CompareTab struct {
+	formatter  *Formatter
+	visualizer *StatsVisualizer
+}
+
+func NewCompareTab() *CompareTab {
+	return &CompareTab{
+		formatter:  NewFormatter(),
+		visualizer: NewStatsVisualizer(),
+	}
+}
+
+// RenderDifferences lists the compared fields from the point of view of one
+// side: differing values are highlighted and followed by the other side's
+// value so each pane reads on its own.
+func (c *CompareTab) RenderDifferences(self, other *models.Container, left bool) string {
+	var diffs []models.FieldDiff
+	if left {
+		diffs = models.CompareContainers(self, other)
+	} else {
+		diffs = models.CompareContainers(other, self)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[title]Differences vs %s (%d)[text]\n",
+		other.ShortName(), models.CountDifferences(diffs)))
+
+	for _, diff := range diffs {
+		value, otherValue := diff.Left, diff.Right
+		if !left {
+			value, otherValue = diff.Right, diff.Left
+		}
+
+		field := c.formatter.TruncateString(diff.Field, 24)
+		if diff.Differs {
+			result.WriteString(fmt.Sprintf("  [diff.changed]%-24s %s[text] [muted](%s)[text]\n",
+				tview.Escape(field), tview.Escape(value), tview.Escape(otherValue)))
+		} else if !strings.HasPrefix(diff.Field, "Env ") && !strings.HasPrefix(diff.Field, "Label ") {
+			result.WriteString(fmt.Sprintf("  [muted]%-24s[text] %s\n", tview.Escape(field), tview.Escape(value)))
+		}
+	}
+
+	return result.String()
+}
+
+// RenderStatsOverlay puts this container's live usage next to the other
+// container's so the two can be read against each other in the Stats tab.
+func (c *CompareTab) RenderStatsOverlay(self, other *models.Container) string {
+	if self.Stats == nil || other.Stats == nil {
+		return "[title]Live Comparison[text]\n  [muted]Both containers must be running to compare stats[text]\n"
+	}
+
+	mine, theirs := self.Stats, other.Stats
+	return fmt.Sprintf(`[title]Live Comparison (this / %s)[text]
+  CPU     : %s
+            %s
+  Memory  : %s
+            %s
+  Net Rx  : %s / %s
+  Net Tx  : %s / %s
+  PIDs    : %d / %d
+`,
+		tview.Escape(other.ShortName()),
+		c.visualizer.BuildProgressBar(mine.CPU.QuotaPercentage(), 30, "this"),
+		c.visualizer.BuildProgressBar(theirs.CPU.QuotaPercentage(), 30, "other"),
+		c.visualizer.BuildProgressBar(mine.Memory.Percentage(), 30, "this"),
+		c.visualizer.BuildProgressBar(theirs.Memory.Percentage(), 30, "other"),
+		c.formatter.FormatRate(mine.Network.Rate.RxBytes), c.formatter.FormatRate(theirs.Network.Rate.RxBytes),
+		c.formatter.FormatRate(mine.Network.Rate.TxBytes), c.formatter.FormatRate(theirs.Network.Rate.TxBytes),
+		mine.PIDs, theirs.PIDs)
+}
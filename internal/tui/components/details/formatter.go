@@ -24,6 +24,17 @@ func (f *Formatter) FormatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+func (f *Formatter) FormatRate(bytesPerSec float64) string {
+	return f.FormatBytes(int64(bytesPerSec)) + "/s"
+}
+
+func (f *Formatter) FormatPerSecond(value float64, unit string) string {
+	if value < 10 {
+		return fmt.Sprintf("%.1f %s/s", value, unit)
+	}
+	return fmt.Sprintf("%s %s/s", f.FormatNumber(int64(value)), unit)
+}
+
 func (f *Formatter) FormatNumber(num int64) string {
 	if num < 1000 {
 		return fmt.Sprintf("%d", num)
@@ -37,18 +48,18 @@ func (f *Formatter) FormatNumber(num int64) string {
 
 func (f *Formatter) FormatTime(t time.Time) string {
 	if t.IsZero() {
-		return "[gray]Never[white]"
+		return "[muted]Never[text]"
 	}
 	return t.Format("2006-01-02 15:04:05")
 }
 
 func (f *Formatter) FormatExitCode(code int) string {
 	if code == 0 {
-		return "[green]0 (success)[white]"
+		return "[success]0 (success)[text]"
 	} else if code > 0 {
-		return fmt.Sprintf("[red]%d (error)[white]", code)
+		return fmt.Sprintf("[error]%d (error)[text]", code)
 	}
-	return "[gray]N/A[white]"
+	return "[muted]N/A[text]"
 }
 
 func (f *Formatter) TruncateString(s string, maxLen int) string {
@@ -58,11 +69,11 @@ func (f *Formatter) TruncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-func (f *Formatter) GetUsageColor(percentage float64) string {
+func (f *Formatter) GetUsageRole(percentage float64) string {
 	if percentage < 50 {
-		return "green"
+		return "usage.low"
 	} else if percentage < 80 {
-		return "yellow"
+		return "usage.medium"
 	}
-	return "red"
+	return "usage.high"
 }
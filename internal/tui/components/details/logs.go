@@ -13,14 +13,20 @@ type LogsTab struct {
 	lastUpdate    time.Time
 	cachedLogs    []string
 	cachedContent string
+	refreshHint   string
 }
 
 func NewLogsTab() *LogsTab {
 	return &LogsTab{
-		formatter: NewFormatter(),
+		formatter:   NewFormatter(),
+		refreshHint: "r",
 	}
 }
 
+func (l *LogsTab) SetRefreshHint(keys string) {
+	l.refreshHint = keys
+}
+
 func (l *LogsTab) Render(container *models.Container) string {
 	if container == nil {
 		return ""
@@ -66,18 +72,18 @@ func (l *LogsTab) renderLogs(container *models.Container) string {
 	}
 
 	var result strings.Builder
-	result.WriteString("[yellow]Container Logs[white]\n\n")
-	result.WriteString(fmt.Sprintf("[gray]Container: %s | Lines: %d | Last Update: %s[white]\n",
+	result.WriteString("[title]Container Logs[text]\n\n")
+	result.WriteString(fmt.Sprintf("[muted]Container: %s | Lines: %d | Last Update: %s[text]\n",
 		container.ShortName(),
 		len(container.Logs),
 		l.formatter.FormatTime(time.Now())))
-	result.WriteString("[gray]" + strings.Repeat("─", 70) + "[white]\n\n")
+	result.WriteString("[muted]" + strings.Repeat("─", 70) + "[text]\n\n")
 
 	maxLines := 50
 	startIndex := 0
 	if len(container.Logs) > maxLines {
 		startIndex = len(container.Logs) - maxLines
-		result.WriteString(fmt.Sprintf("[yellow]... showing last %d lines of %d total ...[white]\n\n",
+		result.WriteString(fmt.Sprintf("[title]... showing last %d lines of %d total ...[text]\n\n",
 			maxLines, len(container.Logs)))
 	}
 
@@ -87,8 +93,8 @@ func (l *LogsTab) renderLogs(container *models.Container) string {
 		result.WriteString(formattedLine + "\n")
 	}
 
-	result.WriteString("\n[gray]" + strings.Repeat("─", 70) + "[white]\n")
-	result.WriteString("[darkgray]Press 'r' to refresh logs | Use scroll to navigate[white]")
+	result.WriteString("\n[muted]" + strings.Repeat("─", 70) + "[text]\n")
+	result.WriteString(fmt.Sprintf("[dim]Press '%s' to refresh logs | Use scroll to navigate[text]", l.refreshHint))
 
 	return result.String()
 }
@@ -96,25 +102,26 @@ func (l *LogsTab) renderLogs(container *models.Container) string {
 func (l *LogsTab) renderNoLogs(container *models.Container) string {
 	statusMessage := ""
 	if container.Status != models.StatusRunning {
-		statusMessage = fmt.Sprintf("\n[yellow]Container is %s - logs may be limited[white]", container.Status)
+		statusMessage = fmt.Sprintf("\n[title]Container is %s - logs may be limited[text]", container.Status)
 	}
 
-	return fmt.Sprintf(`[yellow]Container Logs[white]
+	return fmt.Sprintf(`[title]Container Logs[text]
 
-[gray]┌─────────────────────────────────────────────────────────┐[white]
-[gray]│[white]  No logs available for container:                    [gray]│[white]
-[gray]│[white]  %s[gray]│[white]
-[gray]│[white]                                                     [gray]│[white]
-[gray]│[white]  Possible reasons:                                  [gray]│[white]
-[gray]│[white]  • Container has not produced any output            [gray]│[white]
-[gray]│[white]  • Logs are being written to files instead         [gray]│[white]
-[gray]│[white]  • Container just started                          [gray]│[white]
-[gray]│[white]  • Logging driver is not configured                [gray]│[white]
-[gray]└─────────────────────────────────────────────────────────┘[white]%s
+[muted]┌─────────────────────────────────────────────────────────┐[text]
+[muted]│[text]  No logs available for container:                    [muted]│[text]
+[muted]│[text]  %s[muted]│[text]
+[muted]│[text]                                                     [muted]│[text]
+[muted]│[text]  Possible reasons:                                  [muted]│[text]
+[muted]│[text]  • Container has not produced any output            [muted]│[text]
+[muted]│[text]  • Logs are being written to files instead         [muted]│[text]
+[muted]│[text]  • Container just started                          [muted]│[text]
+[muted]│[text]  • Logging driver is not configured                [muted]│[text]
+[muted]└─────────────────────────────────────────────────────────┘[text]%s
 
-[darkgray]Press 'r' to refresh logs[white]`,
+[dim]Press '%s' to refresh logs[text]`,
 		l.formatContainerName(container.ShortName()),
-		statusMessage)
+		statusMessage,
+		l.refreshHint)
 }
 
 func (l *LogsTab) formatContainerName(name string) string {
@@ -131,7 +138,7 @@ func (l *LogsTab) formatLogLine(logLine string, lineNumber int) string {
 	timestamp, message := l.extractTimestampAndMessage(cleanLine)
 
 	logLevel := l.detectLogLevel(message)
-	color := l.getLogLevelColor(logLevel)
+	color := l.getLogLevelRole(logLevel)
 
 	maxMessageLength := 80
 	if len(message) > maxMessageLength {
@@ -139,11 +146,11 @@ func (l *LogsTab) formatLogLine(logLine string, lineNumber int) string {
 	}
 
 	if timestamp != "" {
-		return fmt.Sprintf("[gray]%3d[white] [darkgray]%s[white] [%s]%s[white]",
+		return fmt.Sprintf("[log.line]%3d[text] [log.timestamp]%s[text] [%s]%s[text]",
 			lineNumber, timestamp, color, message)
 	}
 
-	return fmt.Sprintf("[gray]%3d[white] [%s]%s[white]", lineNumber, color, message)
+	return fmt.Sprintf("[muted]%3d[text] [%s]%s[text]", lineNumber, color, message)
 }
 
 func (l *LogsTab) stripAnsiCodes(input string) string {
@@ -395,17 +402,17 @@ func (l *LogsTab) detectLogLevel(message string) string {
 	return "DEFAULT"
 }
 
-func (l *LogsTab) getLogLevelColor(level string) string {
+func (l *LogsTab) getLogLevelRole(level string) string {
 	switch level {
 	case "ERROR":
-		return "red"
+		return "log.error"
 	case "WARN":
-		return "yellow"
+		return "log.warn"
 	case "INFO":
-		return "cyan"
+		return "log.info"
 	case "DEBUG":
-		return "gray"
+		return "log.debug"
 	default:
-		return "white"
+		return "log.default"
 	}
 }
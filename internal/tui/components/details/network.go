@@ -30,31 +30,41 @@ func (n *NetworkTab) Render(container *models.Container) string {
 		n.renderNetworkStats(container),
 	}
 
-	return fmt.Sprintf("[yellow]Network Configuration[white]\n\n%s", strings.Join(sections, "\n\n"))
+	return fmt.Sprintf("[title]Network Configuration[text]\n\n%s", strings.Join(sections, "\n\n"))
 }
 
 func (n *NetworkTab) renderPortMappings(c *models.Container) string {
 	portsTable := n.tableBuilder.BuildPortsTable(c.Ports)
-	return fmt.Sprintf("[yellow]Port Mappings (%d)[white]\n%s", len(c.Ports), portsTable)
+	return fmt.Sprintf("[title]Port Mappings (%d)[text]\n%s", len(c.Ports), portsTable)
 }
 
 func (n *NetworkTab) renderNetworks(c *models.Container) string {
 	networksTable := n.tableBuilder.BuildNetworksTable(c.Networks)
-	return fmt.Sprintf("[yellow]Networks (%d)[white]\n%s", len(c.Networks), networksTable)
+	return fmt.Sprintf("[title]Networks (%d)[text]\n%s", len(c.Networks), networksTable)
 }
 
 func (n *NetworkTab) renderNetworkStats(c *models.Container) string {
 	if c.Stats == nil {
-		return "[yellow]Network Statistics[white]\n  [gray]Network statistics not available[white]"
+		return "[title]Network Statistics[text]\n  [muted]Network statistics not available[text]"
 	}
 
-	return fmt.Sprintf(`[yellow]Network Statistics[white]
-  Received : %s (%s packets, %d errors)
-  Sent     : %s (%s packets, %d errors)`,
-		n.formatter.FormatBytes(c.Stats.Network.RxBytes),
-		n.formatter.FormatNumber(c.Stats.Network.RxPackets),
-		c.Stats.Network.RxErrors,
-		n.formatter.FormatBytes(c.Stats.Network.TxBytes),
-		n.formatter.FormatNumber(c.Stats.Network.TxPackets),
-		c.Stats.Network.TxErrors)
+	network := c.Stats.Network
+	return fmt.Sprintf(`[title]Network Statistics[text]
+  Received : [accent]%s[text] (%s) | total %s (%s packets, %d errors)
+  Sent     : [accent]%s[text] (%s) | total %s (%s packets, %d errors)
+
+[title]Interfaces (%d)[text]
+%s`,
+		n.formatter.FormatRate(network.Rate.RxBytes),
+		n.formatter.FormatPerSecond(network.Rate.RxPackets, "pkt"),
+		n.formatter.FormatBytes(network.RxBytes),
+		n.formatter.FormatNumber(network.RxPackets),
+		network.RxErrors,
+		n.formatter.FormatRate(network.Rate.TxBytes),
+		n.formatter.FormatPerSecond(network.Rate.TxPackets, "pkt"),
+		n.formatter.FormatBytes(network.TxBytes),
+		n.formatter.FormatNumber(network.TxPackets),
+		network.TxErrors,
+		len(network.Interfaces),
+		n.tableBuilder.BuildInterfacesTable(network.Interfaces))
 }
@@ -32,15 +32,15 @@ func (o *OverviewTab) Render(container *models.Container) string {
 		o.renderLabels(container),
 	}
 
-	return fmt.Sprintf("[yellow]Container Information[white]\n\n%s", strings.Join(sections, "\n\n"))
+	return fmt.Sprintf("[title]Container Information[text]\n\n%s", strings.Join(sections, "\n\n"))
 }
 
 func (o *OverviewTab) renderIdentitySection(c *models.Container) string {
-	return fmt.Sprintf(`[yellow]Identity[white]
+	return fmt.Sprintf(`[title]Identity[text]
 	ID       : %s
 	Name     : %s
-	Image    : [cyan]%s[white]
-	Tag      : [blue]%s[white]`,
+	Image    : [accent]%s[text]
+	Tag      : [accent.alt]%s[text]`,
 		c.ShortID(),
 		c.ShortName(),
 		c.ImageName(),
@@ -49,28 +49,28 @@ func (o *OverviewTab) renderIdentitySection(c *models.Container) string {
 
 func (o *OverviewTab) renderStatusSection(c *models.Container) string {
 	healthStatus := "Unknown"
-	healthColor := "gray"
+	healthColor := models.HealthStatusNone.Role()
 	healthIcon := ""
 
 	if c.Health != nil {
 		healthStatus = string(c.Health.Status)
-		healthColor = c.Health.Status.Color()
+		healthColor = c.Health.Status.Role()
 		healthIcon = c.Health.Status.Icon()
 	}
 
-	return fmt.Sprintf(`[yellow]Status[white]
-	Status   : [%s]%s %s[white]
+	return fmt.Sprintf(`[title]Status[text]
+	Status   : [%s]%s %s[text]
 	State    : %s
-	Health   : [%s]%s %s[white]
+	Health   : [%s]%s %s[text]
 	Exit Code: %s`,
-		c.Status.Color(), c.Status.Icon(), c.Status,
+		c.Status.Role(), c.Status.Icon(), c.Status,
 		c.State,
 		healthColor, healthIcon, healthStatus,
 		o.formatter.FormatExitCode(c.ExitCode))
 }
 
 func (o *OverviewTab) renderTimingSection(c *models.Container) string {
-	return fmt.Sprintf(`[yellow]Timing[white]
+	return fmt.Sprintf(`[title]Timing[text]
 	Created  : %s
 	Started  : %s
 	Age      : %s
@@ -90,8 +90,8 @@ func (o *OverviewTab) renderConfigSection(c *models.Container) string {
 		}
 	}
 
-	return fmt.Sprintf(`[yellow]Configuration[white]
-	Command  : [cyan]%s[white]
+	return fmt.Sprintf(`[title]Configuration[text]
+	Command  : [accent]%s[text]
 	Restart  : %s
 	PIDs     : %s`,
 		o.formatter.TruncateString(c.Command, 60),
@@ -101,36 +101,36 @@ func (o *OverviewTab) renderConfigSection(c *models.Container) string {
 
 func (o *OverviewTab) renderQuickStats(c *models.Container) string {
 	if c.Stats == nil {
-		return `[yellow]Quick Stats[white]
-	CPU      : [gray]N/A (not running)[white]
-	Memory   : [gray]N/A (not running)[white]
-	Network  : [gray]N/A (not running)[white]
-	PIDs     : [gray]N/A (not running)[white]`
+		return `[title]Quick Stats[text]
+	CPU      : [muted]N/A (not running)[text]
+	Memory   : [muted]N/A (not running)[text]
+	Network  : [muted]N/A (not running)[text]
+	PIDs     : [muted]N/A (not running)[text]`
 	}
 
-	cpuColor := o.formatter.GetUsageColor(c.Stats.CPU.Usage)
-	memColor := o.formatter.GetUsageColor(c.Stats.Memory.Percentage())
+	cpuColor := o.formatter.GetUsageRole(c.Stats.CPU.QuotaPercentage())
+	memColor := o.formatter.GetUsageRole(c.Stats.Memory.Percentage())
 
-	return fmt.Sprintf(`[yellow]Quick Stats[white]
-	CPU      : [%s]%.1f%%[white]
-	Memory   : [%s]%.1fMB (%.1f%%)[white]
+	return fmt.Sprintf(`[title]Quick Stats[text]
+	CPU      : [%s]%.1f%%[text]
+	Memory   : [%s]%.1fMB (%.1f%%)[text]
 	Network  : ↓ %s ↑ %s
 	PIDs     : %d processes`,
 		cpuColor, c.Stats.CPU.Usage,
 		memColor, float64(c.Stats.Memory.Usage)/1024/1024, c.Stats.Memory.Percentage(),
-		o.formatter.FormatBytes(c.Stats.Network.RxBytes),
-		o.formatter.FormatBytes(c.Stats.Network.TxBytes),
+		o.formatter.FormatRate(c.Stats.Network.Rate.RxBytes),
+		o.formatter.FormatRate(c.Stats.Network.Rate.TxBytes),
 		c.Stats.PIDs)
 }
 
 func (o *OverviewTab) renderLabels(c *models.Container) string {
 	labelsGrid := o.buildLabelsGrid(c.Labels)
-	return fmt.Sprintf("[yellow]Labels (%d)[white]\n%s", len(c.Labels), labelsGrid)
+	return fmt.Sprintf("[title]Labels (%d)[text]\n%s", len(c.Labels), labelsGrid)
 }
 
 func (o *OverviewTab) buildLabelsGrid(labels map[string]string) string {
 	if len(labels) == 0 {
-		return "  [gray]No labels defined[white]"
+		return "  [muted]No labels defined[text]"
 	}
 
 	var result strings.Builder
@@ -145,7 +145,7 @@ func (o *OverviewTab) buildLabelsGrid(labels map[string]string) string {
 
 	for _, key := range keys {
 		if count >= maxLabels {
-			result.WriteString("  [gray]... and more[white]\n")
+			result.WriteString("  [muted]... and more[text]\n")
 			break
 		}
 
@@ -153,7 +153,7 @@ func (o *OverviewTab) buildLabelsGrid(labels map[string]string) string {
 		truncatedKey := o.formatter.TruncateString(key, 20)
 		truncatedValue := o.formatter.TruncateString(value, 30)
 
-		result.WriteString(fmt.Sprintf("  [yellow]%s[white]: %s\n", truncatedKey, truncatedValue))
+		result.WriteString(fmt.Sprintf("  [title]%s[text]: %s\n", truncatedKey, truncatedValue))
 		count++
 	}
 
@@ -164,5 +164,5 @@ func (o *OverviewTab) formatPIDs(c *models.Container) string {
 	if c.Stats != nil {
 		return fmt.Sprintf("%d processes", c.Stats.PIDs)
 	}
-	return "[gray]N/A[white]"
+	return "[muted]N/A[text]"
 }
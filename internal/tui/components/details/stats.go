@@ -49,34 +49,38 @@ func (s *StatsTab) updateCache(stats *models.ContainerStats, content string) {
 }
 
 func (s *StatsTab) renderNoStats() string {
-	return fmt.Sprintf(`[yellow]Resource Statistics[white]
+	return fmt.Sprintf(`[title]Resource Statistics[text]
 
-[red]No Statistics Available[white]
+[error]No Statistics Available[text]
 
 Statistics are only available for running containers.
 %s`, s.visualizer.BuildStatsPlaceholder())
 }
 
 func (s *StatsTab) renderStats(stats *models.ContainerStats) string {
-	return fmt.Sprintf(`[yellow]Resource Statistics[white]
+	return fmt.Sprintf(`[title]Resource Statistics[text]
 
-[yellow]CPU Performance[white]
+[title]CPU Performance[text]
 %s
 
-[yellow]Memory Usage[white]
+[title]Per-Core Usage[text]
 %s
 
-[yellow]Network Activity[white]
+[title]Memory Usage[text]
 %s
 
-[yellow]Storage I/O[white]
+[title]Network Activity[text]
 %s
 
-[yellow]Process Information[white]
-  Active PIDs: [cyan]%d[white] processes
+[title]Storage I/O[text]
+%s
+
+[title]Process Information[text]
+  Active PIDs: [accent]%d[text] processes
 
-[gray]Last Updated: %s[white]`,
+[muted]Last Updated: %s[text]`,
 		s.visualizer.BuildCPUVisualization(stats.CPU),
+		s.visualizer.BuildPerCoreVisualization(stats.CPU),
 		s.visualizer.BuildMemoryVisualization(stats.Memory),
 		s.visualizer.BuildNetworkVisualization(stats.Network),
 		s.visualizer.BuildBlockIOVisualization(stats.BlockIO),
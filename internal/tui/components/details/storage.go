@@ -29,24 +29,31 @@ func (s *StorageTab) Render(container *models.Container) string {
 		s.renderBlockIOStats(container),
 	}
 
-	return fmt.Sprintf("[yellow]Storage Configuration[white]\n\n%s", strings.Join(sections, "\n\n"))
+	return fmt.Sprintf("[title]Storage Configuration[text]\n\n%s", strings.Join(sections, "\n\n"))
 }
 
 func (s *StorageTab) renderMounts(c *models.Container) string {
 	mountsTable := s.tableBuilder.BuildMountsTable(c.Mounts)
-	return fmt.Sprintf("[yellow]Mounts (%d)[white]\n%s", len(c.Mounts), mountsTable)
+	return fmt.Sprintf("[title]Mounts (%d)[text]\n%s", len(c.Mounts), mountsTable)
 }
 
 func (s *StorageTab) renderBlockIOStats(c *models.Container) string {
 	if c.Stats == nil {
-		return "[yellow]Block I/O Statistics[white]\n  [gray]Block I/O statistics not available[white]"
+		return "[title]Block I/O Statistics[text]\n  [muted]Block I/O statistics not available[text]"
 	}
 
-	return fmt.Sprintf(`[yellow]Block I/O Statistics[white]
-  Read     : %s (%s operations)
-  Write    : %s (%s operations)`,
-		s.formatter.FormatBytes(c.Stats.BlockIO.ReadBytes),
-		s.formatter.FormatNumber(c.Stats.BlockIO.ReadOps),
-		s.formatter.FormatBytes(c.Stats.BlockIO.WriteBytes),
-		s.formatter.FormatNumber(c.Stats.BlockIO.WriteOps))
+	blockIO := c.Stats.BlockIO
+	return fmt.Sprintf(`[title]Block I/O Statistics[text]
+  Read     : [accent]%s[text] (%s) | total %s (%s operations)
+  Write    : [accent]%s[text] (%s) | total %s (%s operations)
+  IOPS     : %s`,
+		s.formatter.FormatRate(blockIO.Rate.ReadBytes),
+		s.formatter.FormatPerSecond(blockIO.Rate.ReadOps, "ops"),
+		s.formatter.FormatBytes(blockIO.ReadBytes),
+		s.formatter.FormatNumber(blockIO.ReadOps),
+		s.formatter.FormatRate(blockIO.Rate.WriteBytes),
+		s.formatter.FormatPerSecond(blockIO.Rate.WriteOps, "ops"),
+		s.formatter.FormatBytes(blockIO.WriteBytes),
+		s.formatter.FormatNumber(blockIO.WriteOps),
+		s.formatter.FormatPerSecond(blockIO.IOPS(), "ops"))
 }
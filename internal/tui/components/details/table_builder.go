@@ -19,12 +19,12 @@ func NewTableBuilder() *TableBuilder {
 
 func (t *TableBuilder) BuildPortsTable(ports []models.Port) string {
 	if len(ports) == 0 {
-		return "  [gray]No ports exposed[white]"
+		return "  [muted]No ports exposed[text]"
 	}
 
 	var result strings.Builder
-	result.WriteString("  [gray]Private    Public     Type    IP[white]\n")
-	result.WriteString("  [gray]─────────────────────────────────────[white]\n")
+	result.WriteString("  [muted]Private    Public     Type    IP[text]\n")
+	result.WriteString("  [muted]─────────────────────────────────────[text]\n")
 
 	for _, port := range ports {
 		publicStr := "─"
@@ -46,12 +46,12 @@ func (t *TableBuilder) BuildPortsTable(ports []models.Port) string {
 
 func (t *TableBuilder) BuildNetworksTable(networks []models.Network) string {
 	if len(networks) == 0 {
-		return "  [gray]No networks configured[white]"
+		return "  [muted]No networks configured[text]"
 	}
 
 	var result strings.Builder
-	result.WriteString("  [gray]Network          IP Address       Gateway[white]\n")
-	result.WriteString("  [gray]───────────────────────────────────────────────[white]\n")
+	result.WriteString("  [muted]Network          IP Address       Gateway[text]\n")
+	result.WriteString("  [muted]───────────────────────────────────────────────[text]\n")
 
 	for _, network := range networks {
 		ipStr := network.IPAddress
@@ -71,14 +71,39 @@ func (t *TableBuilder) BuildNetworksTable(networks []models.Network) string {
 	return strings.TrimSuffix(result.String(), "\n")
 }
 
+func (t *TableBuilder) BuildInterfacesTable(interfaces []models.NetworkInterface) string {
+	if len(interfaces) == 0 {
+		return "  [muted]No interface statistics[text]"
+	}
+
+	var result strings.Builder
+	result.WriteString("  [muted]Interface   RX rate     TX rate     RX pkt/s  TX pkt/s  RX total   TX total   Err  Drop[text]\n")
+	result.WriteString("  [muted]──────────────────────────────────────────────────────────────────────────────────────[text]\n")
+
+	for _, iface := range interfaces {
+		result.WriteString(fmt.Sprintf("  %-10s  %-10s  %-10s  %-8.1f  %-8.1f  %-9s  %-9s  %-3d  %d\n",
+			t.formatter.TruncateString(iface.Name, 10),
+			t.formatter.FormatRate(iface.Rate.RxBytes),
+			t.formatter.FormatRate(iface.Rate.TxBytes),
+			iface.Rate.RxPackets,
+			iface.Rate.TxPackets,
+			t.formatter.FormatBytes(iface.RxBytes),
+			t.formatter.FormatBytes(iface.TxBytes),
+			iface.RxErrors+iface.TxErrors,
+			iface.RxDropped+iface.TxDropped))
+	}
+
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
 func (t *TableBuilder) BuildMountsTable(mounts []models.Mount) string {
 	if len(mounts) == 0 {
-		return "  [gray]No mounts configured[white]"
+		return "  [muted]No mounts configured[text]"
 	}
 
 	var result strings.Builder
-	result.WriteString("  [gray]Source                    Destination              Type    Mode[white]\n")
-	result.WriteString("  [gray]─────────────────────────────────────────────────────────────────────[white]\n")
+	result.WriteString("  [muted]Source                    Destination              Type    Mode[text]\n")
+	result.WriteString("  [muted]─────────────────────────────────────────────────────────────────────[text]\n")
 
 	for _, mount := range mounts {
 		modeStr := mount.Mode
@@ -27,28 +27,49 @@ func (v *StatsVisualizer) BuildProgressBar(percentage float64, width int, label
 		filled = width
 	}
 
-	color := v.formatter.GetUsageColor(percentage)
+	color := v.formatter.GetUsageRole(percentage)
 	bar := strings.Repeat("█", filled)
 	empty := strings.Repeat("░", width-filled)
 
-	return fmt.Sprintf("[%s]%s[gray]%s[white] %.1f%% %s",
+	return fmt.Sprintf("[%s]%s[muted]%s[text] %.1f%% %s",
 		color, bar, empty, percentage, label)
 }
 
 func (v *StatsVisualizer) BuildMemoryVisualization(mem models.ContainerMemory) string {
 	if mem.Limit == 0 {
-		return "[gray]No memory limit set[white]"
+		return "[muted]No memory limit set[text]"
 	}
 
 	usagePercentage := mem.Percentage()
 	cachePercentage := float64(mem.Cache) / float64(mem.Limit) * 100
 	rssPercentage := float64(mem.RSS) / float64(mem.Limit) * 100
 
+	rssLabel, cacheLabel := "RSS", "Cache"
+	if mem.CgroupVersion == 2 {
+		rssLabel, cacheLabel = "Anon", "File"
+	}
+
 	var result strings.Builder
-	result.WriteString("  Memory Layout:\n")
-	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(usagePercentage, 40, "Total")))
-	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(rssPercentage, 40, "RSS")))
-	result.WriteString(fmt.Sprintf("  %s", v.BuildProgressBar(cachePercentage, 40, "Cache")))
+	if mem.CgroupVersion > 0 {
+		result.WriteString(fmt.Sprintf("  Memory Layout: [muted](cgroup v%d, %s excl. %s inactive cache)[text]\n",
+			mem.CgroupVersion,
+			v.formatter.FormatBytes(mem.RawUsage),
+			v.formatter.FormatBytes(mem.InactiveFile)))
+	} else {
+		result.WriteString("  Memory Layout:\n")
+	}
+	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(usagePercentage, 40, "Used")))
+	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(rssPercentage, 40, rssLabel)))
+	result.WriteString(fmt.Sprintf("  %s", v.BuildProgressBar(cachePercentage, 40, cacheLabel)))
+
+	if !mem.SwapReported() {
+		return result.String()
+	}
+	if mem.SwapLimit > 0 {
+		result.WriteString(fmt.Sprintf("\n  %s", v.BuildProgressBar(mem.SwapPercentage(), 40, "Swap")))
+	} else if mem.Swap > 0 {
+		result.WriteString(fmt.Sprintf("\n  Swap: %s", v.formatter.FormatBytes(mem.Swap)))
+	}
 
 	return result.String()
 }
@@ -56,66 +77,85 @@ func (v *StatsVisualizer) BuildMemoryVisualization(mem models.ContainerMemory) s
 func (v *StatsVisualizer) BuildCPUVisualization(cpu models.ContainerCPU) string {
 	var result strings.Builder
 
-	result.WriteString("  CPU Usage:\n")
-	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(cpu.Usage, 40, fmt.Sprintf("(%d cores)", cpu.Cores))))
+	hostPercentage := cpu.Usage
+	if cpu.Cores > 0 {
+		hostPercentage = cpu.Usage / float64(cpu.Cores)
+	}
+
+	result.WriteString(fmt.Sprintf("  CPU Usage: [accent]%.1f%%[text] [muted](100%% = 1 core)[text]\n", cpu.Usage))
+	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(hostPercentage, 40, fmt.Sprintf("of %d host cores", cpu.Cores))))
+	if cpu.Limit > 0 {
+		result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(cpu.QuotaPercentage(), 40, fmt.Sprintf("of %s limit", cpu.LimitString()))))
+	} else {
+		result.WriteString("  [muted]No CPU limit set[text]\n")
+	}
+	result.WriteString(fmt.Sprintf("  User: [accent]%.1f%%[text] | Kernel: [accent]%.1f%%[text]", cpu.User, cpu.System))
 
 	if cpu.Throttling.Periods > 0 {
 		throttlePercentage := float64(cpu.Throttling.ThrottledPeriods) / float64(cpu.Throttling.Periods) * 100
-		result.WriteString(fmt.Sprintf("  %s", v.BuildProgressBar(throttlePercentage, 40, "Throttled")))
+		result.WriteString(fmt.Sprintf("\n  %s", v.BuildProgressBar(throttlePercentage, 40, "Throttled")))
 	}
 
 	return result.String()
 }
 
-func (v *StatsVisualizer) BuildNetworkVisualization(network models.ContainerNetwork) string {
-	if network.RxBytes == 0 && network.TxBytes == 0 {
-		return "  [gray]No network activity[white]"
+func (v *StatsVisualizer) BuildPerCoreVisualization(cpu models.ContainerCPU) string {
+	if len(cpu.PerCore) == 0 {
+		return "  [muted]Per-core usage not available, either not sampled yet or not reported by this runtime[text]"
 	}
 
-	maxBytes := network.RxBytes
-	if network.TxBytes > maxBytes {
-		maxBytes = network.TxBytes
+	var lines []string
+	for i, usage := range cpu.PerCore {
+		lines = append(lines, fmt.Sprintf("  %s", v.BuildProgressBar(usage, 20, fmt.Sprintf("cpu%d", i))))
 	}
+	return strings.Join(lines, "\n")
+}
 
-	rxPercentage := float64(network.RxBytes) / float64(maxBytes) * 100
-	txPercentage := float64(network.TxBytes) / float64(maxBytes) * 100
+func (v *StatsVisualizer) BuildNetworkVisualization(network models.ContainerNetwork) string {
+	if network.RxBytes == 0 && network.TxBytes == 0 {
+		return "  [muted]No network activity[text]"
+	}
 
 	var result strings.Builder
 	result.WriteString("  Network I/O:\n")
-	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(rxPercentage, 35, fmt.Sprintf("RX %s", v.formatter.FormatBytes(network.RxBytes)))))
-	result.WriteString(fmt.Sprintf("  %s", v.BuildProgressBar(txPercentage, 35, fmt.Sprintf("TX %s", v.formatter.FormatBytes(network.TxBytes)))))
+	result.WriteString(fmt.Sprintf("  RX  [accent]%-10s[text]  %-14s  [muted]total %s[text]\n",
+		v.formatter.FormatRate(network.Rate.RxBytes),
+		v.formatter.FormatPerSecond(network.Rate.RxPackets, "pkt"),
+		v.formatter.FormatBytes(network.RxBytes)))
+	result.WriteString(fmt.Sprintf("  TX  [accent]%-10s[text]  %-14s  [muted]total %s[text]",
+		v.formatter.FormatRate(network.Rate.TxBytes),
+		v.formatter.FormatPerSecond(network.Rate.TxPackets, "pkt"),
+		v.formatter.FormatBytes(network.TxBytes)))
 
 	return result.String()
 }
 
 func (v *StatsVisualizer) BuildBlockIOVisualization(blockIO models.ContainerBlockIO) string {
 	if blockIO.ReadBytes == 0 && blockIO.WriteBytes == 0 {
-		return "  [gray]No block I/O activity[white]"
-	}
-
-	maxBytes := blockIO.ReadBytes
-	if blockIO.WriteBytes > maxBytes {
-		maxBytes = blockIO.WriteBytes
+		return "  [muted]No block I/O activity[text]"
 	}
 
-	readPercentage := float64(blockIO.ReadBytes) / float64(maxBytes) * 100
-	writePercentage := float64(blockIO.WriteBytes) / float64(maxBytes) * 100
-
 	var result strings.Builder
 	result.WriteString("  Block I/O:\n")
-	result.WriteString(fmt.Sprintf("  %s\n", v.BuildProgressBar(readPercentage, 35, fmt.Sprintf("Read %s", v.formatter.FormatBytes(blockIO.ReadBytes)))))
-	result.WriteString(fmt.Sprintf("  %s", v.BuildProgressBar(writePercentage, 35, fmt.Sprintf("Write %s", v.formatter.FormatBytes(blockIO.WriteBytes)))))
+	result.WriteString(fmt.Sprintf("  Read   [accent]%-10s[text]  %-14s  [muted]total %s[text]\n",
+		v.formatter.FormatRate(blockIO.Rate.ReadBytes),
+		v.formatter.FormatPerSecond(blockIO.Rate.ReadOps, "ops"),
+		v.formatter.FormatBytes(blockIO.ReadBytes)))
+	result.WriteString(fmt.Sprintf("  Write  [accent]%-10s[text]  %-14s  [muted]total %s[text]",
+		v.formatter.FormatRate(blockIO.Rate.WriteBytes),
+		v.formatter.FormatPerSecond(blockIO.Rate.WriteOps, "ops"),
+		v.formatter.FormatBytes(blockIO.WriteBytes)))
 
 	return result.String()
 }
 
 func (v *StatsVisualizer) BuildStatsPlaceholder() string {
 	return `
-[gray]┌─────────────────────────────────┐[white]
-[gray]│[white]  CPU Usage    : N/A             [gray]│[white]
-[gray]│[white]  Memory Usage : N/A             [gray]│[white]
-[gray]│[white]  Network I/O  : N/A             [gray]│[white]
-[gray]│[white]  Block I/O    : N/A             [gray]│[white]
-[gray]│[white]  PIDs         : N/A             [gray]│[white]
-[gray]└─────────────────────────────────┘[white]`
+[muted]┌─────────────────────────────────┐[text]
+[muted]│[text]  CPU Usage    : N/A             [muted]│[text]
+[muted]│[text]  Memory Usage : N/A             [muted]│[text]
+[muted]│[text]  Network I/O  : N/A             [muted]│[text]
+[muted]│[text]  Block I/O    : N/A             [muted]│[text]
+[muted]│[text]  PIDs         : N/A             [muted]│[text]
+[muted]└─────────────────────────────────┘[text]`
 }
@@ -0,0 +1,51 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyScore matches pattern as a case-insensitive subsequence of text.
+// Consecutive runs and matches at word boundaries score higher, so "wb1"
+// ranks "web-1" above "worker-b-01".
+func FuzzyScore(pattern, text string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+
+	p := []rune(strings.ToLower(pattern))
+	t := []rune(strings.ToLower(text))
+
+	score := 0
+	pi := 0
+	streak := 0
+	for ti := 0; ti < len(t) && pi < len(p); ti++ {
+		if t[ti] != p[pi] {
+			streak = 0
+			continue
+		}
+
+		score++
+		if streak > 0 {
+			score += 2 * streak
+		}
+		if ti == 0 || isWordBoundary(t[ti-1]) {
+			score += 3
+		}
+		streak++
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, false
+	}
+
+	if strings.HasPrefix(string(t), string(p)) {
+		score += 5
+	}
+	return score - len(t)/10, true
+}
+
+func isWordBoundary(r rune) bool {
+	return r == '-' || r == '_' || r == '/' || r == '.' || r == ':' || unicode.IsSpace(r)
+}
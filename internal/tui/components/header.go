@@ -2,14 +2,20 @@ package components
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/kqnd/kernus/internal/tui/theme"
 	"github.com/rivo/tview"
 )
 
 type Header struct {
 	server string
 	group  string
+	notice string
+	firing int
+	status func() string
+	hosts  []HostState
 	view   *tview.TextView
 	ticker *time.Ticker
 	stopCh chan bool
@@ -44,15 +50,45 @@ func (h *Header) setupView() {
 
 func (h *Header) updateContent() {
 	currentTime := time.Now().Format("15:04:05")
-	headerText := fmt.Sprintf("[yellow]Server:[white] %s", h.server)
+	headerText := fmt.Sprintf("[title]Server:[text] %s", h.server)
 	if h.group != "" {
-		headerText += fmt.Sprintf(" [yellow]| Group:[white] %s", h.group)
+		headerText += fmt.Sprintf(" [title]| Group:[text] %s", h.group)
 	}
 
-	headerText += fmt.Sprintf(" [yellow]| Time:[white] %s", currentTime)
-	headerText += " [yellow]| Status:[green] Connected[white]"
+	headerText += fmt.Sprintf(" [title]| Time:[text] %s", currentTime)
+	if h.firing > 0 {
+		headerText += fmt.Sprintf(" [title]| Alerts:[error] %d firing[text]", h.firing)
+	}
+	headerText += " [title]| Status:" + h.statusText()
+	if h.notice != "" {
+		headerText += fmt.Sprintf(" [title]|[accent] %s[text]", tview.Escape(h.notice))
+	}
+
+	h.view.SetText(theme.Apply(headerText))
+	if h.status != nil {
+		h.view.SetTitle(" Replay " + tview.Escape(h.status()) + " ")
+	}
+}
+
+func (h *Header) statusText() string {
+	var down []string
+	for _, host := range h.hosts {
+		if host.Err != nil {
+			down = append(down, tview.Escape(host.Name))
+		}
+	}
 
-	h.view.SetText(headerText)
+	switch {
+	case len(h.hosts) <= 1 && len(down) == 0:
+		return "[success] Connected[text]"
+	case len(h.hosts) <= 1:
+		return "[error] Disconnected[text]"
+	case len(down) == 0:
+		return fmt.Sprintf("[success] %d hosts connected[text]", len(h.hosts))
+	default:
+		return fmt.Sprintf("[warning] %d/%d hosts connected[text] [muted](down: %s)[text]",
+			len(h.hosts)-len(down), len(h.hosts), strings.Join(down, ", "))
+	}
 }
 
 func (h *Header) startClock() {
@@ -71,6 +107,34 @@ func (h *Header) startClock() {
 	}()
 }
 
+func (h *Header) SetNotice(notice string) {
+	h.notice = notice
+	h.updateContent()
+}
+
+// SetPlayback shows the status of a replayed session as the title, read
+// again on every clock tick.
+func (h *Header) SetPlayback(status func() string) {
+	h.status = status
+	h.updateContent()
+}
+
+// Refresh redraws the header content right away.
+func (h *Header) Refresh() {
+	h.updateContent()
+}
+
+// SetAlerts shows how many alerts are firing, if any.
+func (h *Header) SetAlerts(firing int) {
+	h.firing = firing
+	h.updateContent()
+}
+
+func (h *Header) SetHosts(hosts []HostState) {
+	h.hosts = hosts
+	h.updateContent()
+}
+
 func (h *Header) Stop() {
 	if h.ticker != nil {
 		h.ticker.Stop()
@@ -0,0 +1,87 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+type HelpView struct {
+	text *tview.TextView
+	view *tview.Flex
+	keys *keymap.Keymap
+}
+
+var scopeTitles = map[keymap.Scope]string{
+	keymap.ScopeGlobal:   "Global",
+	keymap.ScopeList:     "Container List",
+	keymap.ScopeTable:    "Container Table",
+	keymap.ScopeDetails:  "Details",
+	keymap.ScopeImages:   "Images",
+	keymap.ScopeVolumes:  "Volumes",
+	keymap.ScopeNetworks: "Networks",
+	keymap.ScopeAlerts:   "Alerts",
+	keymap.ScopeReplay:   "Replay",
+}
+
+func NewHelpView(keys *keymap.Keymap) *HelpView {
+	h := &HelpView{
+		text: tview.NewTextView(),
+		keys: keys,
+	}
+
+	h.text.SetDynamicColors(true).
+		SetScrollable(true).
+		SetBorder(true).
+		SetTitle(" Key Bindings (any key to close) ")
+
+	h.view = tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(h.text, 0, 4, true).
+			AddItem(nil, 0, 1, false), 70, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	return h
+}
+
+// Show lists the bindings of the focused scope, the global ones and any
+// extra scopes that currently apply.
+func (h *HelpView) Show(focused keymap.Scope, extra ...keymap.Scope) {
+	sections := []string{
+		h.renderScope(focused, true),
+		h.renderScope(keymap.ScopeGlobal, false),
+	}
+	for _, scope := range extra {
+		sections = append(sections, h.renderScope(scope, false))
+	}
+	h.text.SetText(theme.Apply(strings.Join(sections, "\n\n")))
+	h.text.ScrollToBeginning()
+}
+
+func (h *HelpView) renderScope(scope keymap.Scope, focused bool) string {
+	title := scopeTitles[scope]
+	if focused {
+		title += " (focused)"
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[title]%s[text]\n", title))
+	for _, binding := range h.keys.Bindings(scope) {
+		result.WriteString(fmt.Sprintf("  [accent]%-16s[text] %s\n",
+			tview.Escape(binding.KeysString()), binding.Description))
+	}
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+func (h *HelpView) GetView() tview.Primitive {
+	return h.view
+}
+
+func (h *HelpView) GetFocusTarget() tview.Primitive {
+	return h.text
+}
@@ -0,0 +1,280 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+const ResourceImages = "images"
+
+type ImagesView struct {
+	layout    *tview.Flex
+	table     *tview.Table
+	history   *tview.TextView
+	formatter *details.Formatter
+	keys      *keymap.Keymap
+
+	images []models.Image
+
+	onHistory func(*models.Image)
+	onRemove  func(*models.Image)
+	onPrune   func([]models.Image)
+}
+
+func NewImagesView(keys *keymap.Keymap) *ImagesView {
+	iv := &ImagesView{
+		table:     tview.NewTable(),
+		history:   tview.NewTextView(),
+		formatter: details.NewFormatter(),
+		keys:      keys,
+	}
+
+	iv.setupView()
+	iv.setupKeyBindings()
+	iv.refreshView()
+	return iv
+}
+
+func (iv *ImagesView) setupView() {
+	iv.table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	iv.table.SetBorder(true)
+
+	iv.table.SetSelectionChangedFunc(func(row, column int) {
+		if img := iv.imageAt(row); img != nil {
+			iv.showSummary(img)
+		}
+	})
+
+	iv.history.SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetBorder(true).
+		SetTitle(" Image ")
+
+	iv.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(iv.table, 0, 3, true).
+		AddItem(iv.history, 0, 2, false)
+}
+
+func (iv *ImagesView) setupKeyBindings() {
+	iv.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := iv.keys.Match(keymap.ScopeImages, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionSelect:
+			if img := iv.GetSelectedImage(); img != nil && iv.onHistory != nil {
+				iv.onHistory(img)
+			}
+			return nil
+		case keymap.ActionRemoveImage:
+			if img := iv.GetSelectedImage(); img != nil && iv.onRemove != nil {
+				iv.onRemove(img)
+			}
+			return nil
+		case keymap.ActionPrune:
+			if iv.onPrune != nil {
+				iv.onPrune(iv.DanglingImages())
+			}
+			return nil
+		}
+		return event
+	})
+}
+
+func (iv *ImagesView) SetHistoryFunc(fn func(*models.Image)) {
+	iv.onHistory = fn
+}
+
+func (iv *ImagesView) SetRemoveFunc(fn func(*models.Image)) {
+	iv.onRemove = fn
+}
+
+func (iv *ImagesView) SetPruneFunc(fn func([]models.Image)) {
+	iv.onPrune = fn
+}
+
+func (iv *ImagesView) SetImages(images []models.Image) {
+	var selectedID string
+	if img := iv.GetSelectedImage(); img != nil {
+		selectedID = img.ID
+	}
+
+	iv.images = images
+	iv.refreshView()
+
+	for i := range iv.images {
+		if iv.images[i].ID == selectedID {
+			iv.table.Select(i+1, 0)
+			return
+		}
+	}
+}
+
+func (iv *ImagesView) DanglingImages() []models.Image {
+	var dangling []models.Image
+	for _, img := range iv.images {
+		if img.Dangling() {
+			dangling = append(dangling, img)
+		}
+	}
+	return dangling
+}
+
+func (iv *ImagesView) refreshView() {
+	headers := []string{"REPOSITORY:TAG", "ID", "SIZE", "SHARED", "UNIQUE", "CREATED", "CONTAINERS"}
+
+	iv.table.Clear()
+	for col, header := range headers {
+		iv.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(theme.TcellColor("title")).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	var total, reclaimable int64
+	for row := range iv.images {
+		img := &iv.images[row]
+		total += img.UniqueSize()
+		if img.Dangling() || !img.InUse() {
+			reclaimable += img.UniqueSize()
+		}
+
+		textRole := "text"
+		if img.Dangling() {
+			textRole = "dim"
+		}
+
+		cells := []string{
+			img.RepoTag(),
+			img.ShortID(),
+			iv.formatter.FormatBytes(img.Size),
+			iv.sharedSizeText(img),
+			iv.formatter.FormatBytes(img.UniqueSize()),
+			img.FormatAge(),
+			iv.usedByText(img),
+		}
+		for col, text := range cells {
+			cell := tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(theme.TcellColor(textRole)).
+				SetExpansion(1)
+			if col == len(cells)-1 && img.InUse() {
+				cell.SetTextColor(theme.TcellColor("status.running"))
+			}
+			iv.table.SetCell(row+1, col, cell)
+		}
+	}
+
+	iv.table.SetTitle(fmt.Sprintf(" Images (%d, %s, %s reclaimable, %d dangling) ",
+		len(iv.images), iv.formatter.FormatBytes(total), iv.formatter.FormatBytes(reclaimable), len(iv.DanglingImages())))
+
+	if len(iv.images) > 0 {
+		row, _ := iv.table.GetSelection()
+		if row < 1 || row > len(iv.images) {
+			iv.table.Select(1, 0)
+		}
+	} else {
+		iv.history.SetText(theme.Apply("[muted]No images[text]"))
+	}
+}
+
+// Docker reports -1 when the shared size was not computed.
+func (iv *ImagesView) sharedSizeText(img *models.Image) string {
+	if img.SharedSize < 0 {
+		return "n/a"
+	}
+	return iv.formatter.FormatBytes(img.SharedSize)
+}
+
+func (iv *ImagesView) usedByText(img *models.Image) string {
+	switch len(img.Containers) {
+	case 0:
+		return "unused"
+	case 1:
+		return img.Containers[0]
+	default:
+		return fmt.Sprintf("%s +%d", img.Containers[0], len(img.Containers)-1)
+	}
+}
+
+func (iv *ImagesView) showSummary(img *models.Image) {
+	iv.history.SetTitle(fmt.Sprintf(" Image - %s ", tview.Escape(img.RepoTag())))
+	iv.history.SetText(theme.Apply(iv.renderSummary(img) + fmt.Sprintf(
+		"\n[dim]Press %s for layer history[text]", iv.keys.KeysFor(keymap.ScopeImages, keymap.ActionSelect))))
+}
+
+func (iv *ImagesView) renderSummary(img *models.Image) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[title]%s[text]  [muted]%s[text]\n", tview.Escape(img.RepoTag()), img.ShortID()))
+	if len(img.RepoTags) > 1 {
+		result.WriteString(fmt.Sprintf("  Tags      : %s\n", tview.Escape(strings.Join(img.RepoTags, ", "))))
+	}
+	result.WriteString(fmt.Sprintf("  Size      : %s (shared %s, unique %s)\n",
+		iv.formatter.FormatBytes(img.Size), iv.sharedSizeText(img), iv.formatter.FormatBytes(img.UniqueSize())))
+	result.WriteString(fmt.Sprintf("  Created   : %s (%s ago)\n", iv.formatter.FormatTime(img.Created), img.FormatAge()))
+
+	if img.InUse() {
+		result.WriteString(fmt.Sprintf("  Used by   : [status.running]%s[text]\n", tview.Escape(strings.Join(img.Containers, ", "))))
+	} else {
+		result.WriteString("  Used by   : [muted]no containers[text]\n")
+	}
+	return result.String()
+}
+
+// ShowHistory renders the layers of an image, newest first as returned by
+// the daemon, with the instruction that created each one.
+func (iv *ImagesView) ShowHistory(img *models.Image, layers []models.ImageLayer, err error) {
+	var result strings.Builder
+	result.WriteString(iv.renderSummary(img))
+	result.WriteString("\n")
+
+	if err != nil {
+		result.WriteString(fmt.Sprintf("[error]Failed to load history: %s[text]", tview.Escape(err.Error())))
+	} else {
+		result.WriteString(fmt.Sprintf("[title]Layers (%d)[text]\n", len(layers)))
+		for _, layer := range layers {
+			sizeRole := "muted"
+			if layer.Size > 0 {
+				sizeRole = "accent"
+			}
+			result.WriteString(fmt.Sprintf("  [%s]%10s[text]  [dim]%-12s  %s[text]  %s\n",
+				sizeRole,
+				iv.formatter.FormatBytes(layer.Size),
+				layer.ShortID(),
+				layer.Created.Format("2006-01-02"),
+				tview.Escape(iv.formatter.TruncateString(layer.Instruction(), 120))))
+		}
+	}
+
+	iv.history.SetTitle(fmt.Sprintf(" Layers - %s ", tview.Escape(img.RepoTag())))
+	iv.history.SetText(theme.Apply(result.String()))
+	iv.history.ScrollToBeginning()
+}
+
+func (iv *ImagesView) imageAt(row int) *models.Image {
+	index := row - 1
+	if index >= 0 && index < len(iv.images) {
+		return &iv.images[index]
+	}
+	return nil
+}
+
+func (iv *ImagesView) GetSelectedImage() *models.Image {
+	row, _ := iv.table.GetSelection()
+	return iv.imageAt(row)
+}
+
+func (iv *ImagesView) GetView() tview.Primitive {
+	return iv.layout
+}
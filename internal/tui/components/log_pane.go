@@ -0,0 +1,54 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+const PaneLogs = "logs"
+
+type LogPane struct {
+	view             *tview.TextView
+	logsTab          *details.LogsTab
+	currentContainer *models.Container
+}
+
+func NewLogPane(keys *keymap.Keymap) *LogPane {
+	lp := &LogPane{
+		view: tview.NewTextView().
+			SetDynamicColors(true).
+			SetScrollable(true),
+		logsTab: details.NewLogsTab(),
+	}
+
+	lp.view.SetBorder(true).SetTitle(" Logs ")
+	lp.logsTab.SetRefreshHint(keys.KeysFor(keymap.ScopeDetails, keymap.ActionRefreshLogs))
+
+	return lp
+}
+
+func (lp *LogPane) ShowContainer(container *models.Container) {
+	changed := container != lp.currentContainer
+	lp.currentContainer = container
+
+	if container == nil {
+		lp.view.SetTitle(" Logs ")
+		lp.view.SetText("")
+		return
+	}
+
+	lp.view.SetTitle(fmt.Sprintf(" Logs - %s ", container.ShortName()))
+	lp.view.SetText(theme.Apply(lp.logsTab.Render(container)))
+	if changed {
+		lp.view.ScrollToEnd()
+	}
+}
+
+func (lp *LogPane) GetView() tview.Primitive {
+	return lp.view
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/theme"
 	"github.com/rivo/tview"
 )
 
@@ -65,7 +66,7 @@ func (ml *MachineList) refreshView() {
 		mainText := machine.Name
 		secondaryText := ml.formatSecondaryText(machine)
 
-		ml.list.AddItem(mainText, secondaryText, rune('0'+i%10), nil)
+		ml.list.AddItem(mainText, theme.Apply(secondaryText), rune('0'+i%10), nil)
 	}
 }
 
@@ -75,14 +76,14 @@ func (ml *MachineList) buildTitle() string {
 }
 
 func (ml *MachineList) formatSecondaryText(machine *models.Machine) string {
-	statusColor := machine.Status.Color()
+	statusColor := machine.Status.Role()
 
 	if machine.Status == models.StatusOffline {
 		timeSince := time.Since(machine.LastSeen)
-		return fmt.Sprintf("[%s]%s[white] | Last: %s", statusColor, machine.Status, ml.formatDuration(timeSince))
+		return fmt.Sprintf("[%s]%s[text] | Last: %s", statusColor, machine.Status, ml.formatDuration(timeSince))
 	}
 
-	return fmt.Sprintf("[%s]%s[white]  | CPU: %.1f%%", statusColor, machine.Status, machine.CPUUsage)
+	return fmt.Sprintf("[%s]%s[text]  | CPU: %.1f%%", statusColor, machine.Status, machine.CPUUsage)
 }
 
 func (ml *MachineList) formatDuration(d time.Duration) string {
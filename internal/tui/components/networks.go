@@ -0,0 +1,349 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+const ResourceNetworks = "networks"
+
+const (
+	topologyNameWidth   = 24
+	topologyColumnWidth = 12
+)
+
+type NetworksView struct {
+	layout    *tview.Flex
+	table     *tview.Table
+	info      *tview.TextView
+	formatter *details.Formatter
+	keys      *keymap.Keymap
+
+	networks     []models.DockerNetwork
+	showTopology bool
+}
+
+func NewNetworksView(keys *keymap.Keymap) *NetworksView {
+	nv := &NetworksView{
+		table:     tview.NewTable(),
+		info:      tview.NewTextView(),
+		formatter: details.NewFormatter(),
+		keys:      keys,
+	}
+
+	nv.setupView()
+	nv.setupKeyBindings()
+	nv.refreshView()
+	return nv
+}
+
+func (nv *NetworksView) setupView() {
+	nv.table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	nv.table.SetBorder(true)
+
+	nv.table.SetSelectionChangedFunc(func(row, column int) {
+		nv.refreshInfo()
+	})
+
+	nv.info.SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false).
+		SetBorder(true).
+		SetTitle(" Network ")
+
+	nv.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(nv.table, 0, 2, true).
+		AddItem(nv.info, 0, 3, false)
+}
+
+func (nv *NetworksView) setupKeyBindings() {
+	nv.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := nv.keys.Match(keymap.ScopeNetworks, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionSelect:
+			nv.showTopology = false
+			nv.refreshInfo()
+			return nil
+		case keymap.ActionTopology:
+			nv.showTopology = !nv.showTopology
+			nv.refreshInfo()
+			return nil
+		}
+		return event
+	})
+}
+
+func (nv *NetworksView) SetNetworks(networks []models.DockerNetwork) {
+	var selectedID string
+	if network := nv.GetSelectedNetwork(); network != nil {
+		selectedID = network.ID
+	}
+
+	nv.networks = networks
+	nv.refreshView()
+
+	for i := range nv.networks {
+		if nv.networks[i].ID == selectedID {
+			nv.table.Select(i+1, 0)
+			break
+		}
+	}
+	nv.refreshInfo()
+}
+
+func (nv *NetworksView) refreshView() {
+	headers := []string{"NAME", "ID", "DRIVER", "SCOPE", "SUBNET", "GATEWAY", "CONTAINERS"}
+
+	nv.table.Clear()
+	for col, header := range headers {
+		nv.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(theme.TcellColor("title")).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	for row := range nv.networks {
+		network := &nv.networks[row]
+
+		textRole := "text"
+		if network.Predefined() {
+			textRole = "muted"
+		}
+
+		cells := []string{
+			network.Name,
+			network.ShortID(),
+			network.Driver,
+			network.Scope,
+			network.SubnetString(),
+			network.GatewayString(),
+			fmt.Sprintf("%d", len(network.Endpoints)),
+		}
+		for col, text := range cells {
+			cell := tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(theme.TcellColor(textRole)).
+				SetExpansion(1)
+			if col == len(cells)-1 && len(network.Endpoints) == 0 {
+				cell.SetTextColor(theme.TcellColor("dim"))
+			}
+			nv.table.SetCell(row+1, col, cell)
+		}
+	}
+
+	nv.table.SetTitle(fmt.Sprintf(" Networks (%d) ", len(nv.networks)))
+
+	if len(nv.networks) > 0 {
+		row, _ := nv.table.GetSelection()
+		if row < 1 || row > len(nv.networks) {
+			nv.table.Select(1, 0)
+		}
+	}
+}
+
+func (nv *NetworksView) refreshInfo() {
+	network := nv.GetSelectedNetwork()
+
+	switch {
+	case nv.showTopology:
+		nv.info.SetTitle(" Topology ")
+		nv.info.SetText(theme.Apply(nv.renderTopology(network)))
+	case network != nil:
+		nv.info.SetTitle(fmt.Sprintf(" Network - %s ", tview.Escape(network.Name)))
+		nv.info.SetText(theme.Apply(nv.renderNetwork(network)))
+	default:
+		nv.info.SetTitle(" Network ")
+		nv.info.SetText(theme.Apply("[muted]No networks[text]"))
+	}
+	nv.info.ScrollToBeginning()
+}
+
+func (nv *NetworksView) renderNetwork(network *models.DockerNetwork) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[title]%s[text]  [muted]%s[text]\n", tview.Escape(network.Name), network.ShortID()))
+	result.WriteString(fmt.Sprintf("  Driver    : %s (%s scope)\n", tview.Escape(network.Driver), tview.Escape(network.Scope)))
+	result.WriteString(fmt.Sprintf("  Subnet    : %s\n", tview.Escape(network.SubnetString())))
+	result.WriteString(fmt.Sprintf("  Gateway   : %s\n", tview.Escape(network.GatewayString())))
+
+	var flags []string
+	if network.Internal {
+		flags = append(flags, "internal")
+	}
+	if network.IPv6 {
+		flags = append(flags, "ipv6")
+	}
+	if network.Predefined() {
+		flags = append(flags, "predefined")
+	}
+	if len(flags) > 0 {
+		result.WriteString(fmt.Sprintf("  Flags     : %s\n", strings.Join(flags, ", ")))
+	}
+
+	result.WriteString(fmt.Sprintf("\n[title]Containers (%d)[text]\n", len(network.Endpoints)))
+	if len(network.Endpoints) == 0 {
+		result.WriteString("  [muted]No containers attached[text]\n")
+	}
+	peers := models.NetworkPeers(nv.networks)
+	for _, endpoint := range network.Endpoints {
+		state, stateRole := "stopped", "muted"
+		if endpoint.Running {
+			state, stateRole = "running", "status.running"
+		}
+
+		var others []string
+		for _, name := range peers[endpoint.Container] {
+			if name != network.Name {
+				others = append(others, name)
+			}
+		}
+
+		line := fmt.Sprintf("  [%s]%-8s[text] %-*s [accent]%-18s[text] [dim]%s[text]",
+			stateRole, state, topologyNameWidth, tview.Escape(nv.formatter.TruncateString(endpoint.Container, topologyNameWidth)),
+			endpoint.Address(), endpoint.MacAddress)
+		if len(others) > 0 {
+			line += fmt.Sprintf(" [muted]also on %s[text]", tview.Escape(strings.Join(others, ", ")))
+		}
+		result.WriteString(line + "\n")
+	}
+
+	result.WriteString(fmt.Sprintf("\n[dim]Press %s for the topology graph[text]", nv.keys.KeysFor(keymap.ScopeNetworks, keymap.ActionTopology)))
+	return result.String()
+}
+
+// renderTopology draws one column per network that has containers and one
+// row per container, with a dot where the container is attached. Dots on
+// the same row are joined, so containers that bridge networks stand out.
+func (nv *NetworksView) renderTopology(selected *models.DockerNetwork) string {
+	var columns []*models.DockerNetwork
+	for i := range nv.networks {
+		if len(nv.networks[i].Endpoints) > 0 {
+			columns = append(columns, &nv.networks[i])
+		}
+	}
+	if len(columns) == 0 {
+		return "[muted]No containers are attached to any network[text]"
+	}
+
+	running := make(map[string]bool)
+	for _, network := range columns {
+		for _, endpoint := range network.Endpoints {
+			running[endpoint.Container] = running[endpoint.Container] || endpoint.Running
+		}
+	}
+	peers := models.NetworkPeers(nv.networks)
+	names := make([]string, 0, len(peers))
+	for name := range peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result strings.Builder
+	result.WriteString(strings.Repeat(" ", topologyNameWidth+2))
+	for _, network := range columns {
+		role := "title"
+		if selected != nil && network.ID == selected.ID {
+			role = "accent"
+		}
+		label := nv.formatter.TruncateString(network.Name, topologyColumnWidth-2)
+		result.WriteString(fmt.Sprintf("[%s]%-*s[text]", role, topologyColumnWidth, tview.Escape(label)))
+	}
+	result.WriteString("\n")
+
+	for _, name := range names {
+		nameRole := "text"
+		if selected != nil && !selected.HasContainer(name) {
+			nameRole = "dim"
+		}
+		result.WriteString(fmt.Sprintf("[%s]%-*s[text]  ", nameRole, topologyNameWidth,
+			tview.Escape(nv.formatter.TruncateString(name, topologyNameWidth))))
+
+		first, last := -1, -1
+		for i, network := range columns {
+			if network.HasContainer(name) {
+				if first < 0 {
+					first = i
+				}
+				last = i
+			}
+		}
+
+		dotRole := "muted"
+		if running[name] {
+			dotRole = "status.running"
+		}
+		for i, network := range columns {
+			switch {
+			case network.HasContainer(name):
+				result.WriteString(fmt.Sprintf("[%s]●[text]", dotRole))
+			case i > first && i < last:
+				result.WriteString("[dim]─[text]")
+			default:
+				result.WriteString(" ")
+			}
+			if i >= first && i < last {
+				result.WriteString("[dim]" + strings.Repeat("─", topologyColumnWidth-1) + "[text]")
+			} else {
+				result.WriteString(strings.Repeat(" ", topologyColumnWidth-1))
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	var bridges []string
+	for _, name := range names {
+		if len(peers[name]) > 1 {
+			bridges = append(bridges, fmt.Sprintf("  %s [muted]joins[text] %s",
+				tview.Escape(name), tview.Escape(strings.Join(peers[name], ", "))))
+		}
+	}
+	result.WriteString("\n[title]Bridging containers[text]\n")
+	if len(bridges) == 0 {
+		result.WriteString("  [muted]No container is attached to more than one network[text]\n")
+	} else {
+		result.WriteString(strings.Join(bridges, "\n") + "\n")
+	}
+
+	var unused []string
+	for i := range nv.networks {
+		if len(nv.networks[i].Endpoints) == 0 && !nv.networks[i].Predefined() {
+			unused = append(unused, nv.networks[i].Name)
+		}
+	}
+	if len(unused) > 0 {
+		result.WriteString(fmt.Sprintf("\n[title]Unused networks[text]\n  [muted]%s[text]\n", tview.Escape(strings.Join(unused, ", "))))
+	}
+
+	result.WriteString(fmt.Sprintf("\n[status.running]●[text] running  [muted]●[text] stopped   [dim]Press %s for network details[text]",
+		nv.keys.KeysFor(keymap.ScopeNetworks, keymap.ActionSelect)))
+	return result.String()
+}
+
+func (nv *NetworksView) networkAt(row int) *models.DockerNetwork {
+	index := row - 1
+	if index >= 0 && index < len(nv.networks) {
+		return &nv.networks[index]
+	}
+	return nil
+}
+
+func (nv *NetworksView) GetSelectedNetwork() *models.DockerNetwork {
+	row, _ := nv.table.GetSelection()
+	return nv.networkAt(row)
+}
+
+func (nv *NetworksView) GetView() tview.Primitive {
+	return nv.layout
+}
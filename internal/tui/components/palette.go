@@ -0,0 +1,170 @@
+package components
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+type PaletteItem struct {
+	Label      string
+	Hint       string
+	Completion string
+	Run        func()
+}
+
+type CommandPalette struct {
+	input *tview.InputField
+	list  *tview.List
+	view  *tview.Flex
+
+	items     []PaletteItem
+	suggest   func(query string) []PaletteItem
+	onClose   func()
+	maxHeight int
+}
+
+func NewCommandPalette() *CommandPalette {
+	p := &CommandPalette{
+		input:     tview.NewInputField(),
+		list:      tview.NewList(),
+		maxHeight: 14,
+	}
+
+	p.setupView()
+	p.setupKeyBindings()
+	return p
+}
+
+func (p *CommandPalette) setupView() {
+	p.input.SetLabel(": ").
+		SetFieldBackgroundColor(tcell.ColorDefault).
+		SetChangedFunc(func(text string) {
+			p.refresh(text)
+		})
+
+	p.list.ShowSecondaryText(false).
+		SetHighlightFullLine(true).
+		SetSelectedFunc(func(int, string, string, rune) {
+			p.runSelected()
+		})
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(p.input, 1, 0, true).
+		AddItem(p.list, 0, 1, false)
+	box.SetBorder(true).SetTitle(" Command Palette ")
+
+	p.view = tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 3, 0, false).
+			AddItem(box, p.maxHeight+3, 0, true).
+			AddItem(nil, 0, 1, false), 80, 0, true).
+		AddItem(nil, 0, 1, false)
+}
+
+func (p *CommandPalette) setupKeyBindings() {
+	p.input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyDown, tcell.KeyCtrlN:
+			p.moveSelection(1)
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			p.moveSelection(-1)
+			return nil
+		case tcell.KeyTab:
+			p.complete()
+			return nil
+		case tcell.KeyEnter:
+			p.runSelected()
+			return nil
+		case tcell.KeyEscape:
+			p.Close()
+			return nil
+		}
+		return event
+	})
+}
+
+func (p *CommandPalette) moveSelection(delta int) {
+	count := p.list.GetItemCount()
+	if count == 0 {
+		return
+	}
+	p.list.SetCurrentItem((p.list.GetCurrentItem() + delta + count) % count)
+}
+
+func (p *CommandPalette) selected() *PaletteItem {
+	index := p.list.GetCurrentItem()
+	if index >= 0 && index < len(p.items) {
+		return &p.items[index]
+	}
+	return nil
+}
+
+func (p *CommandPalette) complete() {
+	if item := p.selected(); item != nil && item.Completion != "" {
+		p.input.SetText(item.Completion)
+	}
+}
+
+func (p *CommandPalette) runSelected() {
+	item := p.selected()
+	if item == nil {
+		return
+	}
+
+	if item.Run == nil {
+		p.complete()
+		return
+	}
+
+	p.Close()
+	item.Run()
+}
+
+func (p *CommandPalette) refresh(query string) {
+	p.items = nil
+	if p.suggest != nil {
+		p.items = p.suggest(query)
+	}
+	if len(p.items) > p.maxHeight {
+		p.items = p.items[:p.maxHeight]
+	}
+
+	p.list.Clear()
+	for _, item := range p.items {
+		text := item.Label
+		if item.Hint != "" {
+			text += "  [muted]" + item.Hint + "[text]"
+		}
+		p.list.AddItem(theme.Apply(text), "", 0, nil)
+	}
+}
+
+func (p *CommandPalette) Open(prefix string) {
+	p.input.SetText(prefix)
+	p.refresh(prefix)
+}
+
+func (p *CommandPalette) Close() {
+	if p.onClose != nil {
+		p.onClose()
+	}
+}
+
+func (p *CommandPalette) SetSuggestFunc(fn func(query string) []PaletteItem) {
+	p.suggest = fn
+}
+
+func (p *CommandPalette) SetCloseFunc(fn func()) {
+	p.onClose = fn
+}
+
+func (p *CommandPalette) GetInput() tview.Primitive {
+	return p.input
+}
+
+func (p *CommandPalette) GetView() tview.Primitive {
+	return p.view
+}
@@ -0,0 +1,290 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+const ResourceVolumes = "volumes"
+
+type VolumesView struct {
+	layout    *tview.Flex
+	table     *tview.Table
+	info      *tview.TextView
+	formatter *details.Formatter
+	keys      *keymap.Keymap
+
+	volumes []models.Volume
+
+	onRemove func(*models.Volume)
+	onPrune  func([]models.Volume)
+}
+
+func NewVolumesView(keys *keymap.Keymap) *VolumesView {
+	vv := &VolumesView{
+		table:     tview.NewTable(),
+		info:      tview.NewTextView(),
+		formatter: details.NewFormatter(),
+		keys:      keys,
+	}
+
+	vv.setupView()
+	vv.setupKeyBindings()
+	vv.refreshView()
+	return vv
+}
+
+func (vv *VolumesView) setupView() {
+	vv.table.SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	vv.table.SetBorder(true)
+
+	vv.table.SetSelectionChangedFunc(func(row, column int) {
+		if vol := vv.volumeAt(row); vol != nil {
+			vv.showVolume(vol)
+		}
+	})
+
+	vv.info.SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true).
+		SetBorder(true).
+		SetTitle(" Volume ")
+
+	vv.layout = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(vv.table, 0, 3, true).
+		AddItem(vv.info, 0, 2, false)
+}
+
+func (vv *VolumesView) setupKeyBindings() {
+	vv.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		action, ok := vv.keys.Match(keymap.ScopeVolumes, event)
+		if !ok {
+			return event
+		}
+
+		switch action {
+		case keymap.ActionRemoveVolume:
+			if vol := vv.GetSelectedVolume(); vol != nil && vv.onRemove != nil {
+				vv.onRemove(vol)
+			}
+			return nil
+		case keymap.ActionPrune:
+			if vv.onPrune != nil {
+				vv.onPrune(vv.OrphanedVolumes())
+			}
+			return nil
+		}
+		return event
+	})
+}
+
+func (vv *VolumesView) SetRemoveFunc(fn func(*models.Volume)) {
+	vv.onRemove = fn
+}
+
+func (vv *VolumesView) SetPruneFunc(fn func([]models.Volume)) {
+	vv.onPrune = fn
+}
+
+func (vv *VolumesView) SetVolumes(volumes []models.Volume) {
+	var selectedName string
+	if vol := vv.GetSelectedVolume(); vol != nil {
+		selectedName = vol.Name
+	}
+
+	vv.volumes = volumes
+	vv.refreshView()
+
+	for i := range vv.volumes {
+		if vv.volumes[i].Name == selectedName {
+			vv.table.Select(i+1, 0)
+			vv.showVolume(&vv.volumes[i])
+			return
+		}
+	}
+	if vol := vv.GetSelectedVolume(); vol != nil {
+		vv.showVolume(vol)
+	}
+}
+
+func (vv *VolumesView) OrphanedVolumes() []models.Volume {
+	var orphaned []models.Volume
+	for _, vol := range vv.volumes {
+		if vol.Orphaned() {
+			orphaned = append(orphaned, vol)
+		}
+	}
+	return orphaned
+}
+
+func (vv *VolumesView) refreshView() {
+	headers := []string{"NAME", "DRIVER", "SIZE", "CREATED", "CONTAINERS", "MOUNTPOINT"}
+
+	vv.table.Clear()
+	for col, header := range headers {
+		vv.table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(theme.TcellColor("title")).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+
+	var total, reclaimable int64
+	for row := range vv.volumes {
+		vol := &vv.volumes[row]
+		if vol.SizeKnown() {
+			total += vol.Size
+			if vol.Orphaned() {
+				reclaimable += vol.Size
+			}
+		}
+
+		cells := []string{
+			vol.ShortName(),
+			vol.Driver,
+			vv.sizeText(vol),
+			vol.FormatAge(),
+			vv.usedByText(vol),
+			vol.Mountpoint,
+		}
+		for col, text := range cells {
+			cell := tview.NewTableCell(tview.Escape(text)).
+				SetTextColor(theme.TcellColor("text")).
+				SetExpansion(1)
+			if col == 4 {
+				cell.SetTextColor(theme.TcellColor(vv.usedByRole(vol)))
+			}
+			if col == len(cells)-1 {
+				cell.SetTextColor(theme.TcellColor("dim")).SetMaxWidth(60)
+			}
+			vv.table.SetCell(row+1, col, cell)
+		}
+	}
+
+	vv.table.SetTitle(fmt.Sprintf(" Volumes (%d, %s, %s reclaimable, %d orphaned) ",
+		len(vv.volumes), vv.formatter.FormatBytes(total), vv.formatter.FormatBytes(reclaimable), len(vv.OrphanedVolumes())))
+
+	if len(vv.volumes) > 0 {
+		row, _ := vv.table.GetSelection()
+		if row < 1 || row > len(vv.volumes) {
+			vv.table.Select(1, 0)
+		}
+	} else {
+		vv.info.SetText(theme.Apply("[muted]No volumes[text]"))
+	}
+}
+
+// Docker reports -1 when the driver cannot compute the size.
+func (vv *VolumesView) sizeText(vol *models.Volume) string {
+	if !vol.SizeKnown() {
+		return "n/a"
+	}
+	return vv.formatter.FormatBytes(vol.Size)
+}
+
+func (vv *VolumesView) usedByText(vol *models.Volume) string {
+	switch len(vol.Users) {
+	case 0:
+		return "orphaned"
+	case 1:
+		return vol.Users[0].Container
+	default:
+		return fmt.Sprintf("%s +%d", vol.Users[0].Container, len(vol.Users)-1)
+	}
+}
+
+func (vv *VolumesView) usedByRole(vol *models.Volume) string {
+	switch {
+	case vol.Orphaned():
+		return "warning"
+	case vol.RunningUsers() > 0:
+		return "status.running"
+	default:
+		return "muted"
+	}
+}
+
+func (vv *VolumesView) showVolume(vol *models.Volume) {
+	vv.info.SetTitle(fmt.Sprintf(" Volume - %s ", tview.Escape(vol.ShortName())))
+	vv.info.SetText(theme.Apply(vv.renderVolume(vol)))
+	vv.info.ScrollToBeginning()
+}
+
+func (vv *VolumesView) renderVolume(vol *models.Volume) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[title]%s[text]\n", tview.Escape(vol.Name)))
+	if vol.Scope != "" {
+		result.WriteString(fmt.Sprintf("  Driver    : %s (%s scope)\n", tview.Escape(vol.Driver), tview.Escape(vol.Scope)))
+	} else {
+		result.WriteString(fmt.Sprintf("  Driver    : %s\n", tview.Escape(vol.Driver)))
+	}
+	result.WriteString(fmt.Sprintf("  Mountpoint: %s\n", tview.Escape(vol.Mountpoint)))
+	result.WriteString(fmt.Sprintf("  Size      : %s\n", vv.sizeText(vol)))
+	if !vol.Created.IsZero() {
+		result.WriteString(fmt.Sprintf("  Created   : %s (%s ago)\n", vv.formatter.FormatTime(vol.Created), vol.FormatAge()))
+	}
+	if project := vol.ComposeProject(); project != "" {
+		result.WriteString(fmt.Sprintf("  Project   : %s\n", tview.Escape(project)))
+	}
+
+	result.WriteString("\n")
+	if vol.Orphaned() {
+		result.WriteString(fmt.Sprintf("[warning]Orphaned[text] [muted]- no container references this volume. Press %s to remove it or %s to prune all orphans.[text]\n",
+			vv.keys.KeysFor(keymap.ScopeVolumes, keymap.ActionRemoveVolume),
+			vv.keys.KeysFor(keymap.ScopeVolumes, keymap.ActionPrune)))
+	} else {
+		result.WriteString(fmt.Sprintf("[title]Containers (%d, %d running)[text]\n", len(vol.Users), vol.RunningUsers()))
+		for _, user := range vol.Users {
+			state, stateRole := "stopped", "muted"
+			if user.Running {
+				state, stateRole = "running", "status.running"
+			}
+			mode := "ro"
+			if user.RW {
+				mode = "rw"
+			}
+			result.WriteString(fmt.Sprintf("  [%s]%-8s[text] %s [dim]-> %s (%s)[text]\n",
+				stateRole, state, tview.Escape(user.Container), tview.Escape(user.Destination), mode))
+		}
+	}
+
+	if len(vol.Labels) > 0 {
+		keys := make([]string, 0, len(vol.Labels))
+		for key := range vol.Labels {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		result.WriteString("\n[title]Labels[text]\n")
+		for _, key := range keys {
+			result.WriteString(fmt.Sprintf("  [muted]%s[text] = %s\n", tview.Escape(key), tview.Escape(vol.Labels[key])))
+		}
+	}
+	return result.String()
+}
+
+func (vv *VolumesView) volumeAt(row int) *models.Volume {
+	index := row - 1
+	if index >= 0 && index < len(vv.volumes) {
+		return &vv.volumes[index]
+	}
+	return nil
+}
+
+func (vv *VolumesView) GetSelectedVolume() *models.Volume {
+	row, _ := vv.table.GetSelection()
+	return vv.volumeAt(row)
+}
+
+func (vv *VolumesView) GetView() tview.Primitive {
+	return vv.layout
+}
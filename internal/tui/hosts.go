@@ -0,0 +1,221 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kqnd/kernus/internal/alert"
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components"
+)
+
+// dockerHost is one daemon of the session. Each host refreshes on its own so
+// a slow or unreachable one never holds back the others.
+type dockerHost struct {
+	name   string
+	client docker.ContainerRuntime
+
+	mu         sync.Mutex
+	containers []*models.Container
+	err        error
+	loading    bool
+
+	// lastSeen is the time of the last successful listing, the heartbeat
+	// of the host.
+	lastSeen time.Time
+}
+
+func (h *dockerHost) snapshot() ([]*models.Container, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.containers, h.err
+}
+
+// load lists the containers of the host unless a previous listing is still
+// in flight. It reports whether a listing was done.
+func (h *dockerHost) load() bool {
+	h.mu.Lock()
+	if h.loading {
+		h.mu.Unlock()
+		return false
+	}
+	h.loading = true
+	h.mu.Unlock()
+
+	containers, err := h.client.ListContainers(false)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.loading = false
+	h.err = err
+	if err != nil {
+		h.containers = nil
+	} else {
+		h.containers = toPtrSlice(containers)
+		h.lastSeen = time.Now()
+	}
+	return true
+}
+
+func (h *dockerHost) alertHost() alert.Host {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return alert.Host{Name: h.name, Online: h.err == nil, LastSeen: h.lastSeen, Containers: h.containers}
+}
+
+func (a *App) initializeDocker() error {
+	var failures []string
+	if len(a.config.Runtimes) > 0 {
+		for _, runtime := range a.config.Runtimes {
+			a.hosts = append(a.hosts, &dockerHost{name: runtime.Name(), client: runtime})
+		}
+	} else {
+		endpoints := []docker.Endpoint{{Name: a.config.DockerHost, Host: a.config.DockerHost}}
+		if a.config.DockerHost == "" {
+			var err error
+			endpoints, err = docker.ResolveEndpoints(a.config.DockerContexts)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, endpoint := range endpoints {
+			client, err := docker.NewEndpointClient(endpoint)
+			if err != nil {
+				failures = append(failures, err.Error())
+				continue
+			}
+			a.hosts = append(a.hosts, &dockerHost{name: endpoint.Name, client: client})
+		}
+	}
+	if a.config.WrapRuntime != nil {
+		for _, host := range a.hosts {
+			host.client = a.config.WrapRuntime(host.client)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for _, host := range a.hosts {
+		wg.Add(1)
+		go func(host *dockerHost) {
+			defer wg.Done()
+			if err := host.client.Ping(); err != nil {
+				host.err = err
+			}
+		}(host)
+	}
+	wg.Wait()
+
+	for _, host := range a.hosts {
+		if host.err == nil {
+			return nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", host.name, host.err))
+	}
+
+	a.closeHosts()
+	return fmt.Errorf("docker daemon not responding: %s", strings.Join(failures, "; "))
+}
+
+// closeHosts closes every client but keeps the hosts, which refreshes still
+// in flight may read.
+func (a *App) closeHosts() {
+	for _, host := range a.hosts {
+		host.client.Close()
+	}
+}
+
+// loadAllHosts lists every reachable host in parallel and waits for them,
+// which is only done once at startup.
+func (a *App) loadAllHosts() {
+	var wg sync.WaitGroup
+	for _, host := range a.hosts {
+		if _, err := host.snapshot(); err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(host *dockerHost) {
+			defer wg.Done()
+			host.load()
+		}(host)
+	}
+	wg.Wait()
+}
+
+// loadContainers merges the latest listing of every host, in host order.
+func (a *App) loadContainers() []*models.Container {
+	var containers []*models.Container
+	for _, host := range a.hosts {
+		hostContainers, _ := host.snapshot()
+		containers = append(containers, hostContainers...)
+	}
+	return containers
+}
+
+func (a *App) alertHosts() []alert.Host {
+	hosts := make([]alert.Host, 0, len(a.hosts))
+	for _, host := range a.hosts {
+		hosts = append(hosts, host.alertHost())
+	}
+	return hosts
+}
+
+func (a *App) hostStates() []components.HostState {
+	states := make([]components.HostState, 0, len(a.hosts))
+	for _, host := range a.hosts {
+		_, err := host.snapshot()
+		states = append(states, components.HostState{Name: host.name, Err: err})
+	}
+	return states
+}
+
+func (a *App) hostFor(container *models.Container) *dockerHost {
+	if container != nil {
+		for _, host := range a.hosts {
+			if host.name == container.Host {
+				return host
+			}
+		}
+	}
+	return a.activeHost()
+}
+
+func (a *App) clientFor(container *models.Container) docker.ContainerRuntime {
+	if host := a.hostFor(container); host != nil {
+		return host.client
+	}
+	return nil
+}
+
+// activeHost is the host resource views work on: the one of the selected
+// container, or the first reachable host.
+func (a *App) activeHost() *dockerHost {
+	if a.containers != nil {
+		if selected := a.containers.GetSelectedContainer(); selected != nil {
+			for _, host := range a.hosts {
+				if host.name == selected.Host {
+					return host
+				}
+			}
+		}
+	}
+	for _, host := range a.hosts {
+		if _, err := host.snapshot(); err == nil {
+			return host
+		}
+	}
+	if len(a.hosts) > 0 {
+		return a.hosts[0]
+	}
+	return nil
+}
+
+func (a *App) activeClient() docker.ContainerRuntime {
+	if host := a.activeHost(); host != nil {
+		return host.client
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package keymap
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+type Key struct {
+	Key  tcell.Key
+	Rune rune
+	Alt  bool
+}
+
+var keysByName = buildKeysByName()
+
+func buildKeysByName() map[string]tcell.Key {
+	names := make(map[string]tcell.Key, len(tcell.KeyNames))
+	for key, name := range tcell.KeyNames {
+		names[strings.ToLower(strings.ReplaceAll(name, "-", "+"))] = key
+	}
+	names["escape"] = tcell.KeyEsc
+	names["return"] = tcell.KeyEnter
+	names["pageup"] = tcell.KeyPgUp
+	names["pagedown"] = tcell.KeyPgDn
+	names["shift+tab"] = tcell.KeyBacktab
+	return names
+}
+
+// ParseKey accepts a single character ("s", "?"), "space", a tcell key
+// name ("esc", "f1", "left", "ctrl+r") or "alt+" followed by a character.
+func ParseKey(s string) (Key, error) {
+	if utf8.RuneCountInString(s) == 1 {
+		r, _ := utf8.DecodeRuneInString(s)
+		return Key{Key: tcell.KeyRune, Rune: r}, nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "space" {
+		return Key{Key: tcell.KeyRune, Rune: ' '}, nil
+	}
+
+	if rest, ok := strings.CutPrefix(name, "alt+"); ok && utf8.RuneCountInString(rest) == 1 {
+		r, _ := utf8.DecodeRuneInString(s[len("alt+"):])
+		return Key{Key: tcell.KeyRune, Rune: r, Alt: true}, nil
+	}
+
+	if key, ok := keysByName[name]; ok {
+		return Key{Key: key}, nil
+	}
+	return Key{}, fmt.Errorf("unknown key %q", s)
+}
+
+func (k Key) Matches(event *tcell.EventKey) bool {
+	if k.Key != tcell.KeyRune {
+		return event.Key() == k.Key
+	}
+	alt := event.Modifiers()&tcell.ModAlt != 0
+	return event.Key() == tcell.KeyRune && event.Rune() == k.Rune && alt == k.Alt
+}
+
+func (k Key) String() string {
+	if k.Key != tcell.KeyRune {
+		if name, ok := tcell.KeyNames[k.Key]; ok {
+			return name
+		}
+		return fmt.Sprintf("Key(%d)", k.Key)
+	}
+
+	name := string(k.Rune)
+	if k.Rune == ' ' {
+		name = "Space"
+	}
+	if k.Alt {
+		return "Alt-" + name
+	}
+	return name
+}
@@ -0,0 +1,298 @@
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+type Scope string
+
+const (
+	ScopeGlobal   Scope = "global"
+	ScopeList     Scope = "list"
+	ScopeTable    Scope = "table"
+	ScopeDetails  Scope = "details"
+	ScopeImages   Scope = "images"
+	ScopeVolumes  Scope = "volumes"
+	ScopeNetworks Scope = "networks"
+	ScopeAlerts   Scope = "alerts"
+	ScopeReplay   Scope = "replay"
+)
+
+type Action string
+
+const (
+	ActionQuit         Action = "quit"
+	ActionFocusNext    Action = "focus_next"
+	ActionHelp         Action = "help"
+	ActionPalette      Action = "palette"
+	ActionFind         Action = "find"
+	ActionToggleView   Action = "toggle_view"
+	ActionStart        Action = "start"
+	ActionStop         Action = "stop"
+	ActionRestart      Action = "restart"
+	ActionPause        Action = "pause"
+	ActionUnpause      Action = "unpause"
+	ActionRemove       Action = "remove"
+	ActionTabOverview  Action = "tab_overview"
+	ActionTabStats     Action = "tab_stats"
+	ActionTabNetwork   Action = "tab_network"
+	ActionTabStorage   Action = "tab_storage"
+	ActionTabLogs      Action = "tab_logs"
+	ActionShrinkPane   Action = "shrink_pane"
+	ActionGrowPane     Action = "grow_pane"
+	ActionZoom         Action = "zoom"
+	ActionLayout       Action = "toggle_layout"
+	ActionLogsPane     Action = "toggle_logs_pane"
+	ActionCompare      Action = "compare"
+	ActionViewImages   Action = "images"
+	ActionViewVolumes  Action = "volumes"
+	ActionViewNetworks Action = "networks"
+	ActionViewAlerts   Action = "alerts"
+
+	ActionSelect   Action = "select"
+	ActionExpand   Action = "expand"
+	ActionCollapse Action = "collapse"
+
+	ActionFilter      Action = "filter"
+	ActionSortPrev    Action = "sort_prev"
+	ActionSortNext    Action = "sort_next"
+	ActionSortReverse Action = "sort_reverse"
+	ActionColumns     Action = "columns"
+
+	ActionPrevTab     Action = "prev_tab"
+	ActionNextTab     Action = "next_tab"
+	ActionRefreshLogs Action = "refresh_logs"
+
+	ActionRemoveImage  Action = "remove_image"
+	ActionRemoveVolume Action = "remove_volume"
+	ActionPrune        Action = "prune"
+	ActionTopology     Action = "topology"
+
+	ActionReplayPause   Action = "replay_pause"
+	ActionReplayBack    Action = "replay_back"
+	ActionReplayForward Action = "replay_forward"
+	ActionReplaySlower  Action = "replay_slower"
+	ActionReplayFaster  Action = "replay_faster"
+)
+
+type Binding struct {
+	Action      Action
+	Scope       Scope
+	Keys        []Key
+	Description string
+}
+
+type Keymap struct {
+	bindings []*Binding
+}
+
+type Conflict struct {
+	Key     Key
+	First   *Binding
+	Second  *Binding
+	Shadows bool
+}
+
+func (c Conflict) String() string {
+	if c.Shadows {
+		return fmt.Sprintf("key %q of %s.%s is shadowed by global %s",
+			c.Key, c.Second.Scope, c.Second.Action, c.First.Action)
+	}
+	return fmt.Sprintf("key %q is bound to both %s.%s and %s.%s",
+		c.Key, c.First.Scope, c.First.Action, c.Second.Scope, c.Second.Action)
+}
+
+func Default() *Keymap {
+	km := &Keymap{}
+
+	km.add(ScopeGlobal, ActionQuit, "Quit kernus", "q", "Q", "esc")
+	km.add(ScopeGlobal, ActionFocusNext, "Switch focused pane", "tab")
+	km.add(ScopeGlobal, ActionHelp, "Show key bindings", "?")
+	km.add(ScopeGlobal, ActionPalette, "Open command palette", ":")
+	km.add(ScopeGlobal, ActionFind, "Find container", "f", "F")
+	km.add(ScopeGlobal, ActionToggleView, "Toggle list/table view", "v", "V")
+	km.add(ScopeGlobal, ActionStart, "Start container", "s", "S")
+	km.add(ScopeGlobal, ActionStop, "Stop container", "t", "T")
+	km.add(ScopeGlobal, ActionRestart, "Restart container", "r", "R")
+	km.add(ScopeGlobal, ActionPause, "Pause container", "p", "P")
+	km.add(ScopeGlobal, ActionUnpause, "Unpause container", "u", "U")
+	km.add(ScopeGlobal, ActionRemove, "Remove stopped container", "d", "D")
+	km.add(ScopeGlobal, ActionTabOverview, "Overview tab", "1", "f1")
+	km.add(ScopeGlobal, ActionTabStats, "Stats tab", "2", "f2")
+	km.add(ScopeGlobal, ActionTabNetwork, "Network tab", "3", "f3")
+	km.add(ScopeGlobal, ActionTabStorage, "Storage tab", "4", "f4")
+	km.add(ScopeGlobal, ActionTabLogs, "Logs tab", "5", "f6")
+	km.add(ScopeGlobal, ActionShrinkPane, "Shrink focused pane", "[")
+	km.add(ScopeGlobal, ActionGrowPane, "Grow focused pane", "]")
+	km.add(ScopeGlobal, ActionZoom, "Maximize/restore details", "z", "Z")
+	km.add(ScopeGlobal, ActionLayout, "Cycle auto/side-by-side/stacked layout", "o", "O")
+	km.add(ScopeGlobal, ActionLogsPane, "Toggle logs pane under details", "b", "B")
+	km.add(ScopeGlobal, ActionCompare, "Mark container / compare with marked / exit compare", "x", "X")
+	km.add(ScopeGlobal, ActionViewImages, "Toggle images view", "i", "I")
+	km.add(ScopeGlobal, ActionViewVolumes, "Toggle volumes view", "w", "W")
+	km.add(ScopeGlobal, ActionViewNetworks, "Toggle networks view", "n", "N")
+	km.add(ScopeGlobal, ActionViewAlerts, "Toggle alerts view", "a", "A")
+
+	km.add(ScopeList, ActionSelect, "Show container / toggle group", "enter")
+	km.add(ScopeList, ActionExpand, "Expand group", "right")
+	km.add(ScopeList, ActionCollapse, "Collapse group / go to parent", "left")
+
+	km.add(ScopeTable, ActionSelect, "Show container", "enter")
+	km.add(ScopeTable, ActionFilter, "Filter by name, image, host or label", "/")
+	km.add(ScopeTable, ActionSortPrev, "Sort by previous column", "<")
+	km.add(ScopeTable, ActionSortNext, "Sort by next column", ">")
+	km.add(ScopeTable, ActionSortReverse, "Reverse sort order", "!")
+	km.add(ScopeTable, ActionColumns, "Choose visible columns", "c", "C")
+
+	km.add(ScopeDetails, ActionPrevTab, "Previous tab", "left")
+	km.add(ScopeDetails, ActionNextTab, "Next tab", "right")
+	km.add(ScopeDetails, ActionRefreshLogs, "Refresh logs", "ctrl+r")
+
+	km.add(ScopeImages, ActionSelect, "Show layer history", "enter")
+	km.add(ScopeImages, ActionRemoveImage, "Remove image", "delete")
+	km.add(ScopeImages, ActionPrune, "Prune dangling images", "ctrl+p")
+
+	km.add(ScopeVolumes, ActionRemoveVolume, "Remove volume", "delete")
+	km.add(ScopeVolumes, ActionPrune, "Prune orphaned volumes", "ctrl+p")
+
+	km.add(ScopeNetworks, ActionSelect, "Show attached containers", "enter")
+	km.add(ScopeNetworks, ActionTopology, "Toggle topology graph", "g", "G")
+
+	km.add(ScopeAlerts, ActionSelect, "Go to the container of the alert", "enter")
+
+	km.add(ScopeReplay, ActionReplayPause, "Pause/resume playback", "space")
+	km.add(ScopeReplay, ActionReplayBack, "Seek back 10s of playback", ",")
+	km.add(ScopeReplay, ActionReplayForward, "Seek forward 10s of playback", ".")
+	km.add(ScopeReplay, ActionReplaySlower, "Slower playback", "-")
+	km.add(ScopeReplay, ActionReplayFaster, "Faster playback", "+", "=")
+
+	return km
+}
+
+func (km *Keymap) add(scope Scope, action Action, description string, keys ...string) {
+	binding := &Binding{Action: action, Scope: scope, Description: description}
+	for _, k := range keys {
+		key, err := ParseKey(k)
+		if err != nil {
+			panic(err)
+		}
+		binding.Keys = append(binding.Keys, key)
+	}
+	km.bindings = append(km.bindings, binding)
+}
+
+// Apply replaces the keys of the given actions. Overrides are keyed either
+// by action ("restart") or, for actions that exist in several scopes, by
+// "scope.action" ("table.select").
+func (km *Keymap) Apply(overrides map[string][]string) error {
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		targets := km.lookup(name)
+		if len(targets) == 0 {
+			return fmt.Errorf("unknown key binding action %q", name)
+		}
+
+		keys := make([]Key, 0, len(overrides[name]))
+		for _, k := range overrides[name] {
+			key, err := ParseKey(k)
+			if err != nil {
+				return fmt.Errorf("key binding %q: %w", name, err)
+			}
+			keys = append(keys, key)
+		}
+
+		for _, binding := range targets {
+			binding.Keys = keys
+		}
+	}
+	return nil
+}
+
+func (km *Keymap) lookup(name string) []*Binding {
+	scope, action, scoped := strings.Cut(name, ".")
+	var result []*Binding
+	for _, binding := range km.bindings {
+		if scoped && binding.Scope == Scope(scope) && binding.Action == Action(action) {
+			result = append(result, binding)
+		} else if !scoped && binding.Action == Action(name) {
+			result = append(result, binding)
+		}
+	}
+	return result
+}
+
+// Conflicts reports keys bound twice within a scope, and pane keys that can
+// never fire because the application handles global keys first.
+func (km *Keymap) Conflicts() []Conflict {
+	var conflicts []Conflict
+	for i, a := range km.bindings {
+		for _, b := range km.bindings[i+1:] {
+			sameScope := a.Scope == b.Scope
+			shadows := a.Scope == ScopeGlobal && b.Scope != ScopeGlobal
+			if !sameScope && !shadows {
+				continue
+			}
+			for _, ka := range a.Keys {
+				for _, kb := range b.Keys {
+					if ka == kb {
+						conflicts = append(conflicts, Conflict{Key: ka, First: a, Second: b, Shadows: shadows})
+					}
+				}
+			}
+		}
+	}
+	return conflicts
+}
+
+func (km *Keymap) Match(scope Scope, event *tcell.EventKey) (Action, bool) {
+	for _, binding := range km.bindings {
+		if binding.Scope != scope {
+			continue
+		}
+		for _, key := range binding.Keys {
+			if key.Matches(event) {
+				return binding.Action, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (km *Keymap) Bindings(scope Scope) []*Binding {
+	var result []*Binding
+	for _, binding := range km.bindings {
+		if binding.Scope == scope {
+			result = append(result, binding)
+		}
+	}
+	return result
+}
+
+func (km *Keymap) KeysFor(scope Scope, action Action) string {
+	for _, binding := range km.bindings {
+		if binding.Scope == scope && binding.Action == action {
+			return binding.KeysString()
+		}
+	}
+	return ""
+}
+
+func (b *Binding) KeysString() string {
+	keys := make([]string, 0, len(b.Keys))
+	for _, key := range b.Keys {
+		keys = append(keys, key.String())
+	}
+	if len(keys) == 0 {
+		return "unbound"
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,129 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func runeKey(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func TestApplyRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		want      string
+	}{
+		{"unknown action", map[string][]string{"teleport": {"x"}}, `unknown key binding action "teleport"`},
+		{"action outside its scope", map[string][]string{"table.quit": {"x"}}, `unknown key binding action "table.quit"`},
+		{"unknown scope", map[string][]string{"sidebar.select": {"x"}}, `unknown key binding action "sidebar.select"`},
+		{"unknown key name", map[string][]string{"quit": {"hyper+q"}}, `key binding "quit": unknown key "hyper+q"`},
+		{"alt without a character", map[string][]string{"quit": {"alt+"}}, `key binding "quit": unknown key "alt+"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Default().Apply(tt.overrides)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Apply() = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	km := Default()
+	err := km.Apply(map[string][]string{
+		"restart":      {"ctrl+r", "alt+r"},
+		"table.select": {"space"},
+		"refresh_logs": {},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		scope Scope
+		event *tcell.EventKey
+		want  Action
+	}{
+		{ScopeGlobal, tcell.NewEventKey(tcell.KeyCtrlR, 0, tcell.ModCtrl), ActionRestart},
+		{ScopeGlobal, tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModAlt), ActionRestart},
+		{ScopeGlobal, runeKey('r'), ""},
+		{ScopeTable, runeKey(' '), ActionSelect},
+		{ScopeTable, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), ""},
+		// A scoped override leaves the action alone in other scopes.
+		{ScopeList, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), ActionSelect},
+		// An action bound to no keys matches none.
+		{ScopeDetails, tcell.NewEventKey(tcell.KeyCtrlR, 0, tcell.ModCtrl), ""},
+	}
+	for _, tt := range tests {
+		action, ok := km.Match(tt.scope, tt.event)
+		if action != tt.want || ok != (tt.want != "") {
+			t.Errorf("Match(%s, %s) = %q, %v, want %q", tt.scope, tt.event.Name(), action, ok, tt.want)
+		}
+	}
+	if keys := km.KeysFor(ScopeDetails, ActionRefreshLogs); keys != "unbound" {
+		t.Errorf("KeysFor(details, refresh_logs) = %q, want unbound", keys)
+	}
+}
+
+func TestMatchIgnoresOtherScopes(t *testing.T) {
+	km := Default()
+	if action, ok := km.Match(ScopeList, runeKey('c')); ok {
+		t.Errorf("Match(list, c) = %q, want the table key left alone", action)
+	}
+	if action, _ := km.Match(ScopeTable, runeKey('c')); action != ActionColumns {
+		t.Errorf("Match(table, c) = %q, want columns", action)
+	}
+	if action, ok := km.Match(ScopeGlobal, tcell.NewEventKey(tcell.KeyRune, 'q', tcell.ModAlt)); ok {
+		t.Errorf("Match(global, alt+q) = %q, want no match", action)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		want      []string
+	}{
+		{"defaults", nil, nil},
+		{
+			"pane key shadowed by a global key",
+			map[string][]string{"table.filter": {"q"}},
+			[]string{`key "q" of table.filter is shadowed by global quit`},
+		},
+		{
+			"two global actions",
+			map[string][]string{"start": {"t"}},
+			[]string{`key "t" is bound to both global.start and global.stop`},
+		},
+		{
+			"two actions of one pane",
+			map[string][]string{"refresh_logs": {"right", "ctrl+r"}},
+			[]string{`key "Right" is bound to both details.next_tab and details.refresh_logs`},
+		},
+		{
+			"same key in different panes",
+			map[string][]string{"list.expand": {"c"}},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			km := Default()
+			if err := km.Apply(tt.overrides); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, conflict := range km.Conflicts() {
+				got = append(got, conflict.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Conflicts() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/tui/components"
+	"github.com/rivo/tview"
+)
+
+const (
+	layoutAuto       = "auto"
+	layoutHorizontal = "horizontal"
+	layoutVertical   = "vertical"
+
+	headerHeight        = 3
+	defaultStackWidth   = 100
+	defaultListWidth    = 40
+	defaultListHeight   = 12
+	defaultBottomHeight = 12
+	paneStep            = 4
+	minPaneWidth        = 20
+	minPaneHeight       = 5
+)
+
+// isStacked reports whether the container pane sits above the details pane.
+// In auto mode the terminal width decides, so narrow terminals stack.
+func (a *App) isStacked() bool {
+	switch a.config.TUI.Layout.Orientation {
+	case layoutVertical:
+		return true
+	case layoutHorizontal:
+		return false
+	}
+
+	stackWidth := a.config.TUI.Layout.StackWidth
+	if stackWidth <= 0 {
+		stackWidth = defaultStackWidth
+	}
+	return a.screenWidth > 0 && a.screenWidth < stackWidth
+}
+
+func (a *App) bottomPaneVisible() bool {
+	return a.config.TUI.Layout.BottomPane == components.PaneLogs
+}
+
+func (a *App) containerPaneSize() int {
+	layout := a.config.TUI.Layout
+	if a.isStacked() {
+		if layout.ListHeight > 0 {
+			return layout.ListHeight
+		}
+		return defaultListHeight
+	}
+
+	if a.config.TUI.ContainerView == components.ViewModeTable {
+		return layout.TableWidth
+	}
+	if layout.ListWidth > 0 {
+		return layout.ListWidth
+	}
+	return defaultListWidth
+}
+
+func (a *App) bottomPaneHeight() int {
+	if a.config.TUI.Layout.BottomHeight > 0 {
+		return a.config.TUI.Layout.BottomHeight
+	}
+	return defaultBottomHeight
+}
+
+func (a *App) applyLayout() {
+	focused := a.tviewApp.GetFocus()
+	a.stacked = a.isStacked()
+
+	a.mainGrid.Clear()
+
+	bottom := 0
+	if a.bottomPaneVisible() {
+		bottom = a.bottomPaneHeight()
+	}
+	spare := a.screenHeight - headerHeight - minPaneHeight
+	if a.screenHeight > 0 && bottom > spare/2 {
+		bottom = max(spare/2, minPaneHeight)
+	}
+
+	switch {
+	case a.resource != "":
+		a.mainGrid.SetRows(headerHeight, 0).SetColumns(0)
+		a.mainGrid.AddItem(a.header.GetView(), 0, 0, 1, 1, 0, 0, false)
+		a.mainGrid.AddItem(a.resourceView(), 1, 0, 1, 1, 0, 0, false)
+		a.focusables = []tview.Primitive{a.resourceView()}
+		bottom = 0
+
+	case a.config.TUI.Layout.Zoomed:
+		a.mainGrid.SetRows(headerHeight, 0).SetColumns(0)
+		a.mainGrid.AddItem(a.header.GetView(), 0, 0, 1, 1, 0, 0, false)
+		a.mainGrid.AddItem(a.detailsView(), 1, 0, 1, 1, 0, 0, false)
+		a.focusables = []tview.Primitive{a.detailsView()}
+
+	case a.stacked:
+		list := a.containerPaneSize()
+		if a.screenHeight > 0 {
+			list = clamp(list, minPaneHeight, spare-bottom)
+		}
+		rows := []int{headerHeight, list, 0}
+		if bottom > 0 {
+			rows = append(rows, bottom)
+		}
+		a.mainGrid.SetRows(rows...).SetColumns(0)
+		a.mainGrid.AddItem(a.header.GetView(), 0, 0, 1, 1, 0, 0, false)
+		a.mainGrid.AddItem(a.containers.GetView(), 1, 0, 1, 1, 0, 0, false)
+		a.mainGrid.AddItem(a.detailsView(), 2, 0, 1, 1, 0, 0, false)
+		if bottom > 0 {
+			a.mainGrid.AddItem(a.logPane.GetView(), 3, 0, 1, 1, 0, 0, false)
+		}
+		a.focusables = []tview.Primitive{a.containers.GetView(), a.detailsView()}
+
+	default:
+		rows := []int{headerHeight, 0}
+		span := 1
+		if bottom > 0 {
+			rows = append(rows, bottom)
+			span = 2
+		}
+		a.mainGrid.SetRows(rows...).SetColumns(a.containerPaneSize(), 0)
+		a.mainGrid.AddItem(a.header.GetView(), 0, 0, 1, 2, 0, 0, false)
+		a.mainGrid.AddItem(a.containers.GetView(), 1, 0, span, 1, 0, 0, false)
+		a.mainGrid.AddItem(a.detailsView(), 1, 1, 1, 1, 0, 0, false)
+		if bottom > 0 {
+			a.mainGrid.AddItem(a.logPane.GetView(), 2, 1, 1, 1, 0, 0, false)
+		}
+		a.focusables = []tview.Primitive{a.containers.GetView(), a.detailsView()}
+	}
+
+	if bottom > 0 {
+		a.focusables = append(a.focusables, a.logPane.GetView())
+	}
+
+	a.focusIndex = 0
+	for i, p := range a.focusables {
+		if p == focused {
+			a.focusIndex = i
+		}
+	}
+	if !a.pages.HasPage("help") && !a.pages.HasPage("palette") {
+		a.tviewApp.SetFocus(a.focusables[a.focusIndex])
+	}
+}
+
+// watchScreenSize re-lays out the panes whenever the terminal is resized,
+// so auto mode stacks the panes once the window gets narrow. The draw hook runs
+// with the application locked, so the new layout is queued for later.
+func (a *App) watchScreenSize() {
+	a.tviewApp.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+		width, height := screen.Size()
+		if width == a.screenWidth && height == a.screenHeight {
+			return false
+		}
+
+		a.screenWidth, a.screenHeight = width, height
+		go a.tviewApp.QueueUpdateDraw(a.applyLayout)
+		return false
+	})
+}
+
+// resizeFocusedPane grows (delta > 0) or shrinks the focused pane. The
+// container and details panes share one split, so resizing one of them
+// moves that split.
+func (a *App) resizeFocusedPane(delta int) {
+	layout := &a.config.TUI.Layout
+	if layout.Zoomed {
+		return
+	}
+
+	focused := a.focusables[a.focusIndex]
+	if focused == a.logPane.GetView() {
+		layout.BottomHeight = clamp(a.bottomPaneHeight()+delta, minPaneHeight, a.screenHeight-headerHeight-minPaneHeight)
+		a.applyLayout()
+		a.saveTUIConfig()
+		return
+	}
+
+	if focused == a.detailsView() {
+		delta = -delta
+	}
+
+	if a.stacked {
+		layout.ListHeight = clamp(a.containerPaneSize()+delta, minPaneHeight, a.screenHeight-headerHeight-minPaneHeight)
+	} else {
+		size := a.containerPaneSize()
+		if size == 0 {
+			size = a.screenWidth / 2
+		}
+		size = clamp(size+delta, minPaneWidth, a.screenWidth-minPaneWidth)
+
+		if a.config.TUI.ContainerView == components.ViewModeTable {
+			layout.TableWidth = size
+		} else {
+			layout.ListWidth = size
+		}
+	}
+
+	a.applyLayout()
+	a.saveTUIConfig()
+}
+
+func (a *App) toggleZoom() {
+	a.config.TUI.Layout.Zoomed = !a.config.TUI.Layout.Zoomed
+	a.applyLayout()
+	a.saveTUIConfig()
+}
+
+func (a *App) cycleLayout() {
+	switch a.config.TUI.Layout.Orientation {
+	case layoutHorizontal:
+		a.config.TUI.Layout.Orientation = layoutVertical
+	case layoutVertical:
+		a.config.TUI.Layout.Orientation = layoutAuto
+	default:
+		a.config.TUI.Layout.Orientation = layoutHorizontal
+	}
+	a.applyLayout()
+	a.saveTUIConfig()
+}
+
+func (a *App) toggleLogsPane() {
+	if a.bottomPaneVisible() {
+		a.config.TUI.Layout.BottomPane = ""
+	} else {
+		a.config.TUI.Layout.BottomPane = components.PaneLogs
+		a.logPane.ShowContainer(a.containers.GetSelectedContainer())
+	}
+	a.applyLayout()
+	a.saveTUIConfig()
+}
+
+func clamp(value, low, high int) int {
+	if high < low {
+		high = low
+	}
+	if value < low {
+		return low
+	}
+	if value > high {
+		return high
+	}
+	return value
+}
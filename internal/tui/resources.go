@@ -0,0 +1,327 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/models"
+	"github.com/kqnd/kernus/internal/tui/components"
+	"github.com/kqnd/kernus/internal/tui/components/details"
+	"github.com/kqnd/kernus/internal/tui/keymap"
+	"github.com/rivo/tview"
+)
+
+// resourceView returns the view that replaces the container and details
+// panes when a non-container resource is shown, or nil for containers.
+func (a *App) resourceView() tview.Primitive {
+	switch a.resource {
+	case components.ResourceImages:
+		return a.images.GetView()
+	case components.ResourceVolumes:
+		return a.volumes.GetView()
+	case components.ResourceNetworks:
+		return a.networks.GetView()
+	case components.ResourceAlerts:
+		return a.alertsView.GetView()
+	}
+	return nil
+}
+
+func (a *App) resourceScope() keymap.Scope {
+	switch a.resource {
+	case components.ResourceImages:
+		return keymap.ScopeImages
+	case components.ResourceVolumes:
+		return keymap.ScopeVolumes
+	case components.ResourceNetworks:
+		return keymap.ScopeNetworks
+	case components.ResourceAlerts:
+		return keymap.ScopeAlerts
+	}
+	return ""
+}
+
+func (a *App) toggleResource(resource string) {
+	if a.resource == resource {
+		a.resource = ""
+		a.tviewApp.SetFocus(a.containers.GetView())
+	} else {
+		a.resource = resource
+		a.tviewApp.SetFocus(a.resourceView())
+		a.refreshResource()
+		if host := a.activeHost(); host != nil && len(a.hosts) > 1 && resource != components.ResourceAlerts {
+			a.header.SetNotice(fmt.Sprintf("Showing %s of %s", resource, host.name))
+		}
+	}
+	a.applyLayout()
+}
+
+func (a *App) refreshResource() {
+	switch a.resource {
+	case components.ResourceImages:
+		a.refreshImages()
+	case components.ResourceVolumes:
+		a.refreshVolumes()
+	case components.ResourceNetworks:
+		a.refreshNetworks()
+	case components.ResourceAlerts:
+		a.alertsView.SetAlerts(a.alerts.Alerts())
+	}
+}
+
+// allowedInResource reports whether a global action still applies while a
+// resource view is shown; container actions and tabs would otherwise act
+// on a container the user cannot see.
+func allowedInResource(action keymap.Action) bool {
+	switch action {
+	case keymap.ActionQuit, keymap.ActionHelp, keymap.ActionPalette,
+		keymap.ActionViewImages, keymap.ActionViewVolumes, keymap.ActionViewNetworks,
+		keymap.ActionViewAlerts, keymap.ActionLayout:
+		return true
+	}
+	return false
+}
+
+func (a *App) setupResourceViews() {
+	a.images = components.NewImagesView(a.keys)
+	a.images.SetHistoryFunc(a.showImageHistory)
+	a.images.SetRemoveFunc(a.confirmRemoveImage)
+	a.images.SetPruneFunc(a.confirmPruneImages)
+
+	a.volumes = components.NewVolumesView(a.keys)
+	a.volumes.SetRemoveFunc(a.confirmRemoveVolume)
+	a.volumes.SetPruneFunc(a.confirmPruneVolumes)
+
+	a.networks = components.NewNetworksView(a.keys)
+
+	a.alertsView = components.NewAlertsView(a.keys, a.alerts.Rules())
+	a.alertsView.SetSelectedFunc(a.jumpToAlert)
+}
+
+func (a *App) refreshImages() {
+	if client := a.activeClient(); client != nil {
+		a.loadImages(client)
+	}
+}
+
+// loadImages lists the images of client, which may be called from any
+// goroutine, and shows them if client is still the active host.
+func (a *App) loadImages(client docker.ContainerRuntime) {
+	go func() {
+		images, err := client.ImageList()
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.activeClient() != client {
+				return
+			}
+			if err != nil {
+				a.header.SetNotice(fmt.Sprintf("Failed to list images: %v", err))
+				return
+			}
+			a.images.SetImages(images)
+		})
+	}()
+}
+
+func (a *App) showImageHistory(img *models.Image) {
+	client := a.activeClient()
+	if client == nil {
+		return
+	}
+
+	target := *img
+	go func() {
+		layers, err := client.ImageHistory(target.ID)
+		a.tviewApp.QueueUpdateDraw(func() {
+			a.images.ShowHistory(&target, layers, err)
+		})
+	}()
+}
+
+func (a *App) confirmRemoveImage(img *models.Image) {
+	client := a.activeClient()
+	if client == nil {
+		return
+	}
+
+	message := fmt.Sprintf("Remove image %s (%s)?", img.RepoTag(), img.ShortID())
+	if img.InUse() {
+		message += fmt.Sprintf("\n\nIt is used by %d container(s); Docker will refuse until they are removed.", len(img.Containers))
+	}
+
+	target := *img
+	a.confirm(message, "Remove", func() {
+		go func() {
+			err := client.ImageRemove(target.ID, false)
+			a.tviewApp.QueueUpdateDraw(func() {
+				if err != nil {
+					a.header.SetNotice(fmt.Sprintf("Failed to remove %s: %v", target.RepoTag(), err))
+				} else {
+					a.header.SetNotice(fmt.Sprintf("Removed %s", target.RepoTag()))
+				}
+			})
+			a.loadImages(client)
+		}()
+	})
+}
+
+func (a *App) confirmPruneImages(dangling []models.Image) {
+	client := a.activeClient()
+	if client == nil {
+		return
+	}
+
+	if len(dangling) == 0 {
+		a.header.SetNotice("No dangling images to prune")
+		return
+	}
+
+	var size int64
+	for i := range dangling {
+		size += dangling[i].UniqueSize()
+	}
+
+	formatter := details.NewFormatter()
+	message := fmt.Sprintf("Prune %d dangling image(s), freeing about %s?", len(dangling), formatter.FormatBytes(size))
+	a.confirm(message, "Prune", func() {
+		go func() {
+			report, err := client.ImagesPrune()
+			a.tviewApp.QueueUpdateDraw(func() {
+				if err != nil {
+					a.header.SetNotice(fmt.Sprintf("Prune failed: %v", err))
+				} else {
+					a.header.SetNotice(fmt.Sprintf("Pruned %d image(s), reclaimed %s",
+						report.Deleted, formatter.FormatBytes(int64(report.SpaceReclaimed))))
+				}
+			})
+			a.loadImages(client)
+		}()
+	})
+}
+
+func (a *App) refreshVolumes() {
+	if client := a.activeClient(); client != nil {
+		a.loadVolumes(client)
+	}
+}
+
+// loadVolumes lists the volumes of client, which may be called from any
+// goroutine, and shows them if client is still the active host.
+func (a *App) loadVolumes(client docker.ContainerRuntime) {
+	go func() {
+		volumes, err := client.VolumeList()
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.activeClient() != client {
+				return
+			}
+			if err != nil {
+				a.header.SetNotice(fmt.Sprintf("Failed to list volumes: %v", err))
+				return
+			}
+			a.volumes.SetVolumes(volumes)
+		})
+	}()
+}
+
+func (a *App) confirmRemoveVolume(vol *models.Volume) {
+	client := a.activeClient()
+	if client == nil {
+		return
+	}
+
+	message := fmt.Sprintf("Remove volume %s? Its data will be lost.", vol.ShortName())
+	if !vol.Orphaned() {
+		message += fmt.Sprintf("\n\nIt is referenced by %d container(s); Docker will refuse until they are removed.", len(vol.Users))
+	}
+
+	target := *vol
+	a.confirm(message, "Remove", func() {
+		go func() {
+			err := client.VolumeRemove(target.Name, false)
+			a.tviewApp.QueueUpdateDraw(func() {
+				if err != nil {
+					a.header.SetNotice(fmt.Sprintf("Failed to remove %s: %v", target.ShortName(), err))
+				} else {
+					a.header.SetNotice(fmt.Sprintf("Removed volume %s", target.ShortName()))
+				}
+			})
+			a.loadVolumes(client)
+		}()
+	})
+}
+
+func (a *App) confirmPruneVolumes(orphaned []models.Volume) {
+	client := a.activeClient()
+	if client == nil {
+		return
+	}
+
+	if len(orphaned) == 0 {
+		a.header.SetNotice("No orphaned volumes to prune")
+		return
+	}
+
+	var size int64
+	for i := range orphaned {
+		if orphaned[i].SizeKnown() {
+			size += orphaned[i].Size
+		}
+	}
+
+	formatter := details.NewFormatter()
+	message := fmt.Sprintf("Prune %d orphaned volume(s), freeing about %s? Their data will be lost.", len(orphaned), formatter.FormatBytes(size))
+	a.confirm(message, "Prune", func() {
+		go func() {
+			report, err := client.VolumesPrune()
+			a.tviewApp.QueueUpdateDraw(func() {
+				if err != nil {
+					a.header.SetNotice(fmt.Sprintf("Prune failed: %v", err))
+				} else {
+					a.header.SetNotice(fmt.Sprintf("Pruned %d volume(s), reclaimed %s",
+						report.Deleted, formatter.FormatBytes(int64(report.SpaceReclaimed))))
+				}
+			})
+			a.loadVolumes(client)
+		}()
+	})
+}
+
+func (a *App) refreshNetworks() {
+	client := a.activeClient()
+	if client == nil {
+		return
+	}
+
+	go func() {
+		networks, err := client.NetworkList()
+		a.tviewApp.QueueUpdateDraw(func() {
+			if a.activeClient() != client {
+				return
+			}
+			if err != nil {
+				a.header.SetNotice(fmt.Sprintf("Failed to list networks: %v", err))
+				return
+			}
+			a.networks.SetNetworks(networks)
+		})
+	}()
+}
+
+// confirm shows a modal with a cancel button and a button labelled label;
+// fn only runs when the latter is chosen.
+func (a *App) confirm(message, label string, fn func()) {
+	focused := a.tviewApp.GetFocus()
+
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{"Cancel", label}).
+		SetDoneFunc(func(_ int, buttonLabel string) {
+			a.pages.RemovePage("confirm")
+			a.tviewApp.SetFocus(focused)
+			if buttonLabel == label {
+				fn()
+			}
+		})
+
+	a.pages.AddPage("confirm", modal, true, true)
+	a.tviewApp.SetFocus(modal)
+}
@@ -0,0 +1,96 @@
+package theme
+
+var roles = []string{
+	"ui.background", "ui.contrast", "ui.border", "ui.title", "ui.text", "ui.secondary", "ui.inverse",
+	"text", "title", "muted", "dim", "accent", "accent.alt", "success", "error", "warning",
+	"usage.low", "usage.medium", "usage.high", "bar.empty",
+	"status.running", "status.exited", "status.stopped", "status.dead", "status.paused",
+	"status.created", "status.restarting", "status.removing", "status.unknown",
+	"health.healthy", "health.unhealthy", "health.starting", "health.none",
+	"machine.online", "machine.offline", "machine.error",
+	"log.error", "log.warn", "log.info", "log.debug", "log.default", "log.timestamp", "log.line",
+	"diff.changed",
+}
+
+var palettes = map[string]map[string]string{
+	NameDark: {
+		"ui.background": "black", "ui.contrast": "blue", "ui.border": "white", "ui.title": "white",
+		"ui.text": "white", "ui.secondary": "yellow", "ui.inverse": "blue",
+		"text": "white", "title": "yellow", "muted": "gray", "dim": "darkgray",
+		"accent": "cyan", "accent.alt": "blue", "success": "green", "error": "red", "warning": "yellow",
+		"usage.low": "green", "usage.medium": "yellow", "usage.high": "red", "bar.empty": "gray",
+		"status.running": "green", "status.exited": "red", "status.stopped": "red", "status.dead": "red",
+		"status.paused": "yellow", "status.created": "blue", "status.restarting": "orange",
+		"status.removing": "purple", "status.unknown": "white",
+		"health.healthy": "green", "health.unhealthy": "red", "health.starting": "yellow", "health.none": "gray",
+		"machine.online": "green", "machine.offline": "red", "machine.error": "red",
+		"log.error": "red", "log.warn": "yellow", "log.info": "cyan", "log.debug": "gray",
+		"log.default": "white", "log.timestamp": "darkgray", "log.line": "gray",
+		"diff.changed": "orange",
+	},
+	NameLight: {
+		"ui.background": "white", "ui.contrast": "lightgray", "ui.border": "black", "ui.title": "black",
+		"ui.text": "black", "ui.secondary": "darkblue", "ui.inverse": "white",
+		"text": "black", "title": "darkblue", "muted": "dimgray", "dim": "gray",
+		"accent": "teal", "accent.alt": "navy", "success": "darkgreen", "error": "darkred", "warning": "darkorange",
+		"usage.low": "darkgreen", "usage.medium": "darkorange", "usage.high": "darkred", "bar.empty": "lightgray",
+		"status.running": "darkgreen", "status.exited": "darkred", "status.stopped": "darkred", "status.dead": "darkred",
+		"status.paused": "darkorange", "status.created": "navy", "status.restarting": "chocolate",
+		"status.removing": "purple", "status.unknown": "black",
+		"health.healthy": "darkgreen", "health.unhealthy": "darkred", "health.starting": "darkorange", "health.none": "dimgray",
+		"machine.online": "darkgreen", "machine.offline": "darkred", "machine.error": "darkred",
+		"log.error": "darkred", "log.warn": "darkorange", "log.info": "teal", "log.debug": "dimgray",
+		"log.default": "black", "log.timestamp": "gray", "log.line": "dimgray",
+		"diff.changed": "darkorange",
+	},
+	NameSolarized: {
+		"ui.background": "#002b36", "ui.contrast": "#073642", "ui.border": "#586e75", "ui.title": "#93a1a1",
+		"ui.text": "#839496", "ui.secondary": "#b58900", "ui.inverse": "#073642",
+		"text": "#839496", "title": "#b58900", "muted": "#586e75", "dim": "#4f5f63",
+		"accent": "#2aa198", "accent.alt": "#268bd2", "success": "#859900", "error": "#dc322f", "warning": "#b58900",
+		"usage.low": "#859900", "usage.medium": "#b58900", "usage.high": "#dc322f", "bar.empty": "#073642",
+		"status.running": "#859900", "status.exited": "#dc322f", "status.stopped": "#dc322f", "status.dead": "#dc322f",
+		"status.paused": "#b58900", "status.created": "#268bd2", "status.restarting": "#cb4b16",
+		"status.removing": "#6c71c4", "status.unknown": "#839496",
+		"health.healthy": "#859900", "health.unhealthy": "#dc322f", "health.starting": "#b58900", "health.none": "#586e75",
+		"machine.online": "#859900", "machine.offline": "#dc322f", "machine.error": "#dc322f",
+		"log.error": "#dc322f", "log.warn": "#b58900", "log.info": "#2aa198", "log.debug": "#586e75",
+		"log.default": "#839496", "log.timestamp": "#586e75", "log.line": "#586e75",
+		"diff.changed": "#cb4b16",
+	},
+	NameHighContrast: {
+		"ui.background": "black", "ui.contrast": "white", "ui.border": "white", "ui.title": "yellow",
+		"ui.text": "white", "ui.secondary": "yellow", "ui.inverse": "black",
+		"text": "white", "title": "yellow::b", "muted": "white", "dim": "silver",
+		"accent": "aqua::b", "accent.alt": "aqua", "success": "lime::b", "error": "red::b", "warning": "yellow::b",
+		"usage.low": "lime", "usage.medium": "yellow", "usage.high": "red::b", "bar.empty": "white",
+		"status.running": "lime::b", "status.exited": "red::b", "status.stopped": "red::b", "status.dead": "red::b",
+		"status.paused": "yellow::b", "status.created": "aqua", "status.restarting": "fuchsia::b",
+		"status.removing": "fuchsia", "status.unknown": "white",
+		"health.healthy": "lime::b", "health.unhealthy": "red::b", "health.starting": "yellow::b", "health.none": "white",
+		"machine.online": "lime::b", "machine.offline": "red::b", "machine.error": "red::b",
+		"log.error": "red::b", "log.warn": "yellow::b", "log.info": "aqua", "log.debug": "silver",
+		"log.default": "white", "log.timestamp": "silver", "log.line": "silver",
+		"diff.changed": "fuchsia::b",
+	},
+}
+
+func Builtin(name string) *Theme {
+	colors := make(map[string]string, len(roles))
+	switch name {
+	case NameNoColor:
+		for _, role := range roles {
+			colors[role] = "-"
+		}
+		colors["diff.changed"] = "-::b"
+	default:
+		palette, ok := palettes[name]
+		if !ok {
+			return nil
+		}
+		for role, color := range palette {
+			colors[role] = color
+		}
+	}
+	return &Theme{Name: name, Colors: colors}
+}
@@ -0,0 +1,158 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	NameDark         = "dark"
+	NameLight        = "light"
+	NameSolarized    = "solarized"
+	NameHighContrast = "high-contrast"
+	NameNoColor      = "no-color"
+)
+
+type Theme struct {
+	Name   string
+	Colors map[string]string
+}
+
+var current = Builtin(NameDark)
+
+var roleTag = regexp.MustCompile(`\[([a-z][a-z0-9_\-]*(?:\.[a-z0-9_\-]+)*)\]`)
+
+// Load resolves the theme to use. NO_COLOR always wins; otherwise name is
+// looked up in the user's custom palettes first, then in the builtins.
+// Custom palettes only need to list the roles they change, and may not
+// set roles that no theme has.
+func Load(name string, custom map[string]map[string]string) (*Theme, error) {
+	if os.Getenv("NO_COLOR") != "" {
+		return Builtin(NameNoColor), nil
+	}
+	if name == "" {
+		name = NameDark
+	}
+
+	if colors, ok := custom[name]; ok {
+		base := NameDark
+		if extends, ok := colors["extends"]; ok {
+			base = extends
+		}
+		t := Builtin(base)
+		if t == nil {
+			return nil, fmt.Errorf("theme %q extends unknown theme %q", name, base)
+		}
+		t.Name = name
+		for role, color := range colors {
+			if role == "extends" {
+				continue
+			}
+			if !knownRole(role) {
+				return nil, fmt.Errorf("theme %q sets unknown role %q", name, role)
+			}
+			t.Colors[role] = color
+		}
+		return t, nil
+	}
+
+	if t := Builtin(name); t != nil {
+		return t, nil
+	}
+	return nil, fmt.Errorf("unknown theme %q (available: %v)", name, Names(custom))
+}
+
+func knownRole(role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func Names(custom map[string]map[string]string) []string {
+	names := []string{NameDark, NameLight, NameSolarized, NameHighContrast, NameNoColor}
+	var extra []string
+	for name := range custom {
+		if Builtin(name) == nil {
+			extra = append(extra, name)
+		}
+	}
+	sort.Strings(extra)
+	return append(names, extra...)
+}
+
+func Use(t *Theme) {
+	current = t
+	t.applyStyles()
+}
+
+func Current() *Theme {
+	return current
+}
+
+// Apply swaps role tags such as "[status.running]" or "[muted]" for the
+// color tags of the current theme. Roles without explicit attributes reset
+// them, so a bold role does not leak into the text that follows. Unknown
+// tags are left untouched so that regular tview tags keep working.
+func Apply(text string) string {
+	return current.Apply(text)
+}
+
+func Color(role string) string {
+	return current.Color(role)
+}
+
+func TcellColor(role string) tcell.Color {
+	return current.TcellColor(role)
+}
+
+func (t *Theme) Apply(text string) string {
+	return roleTag.ReplaceAllStringFunc(text, func(tag string) string {
+		role := tag[1 : len(tag)-1]
+		color, ok := t.Colors[role]
+		if !ok {
+			return tag
+		}
+		if !strings.Contains(color, ":") {
+			color += "::-"
+		}
+		return "[" + color + "]"
+	})
+}
+
+func (t *Theme) Color(role string) string {
+	if color, ok := t.Colors[role]; ok {
+		return color
+	}
+	return "-"
+}
+
+func (t *Theme) TcellColor(role string) tcell.Color {
+	color, _, _ := strings.Cut(t.Color(role), ":")
+	if color == "-" || color == "" {
+		return tcell.ColorDefault
+	}
+	return tcell.GetColor(color)
+}
+
+func (t *Theme) applyStyles() {
+	tview.Styles.PrimitiveBackgroundColor = t.TcellColor("ui.background")
+	tview.Styles.ContrastBackgroundColor = t.TcellColor("ui.contrast")
+	tview.Styles.MoreContrastBackgroundColor = t.TcellColor("ui.contrast")
+	tview.Styles.BorderColor = t.TcellColor("ui.border")
+	tview.Styles.TitleColor = t.TcellColor("ui.title")
+	tview.Styles.GraphicsColor = t.TcellColor("ui.border")
+	tview.Styles.PrimaryTextColor = t.TcellColor("ui.text")
+	tview.Styles.SecondaryTextColor = t.TcellColor("ui.secondary")
+	tview.Styles.TertiaryTextColor = t.TcellColor("ui.secondary")
+	tview.Styles.InverseTextColor = t.TcellColor("ui.inverse")
+	tview.Styles.ContrastSecondaryTextColor = t.TcellColor("ui.secondary")
+}
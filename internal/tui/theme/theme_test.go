@@ -0,0 +1,107 @@
+package theme
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuiltinsCoverEveryRole(t *testing.T) {
+	for _, name := range Names(nil) {
+		theme := Builtin(name)
+		for _, role := range roles {
+			if _, ok := theme.Colors[role]; !ok {
+				t.Errorf("%s has no color for %s", name, role)
+			}
+		}
+		if len(theme.Colors) != len(roles) {
+			t.Errorf("%s has %d colors for %d roles", name, len(theme.Colors), len(roles))
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	custom := map[string]map[string]string{
+		"ocean":  {"extends": NameSolarized, "status.running": "#00ffff", "title": "white::b"},
+		"tweak":  {"muted": "silver"},
+		NameDark: {"accent": "orange"},
+	}
+
+	tests := []struct {
+		name  string
+		theme string
+		role  string
+		want  string
+	}{
+		{"default is dark", "", "status.running", "green"},
+		{"builtin", NameLight, "status.running", "darkgreen"},
+		{"override", "ocean", "status.running", "#00ffff"},
+		{"role kept from the extended theme", "ocean", "status.exited", "#dc322f"},
+		{"extends dark by default", "tweak", "status.running", "green"},
+		{"user palette named after a builtin", NameDark, "accent", "orange"},
+		{"rest of that builtin", NameDark, "error", "red"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theme, err := Load(tt.theme, custom)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := theme.Color(tt.role); got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.role, got, tt.want)
+			}
+		})
+	}
+
+	// Loading a user palette leaves the builtin it extends untouched.
+	if got := Builtin(NameSolarized).Color("status.running"); got != "#859900" {
+		t.Errorf("solarized status.running = %q after loading ocean", got)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		theme  string
+		custom map[string]map[string]string
+		want   string
+	}{
+		{"unknown theme", "neon", nil, `unknown theme "neon"`},
+		{"unknown base", "neon", map[string]map[string]string{"neon": {"extends": "retro"}}, `theme "neon" extends unknown theme "retro"`},
+		{"unknown role", "neon", map[string]map[string]string{"neon": {"status.zombie": "green"}}, `theme "neon" sets unknown role "status.zombie"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.theme, tt.custom)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load() = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	custom := map[string]map[string]string{"ocean": {"status.running": "#00ffff"}}
+	for _, name := range []string{"", NameSolarized, "ocean", "neon"} {
+		theme, err := Load(name, custom)
+		if err != nil {
+			t.Fatalf("Load(%q) = %v, want monochrome", name, err)
+		}
+		if theme.Name != NameNoColor {
+			t.Errorf("Load(%q) = %s, want %s", name, theme.Name, NameNoColor)
+		}
+	}
+
+	theme, _ := Load(NameDark, nil)
+	if got := theme.Apply("[status.running]up[-] [diff.changed]new"); got != "[-::-]up[-] [-::b]new" {
+		t.Errorf("Apply() = %q, want colors reset and only bold kept", got)
+	}
+}
+
+func TestApplyLeavesUnknownTags(t *testing.T) {
+	theme := Builtin(NameHighContrast)
+	if got := theme.Apply("[error]down [yellow]raw[-] [no.such.role]x"); got != "[red::b]down [yellow]raw[-] [no.such.role]x" {
+		t.Errorf("Apply() = %q", got)
+	}
+}
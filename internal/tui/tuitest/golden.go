@@ -0,0 +1,58 @@
+package tuitest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Golden compares got, with clock times and dates masked, to
+// testdata/<name>.golden relative to the test's package. With update set,
+// as tests do from their own -update flag, it rewrites the file instead.
+func Golden(tb testing.TB, name, got string, update bool) {
+	tb.Helper()
+	got = Mask(got)
+
+	path := filepath.Join("testdata", name+".golden")
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		tb.Fatalf("%v (run with -update to create it)", err)
+	}
+	if string(want) != got {
+		tb.Errorf("%s does not match (run with -update to accept):\n%s", path, diff(string(want), got))
+	}
+}
+
+// diff lists the lines that differ, with the golden line first.
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var out strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w == g {
+			continue
+		}
+		fmt.Fprintf(&out, "line %d:\n- %s\n+ %s\n", i+1, w, g)
+	}
+	return out.String()
+}
@@ -0,0 +1,293 @@
+// Package tuitest runs the TUI headless on a simulated screen, drives it with
+// scripted keys and compares what it renders to golden files.
+package tuitest
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kqnd/kernus/internal/docker"
+	"github.com/kqnd/kernus/internal/docker/fake"
+	"github.com/kqnd/kernus/internal/tui"
+	"github.com/kqnd/kernus/internal/tui/theme"
+	"github.com/rivo/tview"
+)
+
+const (
+	Width  = 120
+	Height = 40
+
+	startTimeout = 5 * time.Second
+	settleDelay  = 5 * time.Millisecond
+	maxSettles   = 40
+)
+
+// sentinel is injected after every scripted key. Events are handled in
+// order, so once it comes through the keys before it have been handled.
+var sentinel = tcell.KeyF64
+
+// Clock times and dates change from run to run, so they are masked.
+var (
+	datePattern  = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	clockPattern = regexp.MustCompile(`\d{2}:\d{2}:\d{2}(\.\d+)?`)
+)
+
+// Harness is a running App on a simulated screen.
+type Harness struct {
+	tb     testing.TB
+	app    *tui.App
+	screen tcell.SimulationScreen
+	done   chan error
+	synced chan struct{}
+}
+
+// New starts an App with the given config on a simulated screen of the
+// given size and stops it when the test ends. Without runtimes the App
+// connects to a single fake runtime serving the mock containers. Unless set,
+// the refresh rate is an hour so containers only reload when asked to.
+func New(tb testing.TB, config *tui.Config, width, height int) *Harness {
+	tb.Helper()
+
+	if config == nil {
+		config = &tui.Config{}
+	}
+	if config.Server == "" {
+		config.Server = "test"
+	}
+	if len(config.Runtimes) == 0 {
+		config.Runtimes = []docker.ContainerRuntime{fake.NewRuntime("local")}
+	}
+	if config.RefreshRate == 0 {
+		config.RefreshRate = time.Hour
+	}
+
+	screen := tcell.NewSimulationScreen("UTF-8")
+	config.Screen = screen
+
+	h := &Harness{
+		tb:     tb,
+		app:    tui.NewApp(config),
+		screen: screen,
+		done:   make(chan error, 1),
+		synced: make(chan struct{}, 1),
+	}
+	go func() {
+		h.done <- h.app.Run()
+	}()
+
+	started := make(chan struct{})
+	go h.app.Application().QueueUpdate(func() {
+		application := h.app.Application()
+		capture := application.GetInputCapture()
+		application.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+			if event.Key() == sentinel {
+				h.synced <- struct{}{}
+				return nil
+			}
+			if capture != nil {
+				return capture(event)
+			}
+			return event
+		})
+		screen.SetSize(width, height)
+		close(started)
+	})
+
+	select {
+	case <-started:
+	case err := <-h.done:
+		tb.Fatalf("tui did not start: %v", err)
+	case <-time.After(startTimeout):
+		tb.Fatal("tui did not start in time")
+	}
+	tb.Cleanup(h.Close)
+
+	screen.PostEvent(tcell.NewEventResize(width, height))
+	h.sync()
+	return h
+}
+
+// App returns the App under test.
+func (h *Harness) App() *tui.App {
+	return h.app
+}
+
+// Press injects special keys, such as tcell.KeyTab or tcell.KeyEnter, one
+// after another.
+func (h *Harness) Press(keys ...tcell.Key) {
+	h.tb.Helper()
+	for _, key := range keys {
+		h.screen.InjectKey(key, 0, tcell.ModNone)
+		h.sync()
+	}
+}
+
+// Type injects the runes of text as key presses.
+func (h *Harness) Type(text string) {
+	h.tb.Helper()
+	for _, r := range text {
+		h.screen.InjectKey(tcell.KeyRune, r, tcell.ModNone)
+		h.sync()
+	}
+}
+
+// Key injects a single key with modifiers, such as ctrl+d.
+func (h *Harness) Key(key tcell.Key, r rune, mod tcell.ModMask) {
+	h.tb.Helper()
+	h.screen.InjectKey(key, r, mod)
+	h.sync()
+}
+
+// Wait lets background work finish, such as a container action that
+// refreshes the list after a short delay.
+func (h *Harness) Wait(d time.Duration) {
+	h.tb.Helper()
+	time.Sleep(d)
+	h.settle()
+}
+
+// Screen returns the rendered screen as text, one line per row without
+// trailing spaces. Clock times and dates are masked.
+func (h *Harness) Screen() string {
+	h.settle()
+	return Mask(h.contents())
+}
+
+// Golden compares the rendered screen to testdata/<name>.golden, or
+// rewrites it when update is set.
+func (h *Harness) Golden(name string, update bool) {
+	h.tb.Helper()
+	Golden(h.tb, name, h.Screen(), update)
+}
+
+// Close stops the App and waits for it to exit.
+func (h *Harness) Close() {
+	select {
+	case err := <-h.done:
+		h.done <- err
+		return
+	default:
+	}
+
+	h.app.Stop()
+	select {
+	case err := <-h.done:
+		h.done <- err
+		if err != nil {
+			h.tb.Errorf("tui exited with error: %v", err)
+		}
+	case <-time.After(startTimeout):
+		h.tb.Error("tui did not stop in time")
+	}
+}
+
+// sync waits until every event injected so far has been handled, then lets
+// the updates they queued settle.
+func (h *Harness) sync() {
+	h.tb.Helper()
+	h.screen.InjectKey(sentinel, 0, tcell.ModNone)
+	select {
+	case <-h.synced:
+	case err := <-h.done:
+		h.done <- err
+		return
+	case <-time.After(startTimeout):
+		h.tb.Fatal("tui stopped handling keys")
+	}
+	h.settle()
+}
+
+// settle redraws until two screens in a row are the same, since handlers
+// may queue updates from goroutines of their own.
+func (h *Harness) settle() {
+	previous := h.contents()
+	for i := 0; i < maxSettles; i++ {
+		if !h.draw() {
+			return
+		}
+		current := h.contents()
+		if current == previous {
+			return
+		}
+		previous = current
+	}
+}
+
+// draw redraws the screen and reports whether the App is still running.
+func (h *Harness) draw() bool {
+	time.Sleep(settleDelay)
+	drawn := make(chan struct{})
+	go h.app.Application().QueueUpdateDraw(func() {
+		close(drawn)
+	})
+	select {
+	case <-drawn:
+		return true
+	case err := <-h.done:
+		h.done <- err
+		return false
+	}
+}
+
+// contents reads the screen on the event loop, since background refreshes
+// and the header clock redraw it in place on their own schedule.
+func (h *Harness) contents() string {
+	var text string
+	read := make(chan struct{})
+	go h.app.Application().QueueUpdate(func() {
+		text = h.render()
+		close(read)
+	})
+	select {
+	case <-read:
+		return text
+	case err := <-h.done:
+		h.done <- err
+		return h.render()
+	}
+}
+
+func (h *Harness) render() string {
+	cells, width, height := h.screen.GetContents()
+
+	var out strings.Builder
+	for row := 0; row < height; row++ {
+		var line strings.Builder
+		for col := 0; col < width; col++ {
+			runes := cells[row*width+col].Runes
+			if len(runes) == 0 {
+				line.WriteRune(' ')
+				continue
+			}
+			line.WriteString(string(runes))
+		}
+		out.WriteString(strings.TrimRight(line.String(), " "))
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+// Mask hides clock times and dates in text. Fractions of a second keep
+// their width so that columns after them stay in place.
+func Mask(text string) string {
+	text = datePattern.ReplaceAllString(text, "YYYY-MM-DD")
+	return clockPattern.ReplaceAllStringFunc(text, func(clock string) string {
+		if fraction := len(clock) - len("hh:mm:ss."); fraction > 0 {
+			return "hh:mm:ss." + strings.Repeat("s", fraction)
+		}
+		return "hh:mm:ss"
+	})
+}
+
+// Plain renders text with the theme and tview tags stripped, the way a tab's
+// Render output reads on screen.
+func Plain(text string) string {
+	return tview.NewTextView().
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetText(theme.Apply(text)).
+		GetText(true)
+}